@@ -0,0 +1,339 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/core/templates"
+	"kool-dev/kool/services/compose"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// KoolAddFlags holds the flags for the kool add command
+type KoolAddFlags struct {
+	List    bool
+	Replace bool
+}
+
+// KoolAdd holds handlers and functions to implement the add command logic
+type KoolAdd struct {
+	DefaultKoolService
+	Flags *KoolAddFlags
+
+	presetsParser  presets.Parser
+	templateParser templates.Parser
+	composeParser  compose.Parser
+	koolYamlParser parser.KoolYamlParser
+	env            environment.EnvStorage
+	sources        *presetSources
+	table          shell.TableWriter
+}
+
+func AddKoolAdd(root *cobra.Command) {
+	var (
+		add    = NewKoolAdd()
+		addCmd = NewAddCommand(add)
+	)
+
+	root.AddCommand(addCmd)
+}
+
+// NewKoolAdd creates a new handler for adding templates with default dependencies
+func NewKoolAdd() *KoolAdd {
+	return &KoolAdd{
+		*newDefaultKoolService(),
+		&KoolAddFlags{false, false},
+		presets.NewParser(),
+		templates.NewParser(),
+		compose.NewParser(),
+		&parser.KoolYaml{},
+		environment.NewEnvStorage(),
+		newPresetSources(),
+		shell.NewTableWriter(),
+	}
+}
+
+// Execute runs the add logic with incoming arguments.
+func (a *KoolAdd) Execute(args []string) (err error) {
+	var (
+		content       []byte
+		added         []string
+		addedServices bool
+		addedScripts  bool
+	)
+
+	a.sources.Load(a.presetsParser, a)
+
+	if a.Flags.List {
+		a.list()
+		return
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("missing the template to add; use kool add --list to see the available templates")
+		return
+	}
+
+	if content, err = os.ReadFile("docker-compose.yml"); err == nil {
+		err = a.composeParser.Parse(string(content))
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed to read docker-compose.yml: %v", err)
+		return
+	}
+
+	if _, statErr := os.Stat("kool.yml"); statErr == nil {
+		if err = a.koolYamlParser.Parse("kool.yml"); err != nil {
+			err = fmt.Errorf("failed to read kool.yml: %v", err)
+			return
+		}
+	}
+
+	for _, name := range args {
+		var template string
+
+		if template, err = a.template(name); err != nil {
+			return
+		}
+
+		if err = a.templateParser.Parse(template); err != nil {
+			err = fmt.Errorf("failed to parse template %s: %v", name, err)
+			return
+		}
+
+		services, scripts := a.addServices(), a.addScripts()
+
+		if services || scripts {
+			added = append(added, name)
+		}
+
+		addedServices = addedServices || services
+		addedScripts = addedScripts || scripts
+	}
+
+	if addedServices {
+		var composeContent string
+
+		if composeContent, err = a.composeParser.String(); err != nil {
+			err = fmt.Errorf("failed to write docker-compose.yml: %v", err)
+			return
+		}
+
+		if err = os.WriteFile("docker-compose.yml", []byte(composeContent), os.ModePerm); err != nil {
+			err = fmt.Errorf("failed to write docker-compose.yml: %v", err)
+			return
+		}
+	}
+
+	if addedScripts {
+		var koolYaml string
+
+		if koolYaml, err = a.koolYamlParser.String(); err != nil {
+			err = fmt.Errorf("failed to write kool.yml: %v", err)
+			return
+		}
+
+		if err = os.WriteFile("kool.yml", []byte(koolYaml), os.ModePerm); err != nil {
+			err = fmt.Errorf("failed to write kool.yml: %v", err)
+			return
+		}
+	}
+
+	if addedServices {
+		a.Success("Added ", strings.Join(added, ", "), "; run kool start to start the new services.")
+	} else if addedScripts {
+		a.Success("Added ", strings.Join(added, ", "))
+	}
+
+	return
+}
+
+// template returns the content of the template given as FOLDER:NAME,
+// rendered with the project name and language version
+func (a *KoolAdd) template(name string) (template string, err error) {
+	var (
+		parts = strings.SplitN(name, ":", 2)
+		found bool
+	)
+
+	if len(parts) == 2 {
+		template, found = a.presetsParser.GetTemplates()[parts[0]][strings.TrimSuffix(parts[1], ".yml")+".yml"]
+	}
+
+	if !found {
+		err = fmt.Errorf("unknown template %s; use kool add --list to see the available templates", name)
+		return
+	}
+
+	language := presets.DetectLanguage()
+
+	if template, err = presets.Render(template, presets.RenderData{
+		Name:     a.env.Get("KOOL_NAME"),
+		Language: language,
+		Version:  presets.DetectVersion(language),
+	}); err != nil {
+		err = fmt.Errorf("failed to render template %s: %v", name, err)
+	}
+
+	return
+}
+
+// addServices adds the services and volumes of the parsed template onto
+// docker-compose.yml, along with the kool networks they use. Existing
+// services are kept, unless replacing them.
+func (a *KoolAdd) addServices() (added bool) {
+	var networks = make(map[string]bool)
+
+	for _, service := range a.templateParser.GetServices() {
+		name := fmt.Sprint(service.Key)
+
+		if a.composeParser.HasService(name) && !a.Flags.Replace {
+			a.Warning("Keeping the existing ", name, " service on docker-compose.yml; use --replace to replace it")
+			continue
+		}
+
+		a.composeParser.SetService(name, service.Value)
+		added = true
+
+		for _, network := range serviceNetworks(service.Value) {
+			networks[network] = true
+		}
+	}
+
+	if !added {
+		return
+	}
+
+	for _, volume := range a.templateParser.GetVolumes() {
+		a.composeParser.SetVolume(fmt.Sprint(volume.Key), volume.Value)
+	}
+
+	for _, network := range compose.DefaultNetworks() {
+		if networks[fmt.Sprint(network.Key)] {
+			a.composeParser.SetNetwork(fmt.Sprint(network.Key), network.Value)
+		}
+	}
+
+	return
+}
+
+// addScripts adds the scripts of the parsed template onto kool.yml;
+// existing scripts are kept, unless replacing them
+func (a *KoolAdd) addScripts() (added bool) {
+	for name, commands := range a.templateParser.GetScripts() {
+		if a.koolYamlParser.HasScript(name) && !a.Flags.Replace {
+			a.Warning("Keeping the existing ", name, " script on kool.yml; use --replace to replace it")
+			continue
+		}
+
+		a.koolYamlParser.SetScript(name, commands)
+		added = true
+	}
+
+	return
+}
+
+// list prints the templates catalog
+func (a *KoolAdd) list() {
+	var names []string
+
+	for folder, files := range a.presetsParser.GetTemplates() {
+		for file := range files {
+			names = append(names, folder+":"+strings.TrimSuffix(file, ".yml"))
+		}
+	}
+
+	if len(names) == 0 {
+		a.Warning("No templates found")
+		return
+	}
+
+	sort.Strings(names)
+
+	a.table.SetWriter(a.OutStream())
+	a.table.AppendHeader("Template", "Services", "Scripts")
+
+	for _, name := range names {
+		var (
+			parts   = strings.SplitN(name, ":", 2)
+			file    templates.TemplateFile
+			scripts []string
+		)
+
+		_ = yaml.Unmarshal([]byte(a.presetsParser.GetTemplates()[parts[0]][parts[1]+".yml"]), &file)
+
+		for script := range file.Scripts {
+			scripts = append(scripts, script)
+		}
+
+		sort.Strings(scripts)
+
+		a.table.AppendRow(name, strings.Join(mapSliceKeys(file.Services), ", "), strings.Join(scripts, ", "))
+	}
+
+	a.table.Render()
+}
+
+// serviceNetworks returns the networks of the docker-compose service,
+// declared either as a list or as a map
+func serviceNetworks(service interface{}) (networks []string) {
+	settings, isMap := service.(yaml.MapSlice)
+
+	if !isMap {
+		return
+	}
+
+	for _, setting := range settings {
+		if setting.Key != "networks" {
+			continue
+		}
+
+		switch value := setting.Value.(type) {
+		case []interface{}:
+			for _, network := range value {
+				networks = append(networks, fmt.Sprint(network))
+			}
+		case yaml.MapSlice:
+			networks = mapSliceKeys(value)
+		}
+	}
+
+	return
+}
+
+func mapSliceKeys(items yaml.MapSlice) (keys []string) {
+	for _, item := range items {
+		keys = append(keys, fmt.Sprint(item.Key))
+	}
+
+	return
+}
+
+// NewAddCommand initializes new kool add command
+func NewAddCommand(add *KoolAdd) (addCmd *cobra.Command) {
+	addCmd = &cobra.Command{
+		Use:   "add [TEMPLATE...]",
+		Short: "Add services from the templates catalog to the current project",
+		Long: `Add the services, volumes and scripts of each TEMPLATE (as FOLDER:NAME, i.e.
+database:postgresql13 or cache:redis6) to the docker-compose.yml and kool.yml of
+the current working directory. Existing services and scripts are kept, unless
+--replace is used. Use --list to see the available templates.`,
+		RunE: DefaultCommandRunFunction(add),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	addCmd.Flags().BoolVarP(&add.Flags.List, "list", "l", false, "List the available templates.")
+	addCmd.Flags().BoolVarP(&add.Flags.Replace, "replace", "", false, "Replace existing services and scripts with the ones from the template.")
+	return
+}
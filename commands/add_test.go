@@ -0,0 +1,335 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/core/templates"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func newFakeKoolAdd() *KoolAdd {
+	return &KoolAdd{
+		*newFakeKoolService(),
+		&KoolAddFlags{false, false},
+		&presets.FakeParser{},
+		&templates.FakeParser{},
+		&compose.FakeParser{},
+		&parser.FakeKoolYaml{},
+		environment.NewFakeEnvStorage(),
+		newFakePresetSources(),
+		&shell.FakeTableWriter{},
+	}
+}
+
+// newTempKoolAdd returns a KoolAdd with real parsers, running on a temporary folder
+func newTempKoolAdd(t *testing.T) *KoolAdd {
+	var (
+		add = newFakeKoolAdd()
+		dir = t.TempDir()
+	)
+
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	add.presetsParser = presets.NewParser()
+	add.templateParser = templates.NewParser()
+	add.composeParser = compose.NewParser()
+	add.koolYamlParser = &parser.KoolYaml{}
+
+	return add
+}
+
+func TestNewKoolAdd(t *testing.T) {
+	k := NewKoolAdd()
+
+	if _, ok := k.DefaultKoolService.shell.(*shell.DefaultShell); !ok {
+		t.Errorf("unexpected shell.Shell on default KoolAdd instance")
+	}
+
+	if k.Flags == nil || k.Flags.List || k.Flags.Replace {
+		t.Errorf("unexpected default flags on KoolAdd instance")
+	}
+
+	if _, ok := k.presetsParser.(*presets.DefaultParser); !ok {
+		t.Errorf("unexpected presets.Parser on default KoolAdd instance")
+	}
+
+	if _, ok := k.templateParser.(*templates.DefaultParser); !ok {
+		t.Errorf("unexpected templates.Parser on default KoolAdd instance")
+	}
+
+	if _, ok := k.composeParser.(*compose.DefaultParser); !ok {
+		t.Errorf("unexpected compose.Parser on default KoolAdd instance")
+	}
+
+	if _, ok := k.koolYamlParser.(*parser.KoolYaml); !ok {
+		t.Errorf("unexpected parser.KoolYamlParser on default KoolAdd instance")
+	}
+
+	if _, ok := k.table.(*shell.DefaultTableWriter); !ok {
+		t.Errorf("unexpected shell.TableWriter on default KoolAdd instance")
+	}
+}
+
+func TestAddToExistingProject(t *testing.T) {
+	add := newTempKoolAdd(t)
+
+	_ = os.WriteFile("docker-compose.yml", []byte(`services:
+  app:
+    image: my-app
+    networks:
+    - kool_local
+networks:
+  kool_local: null
+`), os.ModePerm)
+	_ = os.WriteFile("kool.yml", []byte("scripts:\n  npm: npm\n"), os.ModePerm)
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6", "scripts:yarn.yml"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error adding templates: %v", err)
+	}
+
+	content, _ := os.ReadFile("docker-compose.yml")
+
+	expected := `services:
+  app:
+    image: my-app
+    networks:
+    - kool_local
+  cache:
+    image: redis:6-alpine
+    volumes:
+    - cache:/data:delegated
+    networks:
+    - kool_local
+    healthcheck:
+      test:
+      - CMD
+      - redis-cli
+      - ping
+volumes:
+  cache: null
+networks:
+  kool_local: null
+`
+
+	if string(content) != expected {
+		t.Errorf("expected docker-compose.yml '%s', got '%s'", expected, content)
+	}
+
+	koolYaml, _ := parser.ParseKoolYaml("kool.yml")
+
+	if koolYaml.Scripts["npm"] != "npm" || koolYaml.Scripts["yarn"] == nil {
+		t.Errorf("unexpected kool.yml scripts: %v", koolYaml.Scripts)
+	}
+
+	if expected := "Added cache:redis6, scripts:yarn.yml; run kool start to start the new services."; fmt.Sprint(add.shell.(*shell.FakeShell).SuccessOutput...) != expected {
+		t.Errorf("expected success message '%s', got '%s'", expected, fmt.Sprint(add.shell.(*shell.FakeShell).SuccessOutput...))
+	}
+}
+
+func TestAddToNewProject(t *testing.T) {
+	add := newTempKoolAdd(t)
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error adding template: %v", err)
+	}
+
+	var written compose.Compose
+
+	content, _ := os.ReadFile("docker-compose.yml")
+	_ = yaml.Unmarshal(content, &written)
+
+	if written.Version != "3.7" || !reflect.DeepEqual(mapSliceKeys(written.Services), []string{"cache"}) || !reflect.DeepEqual(mapSliceKeys(written.Networks), []string{"kool_local", "kool_global"}) {
+		t.Errorf("unexpected docker-compose.yml: %s", content)
+	}
+
+	if _, err := os.Stat("kool.yml"); !os.IsNotExist(err) {
+		t.Error("should not create kool.yml without scripts")
+	}
+}
+
+func TestAddRendersTemplate(t *testing.T) {
+	add := newTempKoolAdd(t)
+
+	_ = os.WriteFile("composer.json", []byte(`{"require": {"php": "^8.1"}}`), os.ModePerm)
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"app:php"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error adding template: %v", err)
+	}
+
+	if content, _ := os.ReadFile("docker-compose.yml"); !strings.Contains(string(content), "image: kooldev/php:8.1-nginx") {
+		t.Errorf("expected the PHP version from composer.json, got %s", content)
+	}
+
+	presetsDir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(presetsDir, "templates", "app"), os.ModePerm)
+	_ = os.WriteFile(filepath.Join(presetsDir, "templates", "app", "broken.yml"), []byte("{{ .Name"), os.ModePerm)
+	add.sources.env.Set("KOOL_PRESETS_PATH", presetsDir)
+
+	cmd = NewAddCommand(add)
+	cmd.SetArgs([]string{"app:broken"})
+	assertExecGotError(t, cmd, "failed to render template app:broken")
+}
+
+func TestAddExistingService(t *testing.T) {
+	add := newTempKoolAdd(t)
+
+	_ = os.WriteFile("docker-compose.yml", []byte("services:\n  cache:\n    image: memcached\n"), os.ModePerm)
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error adding template: %v", err)
+	}
+
+	if content, _ := os.ReadFile("docker-compose.yml"); string(content) != "services:\n  cache:\n    image: memcached\n" {
+		t.Errorf("should keep existing service, got %s", content)
+	}
+
+	if warning := fmt.Sprint(add.shell.(*shell.FakeShell).WarningOutput...); warning != "Keeping the existing cache service on docker-compose.yml; use --replace to replace it" {
+		t.Errorf("unexpected warning: %s", warning)
+	}
+
+	cmd = NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6", "--replace"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error replacing template: %v", err)
+	}
+
+	if content, _ := os.ReadFile("docker-compose.yml"); !strings.Contains(string(content), "redis:6-alpine") || strings.Contains(string(content), "memcached") {
+		t.Errorf("should replace existing service, got %s", content)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	add := newTempKoolAdd(t)
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{})
+	assertExecGotError(t, cmd, "missing the template to add")
+
+	cmd = NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:unknown"})
+	assertExecGotError(t, cmd, "unknown template cache:unknown")
+
+	cmd = NewAddCommand(add)
+	cmd.SetArgs([]string{"redis6"})
+	assertExecGotError(t, cmd, "unknown template redis6")
+
+	_ = os.WriteFile("docker-compose.yml", []byte("services: ["), os.ModePerm)
+
+	cmd = NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6"})
+	assertExecGotError(t, cmd, "failed to read docker-compose.yml")
+}
+
+func TestAddTemplateParseError(t *testing.T) {
+	add := newTempKoolAdd(t)
+	add.templateParser = &templates.FakeParser{MockParseError: errors.New("parse error")}
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"cache:redis6"})
+
+	assertExecGotError(t, cmd, "failed to parse template cache:redis6: parse error")
+
+	if add.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("should not report failed templates as added")
+	}
+}
+
+func TestAddList(t *testing.T) {
+	add := newFakeKoolAdd()
+	add.presetsParser = presets.NewParser()
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"--list"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error listing templates: %v", err)
+	}
+
+	table := add.table.(*shell.FakeTableWriter)
+
+	if !table.CalledRender || len(table.Headers) != 1 || !reflect.DeepEqual(table.Headers[0], []interface{}{"Template", "Services", "Scripts"}) {
+		t.Errorf("unexpected templates table: %v", table.Headers)
+	}
+
+	var found bool
+
+	for _, row := range table.Rows {
+		if row[0] == "cache:redis6" {
+			found = true
+
+			if row[1] != "cache" || row[2] != "" {
+				t.Errorf("unexpected row for cache:redis6: %v", row)
+			}
+		}
+
+		if row[0] == "scripts:npm" && !strings.Contains(row[2].(string), "npm, npx") {
+			t.Errorf("unexpected row for scripts:npm: %v", row)
+		}
+	}
+
+	if !found {
+		t.Errorf("cache:redis6 not listed: %v", table.Rows)
+	}
+}
+
+func TestAddListEmpty(t *testing.T) {
+	add := newFakeKoolAdd()
+
+	cmd := NewAddCommand(add)
+	cmd.SetArgs([]string{"--list"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error listing templates: %v", err)
+	}
+
+	if !add.shell.(*shell.FakeShell).CalledWarning || add.table.(*shell.FakeTableWriter).CalledRender {
+		t.Error("expected warning without templates")
+	}
+}
+
+func TestServiceNetworks(t *testing.T) {
+	list := yaml.MapSlice{yaml.MapItem{Key: "networks", Value: []interface{}{"kool_local", "kool_global"}}}
+
+	if networks := serviceNetworks(list); !reflect.DeepEqual(networks, []string{"kool_local", "kool_global"}) {
+		t.Errorf("unexpected networks from list: %v", networks)
+	}
+
+	mapped := yaml.MapSlice{yaml.MapItem{Key: "networks", Value: yaml.MapSlice{yaml.MapItem{Key: "kool_local", Value: nil}}}}
+
+	if networks := serviceNetworks(mapped); !reflect.DeepEqual(networks, []string{"kool_local"}) {
+		t.Errorf("unexpected networks from map: %v", networks)
+	}
+
+	if networks := serviceNetworks("invalid"); len(networks) != 0 {
+		t.Errorf("unexpected networks: %v", networks)
+	}
+}
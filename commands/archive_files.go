@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeArchiveFile writes onto the given file, gzip compressed when asked to;
+// the content goes to a temporary file first so that the target file is only
+// replaced when writing succeeds
+func writeArchiveFile(path string, compress bool, write func(io.Writer) error) (err error) {
+	var (
+		tmp *os.File
+		out io.Writer
+		gz  *gzip.Writer
+	)
+
+	if tmp, err = os.CreateTemp(filepath.Dir(path), ".kool-*"); err != nil {
+		return
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if out = tmp; compress {
+		gz = gzip.NewWriter(tmp)
+		out = gz
+	}
+
+	if err = write(out); err == nil && gz != nil {
+		err = gz.Close()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	return
+}
+
+// readArchiveFile reads the given file, transparently
+// decompressing it when gzip compressed
+func readArchiveFile(path string, read func(io.Reader) error) (err error) {
+	var (
+		file   *os.File
+		reader *bufio.Reader
+		in     io.Reader
+		magic  []byte
+	)
+
+	if file, err = os.Open(path); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	reader = bufio.NewReader(file)
+	in = reader
+
+	if magic, _ = reader.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		var gz *gzip.Reader
+
+		if gz, err = gzip.NewReader(reader); err != nil {
+			return
+		}
+
+		defer gz.Close()
+		in = gz
+	}
+
+	err = read(in)
+	return
+}
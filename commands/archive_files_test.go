@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, compress := range []bool{false, true} {
+		var (
+			path = filepath.Join(dir, "archive")
+			read []byte
+		)
+
+		if err := writeArchiveFile(path, compress, func(w io.Writer) error {
+			_, err := w.Write([]byte("content"))
+			return err
+		}); err != nil {
+			t.Fatalf("unexpected error writing archive file: %v", err)
+		}
+
+		if file, err := os.Open(path); err == nil {
+			_, gzErr := gzip.NewReader(file)
+			if compress != (gzErr == nil) {
+				t.Errorf("unexpected compression on archive file (compress: %v)", compress)
+			}
+			file.Close()
+		}
+
+		if err := readArchiveFile(path, func(r io.Reader) (err error) {
+			read, err = io.ReadAll(r)
+			return
+		}); err != nil {
+			t.Fatalf("unexpected error reading archive file: %v", err)
+		}
+
+		if string(read) != "content" {
+			t.Errorf("expected to read 'content', got '%s'", read)
+		}
+	}
+}
+
+func TestArchiveFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive")
+
+	_ = os.WriteFile(path, []byte("previous"), os.ModePerm)
+
+	if err := writeArchiveFile(path, true, func(w io.Writer) error {
+		return errors.New("write error")
+	}); err == nil || err.Error() != "write error" {
+		t.Errorf("expected write error, got %v", err)
+	}
+
+	if content, _ := os.ReadFile(path); string(content) != "previous" {
+		t.Error("should not replace the file when writing fails")
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Error("should not leave temporary files behind")
+	}
+
+	if err := writeArchiveFile(filepath.Join(dir, "missing", "archive"), false, func(w io.Writer) error {
+		return nil
+	}); err == nil {
+		t.Error("expected error writing to a missing folder")
+	}
+
+	_ = os.WriteFile(path, []byte{0x1f, 0x8b, 0x00}, os.ModePerm)
+
+	if err := readArchiveFile(path, func(r io.Reader) error { return nil }); err == nil {
+		t.Error("expected error reading broken gzip file")
+	}
+
+	if err := readArchiveFile(filepath.Join(dir, "missing"), func(r io.Reader) error { return nil }); err == nil {
+		t.Error("expected error reading missing file")
+	}
+}
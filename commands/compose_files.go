@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+)
+
+// composeFile holds the content of a docker-compose file in use
+type composeFile struct {
+	path    string
+	content string
+}
+
+// readComposeFiles reads the docker-compose files being layered, or the
+// docker-compose defaults, relative to the given directory; missing
+// files are skipped as docker-compose itself will complain about them
+func readComposeFiles(aware compose.FilesAware, dir string) (files []composeFile, err error) {
+	var (
+		paths   []string
+		content []byte
+	)
+
+	if paths, err = aware.Files(); err != nil {
+		return
+	}
+
+	if len(paths) == 0 {
+		paths = []string{"docker-compose.yml", "docker-compose.override.yml"}
+	}
+
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		if content, err = os.ReadFile(path); err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+				continue
+			}
+			return
+		}
+
+		files = append(files, composeFile{path, string(content)})
+	}
+
+	return
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadComposeFiles(t *testing.T) {
+	dir := t.TempDir()
+	env := environment.NewFakeEnvStorage()
+	env.Set("PWD", dir)
+
+	aware := compose.NewDockerCompose("config")
+	aware.SetEnv(env)
+	aware.SetKoolParser(&parser.FakeParser{})
+
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := readComposeFiles(aware, dir)
+
+	if err != nil {
+		t.Errorf("unexpected error reading compose files: %v", err)
+	}
+
+	if len(files) != 1 || files[0].path != filepath.Join(dir, "docker-compose.yml") || files[0].content != "services: {}" {
+		t.Errorf("unexpected compose files read: %v", files)
+	}
+
+	env.Set("KOOL_COMPOSE_FILES", filepath.Join(dir, "docker-compose.yml")+string(filepath.ListSeparator)+"docker-compose.ci.yml")
+
+	if files, err = readComposeFiles(aware, dir); err != nil || len(files) != 1 {
+		t.Errorf("unexpected compose files read from KOOL_COMPOSE_FILES: %v (%v)", files, err)
+	}
+}
+
+func TestReadComposeFilesError(t *testing.T) {
+	env := environment.NewFakeEnvStorage()
+	env.Set("PWD", t.TempDir())
+	env.Set("KOOL_COMPOSE_ENV", "ci")
+
+	aware := compose.NewDockerCompose("config")
+	aware.SetEnv(env)
+	aware.SetKoolParser(&parser.FakeParser{MockParseComposeFilesError: map[string]error{"ci": errors.New("parse error")}})
+
+	if _, err := readComposeFiles(aware, env.Get("PWD")); err == nil || err.Error() != "parse error" {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
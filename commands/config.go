@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/services/compose"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolConfigFlags holds the flags for the kool config command
+type KoolConfigFlags struct {
+	Files    bool
+	Services bool
+}
+
+// KoolConfig holds handlers and functions to implement the config command logic
+type KoolConfig struct {
+	DefaultKoolService
+	Flags *KoolConfigFlags
+
+	composeConfig builder.Command
+}
+
+func AddKoolConfig(root *cobra.Command) {
+	var (
+		config    = NewKoolConfig()
+		configCmd = NewConfigCommand(config)
+	)
+
+	root.AddCommand(configCmd)
+}
+
+// NewKoolConfig creates a new handler for config logic with default dependencies
+func NewKoolConfig() *KoolConfig {
+	return &KoolConfig{
+		*newDefaultKoolService(),
+		&KoolConfigFlags{false, false},
+		compose.NewDockerCompose("config"),
+	}
+}
+
+// Execute runs the config logic with incoming arguments.
+func (c *KoolConfig) Execute(args []string) (err error) {
+	var files []string
+
+	if aware, ok := c.composeConfig.(compose.FilesAware); ok {
+		if files, err = aware.Files(); err != nil {
+			return
+		}
+	}
+
+	if c.Flags.Files {
+		if len(files) == 0 {
+			c.Println("docker-compose.yml")
+			c.Println("docker-compose.override.yml (if present)")
+			return
+		}
+
+		for _, file := range files {
+			c.Println(file)
+		}
+		return
+	}
+
+	if c.Flags.Services {
+		c.composeConfig.AppendArgs("--services")
+	}
+
+	err = c.Interactive(c.composeConfig)
+	return
+}
+
+// NewConfigCommand initializes new kool config command
+func NewConfigCommand(config *KoolConfig) (configCmd *cobra.Command) {
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective docker-compose configuration",
+		Long: `Validate and print the docker-compose configuration in effect, which merges
+all the compose files being layered. The files come from KOOL_COMPOSE_FILES, or from
+the environment set by KOOL_COMPOSE_ENV within the compose section of kool.yml.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(config),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	configCmd.Flags().BoolVarP(&config.Flags.Files, "files", "", false, "Only list the docker-compose files being layered.")
+	configCmd.Flags().BoolVarP(&config.Flags.Services, "services", "", false, "Only list the services names.")
+	return
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"testing"
+)
+
+func newFakeKoolConfig() *KoolConfig {
+	return &KoolConfig{
+		*newFakeKoolService(),
+		&KoolConfigFlags{false, false},
+		&builder.FakeCommand{MockCmd: "config"},
+	}
+}
+
+func TestNewKoolConfig(t *testing.T) {
+	k := NewKoolConfig()
+
+	if _, ok := k.DefaultKoolService.shell.(*shell.DefaultShell); !ok {
+		t.Errorf("unexpected shell.Shell on default KoolConfig instance")
+	}
+
+	if k.Flags == nil || k.Flags.Files || k.Flags.Services {
+		t.Errorf("bad default flags on default KoolConfig instance")
+	}
+
+	if _, ok := k.composeConfig.(*compose.DockerCompose); !ok {
+		t.Errorf("unexpected compose.DockerCompose on default KoolConfig instance")
+	}
+}
+
+func TestConfigCommand(t *testing.T) {
+	f := newFakeKoolConfig()
+	cmd := NewConfigCommand(f)
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing config command; error: %v", err)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["config"] {
+		t.Error("did not call Interactive on KoolConfig.composeConfig Command")
+	}
+
+	f = newFakeKoolConfig()
+	cmd = NewConfigCommand(f)
+	cmd.SetArgs([]string{"--services"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing config command; error: %v", err)
+	}
+
+	if args := f.composeConfig.(*builder.FakeCommand).ArgsAppend; len(args) != 1 || args[0] != "--services" {
+		t.Errorf("bad arguments to KoolConfig.composeConfig Command with services flag: %v", args)
+	}
+}
+
+func TestConfigCommandFailing(t *testing.T) {
+	f := newFakeKoolConfig()
+	f.composeConfig.(*builder.FakeCommand).MockInteractiveError = errors.New("config error")
+
+	cmd := NewConfigCommand(f)
+
+	assertExecGotError(t, cmd, "config error")
+}
+
+func newConfigDockerCompose(env *environment.FakeEnvStorage) *compose.DockerCompose {
+	dc := compose.NewDockerCompose("config")
+	dc.SetShell(&shell.FakeShell{})
+	dc.SetLocalDockerCompose(&builder.FakeCommand{})
+	dc.SetEnv(env)
+	return dc
+}
+
+func TestConfigCommandFilesFlag(t *testing.T) {
+	f := newFakeKoolConfig()
+	env := environment.NewFakeEnvStorage()
+	f.composeConfig = newConfigDockerCompose(env)
+
+	cmd := NewConfigCommand(f)
+	cmd.SetArgs([]string{"--files"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing config command; error: %v", err)
+	}
+
+	if lines := f.shell.(*shell.FakeShell).OutLines; len(lines) != 2 || lines[0] != "docker-compose.yml" {
+		t.Errorf("unexpected output listing default compose files: %v", lines)
+	}
+
+	f = newFakeKoolConfig()
+	env.Set("KOOL_COMPOSE_ENV", "ci")
+	f.composeConfig = newConfigDockerCompose(env)
+
+	cmd = NewConfigCommand(f)
+	cmd.SetArgs([]string{"--files"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing config command; error: %v", err)
+	}
+
+	if lines := f.shell.(*shell.FakeShell).OutLines; len(lines) != 2 || lines[1] != "docker-compose.ci.yml" {
+		t.Errorf("unexpected output listing ci compose files: %v", lines)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledInteractive["config"] {
+		t.Error("should not run docker-compose config when listing files")
+	}
+}
+
+func TestConfigCommandFilesError(t *testing.T) {
+	f := newFakeKoolConfig()
+	dc := newConfigDockerCompose(environment.NewFakeEnvStorage())
+	dc.SetKoolParser(&parser.FakeParser{
+		MockParseComposeFilesError: map[string]error{
+			"default": errors.New("kool.yml error"),
+		},
+	})
+	f.composeConfig = dc
+
+	cmd := NewConfigCommand(f)
+
+	assertExecGotError(t, cmd, "kool.yml error")
+}
@@ -5,20 +5,53 @@ import (
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 )
 
+// createTemplateFile is the file declaring the post-create hooks and the
+// preset of a git template repository
+const createTemplateFile = "kool-template.yml"
+
+const (
+	createRunHooks = "Run the hooks"
+	createCancel   = "Cancel"
+)
+
+// createCommitRef tells a template ref is a commit SHA, which
+// cannot be cloned as a branch and is checked out instead
+var createCommitRef = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// KoolCreateFlags holds the flags for the kool create command
+type KoolCreateFlags struct {
+	Preset string
+	Yes    bool
+}
+
 // KoolCreate holds handlers and functions to implement the preset command logic
 type KoolCreate struct {
 	DefaultKoolService
+	Flags         *KoolCreateFlags
 	parser        presets.Parser
 	env           environment.EnvStorage
 	createCommand builder.Command
+	clone         builder.Command
+	checkout      builder.Command
 	KoolPreset
 }
 
+// createTemplate holds the settings of a git template repository
+type createTemplate struct {
+	Preset   string              `yaml:"preset"`
+	Commands map[string][]string `yaml:"commands"`
+}
+
 func AddKoolCreate(root *cobra.Command) {
 	var (
 		create    = NewKoolCreate()
@@ -30,12 +63,17 @@ func AddKoolCreate(root *cobra.Command) {
 
 // NewKoolCreate creates a new handler for create logic
 func NewKoolCreate() *KoolCreate {
+	preset := NewKoolPreset()
+
 	return &KoolCreate{
 		*newDefaultKoolService(),
-		presets.NewParser(),
+		&KoolCreateFlags{},
+		preset.presetsParser,
 		environment.NewEnvStorage(),
 		&builder.DefaultCommand{},
-		*NewKoolPreset(),
+		builder.NewCommand("git", "-c", "advice.detachedHead=false", "clone", "--quiet"),
+		builder.NewCommand("git", "-c", "advice.detachedHead=false"),
+		*preset,
 	}
 }
 
@@ -52,8 +90,20 @@ func (c *KoolCreate) Execute(args []string) (err error) {
 	// sets env variable CREATE_DIRECTORY that aims to tell
 	c.env.Set("CREATE_DIRECTORY", createDirectory)
 
-	c.parser.LoadPresets(presets.GetAll())
-	c.parser.LoadConfigs(presets.GetConfigs())
+	if c.KoolPreset.Flags.AnswersFile != "" {
+		// the preset is installed from within the new project folder
+		if c.KoolPreset.Flags.AnswersFile, err = filepath.Abs(c.KoolPreset.Flags.AnswersFile); err != nil {
+			return
+		}
+	}
+
+	// the parser is shared with the preset, so the sources are loaded only once
+	c.KoolPreset.loadParsers()
+
+	if url, ref, isTemplate := templateRepository(preset); isTemplate {
+		err = c.createFromTemplate(url, ref, createDirectory)
+		return
+	}
 
 	if !c.parser.Exists(preset) {
 		err = fmt.Errorf("unknown preset %s", preset)
@@ -82,22 +132,183 @@ func (c *KoolCreate) Execute(args []string) (err error) {
 
 	_ = os.Chdir(createDirectory)
 
+	// the preset files are rendered with the new project name
+	c.env.Set("KOOL_NAME", filepath.Base(createDirectory))
+
 	err = c.KoolPreset.Execute([]string{preset})
 
 	return
 }
 
+// createFromTemplate clones the git template repository onto the new project
+// folder, running its post-create hooks and then applying its preset, if any
+func (c *KoolCreate) createFromTemplate(url, ref, createDirectory string) (err error) {
+	var (
+		template = new(createTemplate)
+		preset   = c.Flags.Preset
+		clone    = c.clone.Copy()
+	)
+
+	if preset != "" && !c.parser.Exists(preset) {
+		err = fmt.Errorf("unknown preset %s", preset)
+		return
+	}
+
+	isCommit := createCommitRef.MatchString(ref)
+
+	switch {
+	case isCommit:
+		// a commit may be anywhere in history, so it needs a full clone
+		clone.AppendArgs("--no-checkout")
+	case ref != "":
+		clone.AppendArgs("--depth", "1", "--branch", ref)
+	default:
+		clone.AppendArgs("--depth", "1")
+	}
+
+	clone.AppendArgs(url, createDirectory)
+
+	if err = c.Interactive(clone); err != nil {
+		err = fmt.Errorf("failed to clone %s: %v", url, err)
+		return
+	}
+
+	if isCommit {
+		checkout := c.checkout.Copy()
+		checkout.AppendArgs("-C", createDirectory, "checkout", "--quiet", ref)
+
+		if err = c.Interactive(checkout); err != nil {
+			err = fmt.Errorf("failed to checkout %s: %v", ref, err)
+			return
+		}
+	}
+
+	if err = os.RemoveAll(filepath.Join(createDirectory, ".git")); err != nil {
+		return
+	}
+
+	if content, readErr := os.ReadFile(filepath.Join(createDirectory, createTemplateFile)); readErr == nil {
+		if err = yaml.Unmarshal(content, template); err != nil {
+			err = fmt.Errorf("invalid %s: %v", createTemplateFile, err)
+			return
+		}
+
+		if err = os.Remove(filepath.Join(createDirectory, createTemplateFile)); err != nil {
+			return
+		}
+	}
+
+	if preset == "" && template.Preset != "" {
+		if preset = template.Preset; !c.parser.Exists(preset) {
+			err = fmt.Errorf("unknown preset %s on %s", preset, createTemplateFile)
+			return
+		}
+	}
+
+	if err = c.confirmHooks(template.Commands["post-create"]); err != nil {
+		return
+	}
+
+	if err = os.Chdir(createDirectory); err != nil {
+		return
+	}
+
+	c.env.Set("KOOL_NAME", filepath.Base(createDirectory))
+
+	// the hooks run from within the new project folder
+	for _, hook := range template.Commands["post-create"] {
+		if err = c.createCommand.Parse(hook); err != nil {
+			return
+		}
+
+		if err = c.Interactive(c.createCommand); err != nil {
+			err = fmt.Errorf("post-create hook '%s' failed: %v", hook, err)
+			return
+		}
+	}
+
+	if preset != "" {
+		err = c.KoolPreset.Execute([]string{preset})
+		return
+	}
+
+	c.Success("Project ", filepath.Base(createDirectory), " created!")
+	return
+}
+
+// confirmHooks shows the template post-create hooks and asks whether to
+// run them, unless using --yes; on a non-TTY, --yes is required
+func (c *KoolCreate) confirmHooks(hooks []string) (err error) {
+	var answer string
+
+	if len(hooks) == 0 || c.Flags.Yes {
+		return
+	}
+
+	c.Println("The template declares these post-create hooks:")
+
+	for _, hook := range hooks {
+		c.Println("  -", hook)
+	}
+
+	if !c.IsTerminal() {
+		err = fmt.Errorf("the input device is not a TTY; use --yes to run the post-create hooks without confirmation")
+		return
+	}
+
+	if answer, err = c.promptSelect.Ask("Do you want to run them", []string{createRunHooks, createCancel}); err != nil {
+		return
+	}
+
+	if answer != createRunHooks {
+		err = shell.ErrUserCancelled
+	}
+
+	return
+}
+
+// templateRepository returns the git URL and ref (as URL#REF) of a template
+// repository, given as a git URL or as gh:ORG/REPO for GitHub
+func templateRepository(source string) (url, ref string, isTemplate bool) {
+	if !strings.HasPrefix(source, "gh:") && !isGitSource(source) {
+		return
+	}
+
+	url, isTemplate = source, true
+
+	if i := strings.LastIndex(url, "#"); i > 0 {
+		url, ref = url[:i], url[i+1:]
+	}
+
+	if strings.HasPrefix(url, "gh:") {
+		url = "https://github.com/" + strings.TrimSuffix(url[3:], ".git") + ".git"
+	}
+
+	return
+}
+
 // NewCreateCommand initializes new kool create command
 func NewCreateCommand(create *KoolCreate) (createCmd *cobra.Command) {
 	createCmd = &cobra.Command{
-		Use:   "create PRESET FOLDER",
-		Short: "Create a new project using a preset",
-		Long:  "Create a new project using the specified PRESET in a directory named FOLDER.",
-		Args:  cobra.ExactArgs(2),
-		RunE:  DefaultCommandRunFunction(create),
+		Use:   "create PRESET|TEMPLATE FOLDER",
+		Short: "Create a new project using a preset or a git template repository",
+		Long: `Create a new project using the specified PRESET in a directory named FOLDER.
+External presets from ~/.kool/presets and KOOL_PRESETS_PATH can be used as well,
+and the preset questions can be answered with --answer or --answers-file.
+
+A git TEMPLATE repository (as a git URL or gh:ORG/REPO, optionally followed by
+#REF, where REF is a branch, a tag or a commit SHA) is cloned into FOLDER instead;
+the post-create hooks declared on its kool-template.yml are shown and, once
+confirmed (or with --yes), run from within FOLDER, and then its preset (or the
+one given with --preset) is applied.`,
+		Args: cobra.ExactArgs(2),
+		RunE: DefaultCommandRunFunction(create),
 
 		DisableFlagsInUseLine: true,
 	}
 
+	addPresetAnswersFlags(createCmd, create.KoolPreset.Flags)
+	createCmd.Flags().StringVar(&create.Flags.Preset, "preset", "", "The preset to apply after creating the project from a git TEMPLATE repository.")
+	createCmd.Flags().BoolVarP(&create.Flags.Yes, "yes", "y", false, "Run the post-create hooks of a git TEMPLATE repository without asking for confirmation.")
 	return
 }
@@ -3,21 +3,30 @@ package commands
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/presets"
 	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
 
 func newFakeKoolCreate() *KoolCreate {
+	preset := newFakeKoolPreset()
+
 	return &KoolCreate{
 		*newFakeKoolService(),
-		&presets.FakeParser{},
+		&KoolCreateFlags{},
+		preset.presetsParser,
 		environment.NewFakeEnvStorage(),
 		&builder.FakeCommand{},
-		*newFakeKoolPreset(),
+		&builder.FakeCommand{MockCmd: "git"},
+		&builder.FakeCommand{MockCmd: "checkout"},
+		*preset,
 	}
 }
 
@@ -39,6 +48,14 @@ func TestNewKoolCreate(t *testing.T) {
 	if _, ok := k.parser.(*presets.DefaultParser); !ok {
 		t.Errorf("unexpected presets.Parser on default KoolCreate instance")
 	}
+
+	if k.parser != k.KoolPreset.presetsParser {
+		t.Errorf("KoolCreate should share the presets.Parser with its KoolPreset")
+	}
+
+	if k.clone.Cmd() != "git" {
+		t.Errorf("unexpected clone command on default KoolCreate instance: %s", k.clone.Cmd())
+	}
 }
 
 func TestNewKoolCreateCommand(t *testing.T) {
@@ -82,6 +99,40 @@ func TestNewKoolCreateCommand(t *testing.T) {
 	if val, ok := f.shell.(*shell.FakeShell).CalledInteractive["create"]; !val || !ok {
 		t.Error("did not call Interactive on KoolCreate.createCommand Command")
 	}
+
+	if name := f.env.Get("KOOL_NAME"); name != "my-app" {
+		t.Errorf("expected KOOL_NAME to be set to the new project folder, got %s", name)
+	}
+}
+
+func TestCreateLoadsPresetSourcesOnce(t *testing.T) {
+	f := newFakeKoolCreate()
+
+	f.parser.(*presets.FakeParser).MockExists = true
+	f.parser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": {
+			Commands: map[string][]string{
+				"create": {"kool docker create command"},
+			},
+		},
+	}
+	f.createCommand.(*builder.FakeCommand).MockCmd = "create"
+	f.sources.env.Set("KOOL_PRESETS_PATH", "/company/presets")
+
+	if err := f.Execute([]string{"laravel", "my-app"}); err != nil {
+		t.Fatalf("unexpected error executing create command; error: %v", err)
+	}
+
+	if !f.parser.(*presets.FakeParser).CalledLoadDir["/company/presets"] {
+		t.Error("did not load the external presets")
+	}
+
+	f.parser.(*presets.FakeParser).CalledLoadPresets = false
+	f.KoolPreset.loadParsers()
+
+	if f.parser.(*presets.FakeParser).CalledLoadPresets {
+		t.Error("should not load the presets sources again")
+	}
 }
 
 func TestInvalidPresetCreateCommand(t *testing.T) {
@@ -148,3 +199,234 @@ func TestNoCreateCommandsCreateCommand(t *testing.T) {
 
 	assertExecGotError(t, cmd, "no create commands were found for preset laravel")
 }
+
+func TestAnswersFileCreateCommand(t *testing.T) {
+	f := newFakeKoolCreate()
+
+	cmd := NewCreateCommand(f)
+
+	cmd.SetArgs([]string{"laravel", "my-app", "--answer", "database=none", "--answers-file", "answers.yml"})
+
+	assertExecGotError(t, cmd, "unknown preset laravel")
+
+	if !filepath.IsAbs(f.KoolPreset.Flags.AnswersFile) || filepath.Base(f.KoolPreset.Flags.AnswersFile) != "answers.yml" {
+		t.Errorf("expected answers file to be made absolute, got %s", f.KoolPreset.Flags.AnswersFile)
+	}
+
+	if len(f.KoolPreset.Flags.Answers) != 1 || f.KoolPreset.Flags.Answers[0] != "database=none" {
+		t.Errorf("unexpected answers flags: %v", f.KoolPreset.Flags.Answers)
+	}
+}
+
+// chdirTemplate runs on a temporary folder holding my-app as a freshly
+// cloned template repository with the given kool-template.yml
+func chdirTemplate(t *testing.T, template string) {
+	chdirProject(t, nil)
+
+	if err := os.MkdirAll(filepath.Join("my-app", ".git"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if template != "" {
+		if err := os.WriteFile(filepath.Join("my-app", "kool-template.yml"), []byte(template), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTemplateCreateCommand(t *testing.T) {
+	chdirTemplate(t, "commands:\n  post-create:\n    - composer install\n")
+
+	f := newFakeKoolCreate()
+	f.createCommand.(*builder.FakeCommand).MockCmd = "composer"
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"gh:acme/starter#v2", "my-app", "--yes"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error creating from a template: %v", err)
+	}
+
+	clone := f.clone.(*builder.FakeCommand)
+
+	if !reflect.DeepEqual(clone.ArgsAppend, []string{"--depth", "1", "--branch", "v2", "https://github.com/acme/starter.git", "my-app"}) {
+		t.Errorf("unexpected clone arguments: %v", clone.ArgsAppend)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["git"] {
+		t.Error("did not clone the template repository")
+	}
+
+	for _, file := range []string{".git", "kool-template.yml"} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed from the new project", file)
+		}
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["composer"] {
+		t.Error("did not run the post-create hook")
+	}
+
+	if f.shell.(*shell.FakeShell).CalledInteractive["checkout"] || f.KoolPreset.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should neither checkout a branch nor ask for confirmation with --yes")
+	}
+
+	if f.KoolPreset.presetsParser.(*presets.FakeParser).CalledGetConfig != nil {
+		t.Error("should not apply a preset when none is given")
+	}
+
+	if output := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); output != "Project my-app created!" {
+		t.Errorf("unexpected success output: %s", output)
+	}
+
+	if name := f.env.Get("KOOL_NAME"); name != "my-app" {
+		t.Errorf("expected KOOL_NAME to be set to the new project folder, got %s", name)
+	}
+}
+
+func TestTemplatePresetCreateCommand(t *testing.T) {
+	chdirTemplate(t, "preset: laravel\n")
+
+	f := newFakeKoolCreate()
+	f.parser.(*presets.FakeParser).MockExists = true
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockExists = true
+	f.KoolPreset.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": {},
+	}
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"git@git.acme.com:kits/laravel.git", "my-app"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error creating from a template: %v", err)
+	}
+
+	if args := f.clone.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"--depth", "1", "git@git.acme.com:kits/laravel.git", "my-app"}) {
+		t.Errorf("unexpected clone arguments: %v", args)
+	}
+
+	if !f.KoolPreset.presetsParser.(*presets.FakeParser).CalledGetConfig["laravel"] {
+		t.Error("did not apply the template preset")
+	}
+
+	if f.createCommand.(*builder.FakeCommand).CalledParseCommand {
+		t.Error("should not run the preset create commands for a template")
+	}
+}
+
+func TestTemplateCommitCreateCommand(t *testing.T) {
+	chdirTemplate(t, "")
+
+	f := newFakeKoolCreate()
+
+	cmd := NewCreateCommand(f)
+	cmd.SetArgs([]string{"gh:acme/starter#3f2a9c1", "my-app"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error creating from a template commit: %v", err)
+	}
+
+	if args := f.clone.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"--no-checkout", "https://github.com/acme/starter.git", "my-app"}) {
+		t.Errorf("unexpected clone arguments: %v", args)
+	}
+
+	if args := f.checkout.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"-C", "my-app", "checkout", "--quiet", "3f2a9c1"}) {
+		t.Errorf("unexpected checkout arguments: %v", args)
+	}
+
+	chdirTemplate(t, "")
+
+	f = newFakeKoolCreate()
+	f.checkout.(*builder.FakeCommand).MockInteractiveError = errors.New("reference is not a tree")
+
+	cmd = NewCreateCommand(f)
+	cmd.SetArgs([]string{"gh:acme/starter#3f2a9c1", "my-app"})
+	assertExecGotError(t, cmd, "failed to checkout 3f2a9c1: reference is not a tree")
+}
+
+func TestTemplateHooksConfirmation(t *testing.T) {
+	question := "Do you want to run them"
+
+	chdirTemplate(t, "commands:\n  post-create:\n    - composer install\n")
+
+	f := newFakeKoolCreate()
+	f.createCommand.(*builder.FakeCommand).MockCmd = "composer"
+	f.KoolPreset.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Run the hooks"}
+
+	if err := f.Execute([]string{"gh:acme/starter", "my-app"}); err != nil {
+		t.Fatalf("unexpected error creating from a template: %v", err)
+	}
+
+	if output := strings.Join(f.shell.(*shell.FakeShell).OutLines, "\n"); !strings.Contains(output, "composer install") {
+		t.Errorf("expected the hooks to be shown, got: %s", output)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["composer"] {
+		t.Error("did not run the confirmed post-create hook")
+	}
+
+	chdirTemplate(t, "commands:\n  post-create:\n    - composer install\n")
+
+	f = newFakeKoolCreate()
+	f.createCommand.(*builder.FakeCommand).MockCmd = "composer"
+	f.KoolPreset.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Cancel"}
+
+	if err := f.Execute([]string{"gh:acme/starter", "my-app"}); err != shell.ErrUserCancelled {
+		t.Errorf("expected user cancelled error, got %v", err)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledInteractive["composer"] {
+		t.Error("should not run the post-create hook when cancelled")
+	}
+
+	chdirTemplate(t, "commands:\n  post-create:\n    - composer install\n")
+
+	f = newFakeKoolCreate()
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if err := f.Execute([]string{"gh:acme/starter", "my-app"}); err == nil || !strings.Contains(err.Error(), "use --yes") {
+		t.Errorf("expected --yes to be required on a non-TTY, got %v", err)
+	}
+}
+
+func TestTemplateCreateCommandErrors(t *testing.T) {
+	chdirTemplate(t, "preset: unknown\n")
+
+	f := newFakeKoolCreate()
+	cmd := NewCreateCommand(f)
+
+	cmd.SetArgs([]string{"gh:acme/starter", "my-app", "--preset", "missing"})
+	assertExecGotError(t, cmd, "unknown preset missing")
+
+	if f.shell.(*shell.FakeShell).CalledInteractive["git"] {
+		t.Error("should not clone the template when the preset is unknown")
+	}
+
+	cmd.SetArgs([]string{"gh:acme/starter", "my-app", "--preset", ""})
+	assertExecGotError(t, cmd, "unknown preset unknown on kool-template.yml")
+
+	f = newFakeKoolCreate()
+	f.clone.(*builder.FakeCommand).MockInteractiveError = errors.New("repository not found")
+
+	cmd = NewCreateCommand(f)
+	cmd.SetArgs([]string{"https://git.acme.com/kits/missing.git", "other-app"})
+	assertExecGotError(t, cmd, "failed to clone https://git.acme.com/kits/missing.git: repository not found")
+}
+
+func TestTemplateRepository(t *testing.T) {
+	sources := map[string][3]string{
+		"gh:acme/starter":                      {"https://github.com/acme/starter.git", "", "yes"},
+		"gh:acme/starter.git#main":             {"https://github.com/acme/starter.git", "main", "yes"},
+		"https://git.acme.com/kits/php.git#v1": {"https://git.acme.com/kits/php.git", "v1", "yes"},
+		"git@git.acme.com:kits/php.git":        {"git@git.acme.com:kits/php.git", "", "yes"},
+		"laravel":                              {"", "", ""},
+	}
+
+	for source, expected := range sources {
+		url, ref, isTemplate := templateRepository(source)
+
+		if url != expected[0] || ref != expected[1] || isTemplate != (expected[2] == "yes") {
+			t.Errorf("unexpected template repository for %s: %s %s %v", source, url, ref, isTemplate)
+		}
+	}
+}
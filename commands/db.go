@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// dbSnapshotsDir is where the named database snapshots
+// are stored, relative to the project folder
+const dbSnapshotsDir string = ".kool/snapshots"
+
+// dbSnapshotExt is the file extension of database snapshots
+const dbSnapshotExt string = ".sql.gz"
+
+// dbDumpScripts holds the scripts dumping each database engine to the
+// standard output; credentials come from the service container environment
+var dbDumpScripts = map[string]string{
+	compose.EngineMySQL:      `MYSQL_PWD="$MYSQL_PASSWORD" exec mysqldump --single-transaction --no-tablespaces -u"$MYSQL_USER" "$MYSQL_DATABASE"`,
+	compose.EngineMariaDB:    `MYSQL_PWD="${MARIADB_PASSWORD:-$MYSQL_PASSWORD}" exec mysqldump --single-transaction --no-tablespaces -u"${MARIADB_USER:-$MYSQL_USER}" "${MARIADB_DATABASE:-$MYSQL_DATABASE}"`,
+	compose.EnginePostgreSQL: `PGPASSWORD="$POSTGRES_PASSWORD" exec pg_dump --clean --if-exists -U "${POSTGRES_USER:-postgres}" "${POSTGRES_DB:-${POSTGRES_USER:-postgres}}"`,
+}
+
+// dbRecreateMySQL drops and creates again the $db database, then restores
+// it from the standard input; it is shared by MySQL and MariaDB
+const dbRecreateMySQL = "mysql -u\"$user\" -e \"DROP DATABASE IF EXISTS \\`$db\\`; CREATE DATABASE \\`$db\\`\" </dev/null && " +
+	`exec mysql -u"$user" "$db"`
+
+// dbRestoreScripts holds the scripts restoring each database engine from
+// the standard input; the database is dropped and created again first, so
+// nothing out of the dump is left behind
+var dbRestoreScripts = map[string]string{
+	compose.EngineMySQL:   `export MYSQL_PWD="$MYSQL_PASSWORD"; user="$MYSQL_USER"; db="$MYSQL_DATABASE"; ` + dbRecreateMySQL,
+	compose.EngineMariaDB: `export MYSQL_PWD="${MARIADB_PASSWORD:-$MYSQL_PASSWORD}"; user="${MARIADB_USER:-$MYSQL_USER}"; db="${MARIADB_DATABASE:-$MYSQL_DATABASE}"; ` + dbRecreateMySQL,
+	compose.EnginePostgreSQL: `export PGPASSWORD="$POSTGRES_PASSWORD"; user="${POSTGRES_USER:-postgres}"; db="${POSTGRES_DB:-$user}"; ` +
+		`psql -q -v ON_ERROR_STOP=1 -U "$user" -d template1 ` +
+		`-c "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = '$db' AND pid <> pg_backend_pid()" ` +
+		`-c "DROP DATABASE IF EXISTS \"$db\"" -c "CREATE DATABASE \"$db\"" </dev/null >/dev/null && ` +
+		`exec psql -q -v ON_ERROR_STOP=1 -U "$user" "$db"`,
+}
+
+// KoolDBFlags holds the flags for the kool db commands
+type KoolDBFlags struct {
+	Service string
+}
+
+// KoolDB holds the shared logic of the kool db commands for
+// dumping and restoring the project database service
+type KoolDB struct {
+	Flags *KoolDBFlags
+
+	env   environment.EnvStorage
+	files compose.FilesAware
+	exec  *KoolExec
+}
+
+// NewKoolDB creates a new handler for the database commands with default dependencies
+func NewKoolDB() *KoolDB {
+	return &KoolDB{
+		&KoolDBFlags{""},
+		environment.NewEnvStorage(),
+		compose.NewDockerCompose("config"),
+		NewKoolExec(),
+	}
+}
+
+// NewDBCommand initializes new kool db command
+func NewDBCommand(db *KoolDB) (dbCmd *cobra.Command) {
+	dbCmd = &cobra.Command{
+		Use:   "db",
+		Short: "Dump, restore and snapshot the project database",
+		Long: `Dump, restore and snapshot the database of the project. The database service is detected
+from its image (mysql, mariadb or postgres) on the docker-compose files, and the credentials
+come from its environment variables.`,
+		Args: cobra.NoArgs,
+
+		DisableFlagsInUseLine: true,
+	}
+
+	dbCmd.PersistentFlags().StringVarP(&db.Flags.Service, "service", "s", "", "The database service (detected from the docker-compose files by default).")
+	return
+}
+
+func AddKoolDB(root *cobra.Command) {
+	var (
+		db    = NewKoolDB()
+		dbCmd = NewDBCommand(db)
+	)
+
+	root.AddCommand(dbCmd)
+	dbCmd.AddCommand(NewDBDumpCommand(NewKoolDBDump(db)))
+	dbCmd.AddCommand(NewDBListCommand(NewKoolDBList(db)))
+	dbCmd.AddCommand(NewDBRestoreCommand(NewKoolDBRestore(db)))
+	dbCmd.AddCommand(NewDBSnapshotCommand(NewKoolDBSnapshot(db)))
+}
+
+// database finds out the database service to be used
+func (d *KoolDB) database() (database compose.DatabaseService, err error) {
+	var (
+		files     []composeFile
+		found     []compose.DatabaseService
+		databases = make(map[string]compose.DatabaseService)
+		names     []string
+	)
+
+	if files, err = readComposeFiles(d.files, d.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if found, err = compose.ParseDatabaseServices(file.content); err != nil {
+			err = fmt.Errorf("failed to parse services from %s: %v", file.path, err)
+			return
+		}
+
+		for _, db := range found {
+			if _, exists := databases[db.Service]; !exists {
+				names = append(names, db.Service)
+			}
+			databases[db.Service] = db
+		}
+	}
+
+	if service := d.Flags.Service; service != "" {
+		var exists bool
+
+		if database, exists = databases[service]; !exists {
+			err = fmt.Errorf("service %s does not run a supported database image (mysql, mariadb or postgres)", service)
+		}
+		return
+	}
+
+	switch len(names) {
+	case 0:
+		err = fmt.Errorf("could not find a database service (mysql, mariadb or postgres image) on the docker-compose files")
+	case 1:
+		database = databases[names[0]]
+	default:
+		err = fmt.Errorf("found more than one database service (%s); pick one with --service", strings.Join(names, ", "))
+	}
+
+	return
+}
+
+// dump writes the database dump onto the given writer
+func (d *KoolDB) dump(out io.Writer, in io.Reader, errOut io.Writer) (err error) {
+	var database compose.DatabaseService
+
+	if database, err = d.database(); err != nil {
+		return
+	}
+
+	d.exec.SetInStream(in)
+	d.exec.SetOutStream(out)
+	d.exec.SetErrStream(errOut)
+
+	err = d.exec.Execute([]string{database.Service, "sh", "-c", dbDumpScripts[database.Engine]})
+	return
+}
+
+// restore feeds the database with the dump read from the given reader
+func (d *KoolDB) restore(in io.Reader, out io.Writer, errOut io.Writer) (err error) {
+	var database compose.DatabaseService
+
+	if database, err = d.database(); err != nil {
+		return
+	}
+
+	d.exec.SetInStream(in)
+	d.exec.SetOutStream(out)
+	d.exec.SetErrStream(errOut)
+
+	err = d.exec.Execute([]string{database.Service, "sh", "-c", dbRestoreScripts[database.Engine]})
+	return
+}
+
+// dumpToFile writes the database dump onto the given file, gzip compressed
+// when it has the .gz extension; the file is only replaced on success
+func (d *KoolDB) dumpToFile(path string, in io.Reader, errOut io.Writer) error {
+	return writeArchiveFile(path, strings.HasSuffix(path, ".gz"), func(out io.Writer) error {
+		return d.dump(out, in, errOut)
+	})
+}
+
+// restoreFromFile feeds the database with the dump on the
+// given file, which may be gzip compressed
+func (d *KoolDB) restoreFromFile(path string, out io.Writer, errOut io.Writer) error {
+	return readArchiveFile(path, func(in io.Reader) error {
+		return d.restore(in, out, errOut)
+	})
+}
+
+// snapshotsDir returns the folder holding the project database snapshots
+func (d *KoolDB) snapshotsDir() string {
+	return filepath.Join(d.env.Get("PWD"), filepath.FromSlash(dbSnapshotsDir))
+}
+
+// snapshotPath returns the file path of the given named snapshot
+func (d *KoolDB) snapshotPath(name string) string {
+	return filepath.Join(d.snapshotsDir(), name+dbSnapshotExt)
+}
+
+// ensureSnapshotsDir creates the snapshots folder, keeping it out of version control
+func (d *KoolDB) ensureSnapshotsDir() (err error) {
+	dir := d.snapshotsDir()
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return
+	}
+
+	gitignore := filepath.Join(dir, ".gitignore")
+
+	if _, err = os.Stat(gitignore); os.IsNotExist(err) {
+		err = os.WriteFile(gitignore, []byte("*\n"), 0644)
+	}
+
+	return
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// KoolDBDump holds handlers and functions to implement the db dump command logic
+type KoolDBDump struct {
+	DefaultKoolService
+
+	db *KoolDB
+}
+
+// NewKoolDBDump creates a new handler for db dump logic
+func NewKoolDBDump(db *KoolDB) *KoolDBDump {
+	return &KoolDBDump{
+		*newDefaultKoolService(),
+		db,
+	}
+}
+
+// Execute runs the db dump logic with incoming arguments.
+func (d *KoolDBDump) Execute(args []string) (err error) {
+	if len(args) == 0 {
+		err = d.db.dump(d.OutStream(), d.InStream(), d.ErrStream())
+		return
+	}
+
+	if err = d.db.dumpToFile(args[0], d.InStream(), d.ErrStream()); err != nil {
+		return
+	}
+
+	d.Success("Database dumped to ", args[0])
+	return
+}
+
+// NewDBDumpCommand initializes new kool db dump command
+func NewDBDumpCommand(dump *KoolDBDump) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dump [FILE]",
+		Short: "Dump the project database",
+		Long: `Dump the project database to the standard output, or to FILE when given.
+The dump is gzip compressed when FILE has the .gz extension.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: DefaultCommandRunFunction(dump),
+
+		DisableFlagsInUseLine: true,
+	}
+}
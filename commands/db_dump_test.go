@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewKoolDBDump(t *testing.T) {
+	db := NewKoolDB()
+	dump := NewKoolDBDump(db)
+
+	if dump.db != db {
+		t.Error("unexpected KoolDB on default KoolDBDump instance")
+	}
+
+	if _, ok := dump.shell.(*shell.DefaultShell); !ok {
+		t.Error("unexpected shell.Shell on default KoolDBDump instance")
+	}
+}
+
+func TestDBDumpCommand(t *testing.T) {
+	dump := &KoolDBDump{*newFakeKoolService(), newFakeKoolDB(t)}
+	cmd := NewDBDumpCommand(dump)
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error dumping database: %v", err)
+	}
+
+	if !dump.db.exec.shell.(*shell.FakeShell).CalledInteractive["exec"] {
+		t.Error("did not run the dump through kool exec")
+	}
+
+	if dump.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("should not print messages when dumping to the standard output")
+	}
+
+	dump = &KoolDBDump{*newFakeKoolService(), newFakeKoolDB(t)}
+	path := filepath.Join(dump.db.env.Get("PWD"), "dump.sql")
+	cmd = NewDBDumpCommand(dump)
+	cmd.SetArgs([]string{path})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error dumping database: %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("did not create the dump file: %v", err)
+	}
+
+	if !dump.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the dump file")
+	}
+}
+
+func TestDBDumpCommandError(t *testing.T) {
+	dump := &KoolDBDump{*newFakeKoolService(), newFakeKoolDB(t)}
+	dump.db.exec = newFailedFakeKoolExec()
+
+	assertExecGotError(t, NewDBDumpCommand(dump), "error exec")
+}
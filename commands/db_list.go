@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/shell"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolDBList holds handlers and functions to implement the db list command logic
+type KoolDBList struct {
+	DefaultKoolService
+
+	db    *KoolDB
+	table shell.TableWriter
+}
+
+// NewKoolDBList creates a new handler for db list logic
+func NewKoolDBList(db *KoolDB) *KoolDBList {
+	return &KoolDBList{
+		*newDefaultKoolService(),
+		db,
+		shell.NewTableWriter(),
+	}
+}
+
+// Execute runs the db list logic with incoming arguments.
+func (l *KoolDBList) Execute(args []string) (err error) {
+	var entries []os.DirEntry
+
+	if entries, err = os.ReadDir(l.db.snapshotsDir()); err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	err = nil
+
+	l.table.SetWriter(l.OutStream())
+	l.table.AppendHeader("Snapshot", "Size", "Created")
+
+	for _, entry := range entries {
+		var info os.FileInfo
+
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), dbSnapshotExt) {
+			continue
+		}
+
+		if info, err = entry.Info(); err != nil {
+			return
+		}
+
+		l.table.AppendRow(
+			strings.TrimSuffix(entry.Name(), dbSnapshotExt),
+			humanSize(info.Size()),
+			info.ModTime().Format("2006-01-02 15:04:05"),
+		)
+	}
+
+	l.table.SortBy(1)
+	l.table.Render()
+	return
+}
+
+// humanSize formats the given bytes size for humans
+func humanSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// NewDBListCommand initializes new kool db list command
+func NewDBListCommand(list *KoolDBList) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the project database snapshots",
+		Args:  cobra.NoArgs,
+		RunE:  DefaultCommandRunFunction(list),
+
+		DisableFlagsInUseLine: true,
+	}
+}
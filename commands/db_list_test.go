@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"os"
+	"testing"
+)
+
+func TestNewKoolDBList(t *testing.T) {
+	list := NewKoolDBList(NewKoolDB())
+
+	if _, ok := list.table.(*shell.DefaultTableWriter); !ok {
+		t.Error("unexpected shell.TableWriter on default KoolDBList instance")
+	}
+}
+
+func TestDBListCommand(t *testing.T) {
+	list := &KoolDBList{*newFakeKoolService(), newFakeKoolDB(t), &shell.FakeTableWriter{}}
+
+	if err := NewDBListCommand(list).Execute(); err != nil {
+		t.Errorf("unexpected error listing snapshots without any: %v", err)
+	}
+
+	if rows := list.table.(*shell.FakeTableWriter).Rows; len(rows) != 0 {
+		t.Errorf("unexpected snapshots listed: %v", rows)
+	}
+
+	_ = list.db.ensureSnapshotsDir()
+	_ = os.WriteFile(list.db.snapshotPath("before"), make([]byte, 2048), os.ModePerm)
+
+	list = &KoolDBList{*newFakeKoolService(), list.db, &shell.FakeTableWriter{}}
+
+	if err := NewDBListCommand(list).Execute(); err != nil {
+		t.Errorf("unexpected error listing snapshots: %v", err)
+	}
+
+	rows := list.table.(*shell.FakeTableWriter).Rows
+
+	if len(rows) != 1 || rows[0][0] != "before" || rows[0][1] != "2.0 KB" {
+		t.Errorf("unexpected snapshots listed: %v", rows)
+	}
+
+	if !list.table.(*shell.FakeTableWriter).CalledRender {
+		t.Error("did not render the snapshots table")
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	sizes := map[int64]string{
+		10:              "10 B",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+	}
+
+	for size, expected := range sizes {
+		if got := humanSize(size); got != expected {
+			t.Errorf("expected %s for %d bytes, got %s", expected, size, got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolDBRestore holds handlers and functions to implement the db restore command logic
+type KoolDBRestore struct {
+	DefaultKoolService
+
+	db *KoolDB
+}
+
+// NewKoolDBRestore creates a new handler for db restore logic
+func NewKoolDBRestore(db *KoolDB) *KoolDBRestore {
+	return &KoolDBRestore{
+		*newDefaultKoolService(),
+		db,
+	}
+}
+
+// Execute runs the db restore logic with incoming arguments.
+func (r *KoolDBRestore) Execute(args []string) (err error) {
+	var path = args[0]
+
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		// not a file, so it must be a snapshot name
+		path = r.db.snapshotPath(args[0])
+
+		if _, err = os.Stat(path); os.IsNotExist(err) {
+			err = fmt.Errorf("could not find a file or snapshot named %s", args[0])
+			return
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	if err = r.db.restoreFromFile(path, r.OutStream(), r.ErrStream()); err != nil {
+		return
+	}
+
+	r.Success("Database restored from ", args[0])
+	return
+}
+
+// NewDBRestoreCommand initializes new kool db restore command
+func NewDBRestoreCommand(restore *KoolDBRestore) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore FILE|SNAPSHOT",
+		Short: "Restore the project database from a dump file or a snapshot",
+		Long: `Restore the project database from the given dump FILE (plain or gzip compressed),
+or from a SNAPSHOT previously taken with 'kool db snapshot'. The database is dropped and
+created again before loading the dump, so it ends up exactly as dumped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: DefaultCommandRunFunction(restore),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewKoolDBRestore(t *testing.T) {
+	db := NewKoolDB()
+
+	if restore := NewKoolDBRestore(db); restore.db != db {
+		t.Error("unexpected KoolDB on default KoolDBRestore instance")
+	}
+}
+
+func TestDBRestoreCommand(t *testing.T) {
+	restore := &KoolDBRestore{*newFakeKoolService(), newFakeKoolDB(t)}
+	path := filepath.Join(restore.db.env.Get("PWD"), "dump.sql")
+	_ = os.WriteFile(path, []byte("SELECT 1;"), os.ModePerm)
+
+	cmd := NewDBRestoreCommand(restore)
+	cmd.SetArgs([]string{path})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error restoring database: %v", err)
+	}
+
+	if !restore.db.exec.shell.(*shell.FakeShell).CalledInteractive["exec"] {
+		t.Error("did not run the restore through kool exec")
+	}
+
+	if !restore.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the restored database")
+	}
+}
+
+func TestDBRestoreSnapshotCommand(t *testing.T) {
+	restore := &KoolDBRestore{*newFakeKoolService(), newFakeKoolDB(t)}
+	_ = restore.db.ensureSnapshotsDir()
+	_ = os.WriteFile(restore.db.snapshotPath("before"), []byte("SELECT 1;"), os.ModePerm)
+
+	cmd := NewDBRestoreCommand(restore)
+	cmd.SetArgs([]string{"before"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error restoring snapshot: %v", err)
+	}
+
+	if !restore.db.exec.shell.(*shell.FakeShell).CalledInteractive["exec"] {
+		t.Error("did not run the restore through kool exec")
+	}
+}
+
+func TestDBRestoreCommandErrors(t *testing.T) {
+	restore := &KoolDBRestore{*newFakeKoolService(), newFakeKoolDB(t)}
+	cmd := NewDBRestoreCommand(restore)
+	cmd.SetArgs([]string{"missing"})
+
+	assertExecGotError(t, cmd, "could not find a file or snapshot named missing")
+
+	restore = &KoolDBRestore{*newFakeKoolService(), newFakeKoolDB(t)}
+	restore.db.exec = newFailedFakeKoolExec()
+	path := filepath.Join(restore.db.env.Get("PWD"), "dump.sql")
+	_ = os.WriteFile(path, []byte("SELECT 1;"), os.ModePerm)
+
+	cmd = NewDBRestoreCommand(restore)
+	cmd.SetArgs([]string{path})
+
+	assertExecGotError(t, cmd, "error exec")
+}
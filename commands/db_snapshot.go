@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var dbSnapshotNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// KoolDBSnapshot holds handlers and functions to implement the db snapshot command logic
+type KoolDBSnapshot struct {
+	DefaultKoolService
+
+	db  *KoolDB
+	now func() time.Time
+}
+
+// NewKoolDBSnapshot creates a new handler for db snapshot logic
+func NewKoolDBSnapshot(db *KoolDB) *KoolDBSnapshot {
+	return &KoolDBSnapshot{
+		*newDefaultKoolService(),
+		db,
+		time.Now,
+	}
+}
+
+// Execute runs the db snapshot logic with incoming arguments.
+func (s *KoolDBSnapshot) Execute(args []string) (err error) {
+	var name string
+
+	if len(args) > 0 {
+		name = args[0]
+	} else {
+		name = s.now().Format("2006-01-02-150405")
+	}
+
+	if !dbSnapshotNameRegex.MatchString(name) {
+		err = fmt.Errorf("invalid snapshot name %s; use only letters, numbers, dots, dashes and underscores", name)
+		return
+	}
+
+	if err = s.db.ensureSnapshotsDir(); err != nil {
+		return
+	}
+
+	if err = s.db.dumpToFile(s.db.snapshotPath(name), s.InStream(), s.ErrStream()); err != nil {
+		return
+	}
+
+	s.Success("Database snapshot saved as ", name)
+	return
+}
+
+// NewDBSnapshotCommand initializes new kool db snapshot command
+func NewDBSnapshotCommand(snapshot *KoolDBSnapshot) *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot [NAME]",
+		Short: "Save a named snapshot of the project database",
+		Long: `Save a gzip compressed dump of the project database as the snapshot NAME (the current
+date and time by default), which can be restored later with 'kool db restore NAME'.
+An existing snapshot with the same name is replaced. Snapshots are stored in .kool/snapshots.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: DefaultCommandRunFunction(snapshot),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"os"
+	"testing"
+	"time"
+)
+
+func newFakeKoolDBSnapshot(t *testing.T) *KoolDBSnapshot {
+	return &KoolDBSnapshot{
+		*newFakeKoolService(),
+		newFakeKoolDB(t),
+		func() time.Time {
+			return time.Date(2021, 5, 10, 14, 30, 15, 0, time.UTC)
+		},
+	}
+}
+
+func TestNewKoolDBSnapshot(t *testing.T) {
+	db := NewKoolDB()
+	snapshot := NewKoolDBSnapshot(db)
+
+	if snapshot.db != db || snapshot.now == nil {
+		t.Error("unexpected dependencies on default KoolDBSnapshot instance")
+	}
+}
+
+func TestDBSnapshotCommand(t *testing.T) {
+	snapshot := newFakeKoolDBSnapshot(t)
+	cmd := NewDBSnapshotCommand(snapshot)
+	cmd.SetArgs([]string{"before-migrations"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error taking snapshot: %v", err)
+	}
+
+	if _, err := os.Stat(snapshot.db.snapshotPath("before-migrations")); err != nil {
+		t.Errorf("did not save the snapshot: %v", err)
+	}
+
+	if !snapshot.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the saved snapshot")
+	}
+
+	snapshot = newFakeKoolDBSnapshot(t)
+
+	if err := NewDBSnapshotCommand(snapshot).Execute(); err != nil {
+		t.Errorf("unexpected error taking snapshot: %v", err)
+	}
+
+	if _, err := os.Stat(snapshot.db.snapshotPath("2021-05-10-143015")); err != nil {
+		t.Errorf("did not save the snapshot named after the current time: %v", err)
+	}
+}
+
+func TestDBSnapshotCommandErrors(t *testing.T) {
+	snapshot := newFakeKoolDBSnapshot(t)
+	cmd := NewDBSnapshotCommand(snapshot)
+	cmd.SetArgs([]string{"../escape"})
+
+	assertExecGotError(t, cmd, "invalid snapshot name ../escape")
+
+	snapshot = newFakeKoolDBSnapshot(t)
+	snapshot.db.exec = newFailedFakeKoolExec()
+	cmd = NewDBSnapshotCommand(snapshot)
+	cmd.SetArgs([]string{"failed"})
+
+	assertExecGotError(t, cmd, "error exec")
+
+	if _, err := os.Stat(snapshot.db.snapshotPath("failed")); !os.IsNotExist(err) {
+		t.Error("should not save a snapshot when the dump fails")
+	}
+}
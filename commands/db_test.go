@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dbCompose = `services:
+  app:
+    image: kooldev/php:8.0-nginx
+  database:
+    image: mysql:8.0
+`
+
+func newFakeKoolDB(t *testing.T) *KoolDB {
+	env := environment.NewFakeEnvStorage()
+	env.Set("PWD", t.TempDir())
+
+	if err := os.WriteFile(filepath.Join(env.Get("PWD"), "docker-compose.yml"), []byte(dbCompose), os.ModePerm); err != nil {
+		t.Fatal("failed creating docker-compose.yml for test", err)
+	}
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &KoolDB{
+		&KoolDBFlags{""},
+		env,
+		files,
+		newFakeKoolExec(),
+	}
+}
+
+func TestNewKoolDB(t *testing.T) {
+	db := NewKoolDB()
+
+	if db.Flags == nil || db.Flags.Service != "" {
+		t.Error("unexpected default flags on default KoolDB instance")
+	}
+
+	if _, ok := db.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolDB instance")
+	}
+
+	if _, ok := db.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default KoolDB instance")
+	}
+
+	if db.exec == nil {
+		t.Error("missing KoolExec on default KoolDB instance")
+	}
+}
+
+func TestNewDBCommand(t *testing.T) {
+	db := newFakeKoolDB(t)
+	cmd := NewDBCommand(db)
+
+	if cmd.Use != "db" {
+		t.Errorf("expecting db command Use, got %s", cmd.Use)
+	}
+
+	if err := cmd.ParseFlags([]string{"--service", "mysql"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if db.Flags.Service != "mysql" {
+		t.Error("did not bind the service flag")
+	}
+}
+
+func TestDBDatabaseDetection(t *testing.T) {
+	db := newFakeKoolDB(t)
+
+	database, err := db.database()
+
+	if err != nil {
+		t.Errorf("unexpected error detecting database service: %v", err)
+	}
+
+	if database.Service != "database" || database.Engine != compose.EngineMySQL {
+		t.Errorf("unexpected database service detected: %v", database)
+	}
+
+	db.Flags.Service = "app"
+
+	if _, err = db.database(); err == nil || !strings.Contains(err.Error(), "service app does not run a supported database image") {
+		t.Errorf("expected unsupported service error, got %v", err)
+	}
+
+	db = newFakeKoolDB(t)
+	_ = os.WriteFile(filepath.Join(db.env.Get("PWD"), "docker-compose.override.yml"), []byte("services:\n  pg:\n    image: postgres:13\n"), os.ModePerm)
+
+	if _, err = db.database(); err == nil || !strings.Contains(err.Error(), "found more than one database service (database, pg)") {
+		t.Errorf("expected more than one database error, got %v", err)
+	}
+
+	db.Flags.Service = "pg"
+
+	if database, err = db.database(); err != nil || database.Engine != compose.EnginePostgreSQL {
+		t.Errorf("failed picking the database service with the flag: %v (%v)", database, err)
+	}
+
+	db = newFakeKoolDB(t)
+	_ = os.Remove(filepath.Join(db.env.Get("PWD"), "docker-compose.yml"))
+
+	if _, err = db.database(); err == nil || !strings.Contains(err.Error(), "could not find a database service") {
+		t.Errorf("expected no database service error, got %v", err)
+	}
+
+	db = newFakeKoolDB(t)
+	_ = os.WriteFile(filepath.Join(db.env.Get("PWD"), "docker-compose.yml"), []byte("\tinvalid"), os.ModePerm)
+
+	if _, err = db.database(); err == nil || !strings.Contains(err.Error(), "failed to parse services") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestDBDumpAndRestoreScripts(t *testing.T) {
+	for _, engine := range []string{compose.EngineMySQL, compose.EngineMariaDB, compose.EnginePostgreSQL} {
+		if dbDumpScripts[engine] == "" || dbRestoreScripts[engine] == "" {
+			t.Errorf("missing dump or restore script for engine %s", engine)
+		}
+
+		if restore := dbRestoreScripts[engine]; !strings.Contains(restore, "DROP DATABASE") || !strings.Contains(restore, "CREATE DATABASE") {
+			t.Errorf("restore script for engine %s should recreate the database", engine)
+		}
+	}
+
+	db := newFakeKoolDB(t)
+
+	if err := db.dump(&bytes.Buffer{}, nil, nil); err != nil {
+		t.Errorf("unexpected error dumping database: %v", err)
+	}
+
+	args := db.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]
+
+	if len(args) != 4 || args[0] != "database" || args[1] != "sh" || args[3] != dbDumpScripts[compose.EngineMySQL] {
+		t.Errorf("bad arguments dumping the database: %v", args)
+	}
+
+	db = newFakeKoolDB(t)
+
+	if err := db.restore(&bytes.Buffer{}, nil, nil); err != nil {
+		t.Errorf("unexpected error restoring database: %v", err)
+	}
+
+	args = db.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]
+
+	if len(args) != 4 || args[3] != dbRestoreScripts[compose.EngineMySQL] {
+		t.Errorf("bad arguments restoring the database: %v", args)
+	}
+}
+
+func TestDBDumpToFile(t *testing.T) {
+	db := newFakeKoolDB(t)
+	path := filepath.Join(db.env.Get("PWD"), "dump.sql.gz")
+
+	if err := db.dumpToFile(path, nil, nil); err != nil {
+		t.Fatalf("unexpected error dumping database to file: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("dump file not created: %v", err)
+	}
+	defer file.Close()
+
+	if _, err = gzip.NewReader(file); err != nil {
+		t.Errorf("dump file is not gzip compressed: %v", err)
+	}
+
+	db = newFakeKoolDB(t)
+	db.exec = newFailedFakeKoolExec()
+	path = filepath.Join(db.env.Get("PWD"), "dump.sql")
+
+	if err = db.dumpToFile(path, nil, nil); err == nil {
+		t.Error("expected error dumping database to file")
+	}
+
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Error("should not leave the dump file behind on failure")
+	}
+}
+
+func TestDBRestoreFromFile(t *testing.T) {
+	db := newFakeKoolDB(t)
+	dir := db.env.Get("PWD")
+
+	_ = os.WriteFile(filepath.Join(dir, "dump.sql"), []byte("SELECT 1;"), os.ModePerm)
+
+	if err := db.restoreFromFile(filepath.Join(dir, "dump.sql"), nil, nil); err != nil {
+		t.Errorf("unexpected error restoring plain dump: %v", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("SELECT 1;"))
+	_ = gz.Close()
+	_ = os.WriteFile(filepath.Join(dir, "dump.sql.gz"), buf.Bytes(), os.ModePerm)
+
+	if err := db.restoreFromFile(filepath.Join(dir, "dump.sql.gz"), nil, nil); err != nil {
+		t.Errorf("unexpected error restoring compressed dump: %v", err)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, "broken.gz"), []byte{0x1f, 0x8b, 0x00}, os.ModePerm)
+
+	if err := db.restoreFromFile(filepath.Join(dir, "broken.gz"), nil, nil); err == nil {
+		t.Error("expected error restoring broken compressed dump")
+	}
+
+	if err := db.restoreFromFile(filepath.Join(dir, "missing.sql"), nil, nil); err == nil {
+		t.Error("expected error restoring missing dump")
+	}
+}
+
+func TestDBEnsureSnapshotsDir(t *testing.T) {
+	db := newFakeKoolDB(t)
+
+	if err := db.ensureSnapshotsDir(); err != nil {
+		t.Fatalf("unexpected error creating snapshots folder: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(db.snapshotsDir(), ".gitignore"))
+
+	if err != nil || string(content) != "*\n" {
+		t.Errorf("snapshots folder should be ignored by git: %s (%v)", content, err)
+	}
+
+	if db.snapshotPath("before") != filepath.Join(db.env.Get("PWD"), ".kool", "snapshots", "before.sql.gz") {
+		t.Errorf("unexpected snapshot path: %s", db.snapshotPath("before"))
+	}
+}
@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// composeWorkingDirLabel is the label docker-compose sets on the
+// containers it creates with the folder of the project
+const composeWorkingDirLabel = "com.docker.compose.project.working_dir"
+
+// KoolDisk holds the shared logic for reporting and pruning the
+// disk usage of the docker resources of each project
+type KoolDisk struct {
+	DefaultKoolService
+
+	env         environment.EnvStorage
+	projectDirs cache.Cache
+
+	listContainers builder.Command
+	listImages     builder.Command
+	inspectImages  builder.Command
+	listVolumes    builder.Command
+	listNetworks   builder.Command
+	buildCache     builder.Command
+}
+
+// diskResource holds a docker resource and its size in bytes
+type diskResource struct {
+	name string
+	size float64
+}
+
+// diskProject holds the docker resources of a docker-compose project
+type diskProject struct {
+	name       string
+	dir        string
+	dirMissing bool
+	running    int
+	containers []diskResource
+	images     []diskResource
+	volumes    []diskResource
+	networks   []string
+}
+
+// NewKoolDisk creates a new handler for docker disk usage logic with default dependencies
+func NewKoolDisk() *KoolDisk {
+	var (
+		filter     = "label=" + composeProjectLabel
+		label      = `{{.Label "` + composeProjectLabel + `"}}`
+		workingDir = `{{.Label "` + composeWorkingDirLabel + `"}}`
+	)
+
+	env := environment.NewEnvStorage()
+
+	return &KoolDisk{
+		*newDefaultKoolService(),
+		env,
+		newProjectDirsCache(env),
+		builder.NewCommand("docker", "ps", "-a", "--size", "--filter", filter, "--format", "{{.ID}}\t"+label+"\t"+workingDir+"\t{{.Status}}\t{{.Size}}"),
+		builder.NewCommand("docker", "image", "ls", "--filter", filter, "--format", "{{.ID}}\t{{.Repository}}\t{{.Tag}}\t{{.Size}}"),
+		builder.NewCommand("docker", "image", "inspect", "--format", `{{.Id}}\t{{index .Config.Labels "`+composeProjectLabel+`"}}`),
+		builder.NewCommand("docker", "system", "df", "-v", "--format", "{{range .Volumes}}{{.Name}}\t"+label+"\t{{.Size}}\n{{end}}"),
+		builder.NewCommand("docker", "network", "ls", "--filter", filter, "--format", "{{.Name}}\t"+label),
+		builder.NewCommand("docker", "system", "df", "--format", "{{.Type}}\t{{.Size}}\t{{.Reclaimable}}"),
+	}
+}
+
+// newProjectDirsCache returns the cache of the folder each docker-compose
+// project was last started from, which outlives its containers
+func newProjectDirsCache(env environment.EnvStorage) cache.Cache {
+	return cache.NewFileCache(filepath.Join(env.Get("HOME"), ".kool", "cache", "projects.json"))
+}
+
+// currentProject returns the docker-compose project name in use
+func (d *KoolDisk) currentProject() string {
+	return compose.ProjectName(d.env.Get("KOOL_NAME"))
+}
+
+// projects lists the docker-compose projects with their docker resources and
+// folder, sorted by name. Images belong to the project set on the label
+// docker-compose gives the ones it builds.
+func (d *KoolDisk) projects() (projects []*diskProject, err error) {
+	var (
+		byName = make(map[string]*diskProject)
+		output string
+	)
+
+	projectOf := func(name string) *diskProject {
+		if _, exists := byName[name]; !exists {
+			byName[name] = &diskProject{name: name}
+		}
+
+		return byName[name]
+	}
+
+	if output, err = d.Exec(d.listContainers); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 5) {
+		project := projectOf(fields[1])
+
+		if project.dir == "" {
+			project.dir = fields[2]
+		}
+
+		if strings.HasPrefix(fields[3], "Up") {
+			project.running++
+		} else {
+			project.containers = append(project.containers, diskResource{fields[0], parseSize(fields[4])})
+		}
+	}
+
+	if output, err = d.Exec(d.listVolumes); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 3) {
+		if fields[1] != "" {
+			project := projectOf(fields[1])
+			project.volumes = append(project.volumes, diskResource{fields[0], parseSize(fields[2])})
+		}
+	}
+
+	if output, err = d.Exec(d.listNetworks); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 2) {
+		project := projectOf(fields[1])
+		project.networks = append(project.networks, fields[0])
+	}
+
+	if output, err = d.Exec(d.listImages); err != nil {
+		return
+	}
+
+	var (
+		images = labeledLines(output, 4)
+		owners = make(map[string]string)
+		ids    []string
+	)
+
+	for _, fields := range images {
+		ids = append(ids, fields[0])
+	}
+
+	if len(ids) > 0 {
+		if output, err = d.Exec(d.inspectImages, ids...); err != nil {
+			return
+		}
+
+		for _, fields := range labeledLines(output, 2) {
+			owners[strings.TrimPrefix(fields[0], "sha256:")] = fields[1]
+		}
+	}
+
+	for _, fields := range images {
+		var owner string
+
+		for id, project := range owners {
+			if strings.HasPrefix(id, fields[0]) {
+				owner = project
+			}
+		}
+
+		if owner == "" {
+			continue
+		}
+
+		name := fields[1] + ":" + fields[2]
+
+		if fields[1] == "<none>" {
+			name = fields[0]
+		}
+
+		project := projectOf(owner)
+		project.images = append(project.images, diskResource{name, parseSize(fields[3])})
+	}
+
+	for _, project := range byName {
+		// the containers are gone after kool stop, so the folder kool start
+		// recorded is used for the projects without containers left
+		if project.dir == "" {
+			project.dir, _ = d.projectDirs.Get(project.name)
+		}
+
+		if project.dir != "" {
+			if _, statErr := os.Stat(project.dir); os.IsNotExist(statErr) {
+				project.dirMissing = true
+			}
+		}
+
+		projects = append(projects, project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].name < projects[j].name
+	})
+
+	return
+}
+
+// buildCacheUsage returns the size of the build cache and how much of it can be reclaimed
+func (d *KoolDisk) buildCacheUsage() (size, reclaimable string, err error) {
+	var output string
+
+	if output, err = d.Exec(d.buildCache); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 3) {
+		if strings.EqualFold(fields[0], "Build Cache") {
+			size, reclaimable = fields[1], fields[2]
+			return
+		}
+	}
+
+	size, reclaimable = "0B", "0B"
+	return
+}
+
+// total returns the size of the resources of the project which can be
+// freed: stopped containers, images and volumes
+func (p *diskProject) total() (total float64) {
+	for _, resources := range [][]diskResource{p.containers, p.images, p.volumes} {
+		total += sumSizes(resources)
+	}
+
+	return
+}
+
+func sumSizes(resources []diskResource) (total float64) {
+	for _, resource := range resources {
+		total += resource.size
+	}
+
+	return
+}
+
+func resourcesNames(resources []diskResource) (names []string) {
+	for _, resource := range resources {
+		names = append(names, resource.name)
+	}
+
+	return
+}
+
+// parseSize parses the human readable sizes docker outputs (i.e. 1.2kB,
+// 133MB or 2B (virtual 133MB)) into bytes; unknown sizes count as zero
+func parseSize(size string) float64 {
+	var (
+		fields = strings.Fields(size)
+		units  = []struct {
+			suffix     string
+			multiplier float64
+		}{
+			{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+			{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12}, {"PB", 1e15},
+			{"B", 1},
+		}
+	)
+
+	if len(fields) == 0 {
+		return 0
+	}
+
+	for _, unit := range units {
+		if strings.HasSuffix(fields[0], unit.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], unit.suffix), 64)
+
+			if err != nil {
+				return 0
+			}
+
+			return value * unit.multiplier
+		}
+	}
+
+	return 0
+}
+
+// dockerSize formats the size in bytes just like docker does
+func dockerSize(size float64) string {
+	var units = []string{"B", "kB", "MB", "GB", "TB", "PB"}
+
+	i := 0
+	for ; size >= 1000 && i < len(units)-1; i++ {
+		size /= 1000
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%.0fB", size)
+	}
+
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", size), ".0") + units[i]
+}
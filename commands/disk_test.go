@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
+	"kool-dev/kool/core/environment"
+	"path/filepath"
+	"testing"
+)
+
+func newFakeKoolDisk(t *testing.T) *KoolDisk {
+	var (
+		dir     = t.TempDir()
+		missing = filepath.Join(dir, "removed")
+	)
+
+	env := environment.NewFakeEnvStorage()
+	env.Set("KOOL_NAME", "my-app")
+
+	return &KoolDisk{
+		*newFakeKoolService(),
+		env,
+		&cache.FakeCache{MockEntries: map[string]string{}},
+		&builder.FakeCommand{MockExecOut: "c1\tmy-app\t" + dir + "\tUp 2 hours\t2B (virtual 133MB)\n" +
+			"c2\tmy-app\t" + dir + "\tExited (0) 2 days ago\t1.5kB (virtual 133MB)\n" +
+			"c3\told\t" + missing + "\tExited (137) 3 weeks ago\t2MB (virtual 400MB)\n"},
+		&builder.FakeCommand{MockExecOut: "1a\tmy-app_app\tlatest\t500MB\n2b\told-web\tlatest\t1GB\n3c\tmy\tlatest\t10MB\n4d\tmy-app-admin_web\tlatest\t1MB\n5e\t<none>\t<none>\t3MB\n6f\tmy-app-cache\tlatest\t20MB\n"},
+		&builder.FakeCommand{MockExecOut: "sha256:1a00\tmy-app\nsha256:2b00\told\nsha256:3c00\t\nsha256:4d00\tmy-app-admin\nsha256:5e00\t\nsha256:6f00\tmy-app-admin\n"},
+		&builder.FakeCommand{MockExecOut: "my-app_database\tmy-app\t200MB\nold_database\told\t1.2GB\nanonymous\t\t1MB\n"},
+		&builder.FakeCommand{MockExecOut: "my-app_default\tmy-app\nold_default\told\nmy-app-admin_default\tmy-app-admin\n"},
+		&builder.FakeCommand{MockExecOut: "Images\t2GB\t1GB (50%)\nBuild Cache\t3.4GB\t1.2GB\n"},
+	}
+}
+
+func TestNewKoolDisk(t *testing.T) {
+	disk := NewKoolDisk()
+
+	if _, ok := disk.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolDisk instance")
+	}
+
+	for _, command := range []builder.Command{disk.listContainers, disk.listImages, disk.inspectImages, disk.listVolumes, disk.listNetworks, disk.buildCache} {
+		if command.Cmd() != "docker" {
+			t.Errorf("unexpected command on default KoolDisk instance: %s", command.Cmd())
+		}
+	}
+}
+
+func TestDiskProjects(t *testing.T) {
+	disk := newFakeKoolDisk(t)
+
+	projects, err := disk.projects()
+
+	if err != nil {
+		t.Fatalf("unexpected error listing projects: %v", err)
+	}
+
+	if len(projects) != 3 || projects[0].name != "my-app" || projects[1].name != "my-app-admin" || projects[2].name != "old" {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+
+	app, admin, old := projects[0], projects[1], projects[2]
+
+	if app.running != 1 || len(app.containers) != 1 || app.containers[0].name != "c2" || app.containers[0].size != 1500 {
+		t.Errorf("unexpected containers for my-app: %v (%d running)", app.containers, app.running)
+	}
+
+	if app.dirMissing || !old.dirMissing || admin.dir != "" {
+		t.Error("failed checking the projects folders")
+	}
+
+	if len(app.images) != 1 || app.images[0].name != "my-app_app:latest" {
+		t.Errorf("unexpected images for my-app: %v", app.images)
+	}
+
+	// images only belong to the project on their label, whatever their names
+	if len(admin.images) != 2 || admin.images[0].name != "my-app-admin_web:latest" || admin.images[1].name != "my-app-cache:latest" {
+		t.Errorf("unexpected images for my-app-admin: %v", admin.images)
+	}
+
+	if len(old.images) != 1 || old.images[0].name != "old-web:latest" || len(old.volumes) != 1 || len(old.networks) != 1 {
+		t.Errorf("unexpected resources for old: %v", old)
+	}
+
+	if old.total() != 2e6+1e9+1.2e9 {
+		t.Errorf("unexpected total for old: %f", old.total())
+	}
+
+	if disk.currentProject() != "my-app" {
+		t.Errorf("unexpected current project: %s", disk.currentProject())
+	}
+}
+
+func TestDiskProjectsStopped(t *testing.T) {
+	disk := newFakeKoolDisk(t)
+	dir := t.TempDir()
+
+	// kool stop removes the containers, along with their folder labels
+	disk.listContainers.(*builder.FakeCommand).MockExecOut = ""
+	disk.projectDirs.(*cache.FakeCache).MockEntries["old"] = filepath.Join(dir, "removed")
+	disk.projectDirs.(*cache.FakeCache).MockEntries["my-app-admin"] = dir
+
+	projects, err := disk.projects()
+
+	if err != nil {
+		t.Fatalf("unexpected error listing projects: %v", err)
+	}
+
+	app, admin, old := projects[0], projects[1], projects[2]
+
+	if app.dir != "" || admin.dirMissing || admin.dir != dir || !old.dirMissing {
+		t.Errorf("failed checking the recorded projects folders: %v", projects)
+	}
+}
+
+func TestDiskProjectsErrors(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		disk := newFakeKoolDisk(t)
+		[]builder.Command{disk.listContainers, disk.listVolumes, disk.listNetworks, disk.listImages, disk.inspectImages}[i].(*builder.FakeCommand).MockExecError = errors.New("list error")
+
+		if _, err := disk.projects(); err == nil || err.Error() != "list error" {
+			t.Errorf("expected list error, got %v", err)
+		}
+	}
+}
+
+func TestDiskBuildCacheUsage(t *testing.T) {
+	disk := newFakeKoolDisk(t)
+
+	if size, reclaimable, err := disk.buildCacheUsage(); err != nil || size != "3.4GB" || reclaimable != "1.2GB" {
+		t.Errorf("unexpected build cache usage: %s, %s (%v)", size, reclaimable, err)
+	}
+
+	disk.buildCache.(*builder.FakeCommand).MockExecOut = ""
+
+	if size, _, _ := disk.buildCacheUsage(); size != "0B" {
+		t.Errorf("unexpected empty build cache usage: %s", size)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for size, expected := range map[string]float64{
+		"2B":                 2,
+		"1.5kB":              1500,
+		"133MB":              133e6,
+		"1.2GB":              1.2e9,
+		"2B (virtual 133MB)": 2,
+		"1KiB":               1024,
+		"N/A":                0,
+		"":                   0,
+		"xMB":                0,
+	} {
+		if got := parseSize(size); got != expected {
+			t.Errorf("expected %s to be %f bytes, got %f", size, expected, got)
+		}
+	}
+}
+
+func TestDockerSize(t *testing.T) {
+	for size, expected := range map[float64]string{
+		0:      "0B",
+		999:    "999B",
+		1500:   "1.5kB",
+		133e6:  "133MB",
+		2.25e9: "2.2GB",
+		1e12:   "1TB",
+	} {
+		if got := dockerSize(size); got != expected {
+			t.Errorf("expected %f bytes to be %s, got %s", size, expected, got)
+		}
+	}
+}
@@ -0,0 +1,687 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/services/compose"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doctorOK      = "ok"
+	doctorWarning = "warning"
+	doctorError   = "error"
+	doctorSkipped = "skipped"
+)
+
+var (
+	doctorMinDockerVersion  = semver.MustParse("19.3.0")
+	doctorMinComposeVersion = semver.MustParse("1.28.0")
+	envLineRegex            = regexp.MustCompile(`^(export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=`)
+)
+
+// doctorFileSharingFiles holds how many files the file sharing check writes
+const doctorFileSharingFiles = 500
+
+// KoolDoctorFlags holds the flags for the kool doctor command
+type KoolDoctorFlags struct {
+	JSON bool
+}
+
+// KoolDoctor holds handlers and functions to implement the doctor command logic
+type KoolDoctor struct {
+	DefaultKoolService
+	Flags *KoolDoctorFlags
+
+	env        environment.EnvStorage
+	portsCheck *KoolPortsCheck
+	goos       string
+	dial       func(path string) error
+
+	dockerVersion  builder.Command
+	composeVersion builder.Command
+	listNetwork    builder.Command
+	diskFree       builder.Command
+	runContainer   builder.Command
+}
+
+// doctorCheck holds the result of one of the doctor checks
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// doctorReport holds the results of all the doctor checks
+type doctorReport struct {
+	Version  string         `json:"kool_version"`
+	OS       string         `json:"os"`
+	Arch     string         `json:"arch"`
+	Problems int            `json:"problems"`
+	Checks   []*doctorCheck `json:"checks"`
+}
+
+// NewKoolDoctor creates a new handler for the environment diagnostics with default dependencies
+func NewKoolDoctor() *KoolDoctor {
+	return &KoolDoctor{
+		*newDefaultKoolService(),
+		&KoolDoctorFlags{},
+		environment.NewEnvStorage(),
+		NewKoolPortsCheck(),
+		runtime.GOOS,
+		func(path string) (err error) {
+			var conn net.Conn
+
+			if conn, err = net.DialTimeout("unix", path, 2*time.Second); err == nil {
+				conn.Close()
+			}
+
+			return
+		},
+		builder.NewCommand("docker", "version", "--format", "{{.Client.Version}}\t{{.Client.APIVersion}}\t{{.Server.Version}}\t{{.Server.APIVersion}}\t{{.Server.MinAPIVersion}}"),
+		builder.NewCommand("docker-compose", "version", "--short"),
+		builder.NewCommand("docker", "network", "ls", "-q", "-f"),
+		builder.NewCommand("docker", "run", "--rm", VolumeArchiveImage, "df", "-Pk", "/"),
+		builder.NewCommand("docker", "run", "--rm"),
+	}
+}
+
+// NewDoctorCommand initializes new kool doctor command
+func NewDoctorCommand(doctor *KoolDoctor) (doctorCmd *cobra.Command) {
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the local environment of kool projects",
+		Long: `Run a battery of checks on the local environment: Docker version and API
+compatibility, the docker-compose backend, the Docker socket permissions, free
+disk space, the global network, the .env files, the user mapping (KOOL_ASUSER),
+the host ports published by the project and the file sharing performance. Each
+problem comes with a hint on how to fix it; use --json to attach the report to
+support tickets.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(doctor),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	doctorCmd.Flags().BoolVarP(&doctor.Flags.JSON, "json", "", false, "Print the report as JSON.")
+	return
+}
+
+func AddKoolDoctor(root *cobra.Command) {
+	var doctorCmd = NewDoctorCommand(NewKoolDoctor())
+
+	root.AddCommand(doctorCmd)
+	doctorCmd.AddCommand(NewDoctorDiskCommand(NewKoolDoctorDisk()))
+}
+
+// Execute runs the doctor logic with incoming arguments.
+func (d *KoolDoctor) Execute(args []string) (err error) {
+	var (
+		report = &doctorReport{Version: version, OS: d.goos, Arch: runtime.GOARCH}
+		docker = d.checkDocker()
+		daemon = docker.Status != doctorError
+	)
+
+	report.Checks = []*doctorCheck{
+		docker.doctorCheck,
+		d.whenDaemon(daemon, "Docker API", docker.apiCheck),
+		d.checkCompose(),
+		d.checkSocket(),
+		d.whenDaemon(daemon, "Disk space", d.checkDiskSpace),
+		d.whenDaemon(daemon, "Global network", d.checkGlobalNetwork),
+		d.checkEnvFiles(),
+		d.checkUserMapping(),
+		d.whenDaemon(daemon, "Ports", d.checkPorts),
+		d.whenDaemon(daemon, "File sharing", d.checkFileSharing),
+	}
+
+	for _, check := range report.Checks {
+		if check.Status == doctorError {
+			report.Problems++
+		}
+	}
+
+	if d.Flags.JSON {
+		var encoded []byte
+
+		if encoded, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return
+		}
+
+		d.Println(string(encoded))
+		return
+	}
+
+	for _, check := range report.Checks {
+		switch check.Status {
+		case doctorOK:
+			d.Success("✓ ", check.Name, ": ", check.Detail)
+		case doctorWarning:
+			d.Warning("! ", check.Name, ": ", check.Detail)
+		case doctorError:
+			d.Error(fmt.Errorf("%s: %s", check.Name, check.Detail))
+		default:
+			d.Println("-", check.Name+":", check.Detail)
+		}
+
+		if check.Hint != "" {
+			d.Println("  ", check.Hint)
+		}
+	}
+
+	if report.Problems > 0 {
+		err = fmt.Errorf("found %d problem(s) on the environment", report.Problems)
+	}
+
+	return
+}
+
+// whenDaemon runs the check only if the Docker daemon is reachable
+func (d *KoolDoctor) whenDaemon(daemon bool, name string, check func() *doctorCheck) *doctorCheck {
+	if !daemon {
+		return &doctorCheck{name, doctorSkipped, "the Docker daemon is not reachable", ""}
+	}
+
+	return check()
+}
+
+// dockerCheck holds the Docker check result along with the API versions
+type dockerCheck struct {
+	*doctorCheck
+	clientAPI, serverAPI, minAPI string
+}
+
+func (d *KoolDoctor) checkDocker() *dockerCheck {
+	var (
+		check = &dockerCheck{doctorCheck: &doctorCheck{Name: "Docker"}}
+		out   string
+		err   error
+	)
+
+	if err = d.LookPath(d.dockerVersion); err != nil {
+		check.Status, check.Detail = doctorError, "docker was not found on PATH"
+		check.Hint = "Install Docker: https://docs.docker.com/get-docker/"
+		return check
+	}
+
+	if out, err = d.Exec(d.dockerVersion); err != nil {
+		check.Status = doctorError
+
+		if strings.Contains(strings.ToLower(err.Error()), "permission denied") {
+			check.Detail = "permission denied connecting to the Docker daemon"
+			check.Hint = "Add your user to the docker group (sudo usermod -aG docker $USER) and log in again"
+		} else {
+			check.Detail = "the Docker daemon is not running"
+			check.Hint = "Start Docker Desktop, or the Docker service (i.e. sudo systemctl start docker)"
+		}
+
+		return check
+	}
+
+	fields := strings.Split(out, "\t")
+
+	if len(fields) < 5 {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("unexpected docker version output: %s", out)
+		return check
+	}
+
+	check.clientAPI, check.serverAPI, check.minAPI = fields[1], fields[3], fields[4]
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("Docker %s (client %s)", fields[2], fields[0])
+
+	if server, parseErr := parseDoctorVersion(fields[2]); parseErr == nil && server.LT(doctorMinDockerVersion) {
+		check.Status = doctorWarning
+		check.Detail = fmt.Sprintf("Docker %s is outdated; kool needs 19.03 or newer", fields[2])
+		check.Hint = "Upgrade Docker: https://docs.docker.com/get-docker/"
+	}
+
+	return check
+}
+
+// apiCheck compares the client and daemon API versions
+func (c *dockerCheck) apiCheck() (api *doctorCheck) {
+	api = &doctorCheck{Name: "Docker API"}
+
+	switch {
+	case c.clientAPI == "":
+		api.Status, api.Detail = doctorSkipped, "could not read the API versions"
+	case compareAPIVersions(c.clientAPI, c.minAPI) < 0:
+		api.Status = doctorError
+		api.Detail = fmt.Sprintf("client API %s is older than the minimum %s supported by the daemon", c.clientAPI, c.minAPI)
+		api.Hint = "Upgrade the docker CLI"
+	case compareAPIVersions(c.clientAPI, c.serverAPI) > 0:
+		api.Status = doctorWarning
+		api.Detail = fmt.Sprintf("client API %s is newer than the daemon API %s", c.clientAPI, c.serverAPI)
+		api.Hint = fmt.Sprintf("Upgrade the Docker daemon, or set DOCKER_API_VERSION=%s", c.serverAPI)
+	default:
+		api.Status, api.Detail = doctorOK, fmt.Sprintf("client %s, daemon %s (minimum %s)", c.clientAPI, c.serverAPI, c.minAPI)
+	}
+
+	return
+}
+
+// parseDoctorVersion parses versions like Docker ones (i.e. 19.03.13-ce),
+// which are not semver for their leading zeros
+func parseDoctorVersion(raw string) (parsed semver.Version, err error) {
+	var parts []string
+
+	raw = strings.SplitN(strings.TrimPrefix(strings.TrimSpace(raw), "v"), "-", 2)[0]
+
+	for _, part := range strings.Split(raw, ".") {
+		var number int
+
+		if number, err = strconv.Atoi(part); err != nil {
+			return
+		}
+
+		parts = append(parts, strconv.Itoa(number))
+	}
+
+	parsed, err = semver.ParseTolerant(strings.Join(parts, "."))
+	return
+}
+
+// compareAPIVersions compares Docker API versions (i.e. 1.41)
+func compareAPIVersions(a, b string) int {
+	var (
+		partsA = strings.Split(a, ".")
+		partsB = strings.Split(b, ".")
+	)
+
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (d *KoolDoctor) checkCompose() (check *doctorCheck) {
+	var (
+		out string
+		err error
+	)
+
+	check = &doctorCheck{Name: "Docker Compose"}
+
+	if err = d.LookPath(d.composeVersion); err != nil {
+		check.Status = doctorWarning
+		check.Detail = fmt.Sprintf("docker-compose was not found on PATH; kool runs it inside a container (%s), which is slower", compose.DockerComposeImage)
+		check.Hint = "Install docker-compose: https://docs.docker.com/compose/install/"
+		return
+	}
+
+	if out, err = d.Exec(d.composeVersion); err != nil {
+		check.Status, check.Detail = doctorError, fmt.Sprintf("failed to run docker-compose: %v", err)
+		check.Hint = "Reinstall docker-compose: https://docs.docker.com/compose/install/"
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("docker-compose %s (local)", out)
+
+	if current, parseErr := parseDoctorVersion(out); parseErr == nil && current.LT(doctorMinComposeVersion) {
+		check.Status = doctorWarning
+		check.Detail = fmt.Sprintf("docker-compose %s is older than %s, which kool needs for profiles", out, doctorMinComposeVersion)
+		check.Hint = "Upgrade docker-compose: https://docs.docker.com/compose/install/"
+	}
+
+	return
+}
+
+func (d *KoolDoctor) checkSocket() (check *doctorCheck) {
+	var host = d.env.Get("DOCKER_HOST")
+
+	check = &doctorCheck{Name: "Docker socket"}
+
+	if host == "" && d.goos != "windows" {
+		host = "unix:///var/run/docker.sock"
+	}
+
+	if !strings.HasPrefix(host, "unix://") {
+		check.Status, check.Detail = doctorSkipped, "not using a unix socket"
+
+		if host != "" {
+			check.Detail = fmt.Sprintf("DOCKER_HOST is %s", host)
+		}
+
+		return
+	}
+
+	path := strings.TrimPrefix(host, "unix://")
+
+	if err := d.dial(path); err != nil {
+		check.Status = doctorError
+
+		switch {
+		case os.IsNotExist(err) || strings.Contains(err.Error(), "no such file"):
+			check.Detail = fmt.Sprintf("%s does not exist", path)
+			check.Hint = "Start Docker, or point DOCKER_HOST to its socket"
+		case os.IsPermission(err) || strings.Contains(err.Error(), "permission denied"):
+			check.Detail = fmt.Sprintf("no permission to use %s", path)
+			check.Hint = "Add your user to the docker group (sudo usermod -aG docker $USER) and log in again"
+		default:
+			check.Detail = fmt.Sprintf("could not connect to %s: %v", path, err)
+			check.Hint = "Start Docker, or point DOCKER_HOST to its socket"
+		}
+
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, path
+	return
+}
+
+func (d *KoolDoctor) checkDiskSpace() (check *doctorCheck) {
+	var (
+		out       string
+		err       error
+		available float64
+	)
+
+	check = &doctorCheck{Name: "Disk space"}
+
+	if out, err = d.Exec(d.diskFree); err != nil {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("could not check the Docker free disk space: %v", err)
+		return
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	fields := strings.Fields(lines[len(lines)-1])
+
+	if len(fields) < 4 {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("unexpected df output: %s", out)
+		return
+	}
+
+	if available, err = strconv.ParseFloat(fields[3], 64); err != nil {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("unexpected df output: %s", out)
+		return
+	}
+
+	available *= 1024
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("%s free on the Docker storage", dockerSize(available))
+
+	if available < 5e9 {
+		check.Status = doctorWarning
+		check.Hint = "Check the disk usage of each project with 'kool doctor disk' and free space with 'kool prune'"
+
+		if available < 1e9 {
+			check.Status = doctorError
+		}
+	}
+
+	return
+}
+
+func (d *KoolDoctor) checkGlobalNetwork() (check *doctorCheck) {
+	var (
+		name = d.env.Get("KOOL_GLOBAL_NETWORK")
+		out  string
+		err  error
+	)
+
+	check = &doctorCheck{Name: "Global network"}
+
+	if out, err = d.Exec(d.listNetwork, fmt.Sprintf("NAME=^%s$", name)); err != nil {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("could not list the networks: %v", err)
+		return
+	}
+
+	if out == "" {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("network %s does not exist yet", name)
+		check.Hint = fmt.Sprintf("It is created by 'kool start', or create it with 'docker network create --attachable %s'", name)
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("%s exists", name)
+	return
+}
+
+func (d *KoolDoctor) checkEnvFiles() (check *doctorCheck) {
+	var (
+		dir      = d.env.Get("PWD")
+		problems []string
+		found    []string
+	)
+
+	check = &doctorCheck{Name: "Environment files"}
+
+	for _, name := range []string{".env", ".env.local"} {
+		file, err := os.Open(filepath.Join(dir, name))
+
+		if err != nil {
+			if !os.IsNotExist(err) {
+				problems = append(problems, fmt.Sprintf("could not read %s: %v", name, err))
+			}
+			continue
+		}
+
+		found = append(found, name)
+		problems = append(problems, envFileProblems(name, file)...)
+		file.Close()
+	}
+
+	if len(found) == 0 || found[0] != ".env" {
+		for _, example := range []string{".env.example", ".env.dist"} {
+			if _, err := os.Stat(filepath.Join(dir, example)); err == nil {
+				check.Status, check.Detail = doctorWarning, fmt.Sprintf(".env is missing, but there is a %s", example)
+				check.Hint = fmt.Sprintf("Create it with 'cp %s .env'", example)
+				return
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		check.Status, check.Detail = doctorWarning, strings.Join(problems, "; ")
+		check.Hint = "Use one KEY=value definition per line; comments start with #"
+		return
+	}
+
+	if len(found) == 0 {
+		check.Status, check.Detail = doctorOK, "no .env files"
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, strings.Join(found, ", ")
+	return
+}
+
+// envFileProblems lists the invalid and duplicated lines of the .env file
+func envFileProblems(name string, file *os.File) (problems []string) {
+	var (
+		scanner    = bufio.NewScanner(file)
+		invalid    []string
+		seen       = make(map[string]bool)
+		duplicated = make(map[string]bool)
+		lineNumber int
+		inQuotes   bool
+	)
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if inQuotes {
+			// multiline quoted values end with the closing quote
+			inQuotes = !strings.HasSuffix(line, `"`)
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := envLineRegex.FindStringSubmatch(line)
+
+		if matches == nil {
+			invalid = append(invalid, strconv.Itoa(lineNumber))
+			continue
+		}
+
+		if seen[matches[2]] {
+			duplicated[matches[2]] = true
+		}
+
+		seen[matches[2]] = true
+
+		value := strings.TrimSpace(line[len(matches[0]):])
+		inQuotes = strings.HasPrefix(value, `"`) && (len(value) == 1 || !strings.HasSuffix(value, `"`))
+	}
+
+	if len(invalid) > 0 {
+		problems = append(problems, fmt.Sprintf("invalid lines on %s: %s", name, strings.Join(invalid, ", ")))
+	}
+
+	if len(duplicated) > 0 {
+		var keys []string
+
+		for key := range duplicated {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+		problems = append(problems, fmt.Sprintf("defined more than once on %s: %s", name, strings.Join(keys, ", ")))
+	}
+
+	return
+}
+
+func (d *KoolDoctor) checkUserMapping() (check *doctorCheck) {
+	var (
+		asuser = d.env.Get("KOOL_ASUSER")
+		uid    = d.env.Get("UID")
+	)
+
+	check = &doctorCheck{Name: "User mapping"}
+
+	if d.goos == "windows" {
+		check.Status, check.Detail = doctorSkipped, "not needed on Windows"
+		return
+	}
+
+	if asuser != uid {
+		check.Status = doctorWarning
+		check.Detail = fmt.Sprintf("KOOL_ASUSER is %s but your user ID is %s; files created by the containers will belong to another user", asuser, uid)
+		check.Hint = "Remove KOOL_ASUSER from your .env files and environment, so kool sets it to your user ID"
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("containers run as UID %s (KOOL_ASUSER)", uid)
+	return
+}
+
+func (d *KoolDoctor) checkPorts() (check *doctorCheck) {
+	var (
+		published []compose.PublishedPort
+		inUse     []string
+		checked   = make(map[string]bool)
+		err       error
+	)
+
+	check = &doctorCheck{Name: "Ports"}
+
+	if published, err = d.portsCheck.publishedPorts(); err != nil {
+		check.Status, check.Detail = doctorWarning, err.Error()
+		return
+	}
+
+	if len(published) == 0 {
+		check.Status, check.Detail = doctorOK, "no host ports published"
+		return
+	}
+
+	project := d.portsCheck.projectPorts()
+
+	for _, port := range published {
+		hostPort := port.Resolve(d.env.Get)
+
+		if checked[hostPort] || project[hostPort] {
+			continue
+		}
+
+		checked[hostPort] = true
+
+		if !d.portsCheck.ports.IsAvailable(hostPort) {
+			inUse = append(inUse, fmt.Sprintf("%s (%s)", hostPort, port.Service))
+		}
+	}
+
+	if len(inUse) > 0 {
+		check.Status, check.Detail = doctorWarning, fmt.Sprintf("already in use: %s", strings.Join(inUse, ", "))
+		check.Hint = "'kool start' offers to remap the ports set through environment variables; otherwise stop whatever is using them"
+		return
+	}
+
+	check.Status, check.Detail = doctorOK, fmt.Sprintf("%d host port(s) available", len(checked))
+	return
+}
+
+func (d *KoolDoctor) checkFileSharing() (check *doctorCheck) {
+	var (
+		mount  = fmt.Sprintf("%s:/kool-doctor", d.env.Get("PWD"))
+		script = fmt.Sprintf("mkdir -p .kool-doctor && for i in $(seq %d); do echo kool > .kool-doctor/$i; done; rm -rf .kool-doctor", doctorFileSharingFiles)
+		start  time.Time
+		base   time.Duration
+		err    error
+	)
+
+	check = &doctorCheck{Name: "File sharing"}
+	hint := "Make sure the project folder is shared with Docker"
+
+	// a first run measures the overhead of starting the container itself
+	start = time.Now()
+	if _, err = d.Exec(d.runContainer, "-v", mount, "-w", "/kool-doctor", VolumeArchiveImage, "true"); err != nil {
+		check.Status, check.Detail, check.Hint = doctorWarning, fmt.Sprintf("could not mount the project folder: %v", err), hint
+		return
+	}
+	base = time.Since(start)
+
+	start = time.Now()
+	if _, err = d.Exec(d.runContainer, "-v", mount, "-w", "/kool-doctor", VolumeArchiveImage, "sh", "-c", script); err != nil {
+		check.Status, check.Detail, check.Hint = doctorWarning, fmt.Sprintf("could not write to the project folder: %v", err), hint
+		return
+	}
+
+	elapsed := time.Since(start) - base
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	check.Status = doctorOK
+	check.Detail = fmt.Sprintf("wrote %d files to the project folder in %s", doctorFileSharingFiles, elapsed.Round(time.Millisecond))
+
+	if elapsed > 5*time.Second {
+		check.Status = doctorWarning
+		check.Hint = "File sharing is slow: keep the project inside the WSL 2 filesystem on Windows, or enable VirtioFS on Docker Desktop for Mac"
+	}
+
+	return
+}
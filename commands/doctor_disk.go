@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/shell"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolDoctorDisk holds handlers and functions to implement the doctor disk command logic
+type KoolDoctorDisk struct {
+	*KoolDisk
+
+	table shell.TableWriter
+}
+
+// NewKoolDoctorDisk creates a new handler for the disk usage report with default dependencies
+func NewKoolDoctorDisk() *KoolDoctorDisk {
+	return &KoolDoctorDisk{
+		NewKoolDisk(),
+		shell.NewTableWriter(),
+	}
+}
+
+// Execute runs the doctor disk logic with incoming arguments.
+func (d *KoolDoctorDisk) Execute(args []string) (err error) {
+	var (
+		projects               []*diskProject
+		cacheSize, reclaimable string
+	)
+
+	if projects, err = d.projects(); err != nil {
+		return
+	}
+
+	if cacheSize, reclaimable, err = d.buildCacheUsage(); err != nil {
+		return
+	}
+
+	if len(projects) == 0 {
+		d.Println("No docker-compose projects found")
+	} else {
+		d.table.SetWriter(d.OutStream())
+		d.table.AppendHeader("Project", "Stopped Containers", "Images", "Volumes", "Networks", "Reclaimable", "Directory")
+
+		for _, project := range projects {
+			dir := project.dir
+
+			switch {
+			case dir == "":
+				dir = "-"
+			case project.dirMissing:
+				dir += " (missing)"
+			}
+
+			name := project.name
+			if name == d.currentProject() {
+				name += " (current)"
+			}
+
+			d.table.AppendRow(
+				name,
+				fmt.Sprintf("%d (%s)", len(project.containers), dockerSize(sumSizes(project.containers))),
+				fmt.Sprintf("%d (%s)", len(project.images), dockerSize(sumSizes(project.images))),
+				fmt.Sprintf("%d (%s)", len(project.volumes), dockerSize(sumSizes(project.volumes))),
+				fmt.Sprint(len(project.networks)),
+				dockerSize(project.total()),
+				dir,
+			)
+		}
+
+		d.table.Render()
+	}
+
+	d.Println(fmt.Sprintf("Build cache: %s (%s reclaimable)", cacheSize, reclaimable))
+	return
+}
+
+// NewDoctorDiskCommand initializes new kool doctor disk command
+func NewDoctorDiskCommand(disk *KoolDoctorDisk) *cobra.Command {
+	return &cobra.Command{
+		Use:   "disk",
+		Short: "Report the disk usage of the docker resources of each project",
+		Long: `Report the stopped containers, images, volumes and networks of each
+docker-compose project (matched by the project name docker-compose labels them
+with, which kool sets from KOOL_NAME), along with the project folder, flagging
+the ones that no longer exist. Use 'kool prune' to remove them.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(disk),
+
+		DisableFlagsInUseLine: true,
+	}
+}
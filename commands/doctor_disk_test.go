@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolDoctorDisk(t *testing.T) *KoolDoctorDisk {
+	return &KoolDoctorDisk{
+		newFakeKoolDisk(t),
+		&shell.FakeTableWriter{},
+	}
+}
+
+func TestNewKoolDoctorDisk(t *testing.T) {
+	disk := NewKoolDoctorDisk()
+
+	if disk.KoolDisk == nil {
+		t.Error("missing KoolDisk on default KoolDoctorDisk instance")
+	}
+
+	if _, ok := disk.table.(*shell.DefaultTableWriter); !ok {
+		t.Error("unexpected shell.TableWriter on default KoolDoctorDisk instance")
+	}
+}
+
+func TestDoctorDiskCommand(t *testing.T) {
+	disk := newFakeKoolDoctorDisk(t)
+
+	if err := NewDoctorDiskCommand(disk).Execute(); err != nil {
+		t.Fatalf("unexpected error reporting disk usage: %v", err)
+	}
+
+	table := disk.table.(*shell.FakeTableWriter)
+
+	if !table.CalledRender || len(table.Rows) != 3 {
+		t.Fatalf("expected 3 projects rows, got %v", table.Rows)
+	}
+
+	if row := fmt.Sprint(table.Rows[0]...); !strings.HasPrefix(row, "my-app (current)1 (1.5kB)1 (500MB)1 (200MB)1700MB") {
+		t.Errorf("unexpected current project row: %v", table.Rows[0])
+	}
+
+	if row := table.Rows[1]; row[6] != "-" {
+		t.Errorf("expected unknown folder for my-app-admin, got %v", row[6])
+	}
+
+	if row := table.Rows[2]; !strings.HasSuffix(fmt.Sprint(row[6]), "removed (missing)") || row[5] != "2.2GB" {
+		t.Errorf("unexpected row for project with missing folder: %v", row)
+	}
+
+	if lines := disk.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "Build cache: 3.4GB (1.2GB reclaimable)" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
+
+func TestDoctorDiskCommandNoProjects(t *testing.T) {
+	disk := newFakeKoolDoctorDisk(t)
+	disk.listContainers.(*builder.FakeCommand).MockExecOut = ""
+	disk.listVolumes.(*builder.FakeCommand).MockExecOut = ""
+	disk.listNetworks.(*builder.FakeCommand).MockExecOut = ""
+	disk.listImages.(*builder.FakeCommand).MockExecOut = ""
+
+	if err := NewDoctorDiskCommand(disk).Execute(); err != nil {
+		t.Fatalf("unexpected error reporting disk usage: %v", err)
+	}
+
+	if disk.table.(*shell.FakeTableWriter).CalledRender {
+		t.Error("should not render the table without projects")
+	}
+
+	if lines := disk.shell.(*shell.FakeShell).OutLines; len(lines) != 2 || lines[0] != "No docker-compose projects found" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
+
+func TestDoctorDiskCommandErrors(t *testing.T) {
+	disk := newFakeKoolDoctorDisk(t)
+	disk.listContainers.(*builder.FakeCommand).MockExecError = errors.New("containers error")
+
+	assertExecGotError(t, NewDoctorDiskCommand(disk), "containers error")
+
+	disk = newFakeKoolDoctorDisk(t)
+	disk.buildCache.(*builder.FakeCommand).MockExecError = errors.New("build cache error")
+
+	assertExecGotError(t, NewDoctorDiskCommand(disk), "build cache error")
+}
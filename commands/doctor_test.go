@@ -0,0 +1,350 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/network"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolDoctor(t *testing.T) *KoolDoctor {
+	portsCheck := newFakeKoolPortsCheck(t)
+	portsCheck.env.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+	portsCheck.env.Set("KOOL_ASUSER", "1000")
+	portsCheck.env.Set("UID", "1000")
+	portsCheck.env.Set("DOCKER_HOST", "unix:///var/run/docker.sock")
+
+	return &KoolDoctor{
+		*newFakeKoolService(),
+		&KoolDoctorFlags{},
+		portsCheck.env,
+		portsCheck,
+		"linux",
+		func(path string) error { return nil },
+		&builder.FakeCommand{MockCmd: "version", MockExecOut: "20.10.6\t1.41\t20.10.6\t1.41\t1.12"},
+		&builder.FakeCommand{MockCmd: "docker-compose", MockExecOut: "1.29.1"},
+		&builder.FakeCommand{MockCmd: "network", MockExecOut: "abc123"},
+		&builder.FakeCommand{MockCmd: "df", MockExecOut: "Filesystem 1024-blocks Used Available Capacity Mounted on\noverlay 61255492 20000000 41255492 33% /"},
+		&builder.FakeCommand{MockCmd: "run"},
+	}
+}
+
+func doctorChecks(t *testing.T, doctor *KoolDoctor) (checks map[string]*doctorCheck) {
+	doctor.Flags.JSON = true
+
+	if err := doctor.Execute(nil); err != nil {
+		t.Fatalf("unexpected error running doctor: %v", err)
+	}
+
+	var report doctorReport
+
+	if lines := doctor.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &report) != nil {
+		t.Fatalf("failed parsing JSON report: %v", lines)
+	}
+
+	checks = make(map[string]*doctorCheck)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+
+	return
+}
+
+func TestNewKoolDoctor(t *testing.T) {
+	doctor := NewKoolDoctor()
+
+	if doctor.Flags == nil || doctor.portsCheck == nil || doctor.dial == nil {
+		t.Error("missing dependencies on default KoolDoctor instance")
+	}
+
+	if doctor.dockerVersion.Cmd() != "docker" || doctor.composeVersion.Cmd() != "docker-compose" {
+		t.Error("unexpected commands on default KoolDoctor instance")
+	}
+
+	if err := doctor.dial(filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Error("expected error dialing missing socket")
+	}
+}
+
+func TestAddKoolDoctor(t *testing.T) {
+	root := NewRootCmd(nil)
+
+	AddKoolDoctor(root)
+
+	if cmd, _, err := root.Find([]string{"doctor", "disk"}); err != nil || cmd.Use != "disk" {
+		t.Errorf("expected doctor disk command, got %v (%v)", cmd, err)
+	}
+
+	if cmd, _, _ := root.Find([]string{"doctor"}); cmd.Flags().Lookup("json") == nil {
+		t.Error("missing --json flag on doctor command")
+	}
+}
+
+func TestDoctorAllChecks(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+
+	checks := doctorChecks(t, doctor)
+
+	if len(checks) != 10 {
+		t.Errorf("expected 10 checks, got %d", len(checks))
+	}
+
+	for name, check := range checks {
+		if check.Status != doctorOK {
+			t.Errorf("expected check %s to be ok, got %s: %s", name, check.Status, check.Detail)
+		}
+	}
+
+	if checks["Docker"].Detail != "Docker 20.10.6 (client 20.10.6)" {
+		t.Errorf("unexpected Docker detail: %s", checks["Docker"].Detail)
+	}
+
+	if checks["Disk space"].Detail != "42.2GB free on the Docker storage" {
+		t.Errorf("unexpected disk space detail: %s", checks["Disk space"].Detail)
+	}
+
+	if !strings.Contains(checks["File sharing"].Detail, "wrote 500 files") {
+		t.Errorf("unexpected file sharing detail: %s", checks["File sharing"].Detail)
+	}
+}
+
+func TestDoctorHumanOutput(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecError = errors.New("Cannot connect to the Docker daemon")
+
+	cmd := NewDoctorCommand(doctor)
+	assertExecGotError(t, cmd, "found 1 problem(s) on the environment")
+
+	fakeShell := doctor.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledSuccess || !fakeShell.CalledError {
+		t.Error("expected both successful and failed checks output")
+	}
+
+	if output := strings.Join(fakeShell.OutLines, "\n"); !strings.Contains(output, "- Disk space: the Docker daemon is not reachable") || !strings.Contains(output, "Start Docker Desktop") {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestDoctorDockerCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockLookPathError = errors.New("not found")
+
+	checks := doctorChecks(t, doctor)
+
+	if checks["Docker"].Status != doctorError || checks["Docker"].Detail != "docker was not found on PATH" {
+		t.Errorf("unexpected Docker check: %v", checks["Docker"])
+	}
+
+	for _, name := range []string{"Docker API", "Disk space", "Global network", "Ports", "File sharing"} {
+		if checks[name].Status != doctorSkipped {
+			t.Errorf("expected %s check to be skipped, got %v", name, checks[name])
+		}
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecError = errors.New("Got permission denied while trying to connect to the Docker daemon socket")
+
+	if check := doctorChecks(t, doctor)["Docker"]; check.Detail != "permission denied connecting to the Docker daemon" || !strings.Contains(check.Hint, "usermod") {
+		t.Errorf("unexpected Docker check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecOut = "18.09.1\t1.39\t18.09.1\t1.39\t1.12"
+
+	if check := doctorChecks(t, doctor)["Docker"]; check.Status != doctorWarning || !strings.Contains(check.Detail, "is outdated") {
+		t.Errorf("unexpected Docker check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecOut = "unexpected"
+
+	checks = doctorChecks(t, doctor)
+
+	if checks["Docker"].Status != doctorWarning || checks["Docker API"].Status != doctorSkipped {
+		t.Errorf("unexpected Docker checks: %v, %v", checks["Docker"], checks["Docker API"])
+	}
+}
+
+func TestDoctorAPICheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecOut = "20.10.6\t1.41\t19.03.13\t1.40\t1.12"
+
+	checks := doctorChecks(t, doctor)
+
+	if check := checks["Docker API"]; check.Status != doctorWarning || check.Hint != "Upgrade the Docker daemon, or set DOCKER_API_VERSION=1.40" {
+		t.Errorf("unexpected API check: %v", check)
+	}
+
+	if checks["Docker"].Status != doctorOK {
+		t.Errorf("Docker 19.03 should be supported: %v", checks["Docker"])
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.dockerVersion.(*builder.FakeCommand).MockExecOut = "17.06.0\t1.30\t23.0.0\t1.42\t1.40"
+
+	if check := doctorChecks(t, doctor)["Docker API"]; check.Status != doctorError {
+		t.Errorf("unexpected API check: %v", check)
+	}
+
+	if compareAPIVersions("1.9", "1.41") != -1 || compareAPIVersions("1.41", "1.41") != 0 || compareAPIVersions("2.0", "1.41") != 1 {
+		t.Error("failed comparing API versions")
+	}
+}
+
+func TestDoctorComposeCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.composeVersion.(*builder.FakeCommand).MockLookPathError = errors.New("not found")
+
+	if check := doctorChecks(t, doctor)["Docker Compose"]; check.Status != doctorWarning || !strings.Contains(check.Detail, "inside a container") {
+		t.Errorf("unexpected compose check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.composeVersion.(*builder.FakeCommand).MockExecOut = "1.25.0"
+
+	if check := doctorChecks(t, doctor)["Docker Compose"]; check.Status != doctorWarning || !strings.Contains(check.Detail, "older than 1.28.0") {
+		t.Errorf("unexpected compose check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.composeVersion.(*builder.FakeCommand).MockExecError = errors.New("broken")
+
+	if check := doctorChecks(t, doctor)["Docker Compose"]; check.Status != doctorError {
+		t.Errorf("unexpected compose check: %v", check)
+	}
+}
+
+func TestDoctorSocketCheck(t *testing.T) {
+	for dialErr, expected := range map[error]string{
+		errors.New("dial unix /var/run/docker.sock: connect: permission denied"):         "no permission to use /var/run/docker.sock",
+		errors.New("dial unix /var/run/docker.sock: connect: no such file or directory"): "/var/run/docker.sock does not exist",
+		errors.New("connection refused"):                                                 "could not connect to /var/run/docker.sock: connection refused",
+	} {
+		doctor := newFakeKoolDoctor(t)
+		err := dialErr
+		doctor.dial = func(path string) error { return err }
+
+		if check := doctorChecks(t, doctor)["Docker socket"]; check.Status != doctorError || check.Detail != expected {
+			t.Errorf("unexpected socket check: %v", check)
+		}
+	}
+
+	doctor := newFakeKoolDoctor(t)
+	doctor.env.Set("DOCKER_HOST", "tcp://127.0.0.1:2375")
+
+	if check := doctorChecks(t, doctor)["Docker socket"]; check.Status != doctorSkipped || check.Detail != "DOCKER_HOST is tcp://127.0.0.1:2375" {
+		t.Errorf("unexpected socket check: %v", check)
+	}
+}
+
+func TestDoctorDiskSpaceCheck(t *testing.T) {
+	for available, status := range map[string]string{"4000000": doctorWarning, "500000": doctorError, "bad": doctorWarning} {
+		doctor := newFakeKoolDoctor(t)
+		doctor.diskFree.(*builder.FakeCommand).MockExecOut = fmt.Sprintf("Filesystem 1024-blocks Used Available Capacity Mounted on\noverlay 61255492 20000000 %s 33%% /", available)
+
+		if check := doctorChecks(t, doctor)["Disk space"]; check.Status != status {
+			t.Errorf("expected %s disk space check with %s KB available, got %v", status, available, check)
+		}
+	}
+}
+
+func TestDoctorGlobalNetworkCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.listNetwork.(*builder.FakeCommand).MockExecOut = ""
+
+	if check := doctorChecks(t, doctor)["Global network"]; check.Status != doctorWarning || check.Detail != "network kool_global does not exist yet" {
+		t.Errorf("unexpected network check: %v", check)
+	}
+}
+
+func TestDoctorEnvFilesCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	dir := doctor.env.Get("PWD")
+
+	_ = os.WriteFile(filepath.Join(dir, ".env.example"), []byte("APP=1\n"), os.ModePerm)
+
+	if check := doctorChecks(t, doctor)["Environment files"]; check.Status != doctorWarning || check.Hint != "Create it with 'cp .env.example .env'" {
+		t.Errorf("unexpected env check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	dir = doctor.env.Get("PWD")
+
+	_ = os.WriteFile(filepath.Join(dir, ".env"), []byte("# comment\nAPP=1\nexport DB = x\nKEY=\"multi\nline\"\nnot a variable\nAPP=2\n"), os.ModePerm)
+	_ = os.WriteFile(filepath.Join(dir, ".env.local"), []byte("LOCAL=1\n"), os.ModePerm)
+
+	if check := doctorChecks(t, doctor)["Environment files"]; check.Status != doctorWarning || check.Detail != "invalid lines on .env: 6; defined more than once on .env: APP" {
+		t.Errorf("unexpected env check: %v", check)
+	}
+
+	_ = os.WriteFile(filepath.Join(dir, ".env"), []byte("APP=1\n"), os.ModePerm)
+	doctor.shell = &shell.FakeShell{}
+
+	if check := doctorChecks(t, doctor)["Environment files"]; check.Status != doctorOK || check.Detail != ".env, .env.local" {
+		t.Errorf("unexpected env check: %v", check)
+	}
+}
+
+func TestDoctorUserMappingCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.env.Set("KOOL_ASUSER", "0")
+
+	if check := doctorChecks(t, doctor)["User mapping"]; check.Status != doctorWarning || !strings.Contains(check.Detail, "KOOL_ASUSER is 0 but your user ID is 1000") {
+		t.Errorf("unexpected user mapping check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	doctor.goos = "windows"
+
+	if check := doctorChecks(t, doctor)["User mapping"]; check.Status != doctorSkipped {
+		t.Errorf("unexpected user mapping check: %v", check)
+	}
+}
+
+func TestDoctorPortsCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.portsCheck.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"3306": true, "6379": true}
+
+	if check := doctorChecks(t, doctor)["Ports"]; check.Status != doctorWarning || check.Detail != "already in use: 3306 (database), 6379 (cache)" {
+		t.Errorf("unexpected ports check: %v", check)
+	}
+
+	doctor = newFakeKoolDoctor(t)
+	_ = os.Remove(filepath.Join(doctor.env.Get("PWD"), "docker-compose.yml"))
+
+	if check := doctorChecks(t, doctor)["Ports"]; check.Status != doctorOK || check.Detail != "no host ports published" {
+		t.Errorf("unexpected ports check: %v", check)
+	}
+}
+
+func TestDoctorFileSharingCheck(t *testing.T) {
+	doctor := newFakeKoolDoctor(t)
+	doctor.runContainer.(*builder.FakeCommand).MockExecError = errors.New("mounts denied")
+
+	if check := doctorChecks(t, doctor)["File sharing"]; check.Status != doctorWarning || !strings.Contains(check.Detail, "mounts denied") {
+		t.Errorf("unexpected file sharing check: %v", check)
+	}
+}
+
+func TestParseDoctorVersion(t *testing.T) {
+	for raw, expected := range map[string]string{
+		"19.03.13-ce": "19.3.13",
+		"v2.0.1":      "2.0.1",
+		"1.29":        "1.29.0",
+	} {
+		if parsed, err := parseDoctorVersion(raw); err != nil || parsed.String() != expected {
+			t.Errorf("expected %s to be parsed as %s, got %s (%v)", raw, expected, parsed, err)
+		}
+	}
+
+	if _, err := parseDoctorVersion("dev"); err == nil {
+		t.Error("expected error parsing invalid version")
+	}
+}
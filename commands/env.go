@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// composeProjectLabel is the label docker-compose sets on the
+// containers, volumes and networks it creates for a project
+const composeProjectLabel = "com.docker.compose.project"
+
+// KoolEnv holds the shared logic of the kool env commands for
+// handling the namespaced environments of the project
+type KoolEnv struct {
+	DefaultKoolService
+
+	env   environment.EnvStorage
+	table shell.TableWriter
+
+	listContainers builder.Command
+	listVolumes    builder.Command
+	listNetworks   builder.Command
+
+	removeContainers builder.Command
+	removeVolumes    builder.Command
+	removeNetworks   builder.Command
+}
+
+// koolEnvironment holds the docker resources of a namespaced environment
+type koolEnvironment struct {
+	project    string
+	containers []string
+	running    int
+	volumes    []string
+	networks   []string
+}
+
+// NewKoolEnv creates a new handler for namespaced environments logic with default dependencies
+func NewKoolEnv() *KoolEnv {
+	var (
+		filter = "label=" + composeProjectLabel
+		label  = `{{.Label "` + composeProjectLabel + `"}}`
+	)
+
+	return &KoolEnv{
+		*newDefaultKoolService(),
+		environment.NewEnvStorage(),
+		shell.NewTableWriter(),
+		builder.NewCommand("docker", "ps", "-a", "--filter", filter, "--format", "{{.ID}}\t"+label+"\t{{.Status}}"),
+		builder.NewCommand("docker", "volume", "ls", "--filter", filter, "--format", "{{.Name}}\t"+label),
+		builder.NewCommand("docker", "network", "ls", "--filter", filter, "--format", "{{.Name}}\t"+label),
+		builder.NewCommand("docker", "rm", "-f"),
+		builder.NewCommand("docker", "volume", "rm"),
+		builder.NewCommand("docker", "network", "rm"),
+	}
+}
+
+// NewEnvCommand initializes new kool env command
+func NewEnvCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "env",
+		Short: "List and remove the namespaced environments of the project",
+		Long: `List and remove the environments of the project namespaced by git branch or
+worktree (set KOOL_NAMESPACE to 'branch' or 'worktree' to enable them), along
+with their containers, volumes and networks.`,
+		Args: cobra.NoArgs,
+
+		DisableFlagsInUseLine: true,
+	}
+}
+
+func AddKoolEnv(root *cobra.Command) {
+	var (
+		env    = NewKoolEnv()
+		envCmd = NewEnvCommand()
+	)
+
+	root.AddCommand(envCmd)
+	envCmd.AddCommand(NewEnvLsCommand(&KoolEnvLs{env}))
+	envCmd.AddCommand(NewEnvRmCommand(&KoolEnvRm{env, &KoolEnvRmFlags{}}))
+}
+
+// baseProject returns the docker-compose project name
+// of the project before namespacing it
+func (e *KoolEnv) baseProject() string {
+	base := e.env.Get("KOOL_NAME_BASE")
+
+	if base == "" {
+		base = e.env.Get("KOOL_NAME")
+	}
+
+	return compose.ProjectName(base)
+}
+
+// currentProject returns the docker-compose project name in use
+func (e *KoolEnv) currentProject() string {
+	return compose.ProjectName(e.env.Get("KOOL_NAME"))
+}
+
+// isNamespaced tells whether the docker-compose project
+// is a namespaced environment of this project
+func (e *KoolEnv) isNamespaced(project string) bool {
+	return strings.HasPrefix(project, e.baseProject()+environment.NamespaceSeparator)
+}
+
+// environments lists the environments of the project, including
+// the non namespaced one, with their docker resources
+func (e *KoolEnv) environments() (envs []*koolEnvironment, err error) {
+	var (
+		byProject = make(map[string]*koolEnvironment)
+		output    string
+	)
+
+	environmentOf := func(project string) *koolEnvironment {
+		if project != e.baseProject() && !e.isNamespaced(project) {
+			return nil
+		}
+
+		if _, exists := byProject[project]; !exists {
+			byProject[project] = &koolEnvironment{project: project}
+		}
+
+		return byProject[project]
+	}
+
+	if output, err = e.Exec(e.listContainers); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 3) {
+		if env := environmentOf(fields[1]); env != nil {
+			env.containers = append(env.containers, fields[0])
+
+			if strings.HasPrefix(fields[2], "Up") {
+				env.running++
+			}
+		}
+	}
+
+	if output, err = e.Exec(e.listVolumes); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 2) {
+		if env := environmentOf(fields[1]); env != nil {
+			env.volumes = append(env.volumes, fields[0])
+		}
+	}
+
+	if output, err = e.Exec(e.listNetworks); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 2) {
+		if env := environmentOf(fields[1]); env != nil {
+			env.networks = append(env.networks, fields[0])
+		}
+	}
+
+	for _, env := range byProject {
+		envs = append(envs, env)
+	}
+
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].project < envs[j].project
+	})
+
+	return
+}
+
+// labeledLines splits the tab separated docker listing output,
+// skipping the lines without the expected number of fields
+func labeledLines(output string, size int) (lines [][]string) {
+	for _, line := range strings.Split(output, "\n") {
+		// trailing tabs are kept, as they delimit empty fields
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+
+		if len(fields) < size || fields[0] == "" {
+			continue
+		}
+
+		lines = append(lines, fields)
+	}
+
+	return
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolEnvLs holds handlers and functions to implement the env ls command logic
+type KoolEnvLs struct {
+	*KoolEnv
+}
+
+// Execute runs the env ls logic with incoming arguments.
+func (l *KoolEnvLs) Execute(args []string) (err error) {
+	var envs []*koolEnvironment
+
+	if envs, err = l.environments(); err != nil {
+		return
+	}
+
+	if len(envs) == 0 {
+		l.Println("No environments found for project", l.baseProject())
+		return
+	}
+
+	l.table.SetWriter(l.OutStream())
+	l.table.AppendHeader("Environment", "Containers", "Volumes", "Networks", "Current")
+
+	for _, env := range envs {
+		current := "no"
+
+		if env.project == l.currentProject() {
+			current = "yes"
+		}
+
+		l.table.AppendRow(
+			env.project,
+			fmt.Sprintf("%d (%d running)", len(env.containers), env.running),
+			fmt.Sprint(len(env.volumes)),
+			fmt.Sprint(len(env.networks)),
+			current,
+		)
+	}
+
+	l.table.Render()
+	return
+}
+
+// NewEnvLsCommand initializes new kool env ls command
+func NewEnvLsCommand(ls *KoolEnvLs) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the environments of the project",
+		Args:  cobra.NoArgs,
+		RunE:  DefaultCommandRunFunction(ls),
+
+		DisableFlagsInUseLine: true,
+	}
+}
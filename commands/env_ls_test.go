@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func TestEnvLsCommand(t *testing.T) {
+	ls := &KoolEnvLs{newFakeKoolEnv()}
+
+	if err := NewEnvLsCommand(ls).Execute(); err != nil {
+		t.Errorf("unexpected error listing environments: %v", err)
+	}
+
+	rows := ls.table.(*shell.FakeTableWriter).Rows
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 environments listed, got %v", rows)
+	}
+
+	if rows[1][0] != "app--feature-a" || rows[1][1] != "2 (1 running)" || rows[1][2] != "1" || rows[1][3] != "0" || rows[1][4] != "yes" {
+		t.Errorf("unexpected feature-a environment row: %v", rows[1])
+	}
+
+	if rows[2][0] != "app--feature-b" || rows[2][4] != "no" {
+		t.Errorf("unexpected feature-b environment row: %v", rows[2])
+	}
+}
+
+func TestEnvLsCommandNoEnvironments(t *testing.T) {
+	ls := &KoolEnvLs{newFakeKoolEnv()}
+	ls.env.Set("KOOL_NAME", "other")
+	ls.env.Set("KOOL_NAME_BASE", "other")
+	ls.listVolumes.(*builder.FakeCommand).MockExecOut = ""
+
+	if err := NewEnvLsCommand(ls).Execute(); err != nil {
+		t.Errorf("unexpected error listing environments: %v", err)
+	}
+
+	if lines := ls.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "No environments found for project other" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+
+	if ls.table.(*shell.FakeTableWriter).CalledRender {
+		t.Error("should not render an empty table")
+	}
+}
+
+func TestEnvLsCommandError(t *testing.T) {
+	ls := &KoolEnvLs{newFakeKoolEnv()}
+	ls.listContainers.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+
+	assertExecGotError(t, NewEnvLsCommand(ls), "docker error")
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/environment"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolEnvRmFlags holds the flags for the kool env rm command
+type KoolEnvRmFlags struct {
+	All bool
+}
+
+// KoolEnvRm holds handlers and functions to implement the env rm command logic
+type KoolEnvRm struct {
+	*KoolEnv
+	Flags *KoolEnvRmFlags
+}
+
+// Execute runs the env rm logic with incoming arguments.
+func (r *KoolEnvRm) Execute(args []string) (err error) {
+	var (
+		envs    []*koolEnvironment
+		remove  []*koolEnvironment
+		byName  = make(map[string]*koolEnvironment)
+		current = r.currentProject()
+	)
+
+	if r.Flags.All == (len(args) > 0) {
+		err = errors.New("either give the environments to remove or --all")
+		return
+	}
+
+	if envs, err = r.environments(); err != nil {
+		return
+	}
+
+	for _, env := range envs {
+		byName[env.project] = env
+	}
+
+	if r.Flags.All {
+		for _, env := range envs {
+			if env.project != current && r.isNamespaced(env.project) {
+				remove = append(remove, env)
+			}
+		}
+	}
+
+	for _, name := range args {
+		project := r.baseProject() + environment.NamespaceSeparator + environment.NamespaceSlug(name)
+
+		if r.isNamespaced(name) {
+			project = name
+		}
+
+		if project == current {
+			err = fmt.Errorf("cannot remove the environment in use (%s); use 'kool stop --purge' instead", project)
+			return
+		}
+
+		env, exists := byName[project]
+		if !exists {
+			err = fmt.Errorf("could not find the environment %s", name)
+			return
+		}
+
+		remove = append(remove, env)
+	}
+
+	if len(remove) == 0 {
+		r.Println("No environments to remove")
+		return
+	}
+
+	for _, env := range remove {
+		if err = r.remove(env); err != nil {
+			return
+		}
+
+		r.Success("Removed environment ", env.project)
+	}
+
+	return
+}
+
+// remove removes the containers, volumes and networks of the environment
+func (r *KoolEnvRm) remove(env *koolEnvironment) (err error) {
+	if len(env.containers) > 0 {
+		if _, err = r.Exec(r.removeContainers, env.containers...); err != nil {
+			return
+		}
+	}
+
+	if len(env.volumes) > 0 {
+		if _, err = r.Exec(r.removeVolumes, env.volumes...); err != nil {
+			return
+		}
+	}
+
+	if len(env.networks) > 0 {
+		_, err = r.Exec(r.removeNetworks, env.networks...)
+	}
+
+	return
+}
+
+// NewEnvRmCommand initializes new kool env rm command
+func NewEnvRmCommand(rm *KoolEnvRm) (rmCmd *cobra.Command) {
+	rmCmd = &cobra.Command{
+		Use:   "rm [ENVIRONMENT...]",
+		Short: "Remove environments of the project",
+		Long: `Remove the containers, volumes and networks of the given ENVIRONMENT (its
+branch or worktree name, or the full project name), or of all environments but
+the one in use with --all.`,
+		RunE: DefaultCommandRunFunction(rm),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	rmCmd.Flags().BoolVarP(&rm.Flags.All, "all", "a", false, "Remove all environments of the project but the one in use.")
+	return
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func newFakeKoolEnvRm() *KoolEnvRm {
+	return &KoolEnvRm{newFakeKoolEnv(), &KoolEnvRmFlags{}}
+}
+
+func TestEnvRmCommand(t *testing.T) {
+	for _, name := range []string{"feature-b", "Feature/B", "app--feature-b"} {
+		rm := newFakeKoolEnvRm()
+		cmd := NewEnvRmCommand(rm)
+		cmd.SetArgs([]string{name})
+
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("unexpected error removing environment %s: %v", name, err)
+			continue
+		}
+
+		fakeShell := rm.shell.(*shell.FakeShell)
+
+		if !fakeShell.CalledExec["rm-containers"] || !fakeShell.CalledExec["rm-volumes"] || !fakeShell.CalledExec["rm-networks"] {
+			t.Errorf("should have removed the feature-b containers, volumes and networks for %s", name)
+		}
+
+		if !fakeShell.CalledSuccess || fmt.Sprint(fakeShell.SuccessOutput...) != "Removed environment app--feature-b" {
+			t.Errorf("unexpected success output: %v", fakeShell.SuccessOutput)
+		}
+	}
+}
+
+func TestEnvRmCommandAll(t *testing.T) {
+	rm := newFakeKoolEnvRm()
+	cmd := NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"--all"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error removing environments: %v", err)
+	}
+
+	// only feature-b, as feature-a is in use and app is not namespaced
+	fakeShell := rm.shell.(*shell.FakeShell)
+
+	if fmt.Sprint(fakeShell.SuccessOutput...) != "Removed environment app--feature-b" {
+		t.Errorf("unexpected success output: %v", fakeShell.SuccessOutput)
+	}
+
+	rm = newFakeKoolEnvRm()
+	rm.env.Set("KOOL_NAME", "app--feature-b")
+	rm.listContainers.(*builder.FakeCommand).MockExecOut = ""
+	rm.listVolumes.(*builder.FakeCommand).MockExecOut = ""
+	cmd = NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"--all"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error removing environments: %v", err)
+	}
+
+	if lines := rm.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "No environments to remove" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
+
+func TestEnvRmCommandErrors(t *testing.T) {
+	rm := newFakeKoolEnvRm()
+	assertExecGotError(t, NewEnvRmCommand(rm), "either give the environments to remove or --all")
+
+	rm = newFakeKoolEnvRm()
+	cmd := NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"feature-b", "--all"})
+	assertExecGotError(t, cmd, "either give the environments to remove or --all")
+
+	rm = newFakeKoolEnvRm()
+	cmd = NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"feature-a"})
+	assertExecGotError(t, cmd, "cannot remove the environment in use (app--feature-a)")
+
+	rm = newFakeKoolEnvRm()
+	cmd = NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"feature-c"})
+	assertExecGotError(t, cmd, "could not find the environment feature-c")
+
+	rm = newFakeKoolEnvRm()
+	rm.listContainers.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+	cmd = NewEnvRmCommand(rm)
+	cmd.SetArgs([]string{"feature-b"})
+	assertExecGotError(t, cmd, "docker error")
+
+	for _, command := range []string{"containers", "volumes", "networks"} {
+		rm = newFakeKoolEnvRm()
+
+		switch command {
+		case "containers":
+			rm.removeContainers.(*builder.FakeCommand).MockExecError = errors.New("rm error")
+		case "volumes":
+			rm.removeVolumes.(*builder.FakeCommand).MockExecError = errors.New("rm error")
+		case "networks":
+			rm.removeNetworks.(*builder.FakeCommand).MockExecError = errors.New("rm error")
+		}
+
+		cmd = NewEnvRmCommand(rm)
+		cmd.SetArgs([]string{"feature-b"})
+		assertExecGotError(t, cmd, "rm error")
+
+		if rm.shell.(*shell.FakeShell).CalledSuccess {
+			t.Errorf("should not report success after failing to remove %s", command)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func newFakeKoolEnv() *KoolEnv {
+	env := environment.NewFakeEnvStorage()
+	env.Set("KOOL_NAME", "app--feature-a")
+	env.Set("KOOL_NAME_BASE", "app")
+
+	return &KoolEnv{
+		*newFakeKoolService(),
+		env,
+		&shell.FakeTableWriter{},
+		&builder.FakeCommand{MockCmd: "list-containers", MockExecOut: "c1\tapp--feature-a\tUp 2 minutes\n" +
+			"c2\tapp--feature-a\tExited (0) 1 hour ago\n" +
+			"c3\tapp--feature-b\tExited (0) 2 days ago\n" +
+			"c4\tapp\tUp 3 hours\n" +
+			"c5\tapp-admin\tUp 3 hours\n"},
+		&builder.FakeCommand{MockCmd: "list-volumes", MockExecOut: "app--feature-a_database\tapp--feature-a\n" +
+			"app--feature-b_database\tapp--feature-b\n" +
+			"other_database\tother\n"},
+		&builder.FakeCommand{MockCmd: "list-networks", MockExecOut: "app--feature-b_default\tapp--feature-b\n"},
+		&builder.FakeCommand{MockCmd: "rm-containers"},
+		&builder.FakeCommand{MockCmd: "rm-volumes"},
+		&builder.FakeCommand{MockCmd: "rm-networks"},
+	}
+}
+
+func TestNewKoolEnv(t *testing.T) {
+	env := NewKoolEnv()
+
+	if _, ok := env.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolEnv instance")
+	}
+
+	if _, ok := env.table.(*shell.DefaultTableWriter); !ok {
+		t.Error("unexpected shell.TableWriter on default KoolEnv instance")
+	}
+
+	if env.listContainers.Cmd() != "docker" || env.removeContainers.Cmd() != "docker" {
+		t.Error("unexpected docker commands on default KoolEnv instance")
+	}
+}
+
+func TestKoolEnvEnvironments(t *testing.T) {
+	env := newFakeKoolEnv()
+
+	envs, err := env.environments()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(envs) != 3 {
+		t.Fatalf("expected 3 environments, got %d", len(envs))
+	}
+
+	if envs[0].project != "app" || len(envs[0].containers) != 1 || envs[0].running != 1 {
+		t.Errorf("unexpected base environment: %+v", envs[0])
+	}
+
+	if envs[1].project != "app--feature-a" || len(envs[1].containers) != 2 || envs[1].running != 1 || len(envs[1].volumes) != 1 || len(envs[1].networks) != 0 {
+		t.Errorf("unexpected feature-a environment: %+v", envs[1])
+	}
+
+	if envs[2].project != "app--feature-b" || len(envs[2].containers) != 1 || envs[2].running != 0 || len(envs[2].volumes) != 1 || len(envs[2].networks) != 1 {
+		t.Errorf("unexpected feature-b environment: %+v", envs[2])
+	}
+}
+
+func TestKoolEnvEnvironmentsNotNamespaced(t *testing.T) {
+	env := newFakeKoolEnv()
+	env.env.Set("KOOL_NAME", "App")
+	env.env.Set("KOOL_NAME_BASE", "")
+
+	if env.baseProject() != "app" || env.currentProject() != "app" {
+		t.Errorf("unexpected projects: %s and %s", env.baseProject(), env.currentProject())
+	}
+
+	if envs, err := env.environments(); err != nil || len(envs) != 3 {
+		t.Errorf("expected the namespaced environments of the base project, got %d (%v)", len(envs), err)
+	}
+}
+
+func TestKoolEnvEnvironmentsErrors(t *testing.T) {
+	for _, command := range []string{"containers", "volumes", "networks"} {
+		env := newFakeKoolEnv()
+
+		switch command {
+		case "containers":
+			env.listContainers.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+		case "volumes":
+			env.listVolumes.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+		case "networks":
+			env.listNetworks.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+		}
+
+		if _, err := env.environments(); err == nil || err.Error() != "docker error" {
+			t.Errorf("expected error listing %s, got %v", command, err)
+		}
+	}
+}
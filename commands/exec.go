@@ -3,18 +3,30 @@ package commands
 import (
 	"fmt"
 	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
 	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
 	"kool-dev/kool/services/compose"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
+const (
+	execStartService = "Start the service"
+	execRunOneOff    = "Run a one-off container"
+	execCancel       = "Cancel"
+)
+
 // KoolExecFlags holds the flags for the exec command
 type KoolExecFlags struct {
 	DisableTty   bool
 	EnvVariables []string
 	Detach       bool
+	User         string
 }
 
 // KoolExec holds handlers and functions to implement the exec command logic
@@ -22,8 +34,15 @@ type KoolExec struct {
 	DefaultKoolService
 	Flags *KoolExecFlags
 
-	env         environment.EnvStorage
-	composeExec builder.Command
+	env          environment.EnvStorage
+	parser       parser.Parser
+	users        cache.Cache
+	promptSelect shell.PromptSelect
+	composeExec  builder.Command
+	composePs    builder.Command
+	composeStart builder.Command
+	composeRun   builder.Command
+	composeUsers builder.Command
 }
 
 func AddKoolExec(root *cobra.Command) {
@@ -37,47 +56,204 @@ func AddKoolExec(root *cobra.Command) {
 
 // NewKoolExec creates a new handler for exec logic
 func NewKoolExec() *KoolExec {
+	env := environment.NewEnvStorage()
+
 	return &KoolExec{
 		*newDefaultKoolService(),
-		&KoolExecFlags{false, []string{}, false},
-		environment.NewEnvStorage(),
+		&KoolExecFlags{false, []string{}, false, ""},
+		env,
+		parser.NewParser(),
+		cache.NewFileCache(filepath.Join(env.Get("HOME"), ".kool", "cache", "exec-users.json")),
+		shell.NewPromptSelect(),
 		compose.NewDockerCompose("exec"),
+		compose.NewDockerCompose("ps", "-q"),
+		compose.NewDockerCompose("up", "-d"),
+		compose.NewDockerCompose("run"),
+		compose.NewDockerCompose("exec", "-T"),
 	}
 }
 
 // Execute runs the exec logic with incoming arguments.
 func (e *KoolExec) Execute(args []string) (err error) {
-	if asuser := e.env.Get("KOOL_ASUSER"); asuser != "" {
-		// we have a KOOL_ASUSER env; now we need to know whether
-		// the image of the target service have such user
-		passwd, _ := e.Exec(e.composeExec, args[0], "cat", "/etc/passwd")
-		// kool:x:UID
-		if strings.Contains(passwd, fmt.Sprintf("kool:x:%s", asuser)) {
-			// since user existing within the container, we use it
-			e.composeExec.AppendArgs("--user", asuser)
-		}
+	var (
+		service = args[0]
+		user    = e.user(service)
+		command builder.Command
+	)
+
+	e.prepare(e.composeExec, user)
+
+	if err = e.Interactive(e.composeExec, args...); err == nil {
+		return
+	}
+
+	// only when exec fails we check whether the service is running at all
+	if command, err = e.fallbackCommand(service, user, err); err != nil {
+		return
+	}
+
+	err = e.Interactive(command, args...)
+	return
+}
+
+// prepare appends the user, TTY, environment and detach flags to the given command
+func (e *KoolExec) prepare(command builder.Command, user string) {
+	isRun := command == e.composeRun
+
+	if isRun && !e.Flags.Detach {
+		// docker-compose run does not take --rm along with --detach
+		command.AppendArgs("--rm")
+	}
+
+	if user != "" {
+		command.AppendArgs("--user", user)
 	}
 
 	if !e.IsTerminal() {
-		e.composeExec.AppendArgs("-T")
+		command.AppendArgs("-T")
 	}
 
-	if aware, ok := e.composeExec.(compose.TtyAware); ok {
+	if aware, ok := command.(compose.TtyAware); ok {
 		// let DockerCompose know about whether we are under TTY or not
 		aware.SetIsTTY(e.IsTerminal())
 	}
 
 	if len(e.Flags.EnvVariables) > 0 {
+		envFlag := "--env"
+
+		if isRun {
+			// docker-compose run only takes the short flag
+			envFlag = "-e"
+		}
+
 		for _, envVar := range e.Flags.EnvVariables {
-			e.composeExec.AppendArgs("--env", envVar)
+			command.AppendArgs(envFlag, envVar)
 		}
 	}
 
 	if e.Flags.Detach {
-		e.composeExec.AppendArgs("--detach")
+		command.AppendArgs("--detach")
+	}
+}
+
+// fallbackCommand is called after exec failed; when the service is not running
+// it offers to start it or to use a one-off container, otherwise it returns the
+// exec error
+func (e *KoolExec) fallbackCommand(service, user string, execErr error) (command builder.Command, err error) {
+	var containerID, answer string
+
+	if containerID, err = e.Exec(e.composePs, service); err != nil || containerID != "" {
+		err = execErr
+		return
+	}
+
+	if !e.IsTerminal() {
+		err = fmt.Errorf("service %s is not running; start it with 'kool start %s'", service, service)
+		return
+	}
+
+	if answer, err = e.promptSelect.Ask(
+		fmt.Sprintf("Service %s is not running. What do you want to do", service),
+		[]string{execStartService, execRunOneOff, execCancel},
+	); err != nil {
+		return
+	}
+
+	switch answer {
+	case execStartService:
+		if err = e.Interactive(e.composeStart, service); err != nil {
+			return
+		}
+
+		command = e.composeExec
+
+		if user == "" {
+			// the KOOL_ASUSER lookup needs the service running
+			if user = e.user(service); user != "" {
+				command.AppendArgs("--user", user)
+			}
+		}
+	case execRunOneOff:
+		command = e.composeRun
+		e.prepare(command, user)
+	default:
+		err = shell.ErrUserCancelled
+	}
+
+	return
+}
+
+// user tells which user should run the command within the service container;
+// it comes from the --user flag, the service settings on kool.yml, or
+// KOOL_ASUSER when the container has a user with such UID
+func (e *KoolExec) user(service string) (user string) {
+	var (
+		settings    *parser.KoolYamlService
+		name        string
+		containerID string
+		cached      bool
+		err         error
+	)
+
+	if user = e.Flags.User; user != "" {
+		return
+	}
+
+	_ = e.parser.AddLookupPath(e.env.Get("PWD"))
+	_ = e.parser.AddLookupPath(path.Join(e.env.Get("HOME"), "kool"))
+
+	if settings, err = e.parser.ParseService(service); err == nil && settings != nil && settings.User != "" {
+		user = settings.User
+		return
+	}
+
+	asuser := e.env.Get("KOOL_ASUSER")
+
+	if asuser == "" {
+		return
+	}
+
+	// the lookup is cached per container, so recreating it (i.e. after
+	// a rebuild adding or removing the user) looks the user up again
+	if containerID, err = e.Exec(e.composePs, service); err != nil || strings.TrimSpace(containerID) == "" {
+		return
+	}
+
+	key := strings.Join([]string{compose.ProjectName(e.env.Get("KOOL_NAME")), service, strings.TrimSpace(containerID), asuser}, ":")
+
+	if name, cached = e.users.Get(key); !cached {
+		// we have a KOOL_ASUSER env; now we need to know whether
+		// the container of the target service have such user
+		var passwd string
+
+		if passwd, err = e.Exec(e.composeUsers, service, "cat", "/etc/passwd"); err == nil {
+			// missing users are not cached, as they may be added later on
+			if name = passwdUserByUID(passwd, asuser); name != "" {
+				_ = e.users.Set(key, name)
+			}
+		}
+	}
+
+	if name != "" {
+		// since user existing within the container, we use it
+		user = asuser
+	}
+
+	return
+}
+
+// passwdUserByUID finds the name of the user with the given UID on /etc/passwd content
+func passwdUserByUID(passwd, uid string) (name string) {
+	for _, line := range strings.Split(passwd, "\n") {
+		// name:password:UID:GID:...
+		fields := strings.Split(strings.TrimSpace(line), ":")
+
+		if len(fields) >= 3 && fields[2] == uid {
+			name = fields[0]
+			return
+		}
 	}
 
-	err = e.Interactive(e.composeExec, args...)
 	return
 }
 
@@ -86,9 +262,10 @@ func NewExecCommand(exec *KoolExec) (execCmd *cobra.Command) {
 	execCmd = &cobra.Command{
 		Use:   "exec [OPTIONS] SERVICE COMMAND [--] [ARG...]",
 		Short: "Execute a command inside a running service container",
-		Long:  `Execute a COMMAND inside the specified SERVICE container (similar to an SSH session).`,
-		Args:  cobra.MinimumNArgs(2),
-		RunE:  DefaultCommandRunFunction(exec),
+		Long: `Execute a COMMAND inside the specified SERVICE container (similar to an SSH session).
+If the SERVICE is not running, you are offered to start it or to run a one-off container instead.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: DefaultCommandRunFunction(exec),
 
 		DisableFlagsInUseLine: true,
 	}
@@ -96,6 +273,7 @@ func NewExecCommand(exec *KoolExec) (execCmd *cobra.Command) {
 	execCmd.Flags().BoolVarP(&exec.Flags.DisableTty, "disable-tty", "T", false, "Deprecated - no effect.")
 	execCmd.Flags().StringArrayVarP(&exec.Flags.EnvVariables, "env", "e", []string{}, "Environment variables.")
 	execCmd.Flags().BoolVarP(&exec.Flags.Detach, "detach", "d", false, "Detached mode: Run command in the background.")
+	execCmd.Flags().StringVarP(&exec.Flags.User, "user", "u", "", "Run the command as this user (name or UID). Defaults to the service user on kool.yml.")
 
 	//After a non-flag arg, stop parsing flags
 	execCmd.Flags().SetInterspersed(false)
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
 	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
 	"kool-dev/kool/core/shell"
 	"kool-dev/kool/services/compose"
 	"strings"
@@ -14,19 +16,44 @@ import (
 func newFakeKoolExec() *KoolExec {
 	return &KoolExec{
 		*newFakeKoolService(),
-		&KoolExecFlags{false, []string{}, false},
+		&KoolExecFlags{false, []string{}, false, ""},
 		environment.NewFakeEnvStorage(),
+		&parser.FakeParser{},
+		&cache.FakeCache{},
+		&shell.FakePromptSelect{},
 		&builder.FakeCommand{MockCmd: "exec"},
+		&builder.FakeCommand{MockCmd: "ps", MockExecOut: "container-id"},
+		&builder.FakeCommand{MockCmd: "up"},
+		&builder.FakeCommand{MockCmd: "run"},
+		&builder.FakeCommand{MockCmd: "users"},
 	}
 }
 
 func newFailedFakeKoolExec() *KoolExec {
-	return &KoolExec{
-		*newFakeKoolService(),
-		&KoolExecFlags{false, []string{}, false},
-		environment.NewFakeEnvStorage(),
-		&builder.FakeCommand{MockCmd: "exec", MockInteractiveError: errors.New("error exec")},
-	}
+	f := newFakeKoolExec()
+	f.composeExec = &builder.FakeCommand{MockCmd: "exec", MockInteractiveError: errors.New("error exec")}
+	return f
+}
+
+// startingCommand fakes the command starting the service; once called it
+// makes the exec command stop failing and the service container show up
+type startingCommand struct {
+	*builder.FakeCommand
+	exec *builder.FakeCommand
+	ps   *builder.FakeCommand
+}
+
+func (c *startingCommand) Cmd() string {
+	c.exec.MockInteractiveError = nil
+	c.ps.MockExecOut = "container-id"
+	return c.FakeCommand.Cmd()
+}
+
+func newNotRunningFakeKoolExec() *KoolExec {
+	f := newFakeKoolExec()
+	f.composeExec.(*builder.FakeCommand).MockInteractiveError = errors.New("service is not running")
+	f.composePs.(*builder.FakeCommand).MockExecOut = ""
+	return f
 }
 
 func TestNewKoolExec(t *testing.T) {
@@ -59,6 +86,22 @@ func TestNewKoolExec(t *testing.T) {
 	if _, ok := k.composeExec.(*compose.DockerCompose); !ok {
 		t.Errorf("unexpected compose.DockerCompose on default KoolExec instance")
 	}
+
+	if _, ok := k.parser.(*parser.DefaultParser); !ok {
+		t.Errorf("unexpected parser.Parser on default KoolExec instance")
+	}
+
+	if _, ok := k.users.(*cache.FileCache); !ok {
+		t.Errorf("unexpected cache.Cache on default KoolExec instance")
+	}
+
+	if _, ok := k.promptSelect.(*shell.DefaultPromptSelect); !ok {
+		t.Errorf("unexpected shell.PromptSelect on default KoolExec instance")
+	}
+
+	if !strings.HasSuffix(k.composeUsers.String(), "exec -T") {
+		t.Errorf("unexpected users lookup command on default KoolExec instance: %s", k.composeUsers.String())
+	}
 }
 
 func TestNewExecCommand(t *testing.T) {
@@ -80,6 +123,10 @@ func TestNewExecCommand(t *testing.T) {
 	if !ok || len(interactiveArgs) != 2 || interactiveArgs[0] != "service" || interactiveArgs[1] != "command" {
 		t.Error("bad arguments to Interactive on KoolExec.composeExec Command")
 	}
+
+	if f.shell.(*shell.FakeShell).CalledExec["ps"] {
+		t.Error("should not check whether the service is running when exec succeeds")
+	}
 }
 
 func TestNoArgsNewExecCommand(t *testing.T) {
@@ -101,13 +148,13 @@ func TestKoolUserEnvNewExecCommand(t *testing.T) {
 
 	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "user_testing"
 	// mock /etc/passwd return with existing user
-	f.composeExec.(*builder.FakeCommand).MockExecOut = "kool:x:user_testing"
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = "kool:x:user_testing"
 
 	if err := cmd.Execute(); err != nil {
 		t.Errorf("unexpected error executing exec command; error: %v", err)
 	}
 
-	if !f.shell.(*shell.FakeShell).CalledExec["exec"] {
+	if !f.shell.(*shell.FakeShell).CalledExec["users"] {
 		t.Error("did not call Exec")
 	}
 
@@ -129,7 +176,7 @@ func TestKoolUserEnvNewExecCommand(t *testing.T) {
 
 	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "user_testing"
 	// mock /etc/passwd return without existing user
-	f.composeExec.(*builder.FakeCommand).MockExecOut = ""
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = ""
 
 	if err := cmd.Execute(); err != nil {
 		t.Errorf("unexpected error executing exec command; error: %v", err)
@@ -242,3 +289,234 @@ func TestNonTerminalNewExecCommand(t *testing.T) {
 		t.Errorf("bad arguments to KoolExec.composeExec Command on non terminal environment")
 	}
 }
+
+func TestUserFlagNewExecCommand(t *testing.T) {
+	f := newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.parser.(*parser.FakeParser).MockServices = map[string]*parser.KoolYamlService{
+		"service": {User: "www-data"},
+	}
+
+	cmd := NewExecCommand(f)
+	cmd.SetArgs([]string{"--user", "root", "service", "command"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledExec["users"] {
+		t.Error("should not look for the container users when --user is given")
+	}
+
+	argsAppend := f.composeExec.(*builder.FakeCommand).ArgsAppend
+
+	if len(argsAppend) != 2 || argsAppend[0] != "--user" || argsAppend[1] != "root" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command with --user flag: %v", argsAppend)
+	}
+}
+
+func TestServiceUserNewExecCommand(t *testing.T) {
+	f := newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.parser.(*parser.FakeParser).MockServices = map[string]*parser.KoolYamlService{
+		"service": {User: "www-data"},
+	}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if !f.parser.(*parser.FakeParser).CalledAddLookupPath {
+		t.Error("did not look for kool.yml files")
+	}
+
+	if f.shell.(*shell.FakeShell).CalledExec["users"] {
+		t.Error("should not look for the container users when kool.yml sets the service user")
+	}
+
+	argsAppend := f.composeExec.(*builder.FakeCommand).ArgsAppend
+
+	if len(argsAppend) != 2 || argsAppend[0] != "--user" || argsAppend[1] != "www-data" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command with kool.yml service user: %v", argsAppend)
+	}
+}
+
+func TestCachedUserNewExecCommand(t *testing.T) {
+	f := newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_NAME"] = "My-App"
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = "root:x:0:0:root:/root:/bin/sh\nwww-data:x:1000:1000::/var/www:/bin/sh"
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	users := f.users.(*cache.FakeCache)
+
+	if name, cached := users.MockEntries["my-app:service:container-id:1000"]; !cached || name != "www-data" {
+		t.Errorf("did not cache the container user; got %v", users.MockEntries)
+	}
+
+	argsAppend := f.composeExec.(*builder.FakeCommand).ArgsAppend
+
+	if len(argsAppend) != 2 || argsAppend[0] != "--user" || argsAppend[1] != "1000" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command with user of any name: %v", argsAppend)
+	}
+
+	f = newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_NAME"] = "my-app"
+	f.users = &cache.FakeCache{MockEntries: map[string]string{"my-app:service:container-id:1000": "www-data"}}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledExec["users"] {
+		t.Error("should not look for the container users when cached")
+	}
+
+	if argsAppend = f.composeExec.(*builder.FakeCommand).ArgsAppend; len(argsAppend) != 2 || argsAppend[1] != "1000" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command with cached user: %v", argsAppend)
+	}
+
+	f = newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_NAME"] = "my-app"
+	f.users = &cache.FakeCache{MockEntries: map[string]string{"my-app:service:old-container-id:1000": "www-data"}}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledExec["users"] {
+		t.Error("should look for the container users again once the container is recreated")
+	}
+
+	if argsAppend = f.composeExec.(*builder.FakeCommand).ArgsAppend; len(argsAppend) != 0 {
+		t.Errorf("should not use the user cached for another container: %v", argsAppend)
+	}
+
+	f = newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = "root:x:0:0:root:/root:/bin/sh"
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if len(f.users.(*cache.FakeCache).CalledSet) != 0 {
+		t.Errorf("should not cache a missing container user: %v", f.users.(*cache.FakeCache).CalledSet)
+	}
+
+	f = newFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.composeUsers.(*builder.FakeCommand).MockExecError = errors.New("exec error")
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if len(f.users.(*cache.FakeCache).CalledSet) != 0 {
+		t.Error("should not cache the container user when failing to read /etc/passwd")
+	}
+}
+
+func TestNotRunningNewExecCommand(t *testing.T) {
+	question := "Service service is not running. What do you want to do"
+
+	f := newNotRunningFakeKoolExec()
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if err := f.Execute([]string{"service", "command"}); err == nil || !strings.Contains(err.Error(), "service service is not running") {
+		t.Errorf("expected not running error when not under a terminal, got %v", err)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = "www-data:x:1000:1000::/var/www:/bin/sh"
+	f.composeStart = &startingCommand{&builder.FakeCommand{MockCmd: "up"}, f.composeExec.(*builder.FakeCommand), f.composePs.(*builder.FakeCommand)}
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Start the service"}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["up"] || !f.shell.(*shell.FakeShell).CalledInteractive["exec"] {
+		t.Error("did not start the service before executing the command")
+	}
+
+	if args := f.composeExec.(*builder.FakeCommand).ArgsAppend; strings.Join(args, " ") != "--user 1000" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command after starting the service: %v", args)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.Flags.Detach = true
+	f.Flags.EnvVariables = []string{"VAR=1"}
+	f.env.(*environment.FakeEnvStorage).Envs["KOOL_ASUSER"] = "1000"
+	f.composeUsers.(*builder.FakeCommand).MockExecOut = "www-data:x:1000:1000::/var/www:/bin/sh"
+	f.composeStart = &startingCommand{&builder.FakeCommand{MockCmd: "up"}, f.composeExec.(*builder.FakeCommand), f.composePs.(*builder.FakeCommand)}
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Start the service"}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if args := f.composeUsers.(*builder.FakeCommand).ArgsAppend; len(args) != 0 {
+		t.Errorf("the user lookup should not take the exec flags: %v", args)
+	}
+
+	if args := f.composeExec.(*builder.FakeCommand).ArgsAppend; strings.Join(args, " ") != "--env VAR=1 --detach --user 1000" {
+		t.Errorf("bad arguments to KoolExec.composeExec Command after starting the service detached: %v", args)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.Flags.EnvVariables = []string{"VAR=1"}
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Run a one-off container"}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledInteractive["run"] {
+		t.Error("did not run the command on a one-off container")
+	}
+
+	if args := f.composeRun.(*builder.FakeCommand).ArgsAppend; strings.Join(args, " ") != "--rm -e VAR=1" {
+		t.Errorf("bad arguments to KoolExec.composeRun Command: %v", args)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.Flags.Detach = true
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Run a one-off container"}
+
+	if err := f.Execute([]string{"service", "command"}); err != nil {
+		t.Errorf("unexpected error executing exec command; error: %v", err)
+	}
+
+	if args := f.composeRun.(*builder.FakeCommand).ArgsAppend; strings.Join(args, " ") != "--detach" {
+		t.Errorf("bad arguments to KoolExec.composeRun Command with Detach flag: %v", args)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Cancel"}
+
+	if err := f.Execute([]string{"service", "command"}); err != shell.ErrUserCancelled {
+		t.Errorf("expected user cancelled error, got %v", err)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.composeStart.(*builder.FakeCommand).MockInteractiveError = errors.New("start error")
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question: "Start the service"}
+
+	if err := f.Execute([]string{"service", "command"}); err == nil || err.Error() != "start error" {
+		t.Errorf("expected start error, got %v", err)
+	}
+
+	f = newNotRunningFakeKoolExec()
+	f.composePs.(*builder.FakeCommand).MockExecError = errors.New("ps error")
+
+	if err := f.Execute([]string{"service", "command"}); err == nil || err.Error() != "service is not running" {
+		t.Errorf("expected the exec error, got %v", err)
+	}
+}
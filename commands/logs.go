@@ -13,6 +13,7 @@ import (
 type KoolLogsFlags struct {
 	Tail   int
 	Follow bool
+	KoolServiceGroupsFlags
 }
 
 // KoolLogs holds handlers and functions to implement the logs command logic
@@ -20,8 +21,9 @@ type KoolLogs struct {
 	DefaultKoolService
 	Flags *KoolLogsFlags
 
-	list builder.Command
-	logs builder.Command
+	list   builder.Command
+	logs   builder.Command
+	groups *serviceGroups
 }
 
 func AddKoolLogs(root *cobra.Command) {
@@ -37,9 +39,10 @@ func AddKoolLogs(root *cobra.Command) {
 func NewKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newDefaultKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, KoolServiceGroupsFlags{}},
 		compose.NewDockerCompose("ps", "-aq"),
 		compose.NewDockerCompose("logs"),
+		newServiceGroups(),
 	}
 }
 
@@ -47,6 +50,10 @@ func NewKoolLogs() *KoolLogs {
 func (l *KoolLogs) Execute(args []string) (err error) {
 	var services string
 
+	if args, err = l.groups.Resolve(&l.Flags.KoolServiceGroupsFlags, args, l.list, l.logs); err != nil {
+		return
+	}
+
 	if services, err = l.Exec(l.list, args...); err != nil {
 		return
 	}
@@ -85,5 +92,6 @@ the command to follow the log output (i.e. 'kool logs -f [SERVICE...]').`,
 
 	logsCmd.Flags().IntVarP(&logs.Flags.Tail, "tail", "t", 25, "Number of lines to show from the end of the logs for each container. A value equal to 0 will show all lines.")
 	logsCmd.Flags().BoolVarP(&logs.Flags.Follow, "follow", "f", false, "Follow log output.")
+	addServiceGroupsFlags(logsCmd, &logs.Flags.KoolServiceGroupsFlags)
 	return
 }
@@ -12,18 +12,20 @@ import (
 func newFakeKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newFakeKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, KoolServiceGroupsFlags{}},
 		&builder.FakeCommand{MockCmd: "list", MockExecOut: "app"},
 		&builder.FakeCommand{MockCmd: "logs"},
+		newFakeServiceGroups(),
 	}
 }
 
 func newFakeFailedKoolLogs() *KoolLogs {
 	return &KoolLogs{
 		*newFakeKoolService(),
-		&KoolLogsFlags{25, false},
+		&KoolLogsFlags{25, false, KoolServiceGroupsFlags{}},
 		&builder.FakeCommand{MockCmd: "list", MockExecOut: "app"},
 		&builder.FakeCommand{MockCmd: "logs", MockInteractiveError: errors.New("error logs")},
+		newFakeServiceGroups(),
 	}
 }
 
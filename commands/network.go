@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolNetwork holds the shared logic of the kool network commands
+// for handling the global network shared between projects
+type KoolNetwork struct {
+	DefaultKoolService
+
+	env   environment.EnvStorage
+	table shell.TableWriter
+
+	listAttached      builder.Command
+	inspectContainers builder.Command
+	inspectNetwork    builder.Command
+	pruneNetworks     builder.Command
+	disconnect        builder.Command
+}
+
+// networkEndpoint holds a container attached to the global network
+type networkEndpoint struct {
+	id      string
+	name    string
+	running bool
+	project string
+	service string
+	ip      string
+	aliases []string
+}
+
+// NewKoolNetwork creates a new handler for global network logic with default dependencies
+func NewKoolNetwork() *KoolNetwork {
+	return &KoolNetwork{
+		*newDefaultKoolService(),
+		environment.NewEnvStorage(),
+		shell.NewTableWriter(),
+		builder.NewCommand("docker", "ps", "-a", "-q"),
+		builder.NewCommand("docker", "inspect", "--format"),
+		builder.NewCommand("docker", "network", "inspect"),
+		builder.NewCommand("docker", "network", "prune", "-f"),
+		builder.NewCommand("docker", "network", "disconnect", "-f"),
+	}
+}
+
+// NewNetworkCommand initializes new kool network command
+func NewNetworkCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "network",
+		Short: "Manage the global network shared between projects",
+		Long: `List, inspect and prune the global network (KOOL_GLOBAL_NETWORK) shared between
+kool projects, where containers reach each other by the aliases declared on
+the services section of kool.yml.`,
+		Args: cobra.NoArgs,
+
+		DisableFlagsInUseLine: true,
+	}
+}
+
+func AddKoolNetwork(root *cobra.Command) {
+	var (
+		network    = NewKoolNetwork()
+		networkCmd = NewNetworkCommand()
+	)
+
+	root.AddCommand(networkCmd)
+	networkCmd.AddCommand(NewNetworkInspectCommand(&KoolNetworkInspect{network}))
+	networkCmd.AddCommand(NewNetworkLsCommand(&KoolNetworkLs{network}))
+	networkCmd.AddCommand(NewNetworkPruneCommand(&KoolNetworkPrune{network}))
+}
+
+// globalNetwork returns the name of the global network
+func (n *KoolNetwork) globalNetwork() string {
+	return n.env.Get("KOOL_GLOBAL_NETWORK")
+}
+
+// endpoints lists the containers attached to the global network
+func (n *KoolNetwork) endpoints() (endpoints []networkEndpoint, err error) {
+	var (
+		output string
+		ids    []string
+		format = fmt.Sprintf(
+			"{{.Id}}\t{{.Name}}\t{{.State.Running}}\t{{index .Config.Labels %q}}\t{{index .Config.Labels %q}}\t"+
+				"{{with index .NetworkSettings.Networks %q}}{{.IPAddress}}\t{{join .Aliases \",\"}}{{end}}",
+			composeProjectLabel, "com.docker.compose.service", n.globalNetwork(),
+		)
+	)
+
+	if output, err = n.Exec(n.listAttached, "--filter", "network="+n.globalNetwork()); err != nil {
+		return
+	}
+
+	if ids = strings.Fields(output); len(ids) == 0 {
+		return
+	}
+
+	if output, err = n.Exec(n.inspectContainers, append([]string{format}, ids...)...); err != nil {
+		return
+	}
+
+	for _, fields := range labeledLines(output, 6) {
+		endpoint := networkEndpoint{
+			id:      fields[0],
+			name:    strings.TrimPrefix(fields[1], "/"),
+			running: fields[2] == "true",
+			project: fields[3],
+			service: fields[4],
+			ip:      fields[5],
+		}
+
+		if len(fields) > 6 {
+			for _, alias := range strings.Split(fields[6], ",") {
+				// docker adds the short container ID as an alias
+				if alias != "" && !strings.HasPrefix(endpoint.id, alias) {
+					endpoint.aliases = append(endpoint.aliases, alias)
+				}
+			}
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].project != endpoints[j].project {
+			return endpoints[i].project < endpoints[j].project
+		}
+
+		return endpoints[i].name < endpoints[j].name
+	})
+
+	return
+}
+
+// renderEndpoints prints the given network endpoints as a table
+func (n *KoolNetwork) renderEndpoints(endpoints []networkEndpoint) {
+	n.table.SetWriter(n.OutStream())
+	n.table.AppendHeader("Container", "Project", "Service", "IP", "Aliases", "Running")
+
+	for _, endpoint := range endpoints {
+		running := "no"
+
+		if endpoint.running {
+			running = "yes"
+		}
+
+		n.table.AppendRow(endpoint.name, endpoint.project, endpoint.service, endpoint.ip, strings.Join(endpoint.aliases, ", "), running)
+	}
+
+	n.table.Render()
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/services/compose"
+	"sort"
+	"strings"
+)
+
+// KoolNetworkAliases holds handlers and functions for attaching the
+// services containers to the global network with the aliases declared
+// on kool.yml, so other projects can reach them by stable names
+type KoolNetworkAliases struct {
+	*KoolNetwork
+
+	parser     parser.Parser
+	containers builder.Command
+	connect    builder.Command
+}
+
+// NewKoolNetworkAliases creates a new handler for setting
+// the global network aliases with default dependencies
+func NewKoolNetworkAliases() *KoolNetworkAliases {
+	return &KoolNetworkAliases{
+		NewKoolNetwork(),
+		parser.NewParser(),
+		compose.NewDockerCompose("ps", "-q"),
+		builder.NewCommand("docker", "network", "connect"),
+	}
+}
+
+// Execute sets the global network aliases of the given services
+// containers, or of all services when none is given
+func (a *KoolNetworkAliases) Execute(args []string) (err error) {
+	var (
+		services  map[string]*parser.KoolYamlService
+		endpoints []networkEndpoint
+		names     []string
+		started   = make(map[string]bool)
+	)
+
+	_ = a.parser.AddLookupPath(a.env.Get("PWD"))
+
+	if services, err = a.parser.ParseServices(); err != nil {
+		if errors.Is(err, parser.ErrKoolYmlNotFound) {
+			// no kool.yml means no aliases to set
+			err = nil
+		} else {
+			err = fmt.Errorf("failed to read the services aliases: %v", err)
+		}
+		return
+	}
+
+	for _, service := range args {
+		started[service] = true
+	}
+
+	for service, settings := range services {
+		if settings != nil && len(settings.Aliases) > 0 && (len(started) == 0 || started[service]) {
+			names = append(names, service)
+		}
+	}
+
+	if len(names) == 0 {
+		return
+	}
+
+	sort.Strings(names)
+
+	if endpoints, err = a.endpoints(); err != nil {
+		return
+	}
+
+	for _, service := range names {
+		if err = a.setAliases(service, services[service].Aliases, endpoints); err != nil {
+			err = fmt.Errorf("failed to set network aliases of service %s: %v", service, err)
+			return
+		}
+	}
+
+	return
+}
+
+// setAliases reconnects the service containers to the global network
+// with the given aliases, keeping the aliases they already had there
+func (a *KoolNetworkAliases) setAliases(service string, aliases []string, endpoints []networkEndpoint) (err error) {
+	var (
+		output  string
+		project = compose.ProjectName(a.env.Get("KOOL_NAME"))
+	)
+
+	if output, err = a.Exec(a.containers, service); err != nil {
+		return
+	}
+
+	for _, alias := range aliases {
+		for _, endpoint := range endpoints {
+			if endpoint.running && endpoint.project != project && hasAlias(endpoint.aliases, alias) {
+				a.Warning("Alias ", alias, " is also used by ", endpoint.name, " (project ", endpoint.project, "); requests will be spread between both")
+			}
+		}
+	}
+
+	for _, id := range strings.Fields(output) {
+		var (
+			args    []string
+			current = aliases
+		)
+
+		for _, endpoint := range endpoints {
+			if strings.HasPrefix(endpoint.id, id) {
+				current = append(append([]string{}, endpoint.aliases...), aliases...)
+				// aliases can only be set when connecting
+				_, _ = a.Exec(a.disconnect, a.globalNetwork(), id)
+			}
+		}
+
+		seen := make(map[string]bool)
+		for _, alias := range current {
+			if !seen[alias] {
+				seen[alias] = true
+				args = append(args, "--alias", alias)
+			}
+		}
+
+		if _, err = a.Exec(a.connect, append(args, a.globalNetwork(), id)...); err != nil {
+			return
+		}
+	}
+
+	return
+}
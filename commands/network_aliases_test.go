@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolNetworkAliases() *KoolNetworkAliases {
+	return &KoolNetworkAliases{
+		newFakeKoolNetwork(),
+		&parser.FakeParser{MockServices: map[string]*parser.KoolYamlService{
+			"app":    {Aliases: []string{"web.kool.local"}},
+			"worker": {User: "kool"},
+		}},
+		&builder.FakeCommand{MockCmd: "containers", MockExecOut: "bbbbbbbbbbbb\n"},
+		&builder.FakeCommand{MockCmd: "connect"},
+	}
+}
+
+func TestNewKoolNetworkAliases(t *testing.T) {
+	aliases := NewKoolNetworkAliases()
+
+	if aliases.KoolNetwork == nil {
+		t.Error("missing KoolNetwork on default KoolNetworkAliases instance")
+	}
+
+	if _, ok := aliases.parser.(*parser.DefaultParser); !ok {
+		t.Error("unexpected parser.Parser on default KoolNetworkAliases instance")
+	}
+}
+
+func TestKoolNetworkAliases(t *testing.T) {
+	aliases := newFakeKoolNetworkAliases()
+
+	if err := aliases.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := aliases.shell.(*shell.FakeShell)
+
+	if !aliases.parser.(*parser.FakeParser).CalledAddLookupPath {
+		t.Error("should have looked up the project kool.yml")
+	}
+
+	if !fakeShell.CalledExec["containers"] || !fakeShell.CalledExec["disconnect"] || !fakeShell.CalledExec["connect"] {
+		t.Error("should have reconnected the app container to the global network")
+	}
+
+	if fakeShell.CalledWarning {
+		t.Errorf("unexpected warning: %v", fakeShell.WarningOutput)
+	}
+}
+
+func TestKoolNetworkAliasesConflict(t *testing.T) {
+	aliases := newFakeKoolNetworkAliases()
+	aliases.parser.(*parser.FakeParser).MockServices["app"].Aliases = []string{"api.kool.local"}
+
+	if err := aliases.Execute([]string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := aliases.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledWarning || !strings.Contains(fmt.Sprint(fakeShell.WarningOutput...), "Alias api.kool.local is also used by api_app_1 (project api)") {
+		t.Errorf("expected alias conflict warning, got %v", fakeShell.WarningOutput)
+	}
+}
+
+func TestKoolNetworkAliasesNothingToSet(t *testing.T) {
+	aliases := newFakeKoolNetworkAliases()
+
+	if err := aliases.Execute([]string{"worker"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if aliases.shell.(*shell.FakeShell).CalledExec["list-attached"] {
+		t.Error("should not look at the network when the services have no aliases")
+	}
+
+	aliases = newFakeKoolNetworkAliases()
+	aliases.parser.(*parser.FakeParser).MockParseServicesError = parser.ErrKoolYmlNotFound
+
+	if err := aliases.Execute(nil); err != nil {
+		t.Errorf("unexpected error without kool.yml: %v", err)
+	}
+}
+
+func TestKoolNetworkAliasesErrors(t *testing.T) {
+	aliases := newFakeKoolNetworkAliases()
+	aliases.parser.(*parser.FakeParser).MockParseServicesError = errors.New("yaml: line 3: mapping values are not allowed")
+
+	if err := aliases.Execute(nil); err == nil || !strings.Contains(err.Error(), "failed to read the services aliases: yaml: line 3") {
+		t.Errorf("expected kool.yml parse error, got %v", err)
+	}
+
+	aliases = newFakeKoolNetworkAliases()
+	aliases.listAttached.(*builder.FakeCommand).MockExecError = errors.New("list error")
+
+	if err := aliases.Execute(nil); err == nil || err.Error() != "list error" {
+		t.Errorf("expected list error, got %v", err)
+	}
+
+	aliases = newFakeKoolNetworkAliases()
+	aliases.containers.(*builder.FakeCommand).MockExecError = errors.New("ps error")
+
+	if err := aliases.Execute(nil); err == nil || err.Error() != "failed to set network aliases of service app: ps error" {
+		t.Errorf("expected ps error, got %v", err)
+	}
+
+	aliases = newFakeKoolNetworkAliases()
+	aliases.connect.(*builder.FakeCommand).MockExecError = errors.New("connect error")
+
+	if err := aliases.Execute(nil); err == nil || !strings.Contains(err.Error(), "connect error") {
+		t.Errorf("expected connect error, got %v", err)
+	}
+}
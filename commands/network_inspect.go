@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolNetworkInspect holds handlers and functions to implement the network inspect command logic
+type KoolNetworkInspect struct {
+	*KoolNetwork
+}
+
+// Execute runs the network inspect logic with incoming arguments.
+func (i *KoolNetworkInspect) Execute(args []string) (err error) {
+	var endpoints, found []networkEndpoint
+
+	if len(args) == 0 {
+		err = i.Interactive(i.inspectNetwork, i.globalNetwork())
+		return
+	}
+
+	if endpoints, err = i.endpoints(); err != nil {
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.name == args[0] || endpoint.service == args[0] || hasAlias(endpoint.aliases, args[0]) {
+			found = append(found, endpoint)
+		}
+	}
+
+	if len(found) == 0 {
+		err = fmt.Errorf("no container on network %s answers to %s", i.globalNetwork(), args[0])
+		return
+	}
+
+	i.renderEndpoints(found)
+	return
+}
+
+// hasAlias tells whether the alias is among the given ones
+func hasAlias(aliases []string, alias string) bool {
+	for _, a := range aliases {
+		if a == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewNetworkInspectCommand initializes new kool network inspect command
+func NewNetworkInspectCommand(inspect *KoolNetworkInspect) *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect [NAME]",
+		Short: "Show which containers of the global network answer to NAME",
+		Long: `Show the containers of the global network answering to NAME (an alias, service
+or container name). Without NAME, show the docker details of the global network.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: DefaultCommandRunFunction(inspect),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func TestNetworkInspectCommand(t *testing.T) {
+	for name, expected := range map[string]string{
+		"api.kool.local": "api_app_1",
+		"api_worker_1":   "api_worker_1",
+		"worker":         "api_worker_1",
+	} {
+		inspect := &KoolNetworkInspect{newFakeKoolNetwork()}
+		cmd := NewNetworkInspectCommand(inspect)
+		cmd.SetArgs([]string{name})
+
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("unexpected error inspecting %s: %v", name, err)
+			continue
+		}
+
+		rows := inspect.table.(*shell.FakeTableWriter).Rows
+
+		if len(rows) != 1 || rows[0][0] != expected {
+			t.Errorf("expected %s to resolve to %s, got %v", name, expected, rows)
+		}
+	}
+
+	// the service name is shared by many projects
+	inspect := &KoolNetworkInspect{newFakeKoolNetwork()}
+	cmd := NewNetworkInspectCommand(inspect)
+	cmd.SetArgs([]string{"app"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows := inspect.table.(*shell.FakeTableWriter).Rows; len(rows) != 2 {
+		t.Errorf("expected app to resolve to 2 containers, got %v", rows)
+	}
+}
+
+func TestNetworkInspectCommandNetwork(t *testing.T) {
+	inspect := &KoolNetworkInspect{newFakeKoolNetwork()}
+
+	if err := NewNetworkInspectCommand(inspect).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := inspect.shell.(*shell.FakeShell)
+
+	if args := fakeShell.ArgsInteractive["inspect-network"]; len(args) != 1 || args[0] != "kool_global" {
+		t.Errorf("should have inspected the global network, got %v", args)
+	}
+}
+
+func TestNetworkInspectCommandNotFound(t *testing.T) {
+	inspect := &KoolNetworkInspect{newFakeKoolNetwork()}
+	cmd := NewNetworkInspectCommand(inspect)
+	cmd.SetArgs([]string{"web.kool.local"})
+
+	assertExecGotError(t, cmd, "no container on network kool_global answers to web.kool.local")
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// KoolNetworkLs holds handlers and functions to implement the network ls command logic
+type KoolNetworkLs struct {
+	*KoolNetwork
+}
+
+// Execute runs the network ls logic with incoming arguments.
+func (l *KoolNetworkLs) Execute(args []string) (err error) {
+	var endpoints []networkEndpoint
+
+	if endpoints, err = l.endpoints(); err != nil {
+		return
+	}
+
+	if len(endpoints) == 0 {
+		l.Println("No containers attached to network", l.globalNetwork())
+		return
+	}
+
+	l.renderEndpoints(endpoints)
+	return
+}
+
+// NewNetworkLsCommand initializes new kool network ls command
+func NewNetworkLsCommand(ls *KoolNetworkLs) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the containers attached to the global network",
+		Args:  cobra.NoArgs,
+		RunE:  DefaultCommandRunFunction(ls),
+
+		DisableFlagsInUseLine: true,
+	}
+}
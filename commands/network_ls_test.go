@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func TestNetworkLsCommand(t *testing.T) {
+	ls := &KoolNetworkLs{newFakeKoolNetwork()}
+
+	if err := NewNetworkLsCommand(ls).Execute(); err != nil {
+		t.Errorf("unexpected error listing network: %v", err)
+	}
+
+	rows := ls.table.(*shell.FakeTableWriter).Rows
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 containers listed, got %v", rows)
+	}
+
+	if rows[0][0] != "api_app_1" || rows[0][1] != "api" || rows[0][2] != "app" || rows[0][3] != "172.18.0.2" || rows[0][4] != "app, api.kool.local" || rows[0][5] != "yes" {
+		t.Errorf("unexpected api row: %v", rows[0])
+	}
+
+	if rows[1][5] != "no" {
+		t.Errorf("unexpected worker row: %v", rows[1])
+	}
+}
+
+func TestNetworkLsCommandEmpty(t *testing.T) {
+	ls := &KoolNetworkLs{newFakeKoolNetwork()}
+	ls.listAttached.(*builder.FakeCommand).MockExecOut = ""
+
+	if err := NewNetworkLsCommand(ls).Execute(); err != nil {
+		t.Errorf("unexpected error listing network: %v", err)
+	}
+
+	if lines := ls.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "No containers attached to network kool_global" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
+
+func TestNetworkLsCommandError(t *testing.T) {
+	ls := &KoolNetworkLs{newFakeKoolNetwork()}
+	ls.listAttached.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+
+	assertExecGotError(t, NewNetworkLsCommand(ls), "docker error")
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"kool-dev/kool/services/compose"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolNetworkPrune holds handlers and functions to implement the network prune command logic
+type KoolNetworkPrune struct {
+	*KoolNetwork
+}
+
+// Execute runs the network prune logic with incoming arguments.
+func (p *KoolNetworkPrune) Execute(args []string) (err error) {
+	var (
+		endpoints    []networkEndpoint
+		disconnected int
+	)
+
+	if endpoints, err = p.endpoints(); err != nil {
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		if endpoint.running {
+			continue
+		}
+
+		if _, err = p.Exec(p.disconnect, p.globalNetwork(), endpoint.id); err != nil {
+			return
+		}
+
+		disconnected++
+	}
+
+	if project := compose.ProjectName(p.env.Get("KOOL_NAME")); project != "" {
+		// only the current project networks; other projects may just be stopped
+		if err = p.Interactive(p.pruneNetworks, "--filter", "label="+composeProjectLabel+"="+project); err != nil {
+			return
+		}
+	}
+
+	p.Success("Disconnected ", disconnected, " stopped containers from network ", p.globalNetwork())
+	return
+}
+
+// NewNetworkPruneCommand initializes new kool network prune command
+func NewNetworkPruneCommand(prune *KoolNetworkPrune) *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove stale endpoints and the unused networks of the project",
+		Long: `Disconnect the stopped containers from the global network, freeing their
+aliases, and remove the unused networks created for the current project.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(prune),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func TestNetworkPruneCommand(t *testing.T) {
+	prune := &KoolNetworkPrune{newFakeKoolNetwork()}
+
+	if err := NewNetworkPruneCommand(prune).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := prune.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledExec["disconnect"] || !fakeShell.CalledInteractive["prune-networks"] {
+		t.Error("should have disconnected stopped containers and pruned networks")
+	}
+
+	if args := fakeShell.ArgsInteractive["prune-networks"]; len(args) != 2 || args[1] != "label=com.docker.compose.project=web" {
+		t.Errorf("should prune only the project networks; got %v", args)
+	}
+
+	if output := fmt.Sprint(fakeShell.SuccessOutput...); output != "Disconnected 1 stopped containers from network kool_global" {
+		t.Errorf("unexpected success output: %s", output)
+	}
+}
+
+func TestNetworkPruneCommandNoProject(t *testing.T) {
+	prune := &KoolNetworkPrune{newFakeKoolNetwork()}
+	prune.env.Set("KOOL_NAME", "")
+
+	if err := NewNetworkPruneCommand(prune).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if prune.shell.(*shell.FakeShell).CalledInteractive["prune-networks"] {
+		t.Error("should not prune networks without a project name")
+	}
+}
+
+func TestNetworkPruneCommandErrors(t *testing.T) {
+	prune := &KoolNetworkPrune{newFakeKoolNetwork()}
+	prune.inspectContainers.(*builder.FakeCommand).MockExecError = errors.New("inspect error")
+
+	assertExecGotError(t, NewNetworkPruneCommand(prune), "inspect error")
+
+	prune = &KoolNetworkPrune{newFakeKoolNetwork()}
+	prune.disconnect.(*builder.FakeCommand).MockExecError = errors.New("disconnect error")
+
+	assertExecGotError(t, NewNetworkPruneCommand(prune), "disconnect error")
+
+	prune = &KoolNetworkPrune{newFakeKoolNetwork()}
+	prune.pruneNetworks.(*builder.FakeCommand).MockInteractiveError = errors.New("prune error")
+
+	assertExecGotError(t, NewNetworkPruneCommand(prune), "prune error")
+}
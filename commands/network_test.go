@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+const fakeNetworkEndpoints = "bbbbbbbbbbbb1234\t/web_app_1\ttrue\tweb\tapp\t172.18.0.3\tapp,bbbbbbbbbbbb\n" +
+	"aaaaaaaaaaaa1234\t/api_app_1\ttrue\tapi\tapp\t172.18.0.2\tapp,api.kool.local,aaaaaaaaaaaa\n" +
+	"cccccccccccc1234\t/api_worker_1\tfalse\tapi\tworker\t\t\n"
+
+func newFakeKoolNetwork() *KoolNetwork {
+	env := environment.NewFakeEnvStorage()
+	env.Set("KOOL_NAME", "web")
+	env.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+
+	return &KoolNetwork{
+		*newFakeKoolService(),
+		env,
+		&shell.FakeTableWriter{},
+		&builder.FakeCommand{MockCmd: "list-attached", MockExecOut: "aaaaaaaaaaaa\nbbbbbbbbbbbb\ncccccccccccc\n"},
+		&builder.FakeCommand{MockCmd: "inspect-containers", MockExecOut: fakeNetworkEndpoints},
+		&builder.FakeCommand{MockCmd: "inspect-network"},
+		&builder.FakeCommand{MockCmd: "prune-networks"},
+		&builder.FakeCommand{MockCmd: "disconnect"},
+	}
+}
+
+func TestNewKoolNetwork(t *testing.T) {
+	network := NewKoolNetwork()
+
+	if _, ok := network.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolNetwork instance")
+	}
+
+	if _, ok := network.table.(*shell.DefaultTableWriter); !ok {
+		t.Error("unexpected shell.TableWriter on default KoolNetwork instance")
+	}
+
+	if network.listAttached.Cmd() != "docker" || network.disconnect.Cmd() != "docker" {
+		t.Error("unexpected docker commands on default KoolNetwork instance")
+	}
+}
+
+func TestKoolNetworkEndpoints(t *testing.T) {
+	network := newFakeKoolNetwork()
+
+	endpoints, err := network.endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(endpoints) != 3 {
+		t.Fatalf("expected 3 endpoints, got %v", endpoints)
+	}
+
+	api := endpoints[0]
+	if api.name != "api_app_1" || api.project != "api" || api.service != "app" || api.ip != "172.18.0.2" || !api.running {
+		t.Errorf("unexpected api endpoint: %+v", api)
+	}
+
+	if len(api.aliases) != 2 || api.aliases[0] != "app" || api.aliases[1] != "api.kool.local" {
+		t.Errorf("unexpected api endpoint aliases: %v", api.aliases)
+	}
+
+	if worker := endpoints[1]; worker.name != "api_worker_1" || worker.running || worker.ip != "" || len(worker.aliases) != 0 {
+		t.Errorf("unexpected worker endpoint: %+v", worker)
+	}
+
+	if web := endpoints[2]; web.name != "web_app_1" || web.project != "web" {
+		t.Errorf("unexpected web endpoint: %+v", web)
+	}
+}
+
+func TestKoolNetworkEndpointsEmpty(t *testing.T) {
+	network := newFakeKoolNetwork()
+	network.listAttached.(*builder.FakeCommand).MockExecOut = ""
+
+	if endpoints, err := network.endpoints(); err != nil || len(endpoints) != 0 {
+		t.Errorf("expected no endpoints, got %v (%v)", endpoints, err)
+	}
+
+	if network.shell.(*shell.FakeShell).CalledExec["inspect-containers"] {
+		t.Error("should not inspect containers when there are none attached")
+	}
+}
+
+func TestKoolNetworkEndpointsErrors(t *testing.T) {
+	network := newFakeKoolNetwork()
+	network.listAttached.(*builder.FakeCommand).MockExecError = errors.New("list error")
+
+	if _, err := network.endpoints(); err == nil || err.Error() != "list error" {
+		t.Errorf("expected list error, got %v", err)
+	}
+
+	network = newFakeKoolNetwork()
+	network.inspectContainers.(*builder.FakeCommand).MockExecError = errors.New("inspect error")
+
+	if _, err := network.endpoints(); err == nil || err.Error() != "inspect error" {
+		t.Errorf("expected inspect error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/parser"
 	"kool-dev/kool/core/presets"
 	"kool-dev/kool/core/shell"
@@ -16,11 +17,16 @@ import (
 // KoolPreset holds handlers and functions to implement the preset command logic
 type KoolPreset struct {
 	DefaultKoolService
+	Flags          *KoolPresetFlags
 	presetsParser  presets.Parser
 	composeParser  compose.Parser
 	templateParser templates.Parser
 	koolYamlParser parser.KoolYamlParser
 	promptSelect   shell.PromptSelect
+	prompt         shell.Prompt
+	env            environment.EnvStorage
+	sources        *presetSources
+	loaded         bool
 }
 
 func AddKoolPreset(root *cobra.Command) {
@@ -30,23 +36,36 @@ func AddKoolPreset(root *cobra.Command) {
 	)
 
 	root.AddCommand(presetCmd)
+	presetCmd.AddCommand(NewPresetUpgradeCommand(&KoolPresetUpgrade{preset}))
+	presetCmd.AddCommand(NewPresetNewCommand(&KoolPresetNew{preset, &KoolPresetNewFlags{}}))
+	presetCmd.AddCommand(NewPresetValidateCommand(&KoolPresetValidate{preset}))
 }
 
 // NewKoolPreset creates a new handler for preset logic
 func NewKoolPreset() *KoolPreset {
 	return &KoolPreset{
 		*newDefaultKoolService(),
+		&KoolPresetFlags{[]string{}, "", false, false, false},
 		presets.NewParser(),
 		compose.NewParser(),
 		templates.NewParser(),
 		&parser.KoolYaml{},
 		shell.NewPromptSelect(),
+		shell.NewPrompt(),
+		environment.NewEnvStorage(),
+		newPresetSources(),
+		false,
 	}
 }
 
 // Execute runs the preset logic with incoming arguments.
 func (p *KoolPreset) Execute(args []string) (err error) {
-	var fileError, preset string
+	var (
+		fileError, preset string
+		answers           map[string]string
+		base              map[string]string
+		inPlace           []string
+	)
 
 	p.loadParsers()
 
@@ -63,22 +82,45 @@ func (p *KoolPreset) Execute(args []string) (err error) {
 
 	backupDate := time.Now().Format("20060102")
 
+	if p.Flags.Merge {
+		// the merged files are updated in place instead of renamed
+		inPlace = presetMergeFiles
+	}
+
 	if existingFiles := p.presetsParser.LookUpFiles(preset); len(existingFiles) > 0 {
 		for _, fileName := range existingFiles {
+			if p.Flags.Merge && isMergedFile(fileName) {
+				continue
+			}
+
 			warning := fmt.Sprintf("Preset file %s already exists and will be renamed to %s.bak.%s", fileName, fileName, backupDate)
 			p.Warning(warning)
 		}
 	}
 
-	if err = p.customizePreset(preset); err != nil {
+	if answers, err = p.customizePreset(preset, nil); err != nil {
 		return
 	}
 
-	if fileError, err = p.presetsParser.WriteFiles(preset); err != nil {
+	// the preset contents before merging are the base for later upgrades
+	base = p.presetsParser.GetPresetFiles(preset)
+
+	if p.Flags.Merge {
+		if err = p.mergePreset(preset); err != nil {
+			return
+		}
+	}
+
+	if fileError, err = p.presetsParser.WriteFiles(preset, inPlace...); err != nil {
 		err = fmt.Errorf("failed to write preset file %s: %v", fileError, err)
 		return
 	}
 
+	if err = p.presetsParser.WriteLock(p.presetLock(preset, answers, base, p.presetsParser.GetPresetFiles(preset))); err != nil {
+		err = fmt.Errorf("failed to write %s: %v", presets.LockFile, err)
+		return
+	}
+
 	p.Success("Preset ", preset, " initialized!")
 	return
 }
@@ -90,66 +132,191 @@ func NewPresetCommand(preset *KoolPreset) (presetCmd *cobra.Command) {
 		Short: "Install configuration files customized for Kool in the current directory",
 		Long: `Initialize a project using the specified [PRESET] by installing configuration
 files customized for Kool in the current working directory. If no [PRESET] is provided,
-an interactive wizard will present the available options.`,
+an interactive wizard will present the available options, pre-selecting the preset
+detected from the project files (i.e. laravel for a composer.json requiring
+laravel/framework); use --auto to apply it without prompting.
+
+The preset questions are prompted on a TTY, and otherwise take their default
+answers; use --answer or --answers-file to answer them without prompting.
+
+With --merge, the services, volumes and scripts of the preset missing on the
+existing docker-compose.yml and kool.yml are added to them, keeping everything
+else; the changes are shown for confirmation before writing the files.
+
+The preset, its answers and the hashes of the written files are recorded on
+kool-preset.lock; use kool preset upgrade to bring later preset changes onto the
+project.
+
+Besides the built-in presets, presets are loaded from ~/.kool/presets and from the
+folders or git repositories (as URL or URL#REF) listed, comma separated, on
+KOOL_PRESETS_PATH.`,
 		Args:                  cobra.MaximumNArgs(1),
 		RunE:                  DefaultCommandRunFunction(preset),
 		DisableFlagsInUseLine: true,
 	}
 
+	addPresetAnswersFlags(presetCmd, preset.Flags)
+	presetCmd.Flags().BoolVarP(&preset.Flags.Merge, "merge", "", false, "Merge the preset onto the existing docker-compose.yml and kool.yml instead of replacing them.")
+	presetCmd.Flags().BoolVarP(&preset.Flags.Yes, "yes", "y", false, "Apply the merged changes without asking for confirmation.")
+	presetCmd.Flags().BoolVarP(&preset.Flags.Auto, "auto", "", false, "Use the preset detected from the project files and the default answers, without prompting.")
 	return
 }
 
 func (p *KoolPreset) loadParsers() {
-	p.presetsParser.LoadPresets(presets.GetAll())
-	p.presetsParser.LoadTemplates(presets.GetTemplates())
-	p.presetsParser.LoadConfigs(presets.GetConfigs())
+	if p.loaded {
+		return
+	}
+
+	p.sources.Load(p.presetsParser, p)
+	p.loaded = true
 }
 
+// getPresetArgOrAsk returns the preset given as argument or otherwise
+// asks for it, pre-selecting the one detected from the project files
+// (which is used as it is with --auto)
 func (p *KoolPreset) getPresetArgOrAsk(args []string) (preset string, err error) {
-	if len(args) == 0 {
-		if !p.IsTerminal() {
-			err = fmt.Errorf("the input device is not a TTY; for non-tty environments, please specify a preset argument")
+	if len(args) > 0 {
+		preset = args[0]
+		return
+	}
+
+	detected := presets.DetectPreset()
+
+	if detected != "" && !p.presetsParser.Exists(detected) {
+		detected = ""
+	}
+
+	if p.Flags.Auto {
+		if detected == "" {
+			err = fmt.Errorf("could not detect the project type; please specify a preset argument")
 			return
 		}
 
-		var language string
-		if language, err = p.promptSelect.Ask("What language do you want to use", p.presetsParser.GetLanguages()); err != nil {
-			return
+		p.Println("Detected a", detected, "project")
+		preset = detected
+		return
+	}
+
+	if !p.IsTerminal() {
+		err = fmt.Errorf("the input device is not a TTY; for non-tty environments, please specify a preset argument or use --auto")
+		return
+	}
+
+	var (
+		language  string
+		languages = p.presetsParser.GetLanguages()
+	)
+
+	if detected != "" {
+		p.Println("Detected a", detected, "project")
+
+		if config, configErr := p.presetsParser.GetConfig(detected); configErr == nil && config != nil {
+			languages = preferOption(languages, config.Language)
 		}
+	}
 
-		preset, err = p.promptSelect.Ask("What preset do you want to use", p.presetsParser.GetPresets(language))
-	} else {
-		preset = args[0]
+	if language, err = p.promptSelect.Ask("What language do you want to use", languages); err != nil {
+		return
 	}
 
+	preset, err = p.promptSelect.Ask("What preset do you want to use", preferOption(p.presetsParser.GetPresets(language), detected))
 	return
 }
 
-func (p *KoolPreset) customizePreset(preset string) (err error) {
-	var presetConfig *presets.PresetConfig
+// preferOption moves the preferred option to the top, so it is pre-selected
+func preferOption(options []string, preferred string) (sorted []string) {
+	sorted = make([]string, 0, len(options))
+
+	for _, option := range options {
+		if option == preferred {
+			sorted = append([]string{option}, sorted...)
+		} else {
+			sorted = append(sorted, option)
+		}
+	}
+
+	return
+}
+
+// customizePreset asks the preset questions (the locked answers are used
+// for the ones not answered by flags) and customizes the preset files
+// accordingly, returning the answers
+func (p *KoolPreset) customizePreset(preset string, locked map[string]string) (answers map[string]string, err error) {
+	return p.renderPreset(preset, locked, true)
+}
+
+// renderPreset customizes the preset files; the language version required
+// by the project on the working directory is only used when detectVersion is set
+func (p *KoolPreset) renderPreset(preset string, locked map[string]string, detectVersion bool) (answers map[string]string, err error) {
+	var (
+		version      string
+		presetConfig *presets.PresetConfig
+		given        map[string]string
+	)
 
 	if presetConfig, err = p.presetsParser.GetConfig(preset); err != nil || presetConfig == nil {
 		err = fmt.Errorf("error parsing preset config; err: %v", err)
 		return
 	}
 
-	if err = p.setDefaultTemplates(presetConfig); err != nil {
+	if given, err = p.presetAnswers(preset, presetConfig); err != nil {
+		return
+	}
+
+	for key, answer := range locked {
+		if _, answered := given[key]; !answered {
+			given[key] = answer
+		}
+	}
+
+	if detectVersion {
+		version = presets.DetectVersion(presetConfig.Language)
+	}
+
+	preferVersionDefaults(presetConfig, version)
+
+	if answers, err = p.askQuestions(presetConfig, given); err != nil {
+		return
+	}
+
+	data := presets.RenderData{
+		Answers:  answers,
+		Name:     p.env.Get("KOOL_NAME"),
+		Language: presetConfig.Language,
+		Version:  version,
+	}
+
+	// the preset files are rendered before the (already rendered) templates
+	// are merged onto them, so each source goes through the templating once
+	if fileError, renderErr := p.presetsParser.RenderFiles(preset, data); renderErr != nil {
+		err = fmt.Errorf("failed to render preset file %s: %v", fileError, renderErr)
+		return
+	}
+
+	if err = p.setDefaultTemplates(presetConfig, data); err != nil {
 		return
 	}
 
-	if err = p.customizeCompose(preset, presetConfig); err != nil {
+	if err = p.customizeCompose(preset, presetConfig, data); err != nil {
 		return
 	}
 
-	err = p.customizeKoolYaml(preset, presetConfig)
+	err = p.customizeKoolYaml(preset, presetConfig, data)
 	return
 }
 
-func (p *KoolPreset) setDefaultTemplates(config *presets.PresetConfig) (err error) {
+func (p *KoolPreset) setDefaultTemplates(config *presets.PresetConfig, data presets.RenderData) (err error) {
 	allTemplates := p.presetsParser.GetTemplates()
 
 	for _, template := range config.Templates {
-		if err = p.templateParser.Parse(allTemplates[template.Key][template.Template]); err != nil {
+		var content string
+
+		if content, err = presets.Render(allTemplates[template.Key][template.Template], data); err != nil {
+			err = fmt.Errorf("failed to load default preset templates: %v", err)
+			return
+		}
+
+		if err = p.templateParser.Parse(content); err != nil {
 			err = fmt.Errorf("failed to load default preset templates: %v", err)
 			return
 		}
@@ -159,7 +326,7 @@ func (p *KoolPreset) setDefaultTemplates(config *presets.PresetConfig) (err erro
 		}
 
 		for _, volume := range p.templateParser.GetVolumes() {
-			p.composeParser.SetVolume(volume.Key.(string))
+			p.composeParser.SetVolume(volume.Key.(string), volume.Value)
 		}
 
 		for scriptName, scripts := range p.templateParser.GetScripts() {
@@ -170,43 +337,38 @@ func (p *KoolPreset) setDefaultTemplates(config *presets.PresetConfig) (err erro
 	return
 }
 
-func (p *KoolPreset) customizeCompose(preset string, config *presets.PresetConfig) (err error) {
+func (p *KoolPreset) customizeCompose(preset string, config *presets.PresetConfig, data presets.RenderData) (err error) {
 	var newCompose string
-	allTemplates := p.presetsParser.GetTemplates()
 
 	if servicesToAsk := config.Questions["compose"]; len(servicesToAsk) > 0 {
 		for _, question := range servicesToAsk {
 			var (
-				options        []string
-				selectedOption string = question.DefaultAnswer
-				serviceName           = question.Key
+				templates   []string
+				serviceName = question.Key
 			)
 
-			optionTemplate := make(map[string]string)
-
-			for _, option := range question.Options {
-				options = append(options, option.Name)
-				optionTemplate[option.Name] = allTemplates[serviceName][option.Template]
-			}
-
-			if p.IsTerminal() && len(options) > 1 {
-				if selectedOption, err = p.promptSelect.Ask(question.Message, options); err != nil {
-					return
-				}
+			if templates, err = p.questionTemplates(question, serviceName, data); err != nil {
+				err = fmt.Errorf("failed to write preset file docker-compose.yml: %v", err)
+				return
 			}
 
-			if selectedOption != "none" {
-				if err = p.templateParser.Parse(optionTemplate[selectedOption]); err != nil {
+			for _, template := range templates {
+				if err = p.templateParser.Parse(template); err != nil {
 					err = fmt.Errorf("failed to write preset file docker-compose.yml: %v", err)
 					return
 				}
 
 				for _, service := range p.templateParser.GetServices() {
-					p.composeParser.SetService(serviceName, service.Value.(yaml.MapSlice))
+					// the service picked on a select question is named after it
+					if question.QuestionType() == presets.QuestionSelect {
+						p.composeParser.SetService(serviceName, service.Value.(yaml.MapSlice))
+					} else {
+						p.composeParser.SetService(service.Key.(string), service.Value.(yaml.MapSlice))
+					}
 				}
 
 				for _, volume := range p.templateParser.GetVolumes() {
-					p.composeParser.SetVolume(volume.Key.(string))
+					p.composeParser.SetVolume(volume.Key.(string), volume.Value)
 				}
 
 				for scriptName, scripts := range p.templateParser.GetScripts() {
@@ -226,32 +388,20 @@ func (p *KoolPreset) customizeCompose(preset string, config *presets.PresetConfi
 	return
 }
 
-func (p *KoolPreset) customizeKoolYaml(preset string, config *presets.PresetConfig) (err error) {
+func (p *KoolPreset) customizeKoolYaml(preset string, config *presets.PresetConfig, data presets.RenderData) (err error) {
 	var newKoolYaml string
-	allTemplates := p.presetsParser.GetTemplates()
 
 	if scriptsToAsk := config.Questions["kool"]; len(scriptsToAsk) > 0 {
 		for _, question := range scriptsToAsk {
-			var (
-				options        []string
-				selectedOption string = question.DefaultAnswer
-			)
+			var templates []string
 
-			optionTemplate := make(map[string]string)
-
-			for _, option := range question.Options {
-				options = append(options, option.Name)
-				optionTemplate[option.Name] = allTemplates["scripts"][option.Template]
-			}
-
-			if p.IsTerminal() {
-				if selectedOption, err = p.promptSelect.Ask(question.Message, options); err != nil {
-					return
-				}
+			if templates, err = p.questionTemplates(question, "scripts", data); err != nil {
+				err = fmt.Errorf("failed to write preset file kool.yml: %v", err)
+				return
 			}
 
-			if selectedOption != "none" {
-				if err = p.templateParser.Parse(optionTemplate[selectedOption]); err != nil {
+			for _, template := range templates {
+				if err = p.templateParser.Parse(template); err != nil {
 					err = fmt.Errorf("failed to write preset file kool.yml: %v", err)
 					return
 				}
@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/presets"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// presetQuestionGroups holds the preset question groups in the order they are asked
+var presetQuestionGroups = []string{"compose", "kool"}
+
+// KoolPresetFlags holds the flags for the kool preset command
+type KoolPresetFlags struct {
+	Answers     []string
+	AnswersFile string
+	Merge       bool
+	Yes         bool
+	Auto        bool
+}
+
+func addPresetAnswersFlags(cmd *cobra.Command, flags *KoolPresetFlags) {
+	cmd.Flags().StringArrayVarP(&flags.Answers, "answer", "", []string{}, "Answer a preset question without prompting, as KEY=VALUE (can be used multiple times); multiselect answers are comma separated and confirm answers are yes or no.")
+	cmd.Flags().StringVarP(&flags.AnswersFile, "answers-file", "", "", "Answer the preset questions from a YAML file mapping each KEY to a VALUE.")
+}
+
+// presetAnswers reads the answers given by flags (which take precedence
+// over the ones from the answers file), validating them against the
+// preset questions and their options
+func (p *KoolPreset) presetAnswers(preset string, config *presets.PresetConfig) (answers map[string]string, err error) {
+	var (
+		given     = make(map[string]string)
+		questions = make(map[string]presets.PresetConfigQuestion)
+		keys      []string
+	)
+
+	if p.Flags.AnswersFile != "" {
+		var content []byte
+
+		if content, err = os.ReadFile(p.Flags.AnswersFile); err != nil {
+			err = fmt.Errorf("failed to read answers file %s: %v", p.Flags.AnswersFile, err)
+			return
+		}
+
+		if err = yaml.Unmarshal(content, &given); err != nil {
+			err = fmt.Errorf("failed to parse answers file %s: %v", p.Flags.AnswersFile, err)
+			return
+		}
+	}
+
+	for _, answer := range p.Flags.Answers {
+		parts := strings.SplitN(answer, "=", 2)
+
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			err = fmt.Errorf("invalid answer '%s'; use KEY=VALUE", answer)
+			return
+		}
+
+		given[strings.TrimSpace(parts[0])] = parts[1]
+	}
+
+	for _, group := range config.Questions {
+		for _, question := range group {
+			questions[question.Key] = question
+			keys = append(keys, question.Key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	answers = make(map[string]string)
+
+	for key, value := range given {
+		question, exists := questions[key]
+
+		if !exists {
+			if len(keys) == 0 {
+				err = fmt.Errorf("unknown question '%s'; preset %s has no questions", key, preset)
+			} else {
+				err = fmt.Errorf("unknown question '%s' for preset %s; questions are: %s", key, preset, strings.Join(keys, ", "))
+			}
+			return
+		}
+
+		if answers[key], err = validateAnswer(question, value); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// validateAnswer checks the answer given to the question according to
+// its type, returning it normalized
+func validateAnswer(question presets.PresetConfigQuestion, value string) (answer string, err error) {
+	switch question.QuestionType() {
+	case presets.QuestionInput:
+		answer = value
+	case presets.QuestionConfirm:
+		var yes bool
+
+		if yes, err = presets.ParseConfirmAnswer(value); err != nil {
+			err = fmt.Errorf("invalid answer '%s' for question '%s'; use yes or no", value, question.Key)
+			return
+		}
+
+		answer = confirmAnswer(yes)
+	case presets.QuestionSelect:
+		answer, err = matchOption(question, value)
+	case presets.QuestionMultiSelect:
+		var selected []string
+
+		for _, part := range presets.SplitAnswer(value) {
+			var option string
+
+			if option, err = matchOption(question, part); err != nil {
+				return
+			}
+
+			selected = append(selected, option)
+		}
+
+		answer = strings.Join(selected, ",")
+	default:
+		err = fmt.Errorf("unknown type '%s' for question '%s'", question.Type, question.Key)
+	}
+
+	return
+}
+
+// matchOption returns the question option matching the value
+func matchOption(question presets.PresetConfigQuestion, value string) (option string, err error) {
+	var options []string
+
+	for _, o := range question.Options {
+		if strings.EqualFold(o.Name, strings.TrimSpace(value)) {
+			option = o.Name
+			return
+		}
+
+		options = append(options, o.Name)
+	}
+
+	err = fmt.Errorf("invalid answer '%s' for question '%s'; options are: %s", value, question.Key, strings.Join(options, ", "))
+	return
+}
+
+func confirmAnswer(yes bool) string {
+	if yes {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// preferVersionDefaults makes the options for the language version
+// required by the project (i.e. PHP 8.1 for a composer.json requiring
+// php ^8.1) the default answers of their questions
+func preferVersionDefaults(config *presets.PresetConfig, version string) {
+	if version == "" || config.Language == "" {
+		return
+	}
+
+	for _, questions := range config.Questions {
+		for i, question := range questions {
+			for _, option := range question.Options {
+				if strings.Contains(strings.ToLower(option.Name), strings.ToLower(config.Language)) && presets.Version(option.Name) == version {
+					questions[i].DefaultAnswer = option.Name
+				}
+			}
+		}
+	}
+}
+
+// askQuestions goes through the preset questions in order, skipping the
+// ones whose when condition does not hold for the earlier answers, and
+// returns all the answers
+func (p *KoolPreset) askQuestions(config *presets.PresetConfig, given map[string]string) (answers map[string]string, err error) {
+	answers = make(map[string]string)
+
+	for _, group := range presetQuestionGroups {
+		for _, question := range config.Questions[group] {
+			var applies bool
+
+			if applies, err = question.Applies(answers); err != nil || !applies {
+				if err != nil {
+					return
+				}
+
+				continue
+			}
+
+			// compose services with a single option are not worth asking about
+			ask := group != "compose" || question.QuestionType() != presets.QuestionSelect || len(question.Options) > 1
+
+			if answers[question.Key], err = p.askQuestion(question, given, ask); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// askQuestion returns the answer to the question: the given one, the one
+// from the prompt (when ask is set, not using --auto and the session is a
+// TTY) or otherwise the default answer
+func (p *KoolPreset) askQuestion(question presets.PresetConfigQuestion, given map[string]string, ask bool) (answer string, err error) {
+	var answered bool
+
+	if answer, answered = given[question.Key]; answered {
+		return
+	}
+
+	answer = question.DefaultAnswer
+
+	if !ask || p.Flags.Auto || !p.IsTerminal() {
+		if question.QuestionType() == presets.QuestionConfirm {
+			answer, err = validateAnswer(question, answer)
+		}
+
+		return
+	}
+
+	var options []string
+
+	for _, option := range question.Options {
+		options = append(options, option.Name)
+	}
+
+	switch question.QuestionType() {
+	case presets.QuestionSelect:
+		answer, err = p.promptSelect.Ask(question.Message, options)
+	case presets.QuestionMultiSelect:
+		var selected []string
+
+		if selected, err = p.prompt.MultiSelect(question.Message, options, presets.SplitAnswer(question.DefaultAnswer)); err == nil {
+			answer = strings.Join(selected, ",")
+		}
+	case presets.QuestionInput:
+		answer, err = p.prompt.Input(question.Message, question.DefaultAnswer)
+	case presets.QuestionConfirm:
+		var yes, defaultYes bool
+
+		if defaultYes, err = presets.ParseConfirmAnswer(question.DefaultAnswer); err != nil {
+			return
+		}
+
+		if yes, err = p.prompt.Confirm(question.Message, defaultYes); err == nil {
+			answer = confirmAnswer(yes)
+		}
+	default:
+		err = fmt.Errorf("unknown type '%s' for question '%s'", question.Type, question.Key)
+	}
+
+	return
+}
+
+// questionTemplates returns the templates (from the given templates folder)
+// of the options picked on the question, rendered with the given data; input
+// questions have no templates and a none answer picks nothing
+func (p *KoolPreset) questionTemplates(question presets.PresetConfigQuestion, folder string, data presets.RenderData) (templates []string, err error) {
+	var (
+		picked       []string
+		allTemplates = p.presetsParser.GetTemplates()
+	)
+
+	answer, answered := data.Answers[question.Key]
+
+	if !answered || question.QuestionType() == presets.QuestionInput {
+		return
+	}
+
+	if question.QuestionType() == presets.QuestionMultiSelect {
+		picked = presets.SplitAnswer(answer)
+	} else {
+		picked = []string{answer}
+	}
+
+	for _, name := range picked {
+		if name == "none" {
+			continue
+		}
+
+		for _, option := range question.Options {
+			if option.Name != name {
+				continue
+			}
+
+			var template string
+
+			if template, err = presets.Render(allTemplates[folder][option.Template], data); err != nil {
+				err = fmt.Errorf("failed to render template %s: %v", option.Template, err)
+				return
+			}
+
+			templates = append(templates, template)
+		}
+	}
+
+	return
+}
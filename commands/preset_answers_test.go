@@ -0,0 +1,426 @@
+package commands
+
+import (
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/core/templates"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newFakePresetAnswersConfig() *presets.PresetConfig {
+	return &presets.PresetConfig{
+		Questions: map[string][]presets.PresetConfigQuestion{
+			"compose": {
+				{
+					Key:           "database",
+					DefaultAnswer: "MySQL 8.0",
+					Message:       "Which database service do you want to use",
+					Options: []presets.PresetConfigQuestionOption{
+						{Name: "MySQL 8.0", Template: "mysql8.yml"},
+						{Name: "PostgreSQL 13.0", Template: "postgresql13.yml"},
+						{Name: "none", Template: "none"},
+					},
+				},
+				{
+					Key:           "cache",
+					DefaultAnswer: "Redis 6.0",
+					Message:       "Which cache service do you want to use",
+					Options: []presets.PresetConfigQuestionOption{
+						{Name: "Redis 6.0", Template: "redis6.yml"},
+						{Name: "none", Template: "none"},
+					},
+				},
+			},
+			"kool": {
+				{
+					Key:           "scripts",
+					DefaultAnswer: "npm",
+					Message:       "Which javascript package manager do you want to use",
+					Options: []presets.PresetConfigQuestionOption{
+						{Name: "npm", Template: "npm.yml"},
+						{Name: "yarn", Template: "yarn.yml"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestPresetAnswersFlags(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.Flags.Answers = []string{"database=postgresql 13.0", "cache=none"}
+
+	answers, err := f.presetAnswers("laravel", newFakePresetAnswersConfig())
+
+	if err != nil {
+		t.Fatalf("unexpected error reading answers: %v", err)
+	}
+
+	expected := map[string]string{"database": "PostgreSQL 13.0", "cache": "none"}
+
+	if !reflect.DeepEqual(answers, expected) {
+		t.Errorf("expected answers %v, got %v", expected, answers)
+	}
+}
+
+func TestPresetAnswersFile(t *testing.T) {
+	var (
+		f    = newFakeKoolPreset()
+		file = filepath.Join(t.TempDir(), "answers.yml")
+	)
+
+	if err := os.WriteFile(file, []byte("database: PostgreSQL 13.0\nscripts: yarn\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Flags.AnswersFile = file
+	f.Flags.Answers = []string{"database=MySQL 8.0"}
+
+	answers, err := f.presetAnswers("laravel", newFakePresetAnswersConfig())
+
+	if err != nil {
+		t.Fatalf("unexpected error reading answers: %v", err)
+	}
+
+	expected := map[string]string{"database": "MySQL 8.0", "scripts": "yarn"}
+
+	if !reflect.DeepEqual(answers, expected) {
+		t.Errorf("expected answers %v, got %v", expected, answers)
+	}
+}
+
+func TestPresetAnswersErrors(t *testing.T) {
+	var (
+		invalidFile = filepath.Join(t.TempDir(), "answers.yml")
+		config      = newFakePresetAnswersConfig()
+	)
+
+	if err := os.WriteFile(invalidFile, []byte("- database"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		answers  []string
+		file     string
+		config   *presets.PresetConfig
+		expected string
+	}{
+		{[]string{"database"}, "", config, "invalid answer 'database'; use KEY=VALUE"},
+		{[]string{"=mysql"}, "", config, "invalid answer '=mysql'; use KEY=VALUE"},
+		{[]string{"queue=rabbitmq"}, "", config, "unknown question 'queue' for preset laravel; questions are: cache, database, scripts"},
+		{[]string{"queue=rabbitmq"}, "", &presets.PresetConfig{}, "unknown question 'queue'; preset laravel has no questions"},
+		{[]string{"database=oracle"}, "", config, "invalid answer 'oracle' for question 'database'; options are: MySQL 8.0, PostgreSQL 13.0, none"},
+		{[]string{}, "/missing/answers.yml", config, "failed to read answers file /missing/answers.yml"},
+		{[]string{}, invalidFile, config, "failed to parse answers file " + invalidFile},
+	} {
+		f := newFakeKoolPreset()
+		f.Flags.Answers = tc.answers
+		f.Flags.AnswersFile = tc.file
+
+		_, err := f.presetAnswers("laravel", tc.config)
+
+		if err == nil || len(err.Error()) < len(tc.expected) || err.Error()[:len(tc.expected)] != tc.expected {
+			t.Errorf("expected error '%s', got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestAskQuestion(t *testing.T) {
+	var (
+		question = newFakePresetAnswersConfig().Questions["compose"][0]
+		f        = newFakeKoolPreset()
+	)
+
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{question.Message: "none"}
+
+	if answer, _ := f.askQuestion(question, map[string]string{"database": "PostgreSQL 13.0"}, true); answer != "PostgreSQL 13.0" || f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Errorf("expected given answer without prompting, got %s", answer)
+	}
+
+	if answer, _ := f.askQuestion(question, nil, false); answer != "MySQL 8.0" || f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Errorf("expected default answer without prompting, got %s", answer)
+	}
+
+	if answer, _ := f.askQuestion(question, nil, true); answer != "none" || !f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Errorf("expected prompted answer, got %s", answer)
+	}
+
+	f.promptSelect.(*shell.FakePromptSelect).CalledAsk = false
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if answer, _ := f.askQuestion(question, nil, true); answer != "MySQL 8.0" || f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Errorf("expected default answer on non-TTY, got %s", answer)
+	}
+}
+
+func TestAskQuestionTypes(t *testing.T) {
+	var (
+		f      = newFakeKoolPreset()
+		prompt = f.prompt.(*shell.FakePrompt)
+	)
+
+	prompt.MockInput = map[string]string{"App name": "blog"}
+	prompt.MockConfirm = map[string]bool{"Use a queue worker": true}
+	prompt.MockMultiSelect = map[string][]string{"Extra services": {"Redis 6.0", "MailHog"}}
+
+	for _, tc := range []struct {
+		question presets.PresetConfigQuestion
+		expected string
+	}{
+		{presets.PresetConfigQuestion{Key: "name", Type: "input", Message: "App name"}, "blog"},
+		{presets.PresetConfigQuestion{Key: "queue", Type: "confirm", Message: "Use a queue worker"}, "yes"},
+		{presets.PresetConfigQuestion{Key: "extras", Type: "multiselect", Message: "Extra services", Options: []presets.PresetConfigQuestionOption{{Name: "Redis 6.0"}, {Name: "MailHog"}}}, "Redis 6.0,MailHog"},
+	} {
+		if answer, err := f.askQuestion(tc.question, nil, true); err != nil || answer != tc.expected {
+			t.Errorf("expected answer '%s' for question %s, got '%s' (error: %v)", tc.expected, tc.question.Key, answer, err)
+		}
+	}
+
+	if !prompt.CalledInput || !prompt.CalledConfirm || !prompt.CalledMultiSelect {
+		t.Error("did not prompt the input, confirm and multiselect questions")
+	}
+
+	if _, err := f.askQuestion(presets.PresetConfigQuestion{Key: "name", Type: "text"}, nil, true); err == nil || err.Error() != "unknown type 'text' for question 'name'" {
+		t.Errorf("expected error on unknown question type, got %v", err)
+	}
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if answer, _ := f.askQuestion(presets.PresetConfigQuestion{Key: "queue", Type: "confirm"}, nil, true); answer != "no" {
+		t.Errorf("expected default confirm answer no on non-TTY, got %s", answer)
+	}
+}
+
+func TestValidateAnswer(t *testing.T) {
+	var options = []presets.PresetConfigQuestionOption{{Name: "Redis 6.0"}, {Name: "MailHog"}}
+
+	for _, tc := range []struct {
+		question presets.PresetConfigQuestion
+		value    string
+		expected string
+		err      string
+	}{
+		{presets.PresetConfigQuestion{Key: "name", Type: "input"}, "My Blog", "My Blog", ""},
+		{presets.PresetConfigQuestion{Key: "queue", Type: "confirm"}, "Y", "yes", ""},
+		{presets.PresetConfigQuestion{Key: "queue", Type: "confirm"}, "false", "no", ""},
+		{presets.PresetConfigQuestion{Key: "queue", Type: "confirm"}, "maybe", "", "invalid answer 'maybe' for question 'queue'; use yes or no"},
+		{presets.PresetConfigQuestion{Key: "cache", Options: options}, "redis 6.0", "Redis 6.0", ""},
+		{presets.PresetConfigQuestion{Key: "extras", Type: "multiselect", Options: options}, "mailhog, redis 6.0", "MailHog,Redis 6.0", ""},
+		{presets.PresetConfigQuestion{Key: "extras", Type: "multiselect", Options: options}, "MailHog,Kafka", "", "invalid answer 'Kafka' for question 'extras'; options are: Redis 6.0, MailHog"},
+		{presets.PresetConfigQuestion{Key: "extras", Type: "list"}, "a", "", "unknown type 'list' for question 'extras'"},
+	} {
+		answer, err := validateAnswer(tc.question, tc.value)
+
+		if tc.err != "" {
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("expected error '%s', got %v", tc.err, err)
+			}
+			continue
+		}
+
+		if err != nil || answer != tc.expected {
+			t.Errorf("expected answer '%s', got '%s' (error: %v)", tc.expected, answer, err)
+		}
+	}
+}
+
+func TestAskQuestionsWhen(t *testing.T) {
+	var (
+		f      = newFakeKoolPreset()
+		config = &presets.PresetConfig{
+			Questions: map[string][]presets.PresetConfigQuestion{
+				"compose": {
+					{Key: "queue", Type: "confirm", DefaultAnswer: "no"},
+					{Key: "broker", When: "queue", DefaultAnswer: "Redis 6.0", Options: []presets.PresetConfigQuestionOption{{Name: "Redis 6.0"}}},
+				},
+				"kool": {
+					{Key: "worker", Type: "input", When: "broker == 'Redis 6.0'", DefaultAnswer: "horizon"},
+				},
+			},
+		}
+	)
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	answers, err := f.askQuestions(config, map[string]string{})
+
+	if err != nil {
+		t.Fatalf("unexpected error asking questions: %v", err)
+	}
+
+	if _, asked := answers["broker"]; asked || answers["queue"] != "no" {
+		t.Errorf("expected only the queue question answered, got %v", answers)
+	}
+
+	if answers, _ = f.askQuestions(config, map[string]string{"queue": "yes"}); answers["broker"] != "Redis 6.0" || answers["worker"] != "horizon" {
+		t.Errorf("expected conditional questions answered, got %v", answers)
+	}
+
+	config.Questions["kool"][0].When = "broker ~ redis"
+
+	if _, err = f.askQuestions(config, map[string]string{"queue": "yes"}); err == nil || err.Error() != "invalid when condition 'broker ~ redis' on question worker" {
+		t.Errorf("expected error on invalid when condition, got %v", err)
+	}
+}
+
+func TestAskQuestionsTerminal(t *testing.T) {
+	var (
+		f      = newFakeKoolPreset()
+		config = &presets.PresetConfig{
+			Questions: map[string][]presets.PresetConfigQuestion{
+				"compose": {
+					{Key: "queue", Type: "confirm", Message: "Do you want a queue worker", DefaultAnswer: "no"},
+					{Key: "broker", When: "queue", Message: "Which broker", DefaultAnswer: "Redis 6.0", Options: []presets.PresetConfigQuestionOption{{Name: "Redis 6.0"}}},
+					{Key: "domain", Type: "input", Message: "What is your domain", DefaultAnswer: "localhost"},
+				},
+			},
+		}
+		prompt = f.prompt.(*shell.FakePrompt)
+	)
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = true
+	prompt.MockConfirm = map[string]bool{"Do you want a queue worker": true}
+	prompt.MockInput = map[string]string{"What is your domain": "app.test"}
+
+	answers, err := f.askQuestions(config, map[string]string{})
+
+	if err != nil {
+		t.Fatalf("unexpected error asking questions: %v", err)
+	}
+
+	if !prompt.CalledConfirm || !prompt.CalledInput {
+		t.Error("expected the compose confirm and input questions to be prompted")
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not prompt a compose select question with a single option")
+	}
+
+	expected := map[string]string{"queue": "yes", "broker": "Redis 6.0", "domain": "app.test"}
+
+	if !reflect.DeepEqual(answers, expected) {
+		t.Errorf("expected answers %v, got %v", expected, answers)
+	}
+}
+
+func TestQuestionTemplates(t *testing.T) {
+	var (
+		f        = newFakeKoolPreset()
+		question = presets.PresetConfigQuestion{
+			Key:  "extras",
+			Type: "multiselect",
+			Options: []presets.PresetConfigQuestionOption{
+				{Name: "Redis 6.0", Template: "redis6.yml"},
+				{Name: "MailHog", Template: "mailhog.yml"},
+			},
+		}
+	)
+
+	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
+		"extras": {
+			"redis6.yml":  "redis for {{ .Answers.name }}",
+			"mailhog.yml": "mailhog",
+			"broken.yml":  "{{ .Answers.name",
+		},
+	}
+
+	answers := map[string]string{"name": "blog", "extras": "MailHog,Redis 6.0"}
+
+	if templates, err := f.questionTemplates(question, "extras", presets.RenderData{Answers: answers}); err != nil || len(templates) != 2 || templates[0] != "mailhog" || templates[1] != "redis for blog" {
+		t.Errorf("unexpected templates %v (error: %v)", templates, err)
+	}
+
+	if templates, _ := f.questionTemplates(presets.PresetConfigQuestion{Key: "name", Type: "input"}, "extras", presets.RenderData{Answers: answers}); len(templates) != 0 {
+		t.Errorf("expected no templates for input question, got %v", templates)
+	}
+
+	if templates, _ := f.questionTemplates(question, "extras", presets.RenderData{}); len(templates) != 0 {
+		t.Errorf("expected no templates for unanswered question, got %v", templates)
+	}
+
+	question.Options[0].Template = "broken.yml"
+
+	if _, err := f.questionTemplates(question, "extras", presets.RenderData{Answers: answers}); err == nil || !strings.HasPrefix(err.Error(), "failed to render template broken.yml") {
+		t.Errorf("expected error rendering template, got %v", err)
+	}
+}
+
+func TestAnswersPresetCommand(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": newFakePresetAnswersConfig(),
+	}
+	f.presetsParser.(*presets.FakeParser).MockTemplates = map[string]map[string]string{
+		"database": {"postgresql13.yml": "postgresql"},
+		"cache":    {"redis6.yml": "redis"},
+		"scripts":  {"yarn.yml": "yarn"},
+	}
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"laravel", "--answer", "database=PostgreSQL 13.0", "--answer", "scripts=yarn"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not prompt on non-TTY")
+	}
+
+	for _, template := range []string{"postgresql", "redis", "yarn"} {
+		if !f.templateParser.(*templates.FakeParser).CalledParse[template] {
+			t.Errorf("did not parse template %s", template)
+		}
+	}
+
+	f = newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": newFakePresetAnswersConfig(),
+	}
+
+	cmd = NewPresetCommand(f)
+	cmd.SetArgs([]string{"laravel", "--answer", "cache=memcached"})
+
+	if err := cmd.Execute(); err == nil || err.Error() != "invalid answer 'memcached' for question 'cache'; options are: Redis 6.0, none" {
+		t.Errorf("expected error on invalid answer, got %v", err)
+	}
+
+	if len(f.presetsParser.(*presets.FakeParser).CalledWriteFiles) != 0 {
+		t.Error("should not write preset files on invalid answer")
+	}
+}
+
+func TestPreferVersionDefaults(t *testing.T) {
+	config := &presets.PresetConfig{
+		Language: "php",
+		Questions: map[string][]presets.PresetConfigQuestion{
+			"compose": {
+				{Key: "app", DefaultAnswer: "PHP 7.4", Options: []presets.PresetConfigQuestionOption{{Name: "PHP 7.4"}, {Name: "PHP 8.1"}}},
+				{Key: "database", DefaultAnswer: "MySQL 5.7", Options: []presets.PresetConfigQuestionOption{{Name: "MySQL 5.7"}, {Name: "MySQL 8.1"}}},
+			},
+		},
+	}
+
+	preferVersionDefaults(config, "")
+
+	if config.Questions["compose"][0].DefaultAnswer != "PHP 7.4" {
+		t.Error("should keep the default answers without a detected version")
+	}
+
+	preferVersionDefaults(config, "8.1")
+
+	if answer := config.Questions["compose"][0].DefaultAnswer; answer != "PHP 8.1" {
+		t.Errorf("expected default answer PHP 8.1, got %s", answer)
+	}
+
+	if answer := config.Questions["compose"][1].DefaultAnswer; answer != "MySQL 5.7" {
+		t.Errorf("should keep the default answer of non language options, got %s", answer)
+	}
+}
@@ -0,0 +1,264 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	presetMergeApply  = "Apply changes"
+	presetMergeCancel = "Cancel"
+
+	// presetDiffContext holds how many unchanged lines are shown around the changes
+	presetDiffContext = 2
+)
+
+// presetMergeFiles holds the preset files merged onto the existing ones
+var presetMergeFiles = []string{"docker-compose.yml", "kool.yml"}
+
+// isMergedFile tells whether the preset file is merged onto the existing one
+func isMergedFile(file string) bool {
+	for _, merged := range presetMergeFiles {
+		if merged == file {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergePreset merges the preset docker-compose.yml and kool.yml onto the
+// existing ones, only adding the services, volumes, networks and scripts
+// they miss. The changes are shown and applied upon confirmation.
+func (p *KoolPreset) mergePreset(preset string) (err error) {
+	var changed bool
+
+	for _, file := range presetMergeFiles {
+		var (
+			existing []byte
+			merged   string
+		)
+
+		content, found := p.presetsParser.GetPresetKeyContent(preset, file)
+
+		if !found {
+			continue
+		}
+
+		if existing, err = os.ReadFile(file); err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+				continue
+			}
+
+			err = fmt.Errorf("failed to read %s: %v", file, err)
+			return
+		}
+
+		if file == "kool.yml" {
+			merged, err = p.mergeKoolYaml(file, content)
+		} else {
+			merged, err = p.mergeCompose(string(existing), content)
+		}
+
+		if err != nil {
+			err = fmt.Errorf("failed to merge preset file %s: %v", file, err)
+			return
+		}
+
+		p.presetsParser.SetPresetKeyContent(preset, file, merged)
+
+		if diff := lineDiff(string(existing), merged); len(diff) > 0 {
+			changed = true
+
+			p.Println("Changes to", file+":")
+
+			for _, line := range diff {
+				p.Println(line)
+			}
+
+			// the YAML round-trip does not keep them
+			p.Println("Note: comments are dropped and YAML anchors and merge keys are expanded on", file)
+		}
+	}
+
+	if changed {
+		err = p.confirmChanges("merge the preset files")
+	}
+
+	return
+}
+
+// confirmChanges asks for confirmation to apply the changes shown, unless
+// using --yes or --auto; on a non-TTY, one of them is required
+func (p *KoolPreset) confirmChanges(action string) (err error) {
+	if p.Flags.Yes || p.Flags.Auto {
+		return
+	}
+
+	if !p.IsTerminal() {
+		err = fmt.Errorf("the input device is not a TTY; use --yes to %s without confirmation", action)
+		return
+	}
+
+	var answer string
+
+	if answer, err = p.promptSelect.Ask("Do you want to apply these changes", []string{presetMergeApply, presetMergeCancel}); err != nil {
+		return
+	}
+
+	if answer != presetMergeApply {
+		err = shell.ErrUserCancelled
+	}
+
+	return
+}
+
+// mergeCompose adds the services, volumes and networks of the preset
+// docker-compose.yml missing on the existing one
+func (p *KoolPreset) mergeCompose(existing, content string) (merged string, err error) {
+	var presetCompose compose.Compose
+
+	if err = yaml.Unmarshal([]byte(content), &presetCompose); err != nil {
+		return
+	}
+
+	if err = p.composeParser.Parse(existing); err != nil {
+		return
+	}
+
+	for _, service := range presetCompose.Services {
+		name := fmt.Sprint(service.Key)
+
+		if p.composeParser.HasService(name) {
+			p.Warning("Keeping the existing ", name, " service on docker-compose.yml")
+			continue
+		}
+
+		p.composeParser.SetService(name, service.Value)
+	}
+
+	for _, volume := range presetCompose.Volumes {
+		p.composeParser.SetVolume(fmt.Sprint(volume.Key), volume.Value)
+	}
+
+	for _, network := range presetCompose.Networks {
+		p.composeParser.SetNetwork(fmt.Sprint(network.Key), network.Value)
+	}
+
+	merged, err = p.composeParser.String()
+	return
+}
+
+// mergeKoolYaml adds the scripts of the preset kool.yml missing on the existing one
+func (p *KoolPreset) mergeKoolYaml(file, content string) (merged string, err error) {
+	var presetKoolYaml parser.KoolYaml
+
+	if err = yaml.Unmarshal([]byte(content), &presetKoolYaml); err != nil {
+		return
+	}
+
+	if err = p.koolYamlParser.Parse(file); err != nil {
+		return
+	}
+
+	for name, script := range presetKoolYaml.Scripts {
+		var commands []string
+
+		if p.koolYamlParser.HasScript(name) {
+			p.Warning("Keeping the existing ", name, " script on kool.yml")
+			continue
+		}
+
+		if lines, isList := script.([]interface{}); isList {
+			for _, line := range lines {
+				commands = append(commands, fmt.Sprint(line))
+			}
+		} else {
+			commands = append(commands, fmt.Sprint(script))
+		}
+
+		p.koolYamlParser.SetScript(name, commands)
+	}
+
+	merged, err = p.koolYamlParser.String()
+	return
+}
+
+// lineDiff returns the changes from old to new content line by line,
+// prefixed with + (added) or - (removed), surrounded by some unchanged
+// lines; skipped unchanged lines are shown as ...
+func lineDiff(old, new string) (diff []string) {
+	var (
+		a   = strings.Split(strings.TrimSuffix(old, "\n"), "\n")
+		b   = strings.Split(strings.TrimSuffix(new, "\n"), "\n")
+		lcs = make([][]int, len(a)+1)
+		ops []string
+	)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, "- "+a[i])
+			i++
+		default:
+			ops = append(ops, "+ "+b[j])
+			j++
+		}
+	}
+
+	keep := make([]bool, len(ops))
+
+	for k, op := range ops {
+		if op[0] == ' ' {
+			continue
+		}
+
+		for c := k - presetDiffContext; c <= k+presetDiffContext; c++ {
+			if c >= 0 && c < len(ops) {
+				keep[c] = true
+			}
+		}
+	}
+
+	for k, op := range ops {
+		if keep[k] {
+			diff = append(diff, op)
+		} else if k > 0 && keep[k-1] {
+			diff = append(diff, "  ...")
+		}
+	}
+
+	if len(diff) > 0 && diff[len(diff)-1] == "  ..." {
+		diff = diff[:len(diff)-1]
+	}
+
+	return
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const presetMergeExistingCompose = `services:
+  app:
+    image: my-custom-app
+    volumes:
+    - .:/app:delegated
+x-logging:
+  driver: none
+`
+
+const presetMergeCompose = `version: "3.7"
+services:
+  app:
+    image: kooldev/php:8.0-nginx
+  cache:
+    image: redis:6-alpine
+    networks:
+    - kool_local
+volumes:
+  cache:
+    driver: local
+networks:
+  kool_local: null
+`
+
+func newFakeMergeKoolPreset(t *testing.T) *KoolPreset {
+	var (
+		f   = newFakeKoolPreset()
+		dir = t.TempDir()
+	)
+
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = os.WriteFile("docker-compose.yml", []byte(presetMergeExistingCompose), os.ModePerm)
+	_ = os.WriteFile("kool.yml", []byte("scripts:\n  setup: make setup\nx-team: backend\n"), os.ModePerm)
+
+	f.presetsParser = presets.NewParser()
+	f.presetsParser.LoadPresets(map[string]map[string]string{
+		"laravel": {
+			"docker-compose.yml": presetMergeCompose,
+			"kool.yml":           "scripts:\n  setup:\n  - kool start\n  - kool run composer install\n  artisan: kool exec app php artisan\n",
+		},
+	})
+	f.composeParser = compose.NewParser()
+	f.koolYamlParser = &parser.KoolYaml{}
+
+	return f
+}
+
+func TestMergePreset(t *testing.T) {
+	f := newFakeMergeKoolPreset(t)
+	f.Flags.Yes = true
+
+	if err := f.mergePreset("laravel"); err != nil {
+		t.Fatalf("unexpected error merging preset: %v", err)
+	}
+
+	mergedCompose, _ := f.presetsParser.GetPresetKeyContent("laravel", "docker-compose.yml")
+
+	expectedCompose := `services:
+  app:
+    image: my-custom-app
+    volumes:
+    - .:/app:delegated
+  cache:
+    image: redis:6-alpine
+    networks:
+    - kool_local
+volumes:
+  cache:
+    driver: local
+networks:
+  kool_local: null
+x-logging:
+  driver: none
+`
+
+	if mergedCompose != expectedCompose {
+		t.Errorf("expected merged docker-compose.yml '%s', got '%s'", expectedCompose, mergedCompose)
+	}
+
+	mergedKoolYaml, _ := f.presetsParser.GetPresetKeyContent("laravel", "kool.yml")
+
+	if expected := "scripts:\n  artisan: kool exec app php artisan\n  setup: make setup\nx-team: backend\n"; mergedKoolYaml != expected {
+		t.Errorf("expected merged kool.yml '%s', got '%s'", expected, mergedKoolYaml)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); warning != "Keeping the existing setup script on kool.yml" {
+		t.Errorf("expected warning about kept script, got '%s'", warning)
+	}
+
+	output := strings.Join(f.shell.(*shell.FakeShell).OutLines, "\n")
+
+	for _, expected := range []string{"Changes to docker-compose.yml:", "+   cache:", "Changes to kool.yml:", "+   artisan: kool exec app php artisan",
+		"Note: comments are dropped and YAML anchors and merge keys are expanded on kool.yml"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected '%s' on the changes preview, got:\n%s", expected, output)
+		}
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not ask for confirmation with --yes")
+	}
+
+	if _, err := f.mergeCompose(presetMergeExistingCompose, presetMergeCompose); err != nil {
+		t.Fatalf("unexpected error merging docker-compose.yml: %v", err)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); warning != "Keeping the existing app service on docker-compose.yml" {
+		t.Errorf("expected warning about kept service, got '%s'", warning)
+	}
+}
+
+func TestMergePresetConfirmation(t *testing.T) {
+	f := newFakeMergeKoolPreset(t)
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{"Do you want to apply these changes": presetMergeCancel}
+
+	if err := f.mergePreset("laravel"); err != shell.ErrUserCancelled {
+		t.Errorf("expected cancelled error, got %v", err)
+	}
+
+	f = newFakeMergeKoolPreset(t)
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{"Do you want to apply these changes": presetMergeApply}
+
+	if err := f.mergePreset("laravel"); err != nil || !f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Errorf("expected confirmed merge, got %v", err)
+	}
+
+	f = newFakeMergeKoolPreset(t)
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+
+	if err := f.mergePreset("laravel"); err == nil || !strings.Contains(err.Error(), "use --yes") {
+		t.Errorf("expected non-TTY error, got %v", err)
+	}
+}
+
+func TestMergePresetWithoutExistingFiles(t *testing.T) {
+	f := newFakeMergeKoolPreset(t)
+
+	_ = os.Remove("docker-compose.yml")
+	_ = os.Remove("kool.yml")
+
+	if err := f.mergePreset("laravel"); err != nil {
+		t.Errorf("unexpected error merging preset: %v", err)
+	}
+
+	if content, _ := f.presetsParser.GetPresetKeyContent("laravel", "docker-compose.yml"); content != presetMergeCompose {
+		t.Error("should keep the preset docker-compose.yml when there is none")
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not ask for confirmation without changes")
+	}
+}
+
+func TestMergePresetError(t *testing.T) {
+	f := newFakeMergeKoolPreset(t)
+
+	_ = os.WriteFile("docker-compose.yml", []byte("services: ["), os.ModePerm)
+
+	if err := f.mergePreset("laravel"); err == nil || !strings.Contains(err.Error(), "failed to merge preset file docker-compose.yml") {
+		t.Errorf("expected merge error, got %v", err)
+	}
+}
+
+func TestMergePresetCommand(t *testing.T) {
+	var (
+		f       = newFakeMergeKoolPreset(t)
+		presets = t.TempDir()
+	)
+
+	_ = os.MkdirAll(presets+"/acme", os.ModePerm)
+	_ = os.WriteFile(presets+"/acme/docker-compose.yml", []byte(presetMergeCompose), os.ModePerm)
+	_ = os.WriteFile(presets+"/acme/.env.example", []byte("APP_ENV=local\n"), os.ModePerm)
+	_ = os.WriteFile(presets+"/acme/preset-config.yml", []byte("language: php"), os.ModePerm)
+	_ = os.WriteFile(".env.example", []byte("APP_ENV=production\n"), os.ModePerm)
+
+	f.sources.env.Set("KOOL_PRESETS_PATH", presets)
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"acme", "--merge", "--yes"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	content, _ := os.ReadFile("docker-compose.yml")
+
+	if !strings.Contains(string(content), "my-custom-app") || !strings.Contains(string(content), "redis:6-alpine") {
+		t.Errorf("unexpected docker-compose.yml after merging: %s", content)
+	}
+
+	backupDate := time.Now().Format("20060102")
+
+	if _, err := os.Stat("docker-compose.yml.bak." + backupDate); !os.IsNotExist(err) {
+		t.Error("should not back up the merged docker-compose.yml")
+	}
+
+	if _, err := os.Stat(".env.example.bak." + backupDate); err != nil {
+		t.Errorf("should back up the existing files not merged: %v", err)
+	}
+}
+
+func TestMergePresetInPlace(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.Flags.Merge = true
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{"laravel": {}}
+	f.presetsParser.(*presets.FakeParser).MockFoundFiles = []string{"docker-compose.yml", "kool.yml"}
+
+	if err := f.Execute([]string{"laravel"}); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledWarning {
+		t.Errorf("should not warn about renaming the merged files, got %v", f.shell.(*shell.FakeShell).WarningOutput)
+	}
+
+	if inPlace := f.presetsParser.(*presets.FakeParser).ArgsWriteFilesInPlace; !reflect.DeepEqual(inPlace, presetMergeFiles) {
+		t.Errorf("expected the merged files to be written in place, got %v", inPlace)
+	}
+
+	f.presetsParser.(*presets.FakeParser).MockFoundFiles = []string{".env.example"}
+
+	if err := f.Execute([]string{"laravel"}); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); !strings.Contains(warning, ".env.example.bak.") {
+		t.Errorf("expected backup warning for the files not merged, got %s", warning)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	diff := lineDiff("a\nb\nc\nd\ne\nf\ng\nh\n", "a\nb\nc\nd\nE\nf\ng\nh\ni\n")
+
+	expected := []string{"  c", "  d", "- e", "+ E", "  f", "  g", "  h", "+ i"}
+
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected diff %v, got %v", expected, diff)
+	}
+
+	diff = lineDiff("1\n2\n3\n4\n5\n6\n7\n8\n9\n", "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	expected = []string{"+ 0", "  1", "  2", "  ...", "  8", "  9", "+ 10"}
+
+	if !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected diff %v, got %v", expected, diff)
+	}
+
+	if diff = lineDiff("same\n", "same\n"); len(diff) != 0 {
+		t.Errorf("expected no diff, got %v", diff)
+	}
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPresetNewFlags holds the flags for the kool preset new command
+type KoolPresetNewFlags struct {
+	Language string
+}
+
+// KoolPresetNew holds handlers and functions to implement the preset new command logic
+type KoolPresetNew struct {
+	*KoolPreset
+	Flags *KoolPresetNewFlags
+}
+
+var presetNewImages = map[string]string{
+	"php":        `kooldev/php:{{ or (version .Answers.app) .Version "8.0" }}-nginx`,
+	"javascript": `kooldev/node:{{ or (version .Answers.app) .Version "16" }}`,
+	"golang":     `golang:{{ or (version .Answers.app) .Version "1.17" }}`,
+}
+
+// Execute runs the preset new logic with incoming arguments.
+func (n *KoolPresetNew) Execute(args []string) (err error) {
+	var (
+		name     = args[0]
+		language = strings.ToLower(n.Flags.Language)
+		files    map[string]string
+	)
+
+	if strings.ContainsAny(name, `/\ `) || name == "templates" || name == "presets" {
+		err = fmt.Errorf("invalid preset name %s", name)
+		return
+	}
+
+	if _, statErr := os.Stat(name); statErr == nil {
+		err = fmt.Errorf("%s already exists", name)
+		return
+	}
+
+	files = presetNewFiles(name, language)
+
+	// nothing is written when any of the files exists already
+	for path := range files {
+		if _, statErr := os.Stat(path); statErr == nil {
+			err = fmt.Errorf("%s already exists", path)
+			return
+		}
+	}
+
+	for path, content := range files {
+		if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return
+		}
+
+		if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+			err = fmt.Errorf("failed to write %s: %v", path, err)
+			return
+		}
+	}
+
+	source, _ := os.Getwd()
+
+	n.Success("Preset ", name, " created!")
+	n.Println("Check it with 'kool preset validate .' and try it out with:")
+	n.Println("  KOOL_PRESETS_PATH=" + source + " kool create " + name + " my-project")
+	return
+}
+
+// presetNewFiles returns the files of a new preset skeleton, keyed
+// by their path on a presets source folder
+func presetNewFiles(name, language string) map[string]string {
+	image, known := presetNewImages[language]
+
+	if !known {
+		image = "nginx:alpine"
+	}
+
+	return map[string]string{
+		filepath.Join(name, "preset-config.yml"): fmt.Sprintf(`language: %s
+commands:
+  create:
+    - mkdir -p $CREATE_DIRECTORY
+questions:
+  compose:
+    - key: app
+      default_answer: %s
+      message: Which app service do you want to use
+      options:
+        - name: %s
+          template: %s.yml
+`, language, name, name, name),
+		filepath.Join(name, "kool.yml"): `scripts:
+  setup:
+    - kool start
+    # - add more setup commands
+`,
+		filepath.Join("templates", "app", name+".yml"): fmt.Sprintf(`services:
+  app:
+    image: %s
+    ports:
+      - "${KOOL_APP_PORT:-80}:80"
+    volumes:
+      - .:/app:delegated
+    networks:
+      - kool_local
+      - kool_global
+`, image),
+	}
+}
+
+// NewPresetNewCommand initializes new kool preset new command
+func NewPresetNewCommand(presetNew *KoolPresetNew) (newCmd *cobra.Command) {
+	newCmd = &cobra.Command{
+		Use:   "new NAME",
+		Short: "Create the skeleton of a new preset",
+		Long: `Create the skeleton of a new preset called NAME on the current working
+directory, which becomes a presets source that can be used through
+KOOL_PRESETS_PATH. The skeleton holds a preset-config.yml with a single
+question and the app service template it refers to.`,
+		Args: cobra.ExactArgs(1),
+		RunE: DefaultCommandRunFunction(presetNew),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	newCmd.Flags().StringVarP(&presetNew.Flags.Language, "language", "l", "php", "The language of the preset (php, javascript, golang or other).")
+	return
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPresetNewCommand(t *testing.T) {
+	presetNew := &KoolPresetNew{newFakeKoolPreset(), &KoolPresetNewFlags{}}
+	cmd := NewPresetNewCommand(presetNew)
+
+	if cmd.Use != "new NAME" {
+		t.Errorf("unexpected command use: %s", cmd.Use)
+	}
+
+	if presetNew.Flags.Language != "php" {
+		t.Errorf("expected default language php, got %s", presetNew.Flags.Language)
+	}
+
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("expected an error when no preset name is given")
+	}
+}
+
+func TestPresetNewCommand(t *testing.T) {
+	chdirProject(t, nil)
+
+	f := &KoolPresetNew{newFakeKoolPreset(), &KoolPresetNewFlags{}}
+	cmd := NewPresetNewCommand(f)
+	cmd.SetArgs([]string{"acme", "--language", "javascript"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset new command: %v", err)
+	}
+
+	if output := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); output != "Preset acme created!" {
+		t.Errorf("unexpected success output: %s", output)
+	}
+
+	config, err := os.ReadFile(filepath.Join("acme", "preset-config.yml"))
+	if err != nil {
+		t.Fatalf("expected preset-config.yml to be created: %v", err)
+	}
+
+	if !strings.Contains(string(config), "language: javascript") || !strings.Contains(string(config), "template: acme.yml") {
+		t.Errorf("unexpected preset-config.yml: %s", config)
+	}
+
+	if _, err = os.Stat(filepath.Join("acme", "kool.yml")); err != nil {
+		t.Errorf("expected kool.yml to be created: %v", err)
+	}
+
+	app, err := os.ReadFile(filepath.Join("templates", "app", "acme.yml"))
+	if err != nil {
+		t.Fatalf("expected app template to be created: %v", err)
+	}
+
+	if !strings.Contains(string(app), "image: kooldev/node:") {
+		t.Errorf("unexpected app template: %s", app)
+	}
+
+	assertExecGotError(t, cmd, "acme already exists")
+}
+
+func TestPresetNewCommandErrors(t *testing.T) {
+	chdirProject(t, nil)
+
+	f := &KoolPresetNew{newFakeKoolPreset(), &KoolPresetNewFlags{}}
+	cmd := NewPresetNewCommand(f)
+
+	cmd.SetArgs([]string{"templates"})
+	assertExecGotError(t, cmd, "invalid preset name templates")
+
+	cmd.SetArgs([]string{"my/preset"})
+	assertExecGotError(t, cmd, "invalid preset name my/preset")
+}
+
+func TestPresetNewCommandExistingTemplate(t *testing.T) {
+	chdirProject(t, nil)
+
+	if err := os.MkdirAll(filepath.Join("templates", "app"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join("templates", "app", "acme.yml"), []byte("services: {}\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &KoolPresetNew{newFakeKoolPreset(), &KoolPresetNewFlags{}}
+	cmd := NewPresetNewCommand(f)
+	cmd.SetArgs([]string{"acme"})
+
+	assertExecGotError(t, cmd, filepath.Join("templates", "app", "acme.yml")+" already exists")
+
+	if _, err := os.Stat("acme"); !os.IsNotExist(err) {
+		t.Error("should not write any of the preset files when one of them exists")
+	}
+}
+
+func TestPresetNewFiles(t *testing.T) {
+	images := map[string]string{
+		"php":    "image: kooldev/php:",
+		"golang": "image: golang:",
+		"ruby":   "image: nginx:alpine",
+	}
+
+	for language, image := range images {
+		files := presetNewFiles("acme", language)
+
+		if len(files) != 3 {
+			t.Errorf("expected 3 files for %s, got %d", language, len(files))
+		}
+
+		if !strings.Contains(files[filepath.Join("templates", "app", "acme.yml")], image) {
+			t.Errorf("expected %s app template to use %s", language, image)
+		}
+	}
+}
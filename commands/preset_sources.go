@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// presetSources holds logic for loading presets from outside the kool
+// binary: ~/.kool/presets and the folders or git repositories listed
+// (comma separated) on KOOL_PRESETS_PATH
+type presetSources struct {
+	env      environment.EnvStorage
+	clone    builder.Command
+	fetch    builder.Command
+	checkout builder.Command
+}
+
+func newPresetSources() *presetSources {
+	return &presetSources{
+		environment.NewEnvStorage(),
+		builder.NewCommand("git", "clone", "--quiet", "--depth", "1"),
+		builder.NewCommand("git"),
+		builder.NewCommand("git"),
+	}
+}
+
+// Load loads the built-in presets and templates onto the parser, merged
+// with the external ones; sources failing to load are warned about and skipped
+func (s *presetSources) Load(parser presets.Parser, sh shell.Shell) {
+	parser.LoadPresets(presets.GetAll())
+	parser.LoadTemplates(presets.GetTemplates())
+	parser.LoadConfigs(presets.GetConfigs())
+
+	for _, dir := range s.dirs(sh) {
+		if err := parser.LoadDir(dir); err != nil {
+			sh.Warning("Could not load presets from ", dir, ": ", err)
+		}
+	}
+}
+
+// dirs returns the local folders holding the external presets, cloning
+// or updating the git repositories onto the kool cache
+func (s *presetSources) dirs(sh shell.Shell) (dirs []string) {
+	var userPresets = filepath.Join(s.env.Get("HOME"), ".kool", "presets")
+
+	if info, err := os.Stat(userPresets); err == nil && info.IsDir() {
+		dirs = append(dirs, userPresets)
+	}
+
+	for _, source := range strings.Split(s.env.Get("KOOL_PRESETS_PATH"), ",") {
+		if source = strings.TrimSpace(source); source == "" {
+			continue
+		}
+
+		if !isGitSource(source) {
+			if strings.HasPrefix(source, "~/") {
+				source = filepath.Join(s.env.Get("HOME"), source[2:])
+			}
+
+			// the presets may be loaded again after changing folders (i.e. kool create)
+			if abs, err := filepath.Abs(source); err == nil {
+				source = abs
+			}
+
+			dirs = append(dirs, source)
+			continue
+		}
+
+		dir, err := s.gitDir(source, sh)
+
+		if err != nil {
+			sh.Warning("Could not load presets from ", source, ": ", err)
+			continue
+		}
+
+		dirs = append(dirs, dir)
+	}
+
+	return
+}
+
+// gitDir clones the git repository (as URL or URL#REF) onto the kool cache
+// returning its folder; an existing clone is updated, and used as it is
+// when the update fails (i.e. offline)
+func (s *presetSources) gitDir(source string, sh shell.Shell) (dir string, err error) {
+	var (
+		url, ref = source, ""
+		sum      = sha1.Sum([]byte(source))
+	)
+
+	if i := strings.LastIndex(source, "#"); i > 0 {
+		url, ref = source[:i], source[i+1:]
+	}
+
+	dir = filepath.Join(s.env.Get("HOME"), ".kool", "cache", "presets", hex.EncodeToString(sum[:])[:12])
+
+	if _, statErr := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(statErr) {
+		clone := s.clone.Copy()
+
+		if ref != "" {
+			clone.AppendArgs("--branch", ref)
+		}
+
+		clone.AppendArgs(url, dir)
+
+		if _, err = sh.Exec(clone); err != nil {
+			err = fmt.Errorf("failed to clone %s: %v", url, err)
+		}
+
+		return
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	fetch := s.fetch.Copy()
+	fetch.AppendArgs("-C", dir, "fetch", "--quiet", "--depth", "1", "origin", ref)
+
+	if _, err = sh.Exec(fetch); err == nil {
+		checkout := s.checkout.Copy()
+		checkout.AppendArgs("-C", dir, "checkout", "--quiet", "--force", "FETCH_HEAD")
+
+		_, err = sh.Exec(checkout)
+	}
+
+	if err != nil {
+		sh.Warning("Could not update presets from ", url, "; using the cached copy: ", err)
+		err = nil
+	}
+
+	return
+}
+
+// isGitSource tells whether the presets source is a git repository URL
+func isGitSource(source string) bool {
+	url := strings.SplitN(source, "#", 2)[0]
+
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "git@") || strings.HasSuffix(url, ".git")
+}
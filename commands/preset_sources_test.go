@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newFakePresetSources() *presetSources {
+	return &presetSources{
+		environment.NewFakeEnvStorage(),
+		&builder.FakeCommand{MockCmd: "git"},
+		&builder.FakeCommand{MockCmd: "git"},
+		&builder.FakeCommand{MockCmd: "git"},
+	}
+}
+
+func TestNewPresetSources(t *testing.T) {
+	s := newPresetSources()
+
+	if _, ok := s.env.(*environment.DefaultEnvStorage); !ok {
+		t.Errorf("unexpected environment.EnvStorage on default presetSources")
+	}
+
+	if s.clone.Cmd() != "git" || s.fetch.Cmd() != "git" || s.checkout.Cmd() != "git" {
+		t.Errorf("unexpected git commands on default presetSources")
+	}
+}
+
+func TestPresetSourcesLoad(t *testing.T) {
+	var (
+		home   = t.TempDir()
+		s      = newFakePresetSources()
+		sh     = &shell.FakeShell{}
+		parser = &presets.FakeParser{MockLoadDirError: map[string]error{"/broken": errors.New("broken")}}
+	)
+
+	if err := os.MkdirAll(filepath.Join(home, ".kool", "presets"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	s.env.Set("HOME", home)
+	s.env.Set("KOOL_PRESETS_PATH", "/company/presets, ~/stacks,,/broken")
+
+	s.Load(parser, sh)
+
+	if !parser.CalledLoadPresets || !parser.CalledLoadTemplates || !parser.CalledLoadConfigs {
+		t.Error("did not load the built-in presets")
+	}
+
+	for _, dir := range []string{filepath.Join(home, ".kool", "presets"), "/company/presets", filepath.Join(home, "stacks"), "/broken"} {
+		if !parser.CalledLoadDir[dir] {
+			t.Errorf("did not load presets from %s", dir)
+		}
+	}
+
+	if len(parser.CalledLoadDir) != 4 {
+		t.Errorf("expected 4 presets folders, got %v", parser.CalledLoadDir)
+	}
+
+	if !sh.CalledWarning || !strings.Contains(fmt.Sprint(sh.WarningOutput...), "/broken: broken") {
+		t.Errorf("expected warning on failing presets folder, got %v", sh.WarningOutput)
+	}
+}
+
+func TestPresetSourcesRelativeDirs(t *testing.T) {
+	var (
+		s  = newFakePresetSources()
+		sh = &shell.FakeShell{}
+	)
+
+	s.env.Set("HOME", t.TempDir())
+	s.env.Set("KOOL_PRESETS_PATH", "./my-presets")
+
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := s.dirs(sh)
+
+	if len(dirs) != 1 || dirs[0] != filepath.Join(wd, "my-presets") {
+		t.Errorf("expected relative presets folder to be made absolute, got %v", dirs)
+	}
+}
+
+func TestPresetSourcesLoadWithoutExternal(t *testing.T) {
+	var (
+		s      = newFakePresetSources()
+		sh     = &shell.FakeShell{}
+		parser = &presets.FakeParser{}
+	)
+
+	s.env.Set("HOME", t.TempDir())
+
+	s.Load(parser, sh)
+
+	if len(parser.CalledLoadDir) != 0 || sh.CalledWarning {
+		t.Errorf("unexpected presets folders loaded: %v", parser.CalledLoadDir)
+	}
+}
+
+func TestPresetSourcesGitClone(t *testing.T) {
+	var (
+		home   = t.TempDir()
+		s      = newFakePresetSources()
+		sh     = &shell.FakeShell{}
+		parser = &presets.FakeParser{}
+	)
+
+	s.env.Set("HOME", home)
+	s.env.Set("KOOL_PRESETS_PATH", "https://github.com/acme/kool-presets.git#v1.2.0")
+
+	s.Load(parser, sh)
+
+	clone := s.clone.(*builder.FakeCommand)
+
+	if len(clone.ArgsAppend) != 4 || !reflect.DeepEqual(clone.ArgsAppend[:3], []string{"--branch", "v1.2.0", "https://github.com/acme/kool-presets.git"}) {
+		t.Fatalf("unexpected clone arguments: %v", clone.ArgsAppend)
+	}
+
+	dir := clone.ArgsAppend[3]
+
+	if filepath.Dir(dir) != filepath.Join(home, ".kool", "cache", "presets") {
+		t.Errorf("unexpected clone folder: %s", dir)
+	}
+
+	if !parser.CalledLoadDir[dir] {
+		t.Errorf("did not load presets from the cloned repository")
+	}
+
+	if s.fetch.(*builder.FakeCommand).CalledAppendArgs {
+		t.Error("should not update a fresh clone")
+	}
+}
+
+func TestPresetSourcesGitCloneError(t *testing.T) {
+	var (
+		s      = newFakePresetSources()
+		sh     = &shell.FakeShell{}
+		parser = &presets.FakeParser{}
+	)
+
+	s.env.Set("HOME", t.TempDir())
+	s.env.Set("KOOL_PRESETS_PATH", "git@github.com:acme/kool-presets")
+	s.clone.(*builder.FakeCommand).MockExecError = errors.New("repository not found")
+
+	s.Load(parser, sh)
+
+	if len(parser.CalledLoadDir) != 0 {
+		t.Errorf("should not load presets from a failed clone: %v", parser.CalledLoadDir)
+	}
+
+	if !sh.CalledWarning || !strings.Contains(fmt.Sprint(sh.WarningOutput...), "failed to clone git@github.com:acme/kool-presets: repository not found") {
+		t.Errorf("expected warning on failed clone, got %v", sh.WarningOutput)
+	}
+}
+
+func TestPresetSourcesGitUpdate(t *testing.T) {
+	var (
+		home   = t.TempDir()
+		s      = newFakePresetSources()
+		sh     = &shell.FakeShell{}
+		source = "https://github.com/acme/kool-presets"
+	)
+
+	s.env.Set("HOME", home)
+
+	dir, _ := s.gitDir(source, sh)
+	s.clone.(*builder.FakeCommand).ArgsAppend = nil
+
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if updated, err := s.gitDir(source, sh); err != nil || updated != dir {
+		t.Fatalf("unexpected update result: %s %v", updated, err)
+	}
+
+	if s.clone.(*builder.FakeCommand).CalledAppendArgs && len(s.clone.(*builder.FakeCommand).ArgsAppend) > 0 {
+		t.Error("should not clone an existing repository again")
+	}
+
+	if args := s.fetch.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"-C", dir, "fetch", "--quiet", "--depth", "1", "origin", "HEAD"}) {
+		t.Errorf("unexpected fetch arguments: %v", args)
+	}
+
+	if args := s.checkout.(*builder.FakeCommand).ArgsAppend; !reflect.DeepEqual(args, []string{"-C", dir, "checkout", "--quiet", "--force", "FETCH_HEAD"}) {
+		t.Errorf("unexpected checkout arguments: %v", args)
+	}
+
+	s.fetch.(*builder.FakeCommand).MockExecError = errors.New("offline")
+
+	if updated, err := s.gitDir(source, sh); err != nil || updated != dir {
+		t.Errorf("expected cached copy when offline, got %s %v", updated, err)
+	}
+
+	if !sh.CalledWarning {
+		t.Error("expected warning on failed update")
+	}
+}
+
+func TestIsGitSource(t *testing.T) {
+	for source, expected := range map[string]bool{
+		"https://github.com/acme/presets":     true,
+		"ssh://git@host/acme/presets#main":    true,
+		"git@github.com:acme/presets":         true,
+		"/opt/presets.git":                    true,
+		"/opt/presets":                        false,
+		"~/presets":                           false,
+		"relative/presets#not-a-ref-for-dirs": false,
+	} {
+		if isGitSource(source) != expected {
+			t.Errorf("unexpected isGitSource(%s); expected %v", source, expected)
+		}
+	}
+}
@@ -3,11 +3,14 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/parser"
 	"kool-dev/kool/core/presets"
 	"kool-dev/kool/core/shell"
 	"kool-dev/kool/core/templates"
 	"kool-dev/kool/services/compose"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -40,11 +43,16 @@ scripts:
 func newFakeKoolPreset() *KoolPreset {
 	return &KoolPreset{
 		*newFakeKoolService(),
+		&KoolPresetFlags{[]string{}, "", false, false, false},
 		&presets.FakeParser{},
 		&compose.FakeParser{},
 		&templates.FakeParser{},
 		&parser.FakeKoolYaml{},
 		&shell.FakePromptSelect{},
+		&shell.FakePrompt{},
+		environment.NewFakeEnvStorage(),
+		newFakePresetSources(),
+		false,
 	}
 }
 
@@ -80,11 +88,108 @@ func TestNewKoolPreset(t *testing.T) {
 		t.Errorf("unexpected shell.PromptSelect on default KoolPreset instance")
 	}
 
+	if _, ok := k.prompt.(*shell.DefaultPrompt); !ok {
+		t.Errorf("unexpected shell.Prompt on default KoolPreset instance")
+	}
+
+	if _, ok := k.env.(*environment.DefaultEnvStorage); !ok {
+		t.Errorf("unexpected environment.EnvStorage on default KoolPreset instance")
+	}
+
 	if _, ok := k.DefaultKoolService.term.(*shell.DefaultTerminalChecker); !ok {
 		t.Errorf("unexpected shell.TerminalChecker on default KoolPreset instance")
 	}
 }
 
+func TestRenderPresetCommand(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": {Language: "php"},
+	}
+	f.env.Set("KOOL_NAME", "blog")
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"laravel"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	data := f.presetsParser.(*presets.FakeParser).MockRenderData
+
+	if !f.presetsParser.(*presets.FakeParser).CalledRenderFiles["laravel"] || data.Name != "blog" || data.Language != "php" {
+		t.Errorf("did not render the preset files with the project data; got %v", data)
+	}
+
+	f = newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": {},
+	}
+	f.presetsParser.(*presets.FakeParser).MockFileError = "docker-compose.yml"
+	f.presetsParser.(*presets.FakeParser).MockRenderError = errors.New("render error")
+
+	cmd = NewPresetCommand(f)
+	cmd.SetArgs([]string{"laravel"})
+
+	if err := cmd.Execute(); err == nil || err.Error() != "failed to render preset file docker-compose.yml: render error" {
+		t.Errorf("expected error rendering preset files, got %v", err)
+	}
+
+	if f.presetsParser.(*presets.FakeParser).CalledWriteFiles["laravel"] {
+		t.Error("should not write preset files failing to render")
+	}
+}
+
+func TestRenderPresetOnce(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser = presets.NewParser()
+	f.templateParser = templates.NewParser()
+	f.koolYamlParser = &parser.KoolYaml{}
+	f.Flags.Auto = true
+	f.env.Set("KOOL_NAME", "blog")
+
+	f.presetsParser.LoadPresets(map[string]map[string]string{
+		"acme": {".env.example": "APP_NAME={{ .Name }}\nAPP_KEY={{ .Answers.key }}\nLITERAL={{\"{{\"}}value}}\n"},
+	})
+	f.presetsParser.LoadTemplates(map[string]map[string]string{
+		"scripts": {"status.yml": "scripts:\n  status: docker inspect --format '{{\"{{\"}}.State.Status}}' {{ .Name }}\n"},
+	})
+	f.presetsParser.LoadConfigs(map[string]string{
+		"acme": `language: php
+questions:
+  kool:
+    - key: key
+      type: input
+      default_answer: "{{.Secret}}"
+      message: Key
+    - key: scripts
+      default_answer: status
+      message: Scripts
+      options:
+        - name: status
+          template: status.yml
+`,
+	})
+
+	if _, err := f.customizePreset("acme", nil); err != nil {
+		t.Fatalf("unexpected error customizing preset: %v", err)
+	}
+
+	env, _ := f.presetsParser.GetPresetKeyContent("acme", ".env.example")
+
+	if env != "APP_NAME=blog\nAPP_KEY={{.Secret}}\nLITERAL={{value}}\n" {
+		t.Errorf("unexpected rendered .env.example: %s", env)
+	}
+
+	koolYml, _ := f.presetsParser.GetPresetKeyContent("acme", "kool.yml")
+
+	if !strings.Contains(koolYml, "docker inspect --format '{{.State.Status}}' blog") {
+		t.Errorf("expected the escaped braces to be rendered once on kool.yml, got: %s", koolYml)
+	}
+}
+
 func TestPresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockExists = true
@@ -249,6 +354,111 @@ func TestNoArgsPresetCommand(t *testing.T) {
 	}
 }
 
+// chdirProject changes into a temporary project folder with the given files
+func chdirProject(t *testing.T, files map[string]string) {
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, content := range files {
+		if err := os.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectedNoArgsPresetCommand(t *testing.T) {
+	chdirProject(t, map[string]string{"composer.json": `{"require": {"symfony/framework-bundle": "6.0.*"}}`})
+
+	f := newFakeKoolPreset()
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
+		"What language do you want to use": "php",
+		"What preset do you want to use":   "symfony",
+	}
+	f.presetsParser.(*presets.FakeParser).MockLanguages = []string{"javascript", "php"}
+	f.presetsParser.(*presets.FakeParser).MockPresets = []string{"laravel", "php", "symfony"}
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"symfony": {Language: "php"},
+	}
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if output := f.shell.(*shell.FakeShell).OutLines[0]; output != "Detected a symfony project" {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	asked := f.promptSelect.(*shell.FakePromptSelect).AskedOptions
+
+	if !reflect.DeepEqual(asked["What language do you want to use"], []string{"php", "javascript"}) {
+		t.Errorf("expected php pre-selected, got %v", asked["What language do you want to use"])
+	}
+
+	if !reflect.DeepEqual(asked["What preset do you want to use"], []string{"symfony", "laravel", "php"}) {
+		t.Errorf("expected symfony pre-selected, got %v", asked["What preset do you want to use"])
+	}
+}
+
+func TestAutoPresetCommand(t *testing.T) {
+	chdirProject(t, map[string]string{"composer.json": `{"require": {"laravel/framework": "^9.0"}}`})
+
+	f := newFakeKoolPreset()
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{
+		"laravel": newFakePresetAnswersConfig(),
+	}
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"--auto"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if !f.presetsParser.(*presets.FakeParser).CalledWriteFiles["laravel"] {
+		t.Error("did not write the detected laravel preset files")
+	}
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = true
+	f.presetsParser.(*presets.FakeParser).CalledWriteFiles = nil
+
+	cmd = NewPresetCommand(f)
+	cmd.SetArgs([]string{"--auto"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	if f.promptSelect.(*shell.FakePromptSelect).CalledAsk || !f.presetsParser.(*presets.FakeParser).CalledWriteFiles["laravel"] {
+		t.Error("should apply the detected preset without prompting on --auto")
+	}
+
+	chdirProject(t, map[string]string{})
+
+	cmd = NewPresetCommand(f)
+	cmd.SetArgs([]string{"--auto"})
+	assertExecGotError(t, cmd, "could not detect the project type; please specify a preset argument")
+}
+
+func TestPreferOption(t *testing.T) {
+	if sorted := preferOption([]string{"a", "b", "c"}, "c"); !reflect.DeepEqual(sorted, []string{"c", "a", "b"}) {
+		t.Errorf("unexpected sorted options: %v", sorted)
+	}
+
+	if sorted := preferOption([]string{"a", "b"}, ""); !reflect.DeepEqual(sorted, []string{"a", "b"}) {
+		t.Errorf("unexpected sorted options: %v", sorted)
+	}
+}
+
 func TestFailingLanguageNoArgsPresetCommand(t *testing.T) {
 	f := newFakeKoolPreset()
 	f.presetsParser.(*presets.FakeParser).MockLanguages = []string{"php"}
@@ -332,7 +542,7 @@ func TestNonTTYPresetCommand(t *testing.T) {
 
 	cmd := NewPresetCommand(f)
 
-	assertExecGotError(t, cmd, "the input device is not a TTY; for non-tty environments, please specify a preset argument")
+	assertExecGotError(t, cmd, "the input device is not a TTY; for non-tty environments, please specify a preset argument or use --auto")
 }
 
 func TestCustomDockerComposePresetCommand(t *testing.T) {
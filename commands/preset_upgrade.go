@@ -0,0 +1,357 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/presets"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPresetUpgrade holds handlers and functions to implement the preset upgrade command logic
+type KoolPresetUpgrade struct {
+	*KoolPreset
+}
+
+// Execute runs the preset upgrade logic with incoming arguments.
+func (u *KoolPresetUpgrade) Execute(args []string) (err error) {
+	var (
+		lock      *presets.Lock
+		config    *presets.PresetConfig
+		answers   map[string]string
+		files     []string
+		changed   []string
+		conflicts []string
+		upgraded  = make(map[string]string)
+		kept      = make(map[string]presets.LockedFile)
+	)
+
+	if lock, err = u.presetsParser.ReadLock(); err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("%s not found; the project files were not written by kool preset", presets.LockFile)
+		} else {
+			err = fmt.Errorf("failed to read %s: %v", presets.LockFile, err)
+		}
+		return
+	}
+
+	u.loadParsers()
+
+	if !u.presetsParser.Exists(lock.Preset) {
+		err = fmt.Errorf("unknown preset %s", lock.Preset)
+		return
+	}
+
+	if config, err = u.presetsParser.GetConfig(lock.Preset); err != nil || config == nil {
+		err = fmt.Errorf("error parsing preset config; err: %v", err)
+		return
+	}
+
+	u.Println("Upgrading preset", lock.Preset, "applied by kool", lock.KoolVersion)
+
+	if answers, err = u.customizePreset(lock.Preset, u.lockedAnswers(lock, config)); err != nil {
+		return
+	}
+
+	base := u.presetsParser.GetPresetFiles(lock.Preset)
+
+	for file := range base {
+		files = append(files, file)
+	}
+
+	sort.Strings(files)
+
+	for _, file := range files {
+		var (
+			existing []byte
+			conflict bool
+		)
+
+		if existing, err = os.ReadFile(file); err != nil && !os.IsNotExist(err) {
+			err = fmt.Errorf("failed to read %s: %v", file, err)
+			return
+		}
+
+		locked, wasLocked := lock.Files[file]
+		missing := os.IsNotExist(err)
+		err = nil
+
+		switch {
+		case missing || !wasLocked && string(existing) == base[file]:
+			upgraded[file] = base[file]
+		case wasLocked && locked.Content != "":
+			upgraded[file], conflict = mergeLines(locked.Content, string(existing), base[file])
+		case wasLocked && locked.Hash == presets.Hash(string(existing)):
+			// the file was not changed since the preset was applied
+			upgraded[file] = base[file]
+		default:
+			if string(existing) != base[file] {
+				u.Warning("Keeping ", file, " as it was changed since the preset was applied")
+
+				if wasLocked {
+					// it stays changed from the preset for later upgrades
+					kept[file] = locked
+				}
+			}
+
+			upgraded[file] = string(existing)
+		}
+
+		if conflict {
+			conflicts = append(conflicts, file)
+		}
+
+		if !missing && upgraded[file] == string(existing) {
+			continue
+		}
+
+		changed = append(changed, file)
+
+		u.Println("Changes to", file+":")
+
+		for _, line := range lineDiff(string(existing), upgraded[file]) {
+			u.Println(line)
+		}
+	}
+
+	if len(changed) > 0 {
+		if err = u.confirmChanges("upgrade the preset files"); err != nil {
+			return
+		}
+
+		for _, file := range changed {
+			if err = os.WriteFile(file, []byte(upgraded[file]), os.ModePerm); err != nil {
+				err = fmt.Errorf("failed to write %s: %v", file, err)
+				return
+			}
+		}
+	}
+
+	upgradedLock := u.presetLock(lock.Preset, answers, base, upgraded)
+
+	for file, locked := range kept {
+		upgradedLock.Files[file] = locked
+	}
+
+	if err = u.presetsParser.WriteLock(upgradedLock); err != nil {
+		err = fmt.Errorf("failed to write %s: %v", presets.LockFile, err)
+		return
+	}
+
+	if len(conflicts) > 0 {
+		u.Warning("Preset ", lock.Preset, " upgraded with conflicts on ", strings.Join(conflicts, ", "), "; resolve them before running kool start")
+	} else if len(changed) > 0 {
+		u.Success("Preset ", lock.Preset, " upgraded!")
+	} else {
+		u.Success("Preset ", lock.Preset, " is up to date")
+	}
+
+	return
+}
+
+// lockedAnswers returns the answers recorded on the lock still valid for
+// the current preset questions
+func (u *KoolPresetUpgrade) lockedAnswers(lock *presets.Lock, config *presets.PresetConfig) (answers map[string]string) {
+	answers = make(map[string]string)
+
+	for _, group := range config.Questions {
+		for _, question := range group {
+			answer, locked := lock.Answers[question.Key]
+
+			if !locked {
+				continue
+			}
+
+			if valid, err := validateAnswer(question, answer); err != nil {
+				u.Warning("Dropping the locked answer to ", question.Key, ": ", err)
+			} else {
+				answers[question.Key] = valid
+			}
+		}
+	}
+
+	return
+}
+
+// presetLock builds the lock recording the preset applied to the project:
+// its answers, the hashes of the written files and, for the files merged
+// on upgrades, the preset contents they were based on
+func (p *KoolPreset) presetLock(preset string, answers, base, written map[string]string) (lock *presets.Lock) {
+	lock = &presets.Lock{
+		Preset:      preset,
+		KoolVersion: version,
+		Answers:     answers,
+		Files:       make(map[string]presets.LockedFile),
+	}
+
+	for file, content := range written {
+		locked := presets.LockedFile{Hash: presets.Hash(content)}
+
+		for _, mergeable := range presetMergeFiles {
+			if file == mergeable {
+				locked.Content = base[file]
+			}
+		}
+
+		lock.Files[file] = locked
+	}
+
+	return
+}
+
+// mergeLines merges line by line the changes from base to theirs onto
+// ours; changes of both sides to the same lines are conflicts, which are
+// kept marked on the merged content
+func mergeLines(base, ours, theirs string) (merged string, conflicts bool) {
+	var (
+		b      = splitLines(base)
+		o      = splitLines(ours)
+		t      = splitLines(theirs)
+		toOurs = lineMatches(b, o)
+		toThem = lineMatches(b, t)
+		lines  []string
+	)
+
+	i, j, k := 0, 0, 0
+
+	for {
+		// lines unchanged on both sides
+		for i < len(b) && toOurs[i] == j && toThem[i] == k {
+			lines = append(lines, b[i])
+			i++
+			j++
+			k++
+		}
+
+		if i == len(b) && j == len(o) && k == len(t) {
+			break
+		}
+
+		// the changed chunk goes up to the next line kept on both sides
+		next, oursEnd, theirsEnd := i, len(o), len(t)
+
+		for next < len(b) && (toOurs[next] < 0 || toThem[next] < 0) {
+			next++
+		}
+
+		if next < len(b) {
+			oursEnd, theirsEnd = toOurs[next], toThem[next]
+		}
+
+		var (
+			baseChunk   = b[i:next]
+			oursChunk   = o[j:oursEnd]
+			theirsChunk = t[k:theirsEnd]
+		)
+
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			lines = append(lines, theirsChunk...)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			lines = append(lines, oursChunk...)
+		default:
+			conflicts = true
+			lines = append(lines, "<<<<<<< yours")
+			lines = append(lines, oursChunk...)
+			lines = append(lines, "=======")
+			lines = append(lines, theirsChunk...)
+			lines = append(lines, ">>>>>>> preset")
+		}
+
+		i, j, k = next, oursEnd, theirsEnd
+	}
+
+	if len(lines) > 0 {
+		merged = strings.Join(lines, "\n") + "\n"
+	}
+
+	return
+}
+
+// lineMatches returns, for each line of a, the index of the line of b it
+// matches on their longest common subsequence, or -1
+func lineMatches(a, b []string) (matches []int) {
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	matches = make([]int, len(a))
+
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[i] = j
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewPresetUpgradeCommand initializes new kool preset upgrade command
+func NewPresetUpgradeCommand(upgrade *KoolPresetUpgrade) (upgradeCmd *cobra.Command) {
+	upgradeCmd = &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the project files to the current version of their preset",
+		Long: `Render again the preset recorded on ` + presets.LockFile + ` with its answers,
+and bring the changes onto the project files. Changes to docker-compose.yml and
+kool.yml are merged with the ones made to them since the preset was applied,
+marking conflicting changes; other files are only replaced when unchanged.
+The changes are shown for confirmation before writing the files.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(upgrade),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	addPresetAnswersFlags(upgradeCmd, upgrade.Flags)
+	upgradeCmd.Flags().BoolVarP(&upgrade.Flags.Yes, "yes", "y", false, "Apply the upgrade changes without asking for confirmation.")
+	return
+}
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const presetUpgradeCompose = `services:
+  app:
+    image: kooldev/php:7.4-nginx
+    ports:
+    - "80:80"
+`
+
+// newFakeUpgradeKoolPreset returns a KoolPreset with a real presets parser,
+// running on a temporary folder, with the acme preset loaded from the
+// returned presets folder
+func newFakeUpgradeKoolPreset(t *testing.T) (f *KoolPreset, presetsDir string) {
+	var dir = t.TempDir()
+
+	f = newFakeKoolPreset()
+	presetsDir = t.TempDir()
+
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	f.presetsParser = presets.NewParser()
+	f.sources.env.Set("KOOL_PRESETS_PATH", presetsDir)
+
+	writeUpgradePreset(t, presetsDir, presetUpgradeCompose, "APP_ENV=local\n")
+	return
+}
+
+func writeUpgradePreset(t *testing.T, presetsDir, compose, env string) {
+	dir := filepath.Join(presetsDir, "acme")
+
+	_ = os.MkdirAll(dir, os.ModePerm)
+
+	for file, content := range map[string]string{
+		"docker-compose.yml": compose,
+		".env.example":       env,
+		"preset-config.yml":  "language: php",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPresetWritesLock(t *testing.T) {
+	f, _ := newFakeUpgradeKoolPreset(t)
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"acme"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	lock, err := f.presetsParser.ReadLock()
+
+	if err != nil {
+		t.Fatalf("unexpected error reading lock: %v", err)
+	}
+
+	if lock.Preset != "acme" || lock.KoolVersion != version {
+		t.Errorf("unexpected lock %v", lock)
+	}
+
+	if locked := lock.Files["docker-compose.yml"]; locked.Hash != presets.Hash(presetUpgradeCompose) || locked.Content != presetUpgradeCompose {
+		t.Errorf("unexpected locked docker-compose.yml: %v", locked)
+	}
+
+	if locked := lock.Files[".env.example"]; locked.Hash != presets.Hash("APP_ENV=local\n") || locked.Content != "" {
+		t.Errorf("unexpected locked .env.example: %v", locked)
+	}
+
+	f = newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockExists = true
+	f.presetsParser.(*presets.FakeParser).MockConfig = map[string]*presets.PresetConfig{"laravel": {}}
+	f.presetsParser.(*presets.FakeParser).MockWriteLockError = errors.New("write error")
+
+	cmd = NewPresetCommand(f)
+	cmd.SetArgs([]string{"laravel"})
+
+	assertExecGotError(t, cmd, "failed to write kool-preset.lock: write error")
+}
+
+func TestPresetUpgradeCommand(t *testing.T) {
+	f, presetsDir := newFakeUpgradeKoolPreset(t)
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"acme"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	// the project changes its files, while the preset changes as well
+	_ = os.WriteFile("docker-compose.yml", []byte(presetUpgradeCompose+"  worker:\n    image: my-worker\n"), os.ModePerm)
+	_ = os.WriteFile(".env.example", []byte("APP_ENV=production\n"), os.ModePerm)
+	writeUpgradePreset(t, presetsDir, strings.Replace(presetUpgradeCompose, "7.4", "8.1", 1), "APP_ENV=local\nAPP_DEBUG=true\n")
+	f.loaded = false // as a new kool run would load the changed preset
+
+	upgrade := NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	upgrade.SetArgs([]string{"--yes"})
+
+	if err := upgrade.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset upgrade command; error: %v", err)
+	}
+
+	expected := strings.Replace(presetUpgradeCompose, "7.4", "8.1", 1) + "  worker:\n    image: my-worker\n"
+
+	if content, _ := os.ReadFile("docker-compose.yml"); string(content) != expected {
+		t.Errorf("expected merged docker-compose.yml '%s', got '%s'", expected, content)
+	}
+
+	if content, _ := os.ReadFile(".env.example"); string(content) != "APP_ENV=production\n" {
+		t.Errorf("should keep the changed .env.example, got '%s'", content)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); warning != "Keeping .env.example as it was changed since the preset was applied" {
+		t.Errorf("unexpected warning: %s", warning)
+	}
+
+	if success := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); success != "Preset acme upgraded!" {
+		t.Errorf("unexpected success message: %s", success)
+	}
+
+	lock, _ := f.presetsParser.ReadLock()
+
+	if locked := lock.Files["docker-compose.yml"]; locked.Hash != presets.Hash(expected) || !strings.Contains(locked.Content, "8.1") || strings.Contains(locked.Content, "worker") {
+		t.Errorf("unexpected locked docker-compose.yml after upgrade: %v", locked)
+	}
+
+	upgrade = NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	upgrade.SetArgs([]string{})
+
+	if err := upgrade.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset upgrade command; error: %v", err)
+	}
+
+	if success := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); success != "Preset acme is up to date" {
+		t.Errorf("unexpected success message: %s", success)
+	}
+}
+
+func TestPresetUpgradeConflicts(t *testing.T) {
+	f, presetsDir := newFakeUpgradeKoolPreset(t)
+
+	cmd := NewPresetCommand(f)
+	cmd.SetArgs([]string{"acme"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset command; error: %v", err)
+	}
+
+	_ = os.WriteFile("docker-compose.yml", []byte(strings.Replace(presetUpgradeCompose, "7.4", "8.0", 1)), os.ModePerm)
+	writeUpgradePreset(t, presetsDir, strings.Replace(presetUpgradeCompose, "7.4", "8.1", 1), "APP_ENV=local\n")
+	f.loaded = false // as a new kool run would load the changed preset
+
+	upgrade := NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	upgrade.SetArgs([]string{})
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	assertExecGotError(t, upgrade, "use --yes to upgrade the preset files without confirmation")
+
+	f.term.(*shell.FakeTerminalChecker).MockIsTerminal = true
+	f.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{"Do you want to apply these changes": presetMergeApply}
+
+	upgrade = NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	upgrade.SetArgs([]string{})
+
+	if err := upgrade.Execute(); err != nil {
+		t.Fatalf("unexpected error executing preset upgrade command; error: %v", err)
+	}
+
+	if content, _ := os.ReadFile("docker-compose.yml"); !strings.Contains(string(content), "<<<<<<< yours\n    image: kooldev/php:8.0-nginx\n=======\n    image: kooldev/php:8.1-nginx\n>>>>>>> preset\n") {
+		t.Errorf("expected conflict markers on docker-compose.yml, got '%s'", content)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); warning != "Preset acme upgraded with conflicts on docker-compose.yml; resolve them before running kool start" {
+		t.Errorf("unexpected warning: %s", warning)
+	}
+}
+
+func TestPresetUpgradeErrors(t *testing.T) {
+	f := newFakeKoolPreset()
+	f.presetsParser.(*presets.FakeParser).MockReadLockError = os.ErrNotExist
+
+	cmd := NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	cmd.SetArgs([]string{})
+	assertExecGotError(t, cmd, "kool-preset.lock not found")
+
+	f.presetsParser.(*presets.FakeParser).MockReadLockError = errors.New("invalid lock")
+
+	cmd = NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	cmd.SetArgs([]string{})
+	assertExecGotError(t, cmd, "failed to read kool-preset.lock: invalid lock")
+
+	f.presetsParser.(*presets.FakeParser).MockReadLockError = nil
+	f.presetsParser.(*presets.FakeParser).MockLock = &presets.Lock{Preset: "gone"}
+
+	cmd = NewPresetUpgradeCommand(&KoolPresetUpgrade{f})
+	cmd.SetArgs([]string{})
+	assertExecGotError(t, cmd, "unknown preset gone")
+}
+
+func TestLockedAnswers(t *testing.T) {
+	var (
+		f    = newFakeKoolPreset()
+		lock = &presets.Lock{Answers: map[string]string{
+			"database": "postgresql 13.0",
+			"cache":    "Memcached 1.6",
+			"removed":  "yes",
+		}}
+	)
+
+	answers := (&KoolPresetUpgrade{f}).lockedAnswers(lock, newFakePresetAnswersConfig())
+
+	if !reflect.DeepEqual(answers, map[string]string{"database": "PostgreSQL 13.0"}) {
+		t.Errorf("unexpected locked answers: %v", answers)
+	}
+
+	if warning := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); !strings.HasPrefix(warning, "Dropping the locked answer to cache: invalid answer 'Memcached 1.6'") {
+		t.Errorf("unexpected warning: %s", warning)
+	}
+}
+
+func TestMergeLines(t *testing.T) {
+	for _, tc := range []struct {
+		base, ours, theirs string
+		expected           string
+		conflicts          bool
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", "a\nB\nc\n", "a\nB\nc\n", false},
+		{"a\nb\nc\n", "z\na\nb\nc\n", "a\nb\nc\nd\n", "z\na\nb\nc\nd\n", false},
+		{"a\nb\nc\n", "a\nc\n", "a\nb\nc\nd\n", "a\nc\nd\n", false},
+		{"a\nb\nc\n", "a\nX\nc\n", "a\nX\nc\n", "a\nX\nc\n", false},
+		{"a\nb\nc\n", "a\nX\nc\n", "a\nY\nc\n", "a\n<<<<<<< yours\nX\n=======\nY\n>>>>>>> preset\nc\n", true},
+		{"", "a\n", "b\n", "<<<<<<< yours\na\n=======\nb\n>>>>>>> preset\n", true},
+		{"a\n", "", "a\n", "", false},
+	} {
+		merged, conflicts := mergeLines(tc.base, tc.ours, tc.theirs)
+
+		if merged != tc.expected || conflicts != tc.conflicts {
+			t.Errorf("merging %q, %q and %q: expected %q (conflicts %v), got %q (conflicts %v)", tc.base, tc.ours, tc.theirs, tc.expected, tc.conflicts, merged, conflicts)
+		}
+	}
+}
+
+func TestLineMatches(t *testing.T) {
+	if matches := lineMatches([]string{"a", "b", "c", "d"}, []string{"a", "c", "x", "d"}); !reflect.DeepEqual(matches, []int{0, -1, 1, 3}) {
+		t.Errorf("unexpected line matches: %v", matches)
+	}
+}
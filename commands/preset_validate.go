@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/templates"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// KoolPresetValidate holds handlers and functions to implement the preset validate command logic
+type KoolPresetValidate struct {
+	*KoolPreset
+}
+
+// Execute runs the preset validate logic with incoming arguments.
+func (v *KoolPresetValidate) Execute(args []string) (err error) {
+	var (
+		source  string
+		names   []string
+		single  bool
+		invalid int
+	)
+
+	if source, names, single, err = presetDirs(args[0]); err != nil {
+		return
+	}
+
+	if len(names) == 0 {
+		err = fmt.Errorf("no presets found on %s", args[0])
+		return
+	}
+
+	// presets may use the built-in templates
+	v.presetsParser.LoadTemplates(presets.GetTemplates())
+
+	if single {
+		err = v.presetsParser.LoadPresetDir(source)
+	} else {
+		err = v.presetsParser.LoadDir(source)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("failed to load presets from %s: %v", source, err)
+		return
+	}
+
+	for _, name := range names {
+		problems := v.presetsParser.Validate(name)
+
+		if len(problems) == 0 {
+			problems = v.checkOutput(name)
+		}
+
+		if len(problems) == 0 {
+			v.Success("Preset ", name, " is valid")
+			continue
+		}
+
+		invalid++
+		v.Warning("Preset ", name, " has problems:")
+
+		for _, problem := range problems {
+			v.Println("  -", problem)
+		}
+	}
+
+	if invalid > 0 {
+		err = fmt.Errorf("%d of %d presets have problems", invalid, len(names))
+	}
+
+	return
+}
+
+// checkOutput renders the preset with the default answers, checking the
+// resulting docker-compose.yml and kool.yml
+func (v *KoolPresetValidate) checkOutput(name string) (problems []string) {
+	checker := *v.KoolPreset
+	checker.Flags = &KoolPresetFlags{Answers: []string{}, Auto: true}
+
+	// each preset is rendered from scratch, not onto the former one
+	checker.composeParser = compose.NewParser()
+	checker.templateParser = templates.NewParser()
+	checker.koolYamlParser = &parser.KoolYaml{}
+
+	// the result must not depend on the project on the working directory
+	if _, err := checker.renderPreset(name, nil, false); err != nil {
+		problems = append(problems, err.Error())
+		return
+	}
+
+	if content, found := v.presetsParser.GetPresetKeyContent(name, "docker-compose.yml"); found {
+		builds, err := compose.ParseServicesBuilds(content)
+
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid docker-compose.yml: %v", err))
+		} else if len(builds) == 0 {
+			problems = append(problems, "docker-compose.yml has no services")
+		}
+
+		for _, build := range builds {
+			if build.Image == "" && !build.IsBuilt() {
+				problems = append(problems, fmt.Sprintf("service %s on docker-compose.yml has no image or build", build.Service))
+			}
+		}
+	}
+
+	if content, found := v.presetsParser.GetPresetKeyContent(name, "kool.yml"); found {
+		var output parser.KoolYaml
+
+		if err := yaml.Unmarshal([]byte(content), &output); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid kool.yml: %v", err))
+		}
+	}
+
+	return
+}
+
+// presetDirs returns the folder to load the presets from for dir, which is
+// either a presets source or a single preset folder, along with the presets
+// in it; single tells the folder is the one of a single preset
+func presetDirs(dir string) (source string, names []string, single bool, err error) {
+	var entries []os.DirEntry
+
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+
+	if _, statErr := os.Stat(filepath.Join(dir, "preset-config.yml")); statErr == nil {
+		source, names, single = dir, []string{filepath.Base(dir)}, true
+		return
+	}
+
+	source = dir
+	presetsDir := dir
+
+	if info, statErr := os.Stat(filepath.Join(dir, "presets")); statErr == nil && info.IsDir() {
+		presetsDir = filepath.Join(dir, "presets")
+	}
+
+	if entries, err = os.ReadDir(presetsDir); err != nil {
+		err = fmt.Errorf("failed to read presets from %s: %v", dir, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "templates" && entry.Name() != "presets" {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return
+}
+
+// NewPresetValidateCommand initializes new kool preset validate command
+func NewPresetValidateCommand(validate *KoolPresetValidate) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate DIR",
+		Short: "Validate the presets on a folder",
+		Long: `Validate the presets on DIR, which is either a single preset folder or a
+presets source (as used on KOOL_PRESETS_PATH). The preset-config.yml of each
+preset is checked against the presets schema, along with the templates its
+questions refer to; the preset is then rendered with the default answers,
+checking the resulting docker-compose.yml and kool.yml.`,
+		Args: cobra.ExactArgs(1),
+		RunE: DefaultCommandRunFunction(validate),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/presets"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newValidateKoolPreset returns a preset validate handler with a real
+// presets parser, running on a temporary folder
+func newValidateKoolPreset(t *testing.T) *KoolPresetValidate {
+	chdirProject(t, nil)
+
+	f := newFakeKoolPreset()
+	f.presetsParser = presets.NewParser()
+
+	return &KoolPresetValidate{f}
+}
+
+func writePresetFiles(t *testing.T, files map[string]string) {
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewPresetValidateCommand(t *testing.T) {
+	cmd := NewPresetValidateCommand(&KoolPresetValidate{newFakeKoolPreset()})
+
+	if cmd.Use != "validate DIR" {
+		t.Errorf("unexpected command use: %s", cmd.Use)
+	}
+
+	if err := cmd.Args(cmd, []string{}); err == nil {
+		t.Error("expected an error when no folder is given")
+	}
+}
+
+func TestPresetValidateCommand(t *testing.T) {
+	f := newValidateKoolPreset(t)
+	writePresetFiles(t, presetNewFiles("acme", "php"))
+
+	cmd := NewPresetValidateCommand(f)
+	cmd.SetArgs([]string{"."})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error validating a new preset: %v", err)
+	}
+
+	if output := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); output != "Preset acme is valid" {
+		t.Errorf("unexpected success output: %s", output)
+	}
+}
+
+func TestPresetValidateCommandProblems(t *testing.T) {
+	f := newValidateKoolPreset(t)
+	writePresetFiles(t, presetNewFiles("acme", "php"))
+	writePresetFiles(t, map[string]string{
+		filepath.Join("broken", "preset-config.yml"): `language: php
+questions:
+  compose:
+    - key: app
+      message: Which app
+      options:
+        - name: missing
+          template: missing.yml
+`,
+		filepath.Join("noimage", "preset-config.yml"): `language: php
+questions:
+  compose:
+    - key: app
+      default_answer: noimage
+      message: Which app
+      options:
+        - name: noimage
+          template: noimage.yml
+`,
+		filepath.Join("templates", "app", "noimage.yml"): `services:
+  app:
+    ports:
+      - "80:80"
+`,
+	})
+
+	cmd := NewPresetValidateCommand(f)
+	cmd.SetArgs([]string{"."})
+
+	assertExecGotError(t, cmd, "2 of 3 presets have problems")
+
+	if output := fmt.Sprint(f.shell.(*shell.FakeShell).WarningOutput...); output != "Preset noimage has problems:" {
+		t.Errorf("unexpected warning output: %s", output)
+	}
+
+	output := strings.Join(f.shell.(*shell.FakeShell).OutLines, "\n")
+
+	if !strings.Contains(output, "template app/missing.yml of question app option missing not found") {
+		t.Errorf("expected the missing template problem, got: %s", output)
+	}
+
+	if !strings.Contains(output, "service app on docker-compose.yml has no image or build") {
+		t.Errorf("expected the missing image problem, got: %s", output)
+	}
+}
+
+func TestPresetValidateCommandIgnoresProjectVersion(t *testing.T) {
+	f := newValidateKoolPreset(t)
+	writePresetFiles(t, map[string]string{
+		"composer.json": `{"require": {"php": "^8.1"}}`,
+		filepath.Join("versioned", "preset-config.yml"): `language: php
+questions:
+  compose:
+    - key: app
+      default_answer: versioned
+      message: Which app
+      options:
+        - name: versioned
+          template: versioned.yml
+`,
+		filepath.Join("templates", "app", "versioned.yml"): `services:
+  app:
+    image: "{{ .Version }}"
+`,
+	})
+
+	cmd := NewPresetValidateCommand(f)
+	cmd.SetArgs([]string{"."})
+
+	assertExecGotError(t, cmd, "1 of 1 presets have problems")
+
+	if output := strings.Join(f.shell.(*shell.FakeShell).OutLines, "\n"); !strings.Contains(output, "service app on docker-compose.yml has no image or build") {
+		t.Errorf("expected the preset to be validated without the project version, got: %s", output)
+	}
+}
+
+func TestPresetValidateCommandSinglePreset(t *testing.T) {
+	f := newValidateKoolPreset(t)
+	writePresetFiles(t, presetNewFiles("acme", "php"))
+	// a broken sibling preset must not get in the way
+	writePresetFiles(t, map[string]string{filepath.Join("broken", "preset-config.yml"): "\tinvalid"})
+
+	cmd := NewPresetValidateCommand(f)
+	cmd.SetArgs([]string{"acme"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error validating a single preset: %v", err)
+	}
+
+	if output := fmt.Sprint(f.shell.(*shell.FakeShell).SuccessOutput...); output != "Preset acme is valid" {
+		t.Errorf("unexpected success output: %s", output)
+	}
+
+	if f.presetsParser.Exists("broken") {
+		t.Error("should not load the sibling presets")
+	}
+}
+
+func TestPresetValidateCommandNoPresets(t *testing.T) {
+	f := newValidateKoolPreset(t)
+
+	cmd := NewPresetValidateCommand(f)
+	cmd.SetArgs([]string{"."})
+
+	assertExecGotError(t, cmd, "no presets found on .")
+}
+
+func TestPresetDirs(t *testing.T) {
+	chdirProject(t, nil)
+	writePresetFiles(t, map[string]string{
+		filepath.Join("source", "presets", "acme", "preset-config.yml"): "language: php",
+		filepath.Join("source", "presets", "other", "kool.yml"):         "scripts: {}",
+		filepath.Join("source", "templates", "app", "acme.yml"):         "services: {}",
+	})
+
+	root, _ := filepath.Abs("source")
+
+	source, names, single, err := presetDirs("source")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if source != root || single || !reflect.DeepEqual(names, []string{"acme", "other"}) {
+		t.Errorf("unexpected presets %s %v", source, names)
+	}
+
+	if source, names, single, err = presetDirs(filepath.Join("source", "presets", "acme")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if source != filepath.Join(root, "presets", "acme") || !single || !reflect.DeepEqual(names, []string{"acme"}) {
+		t.Errorf("unexpected presets for a single preset %s %v", source, names)
+	}
+
+	if _, _, _, err = presetDirs("missing"); err == nil {
+		t.Error("expected an error for a missing folder")
+	}
+}
@@ -0,0 +1,342 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"kool-dev/kool/services/proxy"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// ProxyImage holds the image of the kool proxy container
+	ProxyImage = "traefik:v2.4"
+	// ProxyContainer holds the name of the kool proxy container
+	ProxyContainer = "kool_proxy"
+
+	// proxyContainerDir is where the proxy certificates and routes are mounted within the container
+	proxyContainerDir = "/etc/kool-proxy"
+)
+
+// KoolProxy holds the shared logic for serving the projects services
+// on <service>.<project>.localhost domains through the kool proxy
+type KoolProxy struct {
+	DefaultKoolService
+
+	env   environment.EnvStorage
+	files compose.FilesAware
+	table shell.TableWriter
+
+	running    builder.Command
+	run        builder.Command
+	remove     builder.Command
+	containers builder.Command
+	inspect    builder.Command
+	connect    builder.Command
+}
+
+// NewKoolProxy creates a new handler for the kool proxy with default dependencies
+func NewKoolProxy() *KoolProxy {
+	return &KoolProxy{
+		*newDefaultKoolService(),
+		environment.NewEnvStorage(),
+		compose.NewDockerCompose("config"),
+		shell.NewTableWriter(),
+		builder.NewCommand("docker", "ps", "-q", "--filter", "name=^"+ProxyContainer+"$"),
+		builder.NewCommand("docker", "run", "-d", "--name", ProxyContainer, "--restart", "unless-stopped"),
+		builder.NewCommand("docker", "rm", "-f", ProxyContainer),
+		compose.NewDockerCompose("ps", "-q"),
+		builder.NewCommand("docker", "inspect", "--format"),
+		builder.NewCommand("docker", "network", "connect"),
+	}
+}
+
+// NewProxyCommand initializes new kool proxy command
+func NewProxyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "proxy",
+		Short: "Manage the local HTTPS proxy serving the projects domains",
+		Long: `Manage the local proxy serving the services labeled with 'kool.proxy.port' on
+the docker-compose files on https://<service>.<project>.localhost domains, with
+certificates signed by a local certificate authority kool creates.`,
+		Args: cobra.NoArgs,
+
+		DisableFlagsInUseLine: true,
+	}
+}
+
+func AddKoolProxy(root *cobra.Command) {
+	var (
+		proxy    = NewKoolProxy()
+		proxyCmd = NewProxyCommand()
+	)
+
+	root.AddCommand(proxyCmd)
+	proxyCmd.AddCommand(NewProxyLsCommand(&KoolProxyLs{proxy}))
+	proxyCmd.AddCommand(NewProxyTrustCommand(&KoolProxyTrust{proxy, runtime.GOOS}))
+}
+
+// dir returns the folder holding the proxy CA, certificates and routes
+func (p *KoolProxy) dir() string {
+	return filepath.Join(p.env.Get("HOME"), ".kool", "proxy")
+}
+
+// project returns the docker-compose project name
+func (p *KoolProxy) project() string {
+	return compose.ProjectName(p.env.Get("KOOL_NAME"))
+}
+
+// domain returns the domain the service is served on
+func (p *KoolProxy) domain(service string) string {
+	return fmt.Sprintf("%s.%s.localhost", service, p.project())
+}
+
+// routesFile returns the routes file of the project
+func (p *KoolProxy) routesFile() string {
+	return filepath.Join(p.dir(), "dynamic", p.project()+".yml")
+}
+
+// proxiedServices reads the services labeled to be served through
+// the proxy on the docker-compose files in use
+func (p *KoolProxy) proxiedServices() (services []compose.ProxiedService, err error) {
+	var (
+		files  []composeFile
+		parsed []compose.ProxiedService
+		seen   = make(map[string]int)
+	)
+
+	if files, err = readComposeFiles(p.files, p.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if parsed, err = compose.ParseProxiedServices(file.content); err != nil {
+			err = fmt.Errorf("failed to parse services from %s: %v", file.path, err)
+			return
+		}
+
+		for _, service := range parsed {
+			if i, exists := seen[service.Service]; exists {
+				services[i] = service
+				continue
+			}
+
+			seen[service.Service] = len(services)
+			services = append(services, service)
+		}
+	}
+
+	return
+}
+
+// servers returns the addresses of the service containers, attaching
+// them to the global network so the proxy can reach them
+func (p *KoolProxy) servers(service compose.ProxiedService) (servers []string, err error) {
+	var (
+		output  string
+		network = p.env.Get("KOOL_GLOBAL_NETWORK")
+		format  = fmt.Sprintf("{{.Name}}\t{{with index .NetworkSettings.Networks %q}}{{.NetworkID}}{{end}}", network)
+	)
+
+	if output, err = p.Exec(p.containers, service.Service); err != nil {
+		return
+	}
+
+	for _, id := range strings.Fields(output) {
+		if output, err = p.Exec(p.inspect, format, id); err != nil {
+			return
+		}
+
+		fields := strings.Split(strings.TrimRight(output, "\r\n"), "\t")
+		name := strings.TrimPrefix(fields[0], "/")
+
+		if len(fields) < 2 || fields[1] == "" {
+			if _, err = p.Exec(p.connect, network, id); err != nil {
+				return
+			}
+		}
+
+		servers = append(servers, fmt.Sprintf("http://%s:%s", name, service.Port))
+	}
+
+	return
+}
+
+// isRunning tells whether the proxy container is running
+func (p *KoolProxy) isRunning() (running bool, err error) {
+	var output string
+
+	if output, err = p.Exec(p.running); err != nil {
+		return
+	}
+
+	running = strings.TrimSpace(output) != ""
+	return
+}
+
+// proxyPort returns the host port for the proxy entrypoint, which
+// can be changed through the given environment variable
+func (p *KoolProxy) proxyPort(variable, port string) string {
+	if value := p.env.Get(variable); value != "" {
+		return value
+	}
+
+	return port
+}
+
+// startProxy starts the proxy container when it is not running yet
+func (p *KoolProxy) startProxy() (err error) {
+	var running bool
+
+	if running, err = p.isRunning(); err != nil || running {
+		return
+	}
+
+	// a stopped proxy container would conflict by name
+	_, _ = p.Exec(p.remove)
+
+	_, err = p.Exec(p.run,
+		"--network", p.env.Get("KOOL_GLOBAL_NETWORK"),
+		"-p", p.proxyPort("KOOL_PROXY_HTTP_PORT", "80")+":80",
+		"-p", p.proxyPort("KOOL_PROXY_HTTPS_PORT", "443")+":443",
+		// only the certificates and routes, keeping the CA key out of the container
+		"-v", filepath.Join(p.dir(), "certs")+":"+path.Join(proxyContainerDir, "certs")+":ro",
+		"-v", filepath.Join(p.dir(), "dynamic")+":"+path.Join(proxyContainerDir, "dynamic")+":ro",
+		ProxyImage,
+		"--entrypoints.web.address=:80",
+		"--entrypoints.websecure.address=:443",
+		"--providers.file.directory="+path.Join(proxyContainerDir, "dynamic"),
+		"--providers.file.watch=true",
+	)
+
+	if err != nil {
+		err = fmt.Errorf("failed to start the kool proxy: %v", err)
+	}
+
+	return
+}
+
+// KoolProxyStart holds handlers for routing the project domains
+// through the kool proxy alongside kool start
+type KoolProxyStart struct {
+	*KoolProxy
+}
+
+// Execute routes the domains of the project proxied services to
+// their containers, starting the proxy when it is not running yet
+func (s *KoolProxyStart) Execute(args []string) (err error) {
+	var (
+		services []compose.ProxiedService
+		routes   []proxy.Route
+		hosts    []string
+		servers  []string
+		ca       *proxy.CA
+		config   []byte
+		project  = s.project()
+	)
+
+	if services, err = s.proxiedServices(); err != nil || len(services) == 0 {
+		return
+	}
+
+	for _, service := range services {
+		if servers, err = s.servers(service); err != nil {
+			err = fmt.Errorf("failed to route service %s: %v", service.Service, err)
+			return
+		}
+
+		if len(servers) == 0 {
+			continue
+		}
+
+		routes = append(routes, proxy.Route{
+			Name:    project + "-" + service.Service,
+			Host:    s.domain(service.Service),
+			Servers: servers,
+		})
+		hosts = append(hosts, s.domain(service.Service))
+	}
+
+	if len(routes) == 0 {
+		return
+	}
+
+	hosts = append(hosts, "*."+project+".localhost")
+
+	if ca, err = proxy.LoadOrCreateCA(s.dir()); err != nil {
+		err = fmt.Errorf("failed to load the local certificate authority: %v", err)
+		return
+	}
+
+	if _, _, err = ca.Certificate(filepath.Join(s.dir(), "certs"), project, hosts); err != nil {
+		err = fmt.Errorf("failed to issue the certificate of %s: %v", project, err)
+		return
+	}
+
+	if config, err = proxy.DynamicConfig(
+		routes,
+		path.Join(proxyContainerDir, "certs", project+".pem"),
+		path.Join(proxyContainerDir, "certs", project+"-key.pem"),
+	); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.routesFile()), os.ModePerm); err != nil {
+		return
+	}
+
+	if err = os.WriteFile(s.routesFile(), config, 0644); err != nil {
+		return
+	}
+
+	if err = s.startProxy(); err != nil {
+		return
+	}
+
+	for _, route := range routes {
+		s.Println("Serving", "https://"+route.Host)
+	}
+
+	return
+}
+
+// KoolProxyStop holds handlers for removing the project
+// routes from the kool proxy alongside kool stop
+type KoolProxyStop struct {
+	*KoolProxy
+}
+
+// Execute removes the project routes, stopping the
+// proxy when no other project routes remain
+func (s *KoolProxyStop) Execute(args []string) (err error) {
+	var (
+		remaining []string
+		running   bool
+	)
+
+	if err = os.Remove(s.routesFile()); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	if remaining, err = filepath.Glob(filepath.Join(filepath.Dir(s.routesFile()), "*.yml")); err != nil || len(remaining) > 0 {
+		return
+	}
+
+	if running, err = s.isRunning(); err != nil || !running {
+		return
+	}
+
+	_, err = s.Exec(s.remove)
+	return
+}
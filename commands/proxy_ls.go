@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"kool-dev/kool/services/compose"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolProxyLs holds handlers and functions to implement the proxy ls command logic
+type KoolProxyLs struct {
+	*KoolProxy
+}
+
+// Execute runs the proxy ls logic with incoming arguments.
+func (l *KoolProxyLs) Execute(args []string) (err error) {
+	var services []compose.ProxiedService
+
+	if services, err = l.proxiedServices(); err != nil {
+		return
+	}
+
+	if len(services) == 0 {
+		l.Println("No services labeled with", compose.ProxyPortLabel, "on the docker-compose files")
+		return
+	}
+
+	l.table.SetWriter(l.OutStream())
+	l.table.AppendHeader("Service", "URL", "Port")
+
+	for _, service := range services {
+		l.table.AppendRow(service.Service, "https://"+l.domain(service.Service), service.Port)
+	}
+
+	l.table.Render()
+	return
+}
+
+// NewProxyLsCommand initializes new kool proxy ls command
+func NewProxyLsCommand(ls *KoolProxyLs) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the domains of the project services",
+		Args:  cobra.NoArgs,
+		RunE:  DefaultCommandRunFunction(ls),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProxyLsCommand(t *testing.T) {
+	ls := &KoolProxyLs{newFakeKoolProxy(t)}
+
+	if err := NewProxyLsCommand(ls).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := ls.table.(*shell.FakeTableWriter).Rows
+
+	if len(rows) != 1 || rows[0][0] != "app" || rows[0][1] != "https://app.main.localhost" || rows[0][2] != "80" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestProxyLsCommandNoServices(t *testing.T) {
+	ls := &KoolProxyLs{newFakeKoolProxy(t)}
+
+	if err := os.WriteFile(filepath.Join(ls.env.Get("PWD"), "docker-compose.yml"), []byte("services:\n  app:\n    image: nginx\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewProxyLsCommand(ls).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := ls.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "No services labeled with kool.proxy.port on the docker-compose files" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
+
+func TestProxyLsCommandError(t *testing.T) {
+	ls := &KoolProxyLs{newFakeKoolProxy(t)}
+
+	if err := os.WriteFile(filepath.Join(ls.env.Get("PWD"), "docker-compose.yml"), []byte("services: ["), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	assertExecGotError(t, NewProxyLsCommand(ls), "failed to parse services")
+}
@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const proxyCompose = `services:
+  app:
+    image: nginx
+    labels:
+      kool.proxy.port: 80
+  database:
+    image: mysql:8.0
+`
+
+func newFakeKoolProxy(t *testing.T) *KoolProxy {
+	env := environment.NewFakeEnvStorage()
+	env.Set("HOME", t.TempDir())
+	env.Set("PWD", t.TempDir())
+	env.Set("KOOL_NAME", "main")
+	env.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+
+	if err := os.WriteFile(filepath.Join(env.Get("PWD"), "docker-compose.yml"), []byte(proxyCompose), os.ModePerm); err != nil {
+		t.Fatal("failed creating docker-compose.yml for test", err)
+	}
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &KoolProxy{
+		*newFakeKoolService(),
+		env,
+		files,
+		&shell.FakeTableWriter{},
+		&builder.FakeCommand{MockCmd: "running"},
+		&builder.FakeCommand{MockCmd: "run"},
+		&builder.FakeCommand{MockCmd: "remove"},
+		&builder.FakeCommand{MockCmd: "containers", MockExecOut: "abc123\n"},
+		&builder.FakeCommand{MockCmd: "inspect", MockExecOut: "/main_app_1\t\n"},
+		&builder.FakeCommand{MockCmd: "connect"},
+	}
+}
+
+func TestNewKoolProxy(t *testing.T) {
+	proxy := NewKoolProxy()
+
+	if _, ok := proxy.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolProxy instance")
+	}
+
+	if _, ok := proxy.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default KoolProxy instance")
+	}
+
+	if !strings.Contains(proxy.run.String(), ProxyContainer) {
+		t.Errorf("unexpected run command on default KoolProxy instance: %s", proxy.run.String())
+	}
+}
+
+func TestKoolProxyStart(t *testing.T) {
+	start := &KoolProxyStart{newFakeKoolProxy(t)}
+
+	if err := start.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := start.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledExec["connect"] {
+		t.Error("should have attached the app container to the global network")
+	}
+
+	if !fakeShell.CalledExec["run"] {
+		t.Error("should have started the proxy container")
+	}
+
+	runArgs := strings.Join(fakeShell.ArgsExec["run"], " ")
+
+	for _, mount := range []string{"certs:/etc/kool-proxy/certs:ro", "dynamic:/etc/kool-proxy/dynamic:ro"} {
+		if !strings.Contains(runArgs, filepath.Join(start.dir(), mount)) {
+			t.Errorf("expected %s to be mounted on the proxy container: %s", mount, runArgs)
+		}
+	}
+
+	if strings.Contains(runArgs, start.dir()+":") {
+		t.Errorf("should not mount the whole proxy folder, holding the CA key: %s", runArgs)
+	}
+
+	if lines := fakeShell.OutLines; len(lines) != 1 || lines[0] != "Serving https://app.main.localhost" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+
+	config, err := os.ReadFile(filepath.Join(start.dir(), "dynamic", "main.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Host(`app.main.localhost`)", "url: http://main_app_1:80", "certFile: /etc/kool-proxy/certs/main.pem"} {
+		if !strings.Contains(string(config), expected) {
+			t.Errorf("expected %s on the routes file:\n%s", expected, config)
+		}
+	}
+
+	for _, file := range []string{"ca.pem", "ca-key.pem", filepath.Join("certs", "main.pem"), filepath.Join("certs", "main-key.pem")} {
+		if _, err := os.Stat(filepath.Join(start.dir(), file)); err != nil {
+			t.Errorf("expected %s to be created: %v", file, err)
+		}
+	}
+}
+
+func TestKoolProxyStartAlreadyRunning(t *testing.T) {
+	start := &KoolProxyStart{newFakeKoolProxy(t)}
+	start.running.(*builder.FakeCommand).MockExecOut = "proxyid\n"
+	start.inspect.(*builder.FakeCommand).MockExecOut = "/main_app_1\tnetid\n"
+
+	if err := start.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := start.shell.(*shell.FakeShell)
+
+	if fakeShell.CalledExec["run"] || fakeShell.CalledExec["connect"] {
+		t.Error("should not start the proxy nor connect containers again")
+	}
+}
+
+func TestKoolProxyStartNothingToServe(t *testing.T) {
+	start := &KoolProxyStart{newFakeKoolProxy(t)}
+	start.containers.(*builder.FakeCommand).MockExecOut = ""
+
+	if err := start.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if start.shell.(*shell.FakeShell).CalledExec["run"] {
+		t.Error("should not start the proxy without containers to serve")
+	}
+
+	start = &KoolProxyStart{newFakeKoolProxy(t)}
+
+	if err := os.WriteFile(filepath.Join(start.env.Get("PWD"), "docker-compose.yml"), []byte("services:\n  app:\n    image: nginx\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := start.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if start.shell.(*shell.FakeShell).CalledExec["containers"] {
+		t.Error("should not look for containers without services to serve")
+	}
+}
+
+func TestKoolProxyStartErrors(t *testing.T) {
+	start := &KoolProxyStart{newFakeKoolProxy(t)}
+	start.containers.(*builder.FakeCommand).MockExecError = errors.New("ps error")
+
+	if err := start.Execute(nil); err == nil || err.Error() != "failed to route service app: ps error" {
+		t.Errorf("expected ps error, got %v", err)
+	}
+
+	start = &KoolProxyStart{newFakeKoolProxy(t)}
+	start.connect.(*builder.FakeCommand).MockExecError = errors.New("connect error")
+
+	if err := start.Execute(nil); err == nil || !strings.Contains(err.Error(), "connect error") {
+		t.Errorf("expected connect error, got %v", err)
+	}
+
+	start = &KoolProxyStart{newFakeKoolProxy(t)}
+	start.run.(*builder.FakeCommand).MockExecError = errors.New("port is already allocated")
+
+	if err := start.Execute(nil); err == nil || err.Error() != "failed to start the kool proxy: port is already allocated" {
+		t.Errorf("expected run error, got %v", err)
+	}
+}
+
+func TestKoolProxyStartPorts(t *testing.T) {
+	proxy := newFakeKoolProxy(t)
+	proxy.env.Set("KOOL_PROXY_HTTPS_PORT", "8443")
+
+	if port := proxy.proxyPort("KOOL_PROXY_HTTPS_PORT", "443"); port != "8443" {
+		t.Errorf("expected HTTPS port 8443, got %s", port)
+	}
+
+	if port := proxy.proxyPort("KOOL_PROXY_HTTP_PORT", "80"); port != "80" {
+		t.Errorf("expected HTTP port 80, got %s", port)
+	}
+}
+
+func TestKoolProxyStop(t *testing.T) {
+	proxy := newFakeKoolProxy(t)
+	proxy.running.(*builder.FakeCommand).MockExecOut = "proxyid\n"
+
+	if err := (&KoolProxyStart{proxy}).Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	other := filepath.Join(proxy.dir(), "dynamic", "other.yml")
+	if err := os.WriteFile(other, []byte("http: {}\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := &KoolProxyStop{proxy}
+
+	if err := stop.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(proxy.routesFile()); !os.IsNotExist(err) {
+		t.Error("should have removed the project routes")
+	}
+
+	if proxy.shell.(*shell.FakeShell).CalledExec["remove"] {
+		t.Error("should keep the proxy running while other projects use it")
+	}
+
+	if err := (&KoolProxyStart{proxy}).Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Remove(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stop.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proxy.shell.(*shell.FakeShell).CalledExec["remove"] {
+		t.Error("should have removed the proxy after the last project stopped")
+	}
+}
+
+func TestKoolProxyStopWithoutRoutes(t *testing.T) {
+	stop := &KoolProxyStop{newFakeKoolProxy(t)}
+
+	if err := stop.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if stop.shell.(*shell.FakeShell).CalledExec["running"] {
+		t.Error("should not touch the proxy when the project has no routes")
+	}
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/services/proxy"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolProxyTrust holds handlers and functions to implement the proxy trust command logic
+type KoolProxyTrust struct {
+	*KoolProxy
+
+	goos string
+}
+
+// Execute runs the proxy trust logic with incoming arguments.
+func (t *KoolProxyTrust) Execute(args []string) (err error) {
+	var (
+		ca       *proxy.CA
+		commands []builder.Command
+	)
+
+	if ca, err = proxy.LoadOrCreateCA(t.dir()); err != nil {
+		err = fmt.Errorf("failed to load the local certificate authority: %v", err)
+		return
+	}
+
+	if commands, err = t.trustCommands(ca.CertFile); err != nil {
+		return
+	}
+
+	for _, command := range commands {
+		if err = t.Interactive(command); err != nil {
+			return
+		}
+	}
+
+	t.Success("The kool local certificate authority is now trusted by the system")
+	return
+}
+
+// trustCommands returns the commands adding the CA certificate
+// to the trusted certificates of the operating system
+func (t *KoolProxyTrust) trustCommands(caFile string) (commands []builder.Command, err error) {
+	switch t.goos {
+	case "darwin":
+		commands = append(commands, builder.NewCommand("sudo", "security", "add-trusted-cert", "-d", "-r", "trustRoot", "-k", "/Library/Keychains/System.keychain", caFile))
+	case "linux":
+		if t.LookPath(builder.NewCommand("update-ca-certificates")) == nil {
+			commands = append(commands,
+				builder.NewCommand("sudo", "cp", caFile, "/usr/local/share/ca-certificates/kool-local-ca.crt"),
+				builder.NewCommand("sudo", "update-ca-certificates"),
+			)
+		} else {
+			commands = append(commands, builder.NewCommand("sudo", "trust", "anchor", "--store", caFile))
+		}
+	case "windows":
+		commands = append(commands, builder.NewCommand("certutil", "-addstore", "-f", "ROOT", caFile))
+	default:
+		err = fmt.Errorf("trusting certificates is not supported on %s; trust %s manually", t.goos, caFile)
+	}
+
+	return
+}
+
+// NewProxyTrustCommand initializes new kool proxy trust command
+func NewProxyTrustCommand(trust *KoolProxyTrust) *cobra.Command {
+	return &cobra.Command{
+		Use:   "trust",
+		Short: "Trust the kool local certificate authority",
+		Long: `Add the local certificate authority kool creates for signing the projects
+certificates to the trusted certificates of the operating system (requires
+admin privileges). Some browsers, like Firefox, keep their own trusted
+certificates, so it must be imported on them as well.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(trust),
+
+		DisableFlagsInUseLine: true,
+	}
+}
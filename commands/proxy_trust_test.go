@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/shell"
+	"path/filepath"
+	"testing"
+)
+
+func TestProxyTrustCommand(t *testing.T) {
+	trust := &KoolProxyTrust{newFakeKoolProxy(t), "darwin"}
+
+	if err := NewProxyTrustCommand(trust).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := trust.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledInteractive["sudo"] || !fakeShell.CalledSuccess {
+		t.Error("should have trusted the CA with sudo")
+	}
+
+	if args := fakeShell.ArgsInteractive["sudo"]; len(args) != 0 {
+		t.Errorf("unexpected extra args: %v", args)
+	}
+}
+
+func TestProxyTrustCommands(t *testing.T) {
+	trust := &KoolProxyTrust{newFakeKoolProxy(t), "linux"}
+	caFile := filepath.Join(trust.dir(), "ca.pem")
+
+	commands, err := trust.trustCommands(caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commands) != 2 || commands[1].String() != "sudo update-ca-certificates" {
+		t.Errorf("unexpected linux trust commands: %v", commands)
+	}
+
+	trust.shell.(*shell.FakeShell).MockLookPath = errors.New("not found")
+
+	if commands, _ = trust.trustCommands(caFile); len(commands) != 1 || commands[0].String() != "sudo trust anchor --store "+caFile {
+		t.Errorf("unexpected linux trust commands without update-ca-certificates: %v", commands)
+	}
+
+	trust.goos = "windows"
+
+	if commands, _ = trust.trustCommands(caFile); len(commands) != 1 || commands[0].Cmd() != "certutil" {
+		t.Errorf("unexpected windows trust commands: %v", commands)
+	}
+
+	trust.goos = "plan9"
+
+	if _, err = trust.trustCommands(caFile); err == nil {
+		t.Error("expected error trusting the CA on unsupported systems")
+	}
+}
+
+func TestProxyTrustCommandError(t *testing.T) {
+	trust := &KoolProxyTrust{newFakeKoolProxy(t), "plan9"}
+
+	assertExecGotError(t, NewProxyTrustCommand(trust), "trusting certificates is not supported on plan9")
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolPruneFlags holds the flags for the kool prune command
+type KoolPruneFlags struct {
+	Missing    bool
+	Volumes    bool
+	Images     bool
+	BuildCache bool
+}
+
+// KoolPrune holds handlers and functions to implement the prune command logic
+type KoolPrune struct {
+	*KoolDisk
+	Flags *KoolPruneFlags
+
+	removeContainers builder.Command
+	removeImages     builder.Command
+	removeVolumes    builder.Command
+	removeNetworks   builder.Command
+	pruneBuildCache  builder.Command
+}
+
+// NewKoolPrune creates a new handler for pruning projects resources with default dependencies
+func NewKoolPrune() *KoolPrune {
+	return &KoolPrune{
+		NewKoolDisk(),
+		&KoolPruneFlags{},
+		builder.NewCommand("docker", "rm"),
+		builder.NewCommand("docker", "image", "rm"),
+		builder.NewCommand("docker", "volume", "rm"),
+		builder.NewCommand("docker", "network", "rm"),
+		builder.NewCommand("docker", "builder", "prune", "-f"),
+	}
+}
+
+func AddKoolPrune(root *cobra.Command) {
+	root.AddCommand(NewPruneCommand(NewKoolPrune()))
+}
+
+// Execute runs the prune logic with incoming arguments.
+func (p *KoolPrune) Execute(args []string) (err error) {
+	var (
+		projects []*diskProject
+		prune    []*diskProject
+		byName   = make(map[string]*diskProject)
+	)
+
+	if len(args) == 0 && !p.Flags.Missing && !p.Flags.BuildCache {
+		err = errors.New("either give the projects to prune, --missing or --build-cache")
+		return
+	}
+
+	if len(args) > 0 || p.Flags.Missing {
+		if projects, err = p.projects(); err != nil {
+			return
+		}
+	}
+
+	for _, project := range projects {
+		byName[project.name] = project
+
+		if p.Flags.Missing && project.dirMissing {
+			prune = append(prune, project)
+		}
+	}
+
+	for _, name := range args {
+		project, exists := byName[name]
+
+		if !exists {
+			err = fmt.Errorf("could not find the project %s", name)
+			return
+		}
+
+		if !project.dirMissing || !p.Flags.Missing {
+			prune = append(prune, project)
+		}
+	}
+
+	if len(prune) == 0 && !p.Flags.BuildCache {
+		p.Println("No projects to prune")
+		return
+	}
+
+	for _, project := range prune {
+		if err = p.prune(project); err != nil {
+			return
+		}
+	}
+
+	if p.Flags.BuildCache {
+		if err = p.Interactive(p.pruneBuildCache); err != nil {
+			return
+		}
+
+		p.Success("Pruned the build cache")
+	}
+
+	return
+}
+
+// prune removes the stopped containers and the networks of the project,
+// and its volumes and images if asked to. The project resources still in
+// use by its running containers are kept.
+func (p *KoolPrune) prune(project *diskProject) (err error) {
+	var freed = sumSizes(project.containers)
+
+	if len(project.containers) > 0 {
+		if _, err = p.Exec(p.removeContainers, resourcesNames(project.containers)...); err != nil {
+			return
+		}
+	}
+
+	if project.running > 0 {
+		if p.Flags.Volumes || p.Flags.Images || len(project.networks) > 0 {
+			p.Warning("Project ", project.name, " has running containers; keeping its networks, volumes and images")
+		}
+	} else {
+		if len(project.networks) > 0 {
+			if _, err = p.Exec(p.removeNetworks, project.networks...); err != nil {
+				return
+			}
+		}
+
+		if p.Flags.Volumes && len(project.volumes) > 0 {
+			if _, err = p.Exec(p.removeVolumes, resourcesNames(project.volumes)...); err != nil {
+				return
+			}
+
+			freed += sumSizes(project.volumes)
+		}
+
+		if p.Flags.Images && len(project.images) > 0 {
+			if _, err = p.Exec(p.removeImages, resourcesNames(project.images)...); err != nil {
+				return
+			}
+
+			freed += sumSizes(project.images)
+		}
+	}
+
+	p.Success("Pruned project ", project.name, " (", dockerSize(freed), " freed)")
+	return
+}
+
+// NewPruneCommand initializes new kool prune command
+func NewPruneCommand(prune *KoolPrune) (pruneCmd *cobra.Command) {
+	pruneCmd = &cobra.Command{
+		Use:   "prune [PROJECT...]",
+		Short: "Remove the unused docker resources of the given projects",
+		Long: `Remove the stopped containers and the networks of the given docker-compose
+PROJECT (as listed by 'kool doctor disk'), or of every project whose folder no
+longer exists with --missing. Volumes and images are only removed with --volumes
+and --images, and resources still used by running containers are kept. Unlike
+'docker system prune', other projects are left untouched; the build cache, which
+is shared by all projects, is only pruned with --build-cache.`,
+		RunE: DefaultCommandRunFunction(prune),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	pruneCmd.Flags().BoolVarP(&prune.Flags.Missing, "missing", "m", false, "Prune the projects whose folders no longer exist.")
+	pruneCmd.Flags().BoolVarP(&prune.Flags.Volumes, "volumes", "", false, "Remove the projects volumes as well.")
+	pruneCmd.Flags().BoolVarP(&prune.Flags.Images, "images", "", false, "Remove the images built for the projects as well.")
+	pruneCmd.Flags().BoolVarP(&prune.Flags.BuildCache, "build-cache", "", false, "Prune the docker build cache.")
+	return
+}
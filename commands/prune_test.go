@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolPrune(t *testing.T) *KoolPrune {
+	return &KoolPrune{
+		newFakeKoolDisk(t),
+		&KoolPruneFlags{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{},
+		&builder.FakeCommand{MockCmd: "docker"},
+	}
+}
+
+func TestNewKoolPrune(t *testing.T) {
+	prune := NewKoolPrune()
+
+	if prune.KoolDisk == nil || prune.Flags == nil {
+		t.Error("missing dependencies on default KoolPrune instance")
+	}
+
+	if strings.Join(prune.pruneBuildCache.Args(), " ") != "builder prune -f" {
+		t.Errorf("unexpected build cache prune command: %v", prune.pruneBuildCache.Args())
+	}
+}
+
+func TestPruneCommandFlags(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+	cmd := NewPruneCommand(prune)
+
+	if err := cmd.ParseFlags([]string{"--missing", "--volumes", "--images", "--build-cache"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !prune.Flags.Missing || !prune.Flags.Volumes || !prune.Flags.Images || !prune.Flags.BuildCache {
+		t.Errorf("failed binding flags: %v", prune.Flags)
+	}
+}
+
+func TestPruneCommandMissing(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"--missing", "old"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error pruning: %v", err)
+	}
+
+	if args := prune.removeContainers.(*builder.FakeCommand).ArgsAppend; len(args) != 0 {
+		t.Errorf("unexpected args appended: %v", args)
+	}
+
+	if !prune.shell.(*shell.FakeShell).CalledExec[""] {
+		t.Error("did not remove the stopped containers")
+	}
+
+	if output := fmt.Sprint(prune.shell.(*shell.FakeShell).SuccessOutput...); output != "Pruned project old (2MB freed)" {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	if prune.shell.(*shell.FakeShell).CalledInteractive["docker"] {
+		t.Error("should not prune the build cache without --build-cache")
+	}
+}
+
+func TestPruneCommandVolumesAndImages(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"old", "--volumes", "--images"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error pruning: %v", err)
+	}
+
+	if output := fmt.Sprint(prune.shell.(*shell.FakeShell).SuccessOutput...); output != "Pruned project old (2.2GB freed)" {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestPruneCommandRunningProject(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"my-app", "--volumes"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error pruning: %v", err)
+	}
+
+	fakeShell := prune.shell.(*shell.FakeShell)
+
+	if !fakeShell.CalledWarning || !strings.Contains(fmt.Sprint(fakeShell.WarningOutput...), "has running containers") {
+		t.Errorf("expected running containers warning, got %v", fakeShell.WarningOutput)
+	}
+
+	if output := fmt.Sprint(fakeShell.SuccessOutput...); output != "Pruned project my-app (1.5kB freed)" {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestPruneCommandBuildCache(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"--build-cache"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error pruning: %v", err)
+	}
+
+	if !prune.shell.(*shell.FakeShell).CalledInteractive["docker"] {
+		t.Error("did not prune the build cache")
+	}
+
+	if prune.listContainers.(*builder.FakeCommand).CalledCmd {
+		t.Error("should not list the projects when only pruning the build cache")
+	}
+}
+
+func TestPruneCommandErrors(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+	assertExecGotError(t, NewPruneCommand(prune), "either give the projects to prune, --missing or --build-cache")
+
+	prune = newFakeKoolPrune(t)
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"unknown"})
+	assertExecGotError(t, cmd, "could not find the project unknown")
+
+	prune = newFakeKoolPrune(t)
+	prune.removeVolumes.(*builder.FakeCommand).MockExecError = errors.New("volume in use")
+	cmd = NewPruneCommand(prune)
+	cmd.SetArgs([]string{"old", "--volumes"})
+	assertExecGotError(t, cmd, "volume in use")
+
+	prune = newFakeKoolPrune(t)
+	prune.listContainers.(*builder.FakeCommand).MockExecError = errors.New("list error")
+	cmd = NewPruneCommand(prune)
+	cmd.SetArgs([]string{"--missing"})
+	assertExecGotError(t, cmd, "list error")
+}
+
+func TestPruneCommandNothingToPrune(t *testing.T) {
+	prune := newFakeKoolPrune(t)
+	prune.listContainers.(*builder.FakeCommand).MockExecOut = ""
+
+	cmd := NewPruneCommand(prune)
+	cmd.SetArgs([]string{"--missing"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error pruning: %v", err)
+	}
+
+	if lines := prune.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || lines[0] != "No projects to prune" {
+		t.Errorf("unexpected output: %v", lines)
+	}
+}
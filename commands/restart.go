@@ -8,21 +8,30 @@ import (
 type KoolRestartFlags struct {
 	Purge   bool
 	Rebuild bool
+	KoolServiceGroupsFlags
+	KoolRebuildFlags
 }
 
 // NewRestartCommand initializes new kool start command
 func NewRestartCommand(stop KoolService, start KoolService) (restartCmd *cobra.Command) {
-	var flags *KoolRestartFlags = &KoolRestartFlags{false, false}
+	var flags *KoolRestartFlags = &KoolRestartFlags{false, false, KoolServiceGroupsFlags{}, KoolRebuildFlags{}}
 
 	restartCmd = &cobra.Command{
 		Use:   "restart",
 		Short: "Restart running service containers (the same as 'kool stop' followed by 'kool start')",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if _, ok := stop.(*KoolStop); ok && flags.Purge {
-				stop.(*KoolStop).Flags.Purge = true
+			if _, ok := stop.(*KoolStop); ok {
+				if flags.Purge {
+					stop.(*KoolStop).Flags.Purge = true
+				}
+				stop.(*KoolStop).Flags.KoolServiceGroupsFlags = flags.KoolServiceGroupsFlags
 			}
-			if _, ok := start.(*KoolStart); ok && flags.Rebuild {
-				start.(*KoolStart).Flags.Rebuild = true
+			if _, ok := start.(*KoolStart); ok {
+				if flags.Rebuild {
+					start.(*KoolStart).Flags.Rebuild = true
+				}
+				start.(*KoolStart).Flags.KoolServiceGroupsFlags = flags.KoolServiceGroupsFlags
+				start.(*KoolStart).Flags.KoolRebuildFlags = flags.KoolRebuildFlags
 			}
 
 			return DefaultCommandRunFunction(stop, start)(cmd, args)
@@ -33,6 +42,8 @@ func NewRestartCommand(stop KoolService, start KoolService) (restartCmd *cobra.C
 
 	restartCmd.Flags().BoolVarP(&flags.Purge, "purge", "", false, "Remove all persistent data from volume mounts on containers")
 	restartCmd.Flags().BoolVarP(&flags.Rebuild, "rebuild", "", false, "Updates and builds service's images")
+	addServiceGroupsFlags(restartCmd, &flags.KoolServiceGroupsFlags)
+	addRebuildFlags(restartCmd, &flags.KoolRebuildFlags)
 
 	return
 }
@@ -70,7 +70,7 @@ func TestRebuildRestartCommand(t *testing.T) {
 	fakeStart := newFakeKoolStart()
 
 	cmd := NewRestartCommand(fakeStop, fakeStart)
-	cmd.SetArgs([]string{"--rebuild"})
+	cmd.SetArgs([]string{"--rebuild", "--service", "app", "--no-pull"})
 
 	fakeStart.rebuilder.(*KoolRebuild).shell.(*shell.FakeShell).MockOutStream = io.Discard
 
@@ -81,4 +81,12 @@ func TestRebuildRestartCommand(t *testing.T) {
 	if !fakeStart.Flags.Rebuild {
 		t.Error("did not set the rebuild flag to true in the start service")
 	}
+
+	if !fakeStart.Flags.NoPull || len(fakeStart.Flags.Services) != 1 || fakeStart.Flags.Services[0] != "app" {
+		t.Error("did not pass the rebuild flags to the start service")
+	}
+
+	if fakeStart.rebuilder.(*KoolRebuild).Flags != &fakeStart.Flags.KoolRebuildFlags {
+		t.Error("start and rebuild services should share the rebuild flags")
+	}
 }
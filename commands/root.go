@@ -17,22 +17,32 @@ type AddCommandsFN func(*cobra.Command)
 var hasWarnedDevelopmentVersion = false
 
 var AddCommands AddCommandsFN = func(root *cobra.Command) {
+	AddKoolAdd(root)
 	AddKoolCompletion(root)
+	AddKoolConfig(root)
 	AddKoolCreate(root)
+	AddKoolDB(root)
 	AddKoolDeploy(root)
 	AddKoolDocker(root)
+	AddKoolDoctor(root)
+	AddKoolEnv(root)
 	AddKoolExec(root)
 	AddKoolInfo(root)
 	AddKoolInit(root)
 	AddKoolLogs(root)
+	AddKoolNetwork(root)
 	AddKoolPreset(root)
+	AddKoolProxy(root)
+	AddKoolPrune(root)
 	AddKoolRestart(root)
 	AddKoolRun(root)
 	AddKoolSelfUpdate(root)
 	AddKoolShare(root)
+	AddKoolShell(root)
 	AddKoolStart(root)
 	AddKoolStatus(root)
 	AddKoolStop(root)
+	AddKoolVolume(root)
 }
 
 // DEV_VERSION holds the static version shown for development time builds
@@ -242,22 +242,32 @@ func TestAddCommands(t *testing.T) {
 	AddCommands(root)
 
 	var subcommands map[string]bool = map[string]bool{
+		"add":         false,
 		"completion":  false,
+		"config":      false,
 		"create":      false,
+		"db":          false,
 		"deploy":      false,
 		"docker":      false,
+		"doctor":      false,
+		"env":         false,
 		"exec":        false,
 		"info":        false,
 		"init":        false,
 		"logs":        false,
+		"network":     false,
 		"preset":      false,
+		"proxy":       false,
+		"prune":       false,
 		"restart":     false,
 		"run":         false,
 		"self-update": false,
 		"share":       false,
+		"shell":       false,
 		"start":       false,
 		"status":      false,
 		"stop":        false,
+		"volume":      false,
 	}
 
 	for _, subCmd := range root.Commands() {
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/services/compose"
+	"path"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolServiceGroupsFlags holds the flags for targeting services by
+// kool.yml groups or docker-compose profiles
+type KoolServiceGroupsFlags struct {
+	Groups   []string
+	Profiles []string
+}
+
+// serviceGroups holds logic for translating kool.yml groups and
+// docker-compose profiles onto the services a command targets
+type serviceGroups struct {
+	parser parser.Parser
+	env    environment.EnvStorage
+	files  compose.FilesAware
+}
+
+func newServiceGroups() *serviceGroups {
+	return &serviceGroups{
+		parser.NewParser(),
+		environment.NewEnvStorage(),
+		compose.NewDockerCompose("config"),
+	}
+}
+
+func addServiceGroupsFlags(cmd *cobra.Command, flags *KoolServiceGroupsFlags) {
+	cmd.Flags().StringArrayVarP(&flags.Groups, "group", "g", []string{}, "Target the services of a group defined in kool.yml or a docker-compose profile (can be used multiple times).")
+	cmd.Flags().StringArrayVarP(&flags.Profiles, "profile", "", []string{}, "Enable a docker-compose profile (can be used multiple times).")
+}
+
+// Resolve returns the given services list added by the services from
+// the groups in flags, and enables the flags profiles on the commands.
+// Groups not defined in kool.yml are looked up as docker-compose profiles.
+func (g *serviceGroups) Resolve(flags *KoolServiceGroupsFlags, services []string, commands ...builder.Command) (resolved []string, err error) {
+	var (
+		groupServices []string
+		isProfile     bool
+		added         = make(map[string]bool)
+		profiles      = append([]string{}, flags.Profiles...)
+	)
+
+	for _, service := range services {
+		if !added[service] {
+			resolved = append(resolved, service)
+			added[service] = true
+		}
+	}
+
+	if len(flags.Groups) > 0 {
+		// look for kool.yml on current working directory
+		_ = g.parser.AddLookupPath(g.env.Get("PWD"))
+		// look for kool.yml on kool folder within user home directory
+		_ = g.parser.AddLookupPath(path.Join(g.env.Get("HOME"), "kool"))
+	}
+
+	for _, group := range flags.Groups {
+		if groupServices, isProfile, err = g.groupServices(group); err != nil {
+			err = fmt.Errorf("failed to resolve group %s: %v", group, err)
+			return
+		}
+
+		if isProfile {
+			profiles = append(profiles, group)
+		}
+
+		for _, service := range groupServices {
+			if !added[service] {
+				resolved = append(resolved, service)
+				added[service] = true
+			}
+		}
+	}
+
+	if len(profiles) > 0 {
+		for _, command := range commands {
+			if aware, ok := command.(compose.ProfileAware); ok {
+				aware.SetProfiles(profiles)
+			}
+		}
+	}
+
+	return
+}
+
+// groupServices returns the services of the given kool.yml group, falling
+// back to the services of a docker-compose profile with the same name
+func (g *serviceGroups) groupServices(group string) (services []string, isProfile bool, err error) {
+	var profileErr error
+
+	if services, err = g.parser.ParseGroup(group); err == nil {
+		return
+	}
+
+	if !errors.Is(err, parser.ErrGroupNotFound) && !errors.Is(err, parser.ErrKoolYmlNotFound) {
+		return
+	}
+
+	if services, profileErr = g.ProfileServices([]string{group}); profileErr != nil {
+		err = profileErr
+		return
+	}
+
+	if len(services) > 0 {
+		isProfile = true
+		err = nil
+	}
+
+	return
+}
+
+// ProfileServices returns the sorted services that belong to any of the
+// given profiles, as declared on the project docker-compose files
+func (g *serviceGroups) ProfileServices(profiles []string) (services []string, err error) {
+	var (
+		files           []composeFile
+		fileProfiles    map[string][]string
+		serviceProfiles = make(map[string][]string)
+	)
+
+	if files, err = readComposeFiles(g.files, g.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if fileProfiles, err = compose.ParseProfiles(file.content); err != nil {
+			err = fmt.Errorf("failed to parse %s: %v", file.path, err)
+			return
+		}
+
+		// later files override the profiles of the services they declare
+		for service, list := range fileProfiles {
+			serviceProfiles[service] = list
+		}
+	}
+
+	for service, list := range serviceProfiles {
+		if hasAnyProfile(list, profiles) {
+			services = append(services, service)
+		}
+	}
+
+	sort.Strings(services)
+	return
+}
+
+func hasAnyProfile(list []string, profiles []string) bool {
+	for _, item := range list {
+		for _, profile := range profiles {
+			if item == profile {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const serviceGroupsCompose = `services:
+  app:
+    image: kooldev/php:8.0
+  mailhog:
+    image: mailhog/mailhog
+    profiles: [tools]
+  adminer:
+    image: adminer
+    profiles: [tools, debug]
+`
+
+func newFakeServiceGroups() *serviceGroups {
+	env := environment.NewFakeEnvStorage()
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &serviceGroups{
+		&parser.FakeParser{},
+		env,
+		files,
+	}
+}
+
+func writeServiceGroupsCompose(t *testing.T, g *serviceGroups, files map[string]string) {
+	g.env.Set("PWD", t.TempDir())
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(g.env.Get("PWD"), name), []byte(content), os.ModePerm); err != nil {
+			t.Fatalf("failed creating %s for test: %v", name, err)
+		}
+	}
+}
+
+func TestNewServiceGroups(t *testing.T) {
+	g := newServiceGroups()
+
+	if _, ok := g.parser.(*parser.DefaultParser); !ok {
+		t.Error("unexpected parser.Parser on default serviceGroups instance")
+	}
+
+	if _, ok := g.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default serviceGroups instance")
+	}
+
+	if _, ok := g.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default serviceGroups instance")
+	}
+}
+
+func TestResolveServiceGroups(t *testing.T) {
+	g := newFakeServiceGroups()
+	g.parser.(*parser.FakeParser).MockGroups = map[string][]string{
+		"backend":  {"app", "database"},
+		"frontend": {"node"},
+	}
+
+	services, err := g.Resolve(&KoolServiceGroupsFlags{}, []string{"app"})
+
+	if err != nil {
+		t.Errorf("unexpected error resolving services: %v", err)
+	}
+
+	if len(services) != 1 || services[0] != "app" {
+		t.Errorf("unexpected services without groups: %v", services)
+	}
+
+	if g.parser.(*parser.FakeParser).CalledAddLookupPath {
+		t.Error("should not look for kool.yml without groups")
+	}
+
+	services, err = g.Resolve(&KoolServiceGroupsFlags{Groups: []string{"backend", "frontend"}}, []string{"app", "cache"})
+
+	if err != nil {
+		t.Errorf("unexpected error resolving services: %v", err)
+	}
+
+	expected := []string{"app", "cache", "database", "node"}
+	if strings.Join(services, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected services %v, got %v", expected, services)
+	}
+}
+
+func TestResolveServiceGroupsError(t *testing.T) {
+	g := newFakeServiceGroups()
+	g.parser.(*parser.FakeParser).MockParseGroupError = map[string]error{
+		"invalid": parser.ErrGroupNotFound,
+	}
+
+	_, err := g.Resolve(&KoolServiceGroupsFlags{Groups: []string{"invalid"}}, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve group invalid") {
+		t.Errorf("expected group resolving error, got %v", err)
+	}
+}
+
+func TestResolveServiceGroupsFromComposeProfiles(t *testing.T) {
+	g := newFakeServiceGroups()
+	g.parser.(*parser.FakeParser).MockParseGroupError = map[string]error{
+		"tools": parser.ErrGroupNotFound,
+		"debug": parser.ErrKoolYmlNotFound,
+	}
+	writeServiceGroupsCompose(t, g, map[string]string{"docker-compose.yml": serviceGroupsCompose})
+
+	dc := compose.NewDockerCompose("up")
+	dc.SetShell(&shell.FakeShell{})
+	dc.SetLocalDockerCompose(&builder.FakeCommand{})
+
+	services, err := g.Resolve(&KoolServiceGroupsFlags{Groups: []string{"tools", "debug"}}, []string{"app"}, dc)
+
+	if err != nil {
+		t.Fatalf("unexpected error resolving services: %v", err)
+	}
+
+	expected := []string{"app", "adminer", "mailhog"}
+	if strings.Join(services, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected services %v, got %v", expected, services)
+	}
+
+	if !strings.Contains(dc.String(), "--profile tools --profile debug up") {
+		t.Errorf("group profiles were not set on docker-compose command: %s", dc.String())
+	}
+
+	g.parser.(*parser.FakeParser).MockParseGroupError["other"] = errors.New("parse error")
+
+	if _, err = g.Resolve(&KoolServiceGroupsFlags{Groups: []string{"other"}}, nil); err == nil || !strings.Contains(err.Error(), "parse error") {
+		t.Errorf("expected kool.yml parsing error, got %v", err)
+	}
+}
+
+func TestServiceGroupsProfileServices(t *testing.T) {
+	g := newFakeServiceGroups()
+	writeServiceGroupsCompose(t, g, map[string]string{
+		"docker-compose.yml": serviceGroupsCompose,
+		"docker-compose.override.yml": `services:
+  mailhog:
+    profiles: [mail]
+`,
+	})
+
+	services, err := g.ProfileServices([]string{"tools"})
+
+	if err != nil {
+		t.Fatalf("unexpected error reading profiles services: %v", err)
+	}
+
+	if strings.Join(services, ",") != "adminer" {
+		t.Errorf("expected the override file profiles to prevail, got %v", services)
+	}
+
+	if services, err = g.ProfileServices([]string{"mail", "debug"}); err != nil || strings.Join(services, ",") != "adminer,mailhog" {
+		t.Errorf("unexpected profiles services: %v (%v)", services, err)
+	}
+
+	writeServiceGroupsCompose(t, g, map[string]string{"docker-compose.yml": "services: [invalid"})
+
+	if _, err = g.ProfileServices([]string{"tools"}); err == nil || !strings.Contains(err.Error(), "failed to parse") {
+		t.Errorf("expected parsing error, got %v", err)
+	}
+}
+
+func TestResolveServiceGroupsProfiles(t *testing.T) {
+	g := newFakeServiceGroups()
+
+	dc := compose.NewDockerCompose("up")
+	dc.SetShell(&shell.FakeShell{})
+	dc.SetLocalDockerCompose(&builder.FakeCommand{})
+
+	if _, err := g.Resolve(&KoolServiceGroupsFlags{Profiles: []string{"tools"}}, nil, dc, &builder.FakeCommand{}); err != nil {
+		t.Errorf("unexpected error resolving services: %v", err)
+	}
+
+	if !strings.Contains(dc.String(), "--profile tools up") {
+		t.Errorf("profile was not set on docker-compose command: %s", dc.String())
+	}
+}
+
+func TestStartGroupFlag(t *testing.T) {
+	koolStart := newFakeKoolStart()
+	koolStart.groups.parser.(*parser.FakeParser).MockGroups = map[string][]string{
+		"backend": {"app", "database"},
+	}
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"--group", "backend"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	startedServices := koolStart.shell.(*shell.FakeShell).ArgsInteractive["start"]
+
+	if !startedServicesAreEqual(startedServices, []string{"app", "database"}) {
+		t.Errorf("expected to start backend group services, got '%v'", startedServices)
+	}
+}
+
+func TestStopGroupFlag(t *testing.T) {
+	koolStop := newFakeKoolStop()
+	koolStop.groups.parser.(*parser.FakeParser).MockGroups = map[string][]string{
+		"backend": {"app", "database"},
+	}
+	koolStop.Flags.Groups = []string{"backend"}
+
+	if err := koolStop.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	args := koolStop.rm.(*builder.FakeCommand).ArgsAppend
+	if strings.Join(args, " ") != "-s -f app database" {
+		t.Errorf("expected to stop backend group services, got '%v'", args)
+	}
+
+	koolStop = newFakeKoolStop()
+	koolStop.groups.parser.(*parser.FakeParser).MockParseGroupError = map[string]error{
+		"backend": errors.New("group error"),
+	}
+	koolStop.Flags.Groups = []string{"backend"}
+
+	if err := koolStop.Execute(nil); err == nil || !strings.Contains(err.Error(), "group error") {
+		t.Errorf("expected group error, got %v", err)
+	}
+}
+
+func TestStopProfileFlag(t *testing.T) {
+	koolStop := newFakeKoolStop()
+	writeServiceGroupsCompose(t, koolStop.groups, map[string]string{"docker-compose.yml": serviceGroupsCompose})
+	koolStop.Flags.Profiles = []string{"tools"}
+
+	if err := koolStop.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStop.down.(*builder.FakeCommand).ArgsAppend != nil {
+		t.Errorf("should not take the whole project down, got '%v'", koolStop.down.(*builder.FakeCommand).ArgsAppend)
+	}
+
+	args := koolStop.rm.(*builder.FakeCommand).ArgsAppend
+	if strings.Join(args, " ") != "-s -f adminer mailhog" {
+		t.Errorf("expected to stop tools profile services, got '%v'", args)
+	}
+
+	if koolStop.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should not stop the proxy when stopping only some services")
+	}
+
+	koolStop = newFakeKoolStop()
+	writeServiceGroupsCompose(t, koolStop.groups, map[string]string{"docker-compose.yml": serviceGroupsCompose})
+	koolStop.Flags.Profiles = []string{"unknown"}
+
+	if err := koolStop.Execute(nil); err == nil || !strings.Contains(err.Error(), "no services found for profiles unknown") {
+		t.Errorf("expected no services error, got %v", err)
+	}
+}
+
+func TestLogsGroupFlag(t *testing.T) {
+	koolLogs := newFakeKoolLogs()
+	koolLogs.groups.parser.(*parser.FakeParser).MockGroups = map[string][]string{
+		"frontend": {"node"},
+	}
+
+	cmd := NewLogsCommand(koolLogs)
+	cmd.SetArgs([]string{"-g", "frontend"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	args := koolLogs.shell.(*shell.FakeShell).ArgsInteractive["logs"]
+	if len(args) != 1 || args[0] != "node" {
+		t.Errorf("expected to show logs for frontend group services, got '%v'", args)
+	}
+}
+
+func TestRestartGroupFlags(t *testing.T) {
+	fakeStop := newFakeKoolStop()
+	fakeStart := newFakeKoolStart()
+	writeServiceGroupsCompose(t, fakeStop.groups, map[string]string{"docker-compose.yml": serviceGroupsCompose})
+
+	cmd := NewRestartCommand(fakeStop, fakeStart)
+	cmd.SetArgs([]string{"--group", "backend", "--profile", "tools"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing restart command; error: %v", err)
+	}
+
+	if len(fakeStop.Flags.Groups) != 1 || len(fakeStart.Flags.Groups) != 1 {
+		t.Error("did not set the group flag in the stop and start services")
+	}
+
+	if len(fakeStop.Flags.Profiles) != 1 || len(fakeStart.Flags.Profiles) != 1 {
+		t.Error("did not set the profile flag in the stop and start services")
+	}
+}
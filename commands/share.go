@@ -1,10 +1,15 @@
 package commands
 
 import (
+	"crypto/rand"
 	"fmt"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/qrcode"
+	"kool-dev/kool/services/compose"
+	"kool-dev/kool/services/tunnel"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -12,17 +17,27 @@ import (
 
 // KoolShareFlags holds the flags for the kool share command
 type KoolShareFlags struct {
-	Service   string
-	Subdomain string
-	Port      uint
+	Services   []string
+	Subdomains []string
+	Port       uint
+	BasicAuth  string
+	AllowIPs   []string
+	QRCode     bool
 }
 
-func (f *KoolShareFlags) parseServiceURI() string {
-	if f.Port != 0 {
-		return fmt.Sprintf("%s:%d", f.Service, f.Port)
+// shareTarget holds a service to be shared and its settings
+type shareTarget struct {
+	service   string
+	port      uint
+	subdomain string
+}
+
+func (t *shareTarget) uri() string {
+	if t.port != 0 {
+		return fmt.Sprintf("%s:%d", t.service, t.port)
 	}
 
-	return f.Service
+	return t.service
 }
 
 // KoolShare holds handlers and functions to implement the share command logic
@@ -34,6 +49,8 @@ type KoolShare struct {
 
 	status *KoolStatus
 	share  builder.Command
+	guard  builder.Command
+	remove builder.Command
 }
 
 func AddKoolShare(root *cobra.Command) {
@@ -50,10 +67,12 @@ func NewKoolShare() *KoolShare {
 	defaultKoolService := newDefaultKoolService()
 	return &KoolShare{
 		*defaultKoolService,
-		&KoolShareFlags{"app", "", 0},
+		&KoolShareFlags{[]string{"app"}, []string{}, 0, "", []string{}, false},
 		environment.NewEnvStorage(),
 		NewKoolStatus(),
 		builder.NewCommand("docker", "run", "--rm", "--init"),
+		builder.NewCommand("docker", "run", "-d", "--rm"),
+		builder.NewCommand("docker", "rm", "-f"),
 	}
 }
 
@@ -63,33 +82,228 @@ func (s *KoolShare) validSubdomain(subdomain string) bool {
 
 // Execute runs the share logic.
 func (s *KoolShare) Execute(args []string) (err error) {
-	var isRunning bool
+	var (
+		provider tunnel.Provider
+		targets  []*shareTarget
+		tunnels  []builder.Command
+		names    []string
+		guards   []string
+		htpasswd string
+	)
+
+	if provider, err = tunnel.NewProvider(s.env.Get("KOOL_SHARE_PROVIDER"), s.env.Get); err != nil {
+		return
+	}
+
+	if targets, err = s.targets(); err != nil {
+		return
+	}
+
+	if s.Flags.BasicAuth != "" {
+		if htpasswd, err = tunnel.Htpasswd(s.Flags.BasicAuth); err != nil {
+			return
+		}
+	}
+
+	for _, target := range targets {
+		var isRunning bool
+
+		if isRunning, _, _, err = s.status.getServiceInfo(target.service); err != nil {
+			return
+		}
+
+		if !isRunning {
+			err = fmt.Errorf("service %s is not running, please check kool status and use --service flag to set which service to share", target.service)
+			return
+		}
+	}
+
+	defer func() {
+		if len(guards) > 0 {
+			_, _ = s.Exec(s.remove, guards...)
+		}
+	}()
+
+	for _, target := range targets {
+		var (
+			name    = fmt.Sprintf("%s_share_%s", compose.ProjectName(s.env.Get("KOOL_NAME")), target.service)
+			uri     = target.uri()
+			network = s.env.Get("KOOL_GLOBAL_NETWORK")
+		)
+
+		if len(s.Flags.AllowIPs) > 0 || htpasswd != "" {
+			if err = s.startGuard(name+"_guard", uri, htpasswd); err != nil {
+				return
+			}
+
+			// the tunnel client reaches the guard over its loopback
+			uri, network = tunnel.GuardURI, tunnel.GuardNetwork(name+"_guard")
+			guards = append(guards, name+"_guard")
+		}
+
+		share := s.share.Copy()
+		share.AppendArgs("--name", name, "--network", network)
+		share.AppendArgs(provider.Args(tunnel.Share{URI: uri, Subdomain: target.subdomain})...)
 
-	if isRunning, _, _, err = s.status.getServiceInfo(s.Flags.Service); err != nil {
+		tunnels = append(tunnels, share)
+		names = append(names, name)
+
+		if target.subdomain != "" {
+			s.printURL(target, fmt.Sprintf("https://%s.%s", target.subdomain, provider.Host()))
+		}
+	}
+
+	err = s.runTunnels(tunnels, names)
+	return
+}
+
+// targets reads the services to be shared from the flags, each one
+// as SERVICE[:PORT], matching the subdomains by their order
+func (s *KoolShare) targets() (targets []*shareTarget, err error) {
+	var used = make(map[string]bool)
+
+	if len(s.Flags.Services) == 0 {
+		err = fmt.Errorf("missing the service to share")
 		return
 	}
 
-	if !isRunning {
-		err = fmt.Errorf("service %s is not running, please check kool status and use --service flag to set which service to share", s.Flags.Service)
+	if len(s.Flags.Subdomains) > len(s.Flags.Services) {
+		err = fmt.Errorf("got %d subdomains for %d services; give one --service for each --subdomain", len(s.Flags.Subdomains), len(s.Flags.Services))
 		return
 	}
 
-	s.share.AppendArgs("--network", s.env.Get("KOOL_GLOBAL_NETWORK"))
-	s.share.AppendArgs("beyondcodegmbh/expose-server:1.4.1", "share")
-	s.share.AppendArgs(s.Flags.parseServiceURI())
-	s.share.AppendArgs("--server-host", "kool.live")
+	for i, service := range s.Flags.Services {
+		target := &shareTarget{service: service, port: s.Flags.Port}
+
+		if parts := strings.SplitN(service, ":", 2); len(parts) == 2 {
+			var port uint64
+
+			if port, err = strconv.ParseUint(parts[1], 10, 16); err != nil || port == 0 {
+				err = fmt.Errorf("invalid port on '%s'", service)
+				return
+			}
+
+			target.service, target.port = parts[0], uint(port)
+		}
 
-	if s.Flags.Subdomain != "" {
-		s.Flags.Subdomain = strings.ToLower(s.Flags.Subdomain)
-		if !s.validSubdomain(s.Flags.Subdomain) {
-			err = fmt.Errorf("invalid subdomain '%s'", s.Flags.Subdomain)
+		if used[target.service] {
+			err = fmt.Errorf("service %s is shared more than once", target.service)
 			return
 		}
 
-		s.share.AppendArgs("--subdomain", s.Flags.Subdomain)
+		used[target.service] = true
+
+		if i < len(s.Flags.Subdomains) {
+			target.subdomain = strings.ToLower(s.Flags.Subdomains[i])
+		}
+
+		if target.subdomain == "" && s.Flags.QRCode {
+			// the QR code needs to know the public URL beforehand
+			target.subdomain = s.randomSubdomain(target.service)
+		}
+
+		if target.subdomain != "" {
+			if !s.validSubdomain(target.subdomain) {
+				err = fmt.Errorf("invalid subdomain '%s'", target.subdomain)
+				return
+			}
+
+			if used["."+target.subdomain] {
+				err = fmt.Errorf("subdomain '%s' is used more than once", target.subdomain)
+				return
+			}
+
+			used["."+target.subdomain] = true
+		}
+
+		targets = append(targets, target)
+	}
+
+	return
+}
+
+// randomSubdomain generates a subdomain for the given service
+func (s *KoolShare) randomSubdomain(service string) string {
+	var suffix = make([]byte, 3)
+
+	_, _ = rand.Read(suffix)
+
+	prefix := strings.Trim(regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(strings.ToLower(service), "-"), "-")
+
+	if len(prefix) > 50 {
+		prefix = strings.Trim(prefix[:50], "-")
+	}
+
+	if prefix == "" {
+		return fmt.Sprintf("%x", suffix)
+	}
+
+	return fmt.Sprintf("%s-%x", prefix, suffix)
+}
+
+// startGuard starts the sidecar container letting only the allowed IPs
+// reach the given service address, asking for the basic auth credentials
+// of the htpasswd entry, if any
+func (s *KoolShare) startGuard(name, uri, htpasswd string) (err error) {
+	var config string
+
+	if config, err = tunnel.GuardConfig(uri, s.Flags.AllowIPs, htpasswd != ""); err != nil {
+		return
+	}
+
+	guard := s.guard.Copy()
+	guard.AppendArgs("--name", name, "--network", s.env.Get("KOOL_GLOBAL_NETWORK"))
+	guard.AppendArgs(tunnel.GuardArgs(config, htpasswd)...)
+
+	if _, err = s.Exec(guard); err != nil {
+		err = fmt.Errorf("failed to start the guard container: %v", err)
+	}
+
+	return
+}
+
+func (s *KoolShare) printURL(target *shareTarget, url string) {
+	s.Success("Sharing ", target.service, " on ", url)
+
+	if !s.Flags.QRCode {
+		return
+	}
+
+	if code, err := qrcode.Encode(url); err == nil {
+		s.Println(code.String())
+	} else {
+		s.Warning("Could not generate the QR code: ", err)
+	}
+}
+
+// runTunnels runs the tunnels side by side, and once one of them stops
+// (i.e. the subdomain is taken), removes the other ones as well
+func (s *KoolShare) runTunnels(tunnels []builder.Command, names []string) (err error) {
+	var errs = make(chan error, len(tunnels))
+
+	if len(tunnels) == 1 {
+		err = s.Interactive(tunnels[0])
+		return
+	}
+
+	for _, share := range tunnels {
+		go func(share builder.Command) {
+			errs <- s.Interactive(share)
+		}(share)
+	}
+
+	for i := range tunnels {
+		tunnelErr := <-errs
+
+		if i == 0 {
+			_, _ = s.Exec(s.remove, names...)
+		}
+
+		if err == nil {
+			err = tunnelErr
+		}
 	}
 
-	err = s.Interactive(s.share)
 	return
 }
 
@@ -98,14 +312,27 @@ func NewShareCommand(share *KoolShare) (shareCmd *cobra.Command) {
 	shareCmd = &cobra.Command{
 		Use:   "share",
 		Short: "Live share your local environment on the Internet using an HTTP tunnel",
-		Args:  cobra.NoArgs,
-		RunE:  DefaultCommandRunFunction(share),
+		Long: `Live share local services on the Internet using HTTP tunnels. Repeat --service
+(as SERVICE or SERVICE:PORT) to share several services at once, and --subdomain to
+pick the subdomain of each one, in the same order. The public URLs can be protected
+with HTTP basic auth (--basic-auth) and restricted to some client IPs (--allow-ip),
+and --qr prints a QR code of each URL for testing on mobile devices.
+
+By default, the tunnel goes through the kool.live expose server; set
+KOOL_SHARE_PROVIDER=expose and KOOL_SHARE_SERVER_HOST to tunnel through a
+self-hosted expose server instead. KOOL_SHARE_SERVER_PORT, KOOL_SHARE_AUTH_TOKEN,
+KOOL_SHARE_IMAGE and KOOL_SHARE_VERSION further configure the tunnel client.`,
+		Args: cobra.NoArgs,
+		RunE: DefaultCommandRunFunction(share),
 
 		DisableFlagsInUseLine: true,
 	}
 
-	shareCmd.Flags().StringVarP(&share.Flags.Service, "service", "", "app", "The name of the local service container you want to share.")
-	shareCmd.Flags().StringVarP(&share.Flags.Subdomain, "subdomain", "", "", "The subdomain used to generate your public https://subdomain.kool.live URL.")
+	shareCmd.Flags().StringArrayVarP(&share.Flags.Services, "service", "", []string{"app"}, "The name of the local service container you want to share, optionally with its port (SERVICE[:PORT]). Can be repeated.")
+	shareCmd.Flags().StringArrayVarP(&share.Flags.Subdomains, "subdomain", "", []string{}, "The subdomain used to generate your public URL (i.e. https://subdomain.kool.live). Can be repeated, one for each service.")
 	shareCmd.Flags().UintVarP(&share.Flags.Port, "port", "", 0, "The port from the target service that should be shared. If not provided, it will default to port 80.")
+	shareCmd.Flags().StringVarP(&share.Flags.BasicAuth, "basic-auth", "", "", "Require HTTP basic auth credentials (user:password) to access the public URL.")
+	shareCmd.Flags().StringArrayVarP(&share.Flags.AllowIPs, "allow-ip", "", []string{}, "Only allow access from the given IP address or CIDR range. Can be repeated.")
+	shareCmd.Flags().BoolVarP(&share.Flags.QRCode, "qr", "", false, "Print a QR code of the public URL.")
 	return
 }
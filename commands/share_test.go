@@ -2,17 +2,19 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/shell"
+	"strings"
 	"testing"
 )
 
 func TestShareDefaults(t *testing.T) {
 	share := NewKoolShare()
 
-	if share.Flags.Service != "app" {
-		t.Errorf("bad default service; expected app but got %s", share.Flags.Service)
+	if len(share.Flags.Services) != 1 || share.Flags.Services[0] != "app" {
+		t.Errorf("bad default service; expected app but got %v", share.Flags.Services)
 	}
 
 	if _, ok := share.env.(*environment.DefaultEnvStorage); !ok {
@@ -22,29 +24,103 @@ func TestShareDefaults(t *testing.T) {
 	if len(share.share.Args()) != 3 || share.share.Cmd() != "docker" {
 		t.Error("bad default builder.Command for sharing")
 	}
+
+	if strings.Join(share.guard.Args(), " ") != "run -d --rm" || strings.Join(share.remove.Args(), " ") != "rm -f" {
+		t.Error("bad default builder.Command for the IP allowlist")
+	}
 }
 
 func newFakeShareService() *KoolShare {
 	return &KoolShare{
 		*newFakeKoolService(),
-		&KoolShareFlags{"default-service", "default-subdomain", 0},
+		&KoolShareFlags{[]string{"default-service"}, []string{"default-subdomain"}, 0, "", []string{}, false},
 		environment.NewFakeEnvStorage(),
 		newFakeKoolStatus(),
-		&builder.FakeCommand{},
+		&builder.FakeCommand{MockCmd: "docker"},
+		&builder.FakeCommand{MockCmd: "docker"},
+		&builder.FakeCommand{MockCmd: "docker"},
+	}
+}
+
+func newRunningFakeShareService() *KoolShare {
+	share := newFakeShareService()
+	share.status.getServiceIDCmd.(*builder.FakeCommand).MockExecOut = "100"
+	share.status.getServiceStatusPortCmd.(*builder.FakeCommand).MockExecOut = "Up About an hour|0.0.0.0:80->80/tcp, 9000/tcp"
+	share.env.Set("KOOL_NAME", "my-app")
+	share.env.Set("KOOL_GLOBAL_NETWORK", "kool_global")
+	return share
+}
+
+func TestShareTargetURI(t *testing.T) {
+	target := &shareTarget{"service", 10, ""}
+
+	if target.uri() != "service:10" {
+		t.Errorf("bad service URI generated from flags; expected service:10 but got: %s", target.uri())
+	}
+
+	target.port = 0
+
+	if target.uri() != "service" {
+		t.Errorf("bad service URI generated from flags; expected service but got: %s", target.uri())
 	}
 }
 
-func TestFlagParseServiceURI(t *testing.T) {
-	f := &KoolShareFlags{"service", "", 10}
+func TestShareTargets(t *testing.T) {
+	share := newFakeShareService()
+	share.Flags.Services = []string{"app", "node:3000", "api"}
+	share.Flags.Subdomains = []string{"Review", "front"}
+	share.Flags.Port = 8080
+
+	targets, err := share.targets()
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing targets: %v", err)
+	}
+
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(targets))
+	}
+
+	if targets[0].uri() != "app:8080" || targets[0].subdomain != "review" {
+		t.Errorf("unexpected first target: %v", targets[0])
+	}
+
+	if targets[1].uri() != "node:3000" || targets[1].subdomain != "front" {
+		t.Errorf("unexpected second target: %v", targets[1])
+	}
 
-	if f.parseServiceURI() != "service:10" {
-		t.Errorf("bad service URI generated from flags; expected service:10 but got: %s", f.parseServiceURI())
+	if targets[2].uri() != "api:8080" || targets[2].subdomain != "" {
+		t.Errorf("unexpected third target: %v", targets[2])
 	}
 
-	f.Port = 0
+	for flags, expected := range map[*KoolShareFlags]string{
+		{Services: []string{}}: "missing the service to share",
+		{Services: []string{"app"}, Subdomains: []string{"a", "b"}}:          "got 2 subdomains for 1 services",
+		{Services: []string{"app:http"}}:                                     "invalid port on 'app:http'",
+		{Services: []string{"app:0"}}:                                        "invalid port on 'app:0'",
+		{Services: []string{"app", "app:8080"}}:                              "service app is shared more than once",
+		{Services: []string{"app", "node"}, Subdomains: []string{"a", "a"}}:  "subdomain 'a' is used more than once",
+		{Services: []string{"app", "node"}, Subdomains: []string{"a", "-b"}}: "invalid subdomain '-b'",
+	} {
+		share.Flags = flags
 
-	if f.parseServiceURI() != "service" {
-		t.Errorf("bad service URI generated from flags; expected service but got: %s", f.parseServiceURI())
+		if _, err = share.targets(); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error '%s', got %v", expected, err)
+		}
+	}
+}
+
+func TestShareRandomSubdomain(t *testing.T) {
+	share := newFakeShareService()
+
+	for _, service := range []string{"app", "My_Service", "__", strings.Repeat("a", 80)} {
+		if subdomain := share.randomSubdomain(service); !share.validSubdomain(subdomain) {
+			t.Errorf("generated invalid subdomain '%s' for service '%s'", subdomain, service)
+		}
+	}
+
+	if first, second := share.randomSubdomain("app"), share.randomSubdomain("app"); first == second || !strings.HasPrefix(first, "app-") {
+		t.Errorf("unexpected random subdomains: %s, %s", first, second)
 	}
 }
 
@@ -85,6 +161,36 @@ func TestShareCommandServiceDoesNotExist(t *testing.T) {
 	assertExecGotError(t, cmd, "fake error")
 }
 
+func TestShareCommandSelfHostedProvider(t *testing.T) {
+	share := newRunningFakeShareService()
+	share.env.Set("KOOL_SHARE_PROVIDER", "expose")
+	share.env.Set("KOOL_SHARE_SERVER_HOST", "tunnel.example.com")
+	share.env.Set("KOOL_SHARE_AUTH_TOKEN", "secret")
+
+	cmd := NewShareCommand(share)
+	cmd.SetArgs([]string{"--service", "app", "--subdomain", "review"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error on sharing: %v", err)
+	}
+
+	expected := "--name my-app_share_app --network kool_global beyondcodegmbh/expose-server:1.4.1 share app --server-host tunnel.example.com --auth secret --subdomain review"
+	if args := strings.Join(share.share.(*builder.FakeCommand).ArgsAppend, " "); args != expected {
+		t.Errorf("unexpected share args: %s", args)
+	}
+}
+
+func TestShareCommandBadProvider(t *testing.T) {
+	share := newFakeShareService()
+	share.env.Set("KOOL_SHARE_PROVIDER", "expose")
+
+	assertExecGotError(t, NewShareCommand(share), "KOOL_SHARE_SERVER_HOST must be set")
+
+	if share.share.(*builder.FakeCommand).CalledAppendArgs {
+		t.Error("should not build the tunnel command with a bad provider")
+	}
+}
+
 func TestShareCommandSetFlags(t *testing.T) {
 	share := newFakeShareService()
 	share.status.getServiceIDCmd.(*builder.FakeCommand).MockExecOut = "100"
@@ -98,10 +204,141 @@ func TestShareCommandSetFlags(t *testing.T) {
 		t.Error("unexpected error")
 	}
 	args := share.share.(*builder.FakeCommand).ArgsAppend
-	if args[4] != "foo" {
+	if args[6] != "foo" {
 		t.Error("failed setting service")
 	}
-	if args[8] != "sub" {
+	if args[10] != "sub" {
 		t.Error("failed setting subdomain")
 	}
 }
+
+func TestShareCommandMultipleServices(t *testing.T) {
+	share := newRunningFakeShareService()
+
+	cmd := NewShareCommand(share)
+	cmd.SetArgs([]string{"--service", "app", "--service", "node:3000", "--subdomain", "back", "--subdomain", "front"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error on sharing: %v", err)
+	}
+
+	args := strings.Join(share.share.(*builder.FakeCommand).ArgsAppend, " ")
+
+	for _, expected := range []string{
+		"--name my-app_share_app --network kool_global beyondcodegmbh/expose-server:1.4.1 share app --server-host kool.live --subdomain back",
+		"--name my-app_share_node --network kool_global beyondcodegmbh/expose-server:1.4.1 share node:3000 --server-host kool.live --subdomain front",
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("missing tunnel '%s' on share args: %s", expected, args)
+		}
+	}
+
+	if args := strings.Join(share.remove.(*builder.FakeCommand).ArgsAppend, " "); args != "" {
+		t.Errorf("unexpected args appended to the remove command: %s", args)
+	}
+
+	if !share.shell.(*shell.FakeShell).CalledExec["docker"] {
+		t.Error("should remove the remaining tunnels once one stops")
+	}
+
+	if output := fmt.Sprint(share.shell.(*shell.FakeShell).SuccessOutput...); output != "Sharing node on https://front.kool.live" {
+		t.Errorf("unexpected output: %s", output)
+	}
+}
+
+func TestShareCommandBasicAuth(t *testing.T) {
+	share := newRunningFakeShareService()
+
+	cmd := NewShareCommand(share)
+	cmd.SetArgs([]string{"--basic-auth", "user:secret"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error on sharing: %v", err)
+	}
+
+	if args := strings.Join(share.share.(*builder.FakeCommand).ArgsAppend, " "); strings.Contains(args, "secret") || !strings.Contains(args, "--network container:my-app_share_app_guard") {
+		t.Errorf("the tunnel should go through the guard, without the credentials: %s", args)
+	}
+
+	guardArgs := share.guard.(*builder.FakeCommand).ArgsAppend
+
+	if len(guardArgs) < 8 || !strings.Contains(guardArgs[5], "auth_basic_user_file") || strings.Contains(guardArgs[5], "deny all") {
+		t.Fatalf("unexpected guard args: %v", guardArgs)
+	}
+
+	if htpasswd := guardArgs[7]; !strings.HasPrefix(htpasswd, "KOOL_SHARE_HTPASSWD=user:{SSHA}") || strings.Contains(htpasswd, "secret") {
+		t.Errorf("unexpected guard credentials: %s", htpasswd)
+	}
+
+	for _, bad := range []string{"user", ":secret", "user:"} {
+		share = newRunningFakeShareService()
+		cmd = NewShareCommand(share)
+		cmd.SetArgs([]string{"--basic-auth", bad})
+		assertExecGotError(t, cmd, "invalid basic auth")
+	}
+}
+
+func TestShareCommandAllowIPs(t *testing.T) {
+	share := newRunningFakeShareService()
+
+	cmd := NewShareCommand(share)
+	cmd.SetArgs([]string{"--service", "app:8000", "--allow-ip", "203.0.113.7", "--allow-ip", "10.0.0.0/8"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error on sharing: %v", err)
+	}
+
+	guardArgs := share.guard.(*builder.FakeCommand).ArgsAppend
+
+	if len(guardArgs) < 6 || strings.Join(guardArgs[:4], " ") != "--name my-app_share_app_guard --network kool_global" {
+		t.Fatalf("unexpected guard args: %v", guardArgs)
+	}
+
+	if config := guardArgs[5]; !strings.Contains(config, "allow 203.0.113.7;") || !strings.Contains(config, "allow 10.0.0.0/8;") || !strings.Contains(config, "proxy_pass http://app:8000;") {
+		t.Errorf("unexpected guard config: %s", config)
+	}
+
+	if args := strings.Join(share.share.(*builder.FakeCommand).ArgsAppend, " "); !strings.Contains(args, "--network container:my-app_share_app_guard") || !strings.Contains(args, "share 127.0.0.1 --server-host") {
+		t.Errorf("tunnel should reach the guard over its network namespace: %s", args)
+	}
+
+	if args := strings.Join(share.remove.(*builder.FakeCommand).ArgsAppend, " "); args != "" {
+		t.Errorf("unexpected args appended to the remove command: %s", args)
+	}
+
+	share = newRunningFakeShareService()
+	cmd = NewShareCommand(share)
+	cmd.SetArgs([]string{"--allow-ip", "localhost"})
+	assertExecGotError(t, cmd, "invalid IP address or range 'localhost'")
+
+	share = newRunningFakeShareService()
+	share.guard.(*builder.FakeCommand).MockExecError = errors.New("guard error")
+	cmd = NewShareCommand(share)
+	cmd.SetArgs([]string{"--allow-ip", "10.0.0.1"})
+	assertExecGotError(t, cmd, "failed to start the guard container: guard error")
+}
+
+func TestShareCommandQRCode(t *testing.T) {
+	share := newRunningFakeShareService()
+
+	cmd := NewShareCommand(share)
+	cmd.SetArgs([]string{"--qr"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error on sharing: %v", err)
+	}
+
+	output := fmt.Sprint(share.shell.(*shell.FakeShell).SuccessOutput...)
+
+	if !strings.HasPrefix(output, "Sharing app on https://app-") || !strings.HasSuffix(output, ".kool.live") {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	if lines := share.shell.(*shell.FakeShell).OutLines; len(lines) != 1 || !strings.Contains(lines[0], "█") {
+		t.Errorf("expected QR code output, got %v", lines)
+	}
+
+	if args := strings.Join(share.share.(*builder.FakeCommand).ArgsAppend, " "); !strings.Contains(args, "--subdomain app-") {
+		t.Errorf("tunnel should use the generated subdomain: %s", args)
+	}
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/services/compose"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultShellService is the service used by kool shell
+// when none is given nor set on kool.yml
+const DefaultShellService string = "app"
+
+// shellProbeScript finds out in one go the best shell available in the container
+const shellProbeScript string = "command -v bash || command -v zsh || command -v ash || command -v sh"
+
+// KoolShell holds handlers and functions to implement the shell command logic
+type KoolShell struct {
+	DefaultKoolService
+
+	exec   *KoolExec
+	env    environment.EnvStorage
+	parser parser.Parser
+	probe  builder.Command
+}
+
+func AddKoolShell(root *cobra.Command) {
+	var (
+		koolShell = NewKoolShell()
+		shellCmd  = NewShellCommand(koolShell)
+	)
+
+	root.AddCommand(shellCmd)
+}
+
+// NewKoolShell creates a new handler for shell logic
+func NewKoolShell() *KoolShell {
+	return &KoolShell{
+		*newDefaultKoolService(),
+		NewKoolExec(),
+		environment.NewEnvStorage(),
+		parser.NewParser(),
+		compose.NewDockerCompose("exec", "-T"),
+	}
+}
+
+// Execute runs the shell logic with incoming arguments.
+func (s *KoolShell) Execute(args []string) (err error) {
+	var service string
+
+	_ = s.parser.AddLookupPath(s.env.Get("PWD"))
+	_ = s.parser.AddLookupPath(path.Join(s.env.Get("HOME"), "kool"))
+
+	if len(args) > 0 {
+		service = args[0]
+	} else if service, err = s.parser.ParseDefaultService(); err != nil && !errors.Is(err, parser.ErrKoolYmlNotFound) {
+		err = fmt.Errorf("failed to read the default service: %v", err)
+		return
+	} else if service == "" {
+		service = DefaultShellService
+	}
+
+	s.exec.SetInStream(s.InStream())
+	s.exec.SetOutStream(s.OutStream())
+	s.exec.SetErrStream(s.ErrStream())
+
+	err = s.exec.Execute([]string{service, s.shellFor(service)})
+	return
+}
+
+// shellFor picks the shell from the service settings on kool.yml, or the
+// best one available in the container; sh is the last resort, as it is
+// available even on the slimmest images
+func (s *KoolShell) shellFor(service string) (bin string) {
+	if settings, err := s.parser.ParseService(service); err == nil && settings != nil && settings.Shell != "" {
+		bin = settings.Shell
+		return
+	}
+
+	if output, err := s.Exec(s.probe, service, "sh", "-c", shellProbeScript); err == nil {
+		bin = strings.TrimSpace(strings.Split(strings.TrimSpace(output), "\n")[0])
+	}
+
+	if bin == "" {
+		bin = "sh"
+	}
+
+	return
+}
+
+// NewShellCommand initializes new kool shell command
+func NewShellCommand(koolShell *KoolShell) (shellCmd *cobra.Command) {
+	shellCmd = &cobra.Command{
+		Use:   "shell [OPTIONS] [SERVICE]",
+		Short: "Open a shell inside a service container",
+		Long: `Open the best shell available (bash, zsh, ash or sh) inside the specified SERVICE
+container. The SERVICE defaults to the default_service set on kool.yml, or app.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: DefaultCommandRunFunction(koolShell),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	shellCmd.Flags().StringArrayVarP(&koolShell.exec.Flags.EnvVariables, "env", "e", []string{}, "Environment variables.")
+	shellCmd.Flags().StringVarP(&koolShell.exec.Flags.User, "user", "u", "", "Run the shell as this user (name or UID). Defaults to the service user on kool.yml.")
+	return
+}
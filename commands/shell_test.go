@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolShell() *KoolShell {
+	return &KoolShell{
+		*newFakeKoolService(),
+		newFakeKoolExec(),
+		environment.NewFakeEnvStorage(),
+		&parser.FakeParser{},
+		&builder.FakeCommand{MockCmd: "probe", MockExecOut: "/bin/bash\n"},
+	}
+}
+
+func TestNewKoolShell(t *testing.T) {
+	k := NewKoolShell()
+
+	if k.exec == nil || k.exec.Flags == nil {
+		t.Error("unexpected KoolExec on default KoolShell instance")
+	}
+
+	if _, ok := k.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolShell instance")
+	}
+
+	if _, ok := k.parser.(*parser.DefaultParser); !ok {
+		t.Error("unexpected parser.Parser on default KoolShell instance")
+	}
+
+	if _, ok := k.probe.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.DockerCompose on default KoolShell instance")
+	}
+}
+
+func TestNewShellCommand(t *testing.T) {
+	f := newFakeKoolShell()
+	cmd := NewShellCommand(f)
+	cmd.SetArgs([]string{"--user", "root", "-e", "VAR=1", "node"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if !f.shell.(*shell.FakeShell).CalledExec["probe"] {
+		t.Error("did not probe the container for the available shell")
+	}
+
+	args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]
+	if strings.Join(args, " ") != "node /bin/bash" {
+		t.Errorf("bad arguments to KoolExec; got %v", args)
+	}
+
+	if f.exec.Flags.User != "root" || len(f.exec.Flags.EnvVariables) != 1 {
+		t.Error("did not set the exec flags")
+	}
+}
+
+func TestShellDefaultService(t *testing.T) {
+	f := newFakeKoolShell()
+
+	if err := f.Execute(nil); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[0] != "app" {
+		t.Errorf("expected to use the app service by default; got %v", args)
+	}
+
+	f = newFakeKoolShell()
+	f.parser.(*parser.FakeParser).MockDefaultService = "php"
+
+	if err := f.Execute(nil); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[0] != "php" {
+		t.Errorf("expected to use the kool.yml default service; got %v", args)
+	}
+
+	f = newFakeKoolShell()
+	f.parser.(*parser.FakeParser).MockParseDefaultServiceError = parser.ErrKoolYmlNotFound
+
+	if err := f.Execute(nil); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[0] != "app" {
+		t.Errorf("expected to use the app service without kool.yml; got %v", args)
+	}
+
+	f = newFakeKoolShell()
+	f.parser.(*parser.FakeParser).MockParseDefaultServiceError = errors.New("yaml: line 2: mapping values are not allowed in this context")
+
+	if err := f.Execute(nil); err == nil || !strings.Contains(err.Error(), "failed to read the default service: yaml: line 2") {
+		t.Errorf("expected the kool.yml parsing error, got %v", err)
+	}
+
+	if f.exec.shell.(*shell.FakeShell).CalledInteractive["exec"] {
+		t.Error("should not open a shell when kool.yml is invalid")
+	}
+}
+
+func TestShellDetection(t *testing.T) {
+	f := newFakeKoolShell()
+	f.parser.(*parser.FakeParser).MockServices = map[string]*parser.KoolYamlService{
+		"app": {Shell: "fish"},
+	}
+
+	if err := f.Execute([]string{"app"}); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if f.shell.(*shell.FakeShell).CalledExec["probe"] {
+		t.Error("should not probe the container when kool.yml sets the service shell")
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[1] != "fish" {
+		t.Errorf("expected to use the kool.yml service shell; got %v", args)
+	}
+
+	f = newFakeKoolShell()
+	f.probe.(*builder.FakeCommand).MockExecOut = "/bin/ash"
+
+	if err := f.Execute([]string{"app"}); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[1] != "/bin/ash" {
+		t.Errorf("expected to use the probed shell; got %v", args)
+	}
+
+	f = newFakeKoolShell()
+	f.probe.(*builder.FakeCommand).MockExecError = errors.New("probe error")
+
+	if err := f.Execute([]string{"app"}); err != nil {
+		t.Errorf("unexpected error executing shell command; error: %v", err)
+	}
+
+	if args := f.exec.shell.(*shell.FakeShell).ArgsInteractive["exec"]; len(args) != 2 || args[1] != "sh" {
+		t.Errorf("expected to fallback to sh; got %v", args)
+	}
+}
+
+func TestShellExecError(t *testing.T) {
+	f := newFakeKoolShell()
+	f.exec = newFailedFakeKoolExec()
+
+	if err := f.Execute([]string{"app"}); err == nil || err.Error() != "error exec" {
+		t.Errorf("expected exec error, got %v", err)
+	}
+}
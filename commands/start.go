@@ -2,11 +2,13 @@ package commands
 
 import (
 	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
 	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/network"
 	"kool-dev/kool/services/checker"
 	"kool-dev/kool/services/compose"
 	"kool-dev/kool/services/updater"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +17,8 @@ import (
 type KoolStartFlags struct {
 	Foreground bool
 	Rebuild    bool
+	KoolServiceGroupsFlags
+	KoolRebuildFlags
 }
 
 // KoolStart holds handlers and functions for starting containers logic
@@ -27,14 +31,13 @@ type KoolStart struct {
 	envStorage environment.EnvStorage
 	start      builder.Command
 
-	rebuilder KoolService
-}
-
-// KoolRebuild holds handlers for updating the service's images
-type KoolRebuild struct {
-	DefaultKoolService
+	projectDirs cache.Cache
 
-	pull, build builder.Command
+	rebuilder  KoolService
+	groups     *serviceGroups
+	portsCheck KoolService
+	aliases    KoolService
+	proxy      KoolService
 }
 
 // NewStartCommand initializes new kool start Cobra command
@@ -43,7 +46,15 @@ func NewStartCommand(start *KoolStart) (startCmd *cobra.Command) {
 		Use:   "start [SERVICE...]",
 		Short: "Start service containers defined in docker-compose.yml",
 		Long: `Start one or more specified [SERVICE] containers. If no [SERVICE] is provided,
-all containers are started. If the containers are already running, they are recreated.`,
+all containers are started. If the containers are already running, they are recreated.
+Services can also be targeted by groups defined in kool.yml or docker-compose
+profiles (--group), or by enabling docker-compose profiles (--profile). Host ports already in use are detected
+beforehand, and the ones set through environment variables can be remapped
+to free ports, which are saved to .env.local. When rebuilding (--rebuild), only
+the images whose build context, Dockerfile or base images changed are built.
+Services with aliases set on kool.yml are reachable by them on the global network,
+and the ones labeled with 'kool.proxy.port' are served on HTTPS by the kool proxy
+at https://<service>.<project>.localhost.`,
 		RunE: DefaultCommandRunFunction(CheckNewVersion(start, &updater.DefaultUpdater{RootCommand: rootCmd})),
 
 		DisableFlagsInUseLine: true,
@@ -51,26 +62,40 @@ all containers are started. If the containers are already running, they are recr
 
 	startCmd.Flags().BoolVarP(&start.Flags.Foreground, "foreground", "f", false, "Start containers in foreground mode")
 	startCmd.Flags().BoolVarP(&start.Flags.Rebuild, "rebuild", "b", false, "Updates and builds service's images")
+	addServiceGroupsFlags(startCmd, &start.Flags.KoolServiceGroupsFlags)
+	addRebuildFlags(startCmd, &start.Flags.KoolRebuildFlags)
 
 	return
 }
 
+// addRebuildFlags registers the flags for updating the service's images
+func addRebuildFlags(cmd *cobra.Command, flags *KoolRebuildFlags) {
+	cmd.Flags().StringArrayVarP(&flags.Services, "service", "", []string{}, "Only update the images of the given services (implies --rebuild)")
+	cmd.Flags().BoolVarP(&flags.NoPull, "no-pull", "", false, "Do not pull newer images when rebuilding")
+}
+
 // NewKoolStart creates a new pointer with default KoolStart service
 // dependencies.
 func NewKoolStart() *KoolStart {
-	defaultKoolService := newDefaultKoolService()
+	var (
+		defaultKoolService = newDefaultKoolService()
+		flags              = &KoolStartFlags{false, false, KoolServiceGroupsFlags{}, KoolRebuildFlags{}}
+		env                = environment.NewEnvStorage()
+	)
+
 	return &KoolStart{
 		*defaultKoolService,
-		&KoolStartFlags{false, false},
+		flags,
 		checker.NewChecker(defaultKoolService.shell),
 		network.NewHandler(defaultKoolService.shell),
-		environment.NewEnvStorage(),
+		env,
 		compose.NewDockerCompose("up", "--force-recreate"),
-		&KoolRebuild{
-			*newDefaultKoolService(),
-			compose.NewDockerCompose("pull"),
-			compose.NewDockerCompose("build", "--pull"),
-		},
+		newProjectDirsCache(env),
+		NewKoolRebuild(&flags.KoolRebuildFlags),
+		newServiceGroups(),
+		NewKoolPortsCheck(),
+		NewKoolNetworkAliases(),
+		&KoolProxyStart{NewKoolProxy()},
 	}
 }
 
@@ -78,20 +103,20 @@ func AddKoolStart(root *cobra.Command) {
 	root.AddCommand(NewStartCommand(NewKoolStart()))
 }
 
-// Execute runs the rebuild logic
-func (r *KoolRebuild) Execute(args []string) (err error) {
-	if err = r.Interactive(r.pull); err != nil {
-		return
+// Execute runs the start logic with incoming arguments
+func (s *KoolStart) Execute(args []string) (err error) {
+	var commands = []builder.Command{s.start}
+
+	if rebuilder, ok := s.rebuilder.(*KoolRebuild); ok {
+		commands = append(commands, rebuilder.pull, rebuilder.build)
 	}
 
-	err = r.Interactive(r.build)
-	return
-}
+	if args, err = s.groups.Resolve(&s.Flags.KoolServiceGroupsFlags, args, commands...); err != nil {
+		return
+	}
 
-// Execute runs the start logic with incoming arguments
-func (s *KoolStart) Execute(args []string) (err error) {
-	if s.Flags.Rebuild {
-		if err = s.rebuild(); err != nil {
+	if s.Flags.Rebuild || len(s.Flags.Services) > 0 {
+		if err = s.rebuild(args); err != nil {
 			return
 		}
 	}
@@ -104,11 +129,35 @@ func (s *KoolStart) Execute(args []string) (err error) {
 		return
 	}
 
-	err = s.Interactive(s.start, args...)
+	if err = s.runService(s.portsCheck, args); err != nil {
+		return
+	}
+
+	// kool doctor disk and kool prune --missing find the project folder
+	// through it once the containers are gone
+	if dir, wdErr := os.Getwd(); wdErr == nil {
+		_ = s.projectDirs.Set(compose.ProjectName(s.envStorage.Get("KOOL_NAME")), dir)
+	}
+
+	if err = s.Interactive(s.start, args...); err != nil || s.Flags.Foreground {
+		return
+	}
+
+	if err = s.runService(s.aliases, args); err != nil {
+		return
+	}
+
+	if proxyErr := s.runService(s.proxy, args); proxyErr != nil {
+		// the services are up already, so only their domains are missing
+		// (i.e. host ports 80 or 443 are taken by another web server)
+		s.Warning("The services are running, but their domains could not be routed: ", proxyErr)
+		s.Warning("Set KOOL_PROXY_HTTP_PORT and KOOL_PROXY_HTTPS_PORT to use other host ports for the kool proxy.")
+	}
+
 	return
 }
 
-func (s *KoolStart) rebuild() (err error) {
+func (s *KoolStart) rebuild(args []string) (err error) {
 	var task = NewKoolTask("Updating service's images", s.rebuilder)
 
 	task.SetFrameOutput(false)
@@ -117,7 +166,18 @@ func (s *KoolStart) rebuild() (err error) {
 	task.SetOutStream(s.OutStream())
 	task.SetErrStream(s.ErrStream())
 
-	err = task.Run(nil)
+	err = task.Run(args)
+	return
+}
+
+// runService runs one of the steps of starting the
+// services, sharing the standard streams with it
+func (s *KoolStart) runService(service KoolService, args []string) (err error) {
+	service.SetInStream(s.InStream())
+	service.SetOutStream(s.OutStream())
+	service.SetErrStream(s.ErrStream())
+
+	err = service.Execute(args)
 	return
 }
 
@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/network"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var containerHostPortRegex = regexp.MustCompile(`:(\d+)->`)
+
+// KoolPortsCheck holds handlers for detecting host ports conflicts of the
+// services about to be started, and remapping them to free ports
+type KoolPortsCheck struct {
+	DefaultKoolService
+
+	env          environment.EnvStorage
+	ports        network.PortChecker
+	promptSelect shell.PromptSelect
+	files        compose.FilesAware
+
+	listContainers builder.Command
+	containerPorts builder.Command
+}
+
+// NewKoolPortsCheck creates a new handler for checking
+// host ports conflicts with default dependencies
+func NewKoolPortsCheck() *KoolPortsCheck {
+	return &KoolPortsCheck{
+		*newDefaultKoolService(),
+		environment.NewEnvStorage(),
+		network.NewPortChecker(),
+		shell.NewPromptSelect(),
+		compose.NewDockerCompose("config"),
+		compose.NewDockerCompose("ps", "-q"),
+		builder.NewCommand("docker", "ps", "--format", "{{.Ports}}"),
+	}
+}
+
+// Execute checks the host ports published by the given services (or all
+// of them) and offers to remap the conflicting ones to free ports
+func (p *KoolPortsCheck) Execute(args []string) (err error) {
+	var (
+		published []compose.PublishedPort
+		inUse     map[string]bool
+		checked   = make(map[string]bool)
+		remapped  = make(map[string]string)
+		services  = make(map[string]bool)
+	)
+
+	if published, err = p.publishedPorts(); err != nil {
+		return
+	}
+
+	for _, service := range args {
+		services[service] = true
+	}
+
+	inUse = p.projectPorts()
+
+	for _, port := range published {
+		var hostPort = port.Resolve(p.env.Get)
+
+		if len(services) > 0 && !services[port.Service] {
+			continue
+		}
+
+		if checked[hostPort] || inUse[hostPort] || p.ports.IsAvailable(hostPort) {
+			checked[hostPort] = true
+			continue
+		}
+
+		checked[hostPort] = true
+
+		if !port.IsVariable() {
+			p.Warning(fmt.Sprintf("Port %s published by service %s is already in use; publish it through an environment variable (i.e. ${SOME_PORT:-%s}) so kool can remap it.", hostPort, port.Service, hostPort))
+			continue
+		}
+
+		if !p.IsTerminal() {
+			p.Warning(fmt.Sprintf("Port %s (%s) published by service %s is already in use.", hostPort, port.Variable, port.Service))
+			continue
+		}
+
+		var freePort, answer string
+
+		if freePort, err = p.ports.FreePort(hostPort); err != nil {
+			return
+		}
+
+		useFree := fmt.Sprintf("Use port %s", freePort)
+
+		if answer, err = p.promptSelect.Ask(
+			fmt.Sprintf("Port %s (%s) published by service %s is already in use. What do you want to do", hostPort, port.Variable, port.Service),
+			[]string{useFree, fmt.Sprintf("Keep port %s", hostPort)},
+		); err != nil {
+			return
+		}
+
+		if answer == useFree {
+			remapped[port.Variable] = freePort
+			checked[freePort] = true
+			p.env.Set(port.Variable, freePort)
+		}
+	}
+
+	if len(remapped) > 0 {
+		envFile := filepath.Join(p.env.Get("PWD"), ".env.local")
+
+		if err = environment.PersistVariables(envFile, remapped); err != nil {
+			err = fmt.Errorf("failed to save remapped ports to %s: %v", envFile, err)
+			return
+		}
+
+		p.Success("Remapped ports were saved to .env.local")
+	}
+
+	return
+}
+
+func (p *KoolPortsCheck) publishedPorts() (published []compose.PublishedPort, err error) {
+	var (
+		files []composeFile
+		ports []compose.PublishedPort
+	)
+
+	if files, err = readComposeFiles(p.files, p.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if ports, err = compose.ParsePublishedPorts(file.content); err != nil {
+			err = fmt.Errorf("failed to parse published ports from %s: %v", file.path, err)
+			return
+		}
+
+		published = append(published, ports...)
+	}
+
+	return
+}
+
+// projectPorts returns the host ports bound by the project's running
+// containers, which are about to be recreated and hence are not conflicts
+func (p *KoolPortsCheck) projectPorts() (ports map[string]bool) {
+	var (
+		output string
+		err    error
+		args   []string
+	)
+
+	ports = make(map[string]bool)
+
+	if output, err = p.Exec(p.listContainers); err != nil {
+		return
+	}
+
+	for _, id := range strings.Fields(output) {
+		args = append(args, "--filter", "id="+id)
+	}
+
+	if len(args) == 0 {
+		return
+	}
+
+	if output, err = p.Exec(p.containerPorts, args...); err != nil {
+		return
+	}
+
+	for _, matches := range containerHostPortRegex.FindAllStringSubmatch(output, -1) {
+		ports[matches[1]] = true
+	}
+
+	return
+}
@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/network"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const portsCheckCompose = `services:
+  app:
+    ports:
+      - "${KOOL_APP_PORT:-80}:80"
+  database:
+    ports:
+      - "${KOOL_DATABASE_PORT:-3306}:3306"
+  cache:
+    ports:
+      - "6379:6379"
+`
+
+func newFakeKoolPortsCheck(t *testing.T) *KoolPortsCheck {
+	env := environment.NewFakeEnvStorage()
+	env.Set("PWD", t.TempDir())
+
+	if err := os.WriteFile(filepath.Join(env.Get("PWD"), "docker-compose.yml"), []byte(portsCheckCompose), os.ModePerm); err != nil {
+		t.Fatal("failed creating docker-compose.yml for test", err)
+	}
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &KoolPortsCheck{
+		*newFakeKoolService(),
+		env,
+		&network.FakePortChecker{},
+		&shell.FakePromptSelect{},
+		files,
+		&builder.FakeCommand{MockCmd: "list"},
+		&builder.FakeCommand{MockCmd: "ports"},
+	}
+}
+
+func TestNewKoolPortsCheck(t *testing.T) {
+	k := NewKoolPortsCheck()
+
+	if _, ok := k.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolPortsCheck instance")
+	}
+
+	if _, ok := k.ports.(*network.DefaultPortChecker); !ok {
+		t.Error("unexpected network.PortChecker on default KoolPortsCheck instance")
+	}
+
+	if _, ok := k.promptSelect.(*shell.DefaultPromptSelect); !ok {
+		t.Error("unexpected shell.PromptSelect on default KoolPortsCheck instance")
+	}
+
+	if _, ok := k.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default KoolPortsCheck instance")
+	}
+}
+
+func TestPortsCheckNoConflicts(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	checked := k.ports.(*network.FakePortChecker).CalledIsAvailable
+	if !checked["80"] || !checked["3306"] || !checked["6379"] {
+		t.Errorf("did not check all published ports: %v", checked)
+	}
+
+	if k.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not ask about ports without conflicts")
+	}
+}
+
+func TestPortsCheckServicesFilter(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+
+	if err := k.Execute([]string{"database"}); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	checked := k.ports.(*network.FakePortChecker).CalledIsAvailable
+	if len(checked) != 1 || !checked["3306"] {
+		t.Errorf("should only check the database published port: %v", checked)
+	}
+}
+
+func TestPortsCheckProjectPorts(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"3306": true}
+	k.listContainers.(*builder.FakeCommand).MockExecOut = "abc123\ndef456"
+	k.containerPorts.(*builder.FakeCommand).MockExecOut = "0.0.0.0:3306->3306/tcp, :::3306->3306/tcp\n0.0.0.0:80->80/tcp"
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	if k.ports.(*network.FakePortChecker).CalledIsAvailable["3306"] {
+		t.Error("should not check ports bound by the project's own containers")
+	}
+
+	if k.shell.(*shell.FakeShell).CalledWarning {
+		t.Error("should not warn about ports bound by the project's own containers")
+	}
+}
+
+func TestPortsCheckRemap(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"3306": true}
+	k.ports.(*network.FakePortChecker).MockFreePort = map[string]string{"3306": "3307"}
+	k.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
+		"Port 3306 (KOOL_DATABASE_PORT) published by service database is already in use. What do you want to do": "Use port 3307",
+	}
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	if k.env.Get("KOOL_DATABASE_PORT") != "3307" {
+		t.Error("did not set the remapped port on the environment")
+	}
+
+	content, _ := os.ReadFile(filepath.Join(k.env.Get("PWD"), ".env.local"))
+	if string(content) != "KOOL_DATABASE_PORT=3307\n" {
+		t.Errorf("did not persist the remapped port to .env.local: %s", content)
+	}
+
+	if !k.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the remapped ports")
+	}
+}
+
+func TestPortsCheckKeep(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"80": true}
+	k.ports.(*network.FakePortChecker).MockFreePort = map[string]string{"80": "81"}
+	k.promptSelect.(*shell.FakePromptSelect).MockAnswer = map[string]string{
+		"Port 80 (KOOL_APP_PORT) published by service app is already in use. What do you want to do": "Keep port 80",
+	}
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	if k.env.Get("KOOL_APP_PORT") != "" {
+		t.Error("should not set the port on the environment when keeping it")
+	}
+
+	if _, err := os.Stat(filepath.Join(k.env.Get("PWD"), ".env.local")); !os.IsNotExist(err) {
+		t.Error("should not write .env.local when keeping the ports")
+	}
+}
+
+func TestPortsCheckConflictWarnings(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"6379": true}
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	warning := k.shell.(*shell.FakeShell).WarningOutput
+	if len(warning) != 1 || !strings.Contains(warning[0].(string), "publish it through an environment variable") {
+		t.Errorf("expected warning about not remappable port, got %v", warning)
+	}
+
+	k = newFakeKoolPortsCheck(t)
+	k.term.(*shell.FakeTerminalChecker).MockIsTerminal = false
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"80": true}
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error checking ports: %v", err)
+	}
+
+	if k.promptSelect.(*shell.FakePromptSelect).CalledAsk {
+		t.Error("should not ask when not under a terminal")
+	}
+
+	warning = k.shell.(*shell.FakeShell).WarningOutput
+	if len(warning) != 1 || !strings.Contains(warning[0].(string), "Port 80 (KOOL_APP_PORT)") {
+		t.Errorf("expected warning about port conflict, got %v", warning)
+	}
+}
+
+func TestPortsCheckErrors(t *testing.T) {
+	k := newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"80": true}
+	k.ports.(*network.FakePortChecker).MockError = errors.New("free port error")
+
+	if err := k.Execute(nil); err == nil || err.Error() != "free port error" {
+		t.Errorf("expected free port error, got %v", err)
+	}
+
+	k = newFakeKoolPortsCheck(t)
+	k.ports.(*network.FakePortChecker).MockUnavailable = map[string]bool{"80": true}
+	k.promptSelect.(*shell.FakePromptSelect).MockError = map[string]error{
+		"Port 80 (KOOL_APP_PORT) published by service app is already in use. What do you want to do": shell.ErrUserCancelled,
+	}
+
+	if err := k.Execute(nil); err != shell.ErrUserCancelled {
+		t.Errorf("expected user cancelled error, got %v", err)
+	}
+
+	k = newFakeKoolPortsCheck(t)
+	_ = os.WriteFile(filepath.Join(k.env.Get("PWD"), "docker-compose.yml"), []byte("\tinvalid"), os.ModePerm)
+
+	if err := k.Execute(nil); err == nil || !strings.Contains(err.Error(), "failed to parse published ports") {
+		t.Errorf("expected parsing error, got %v", err)
+	}
+
+	k = newFakeKoolPortsCheck(t)
+	_ = os.Remove(filepath.Join(k.env.Get("PWD"), "docker-compose.yml"))
+
+	if err := k.Execute(nil); err != nil {
+		t.Errorf("unexpected error without compose files: %v", err)
+	}
+}
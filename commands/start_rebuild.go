@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/services/compose"
+	"path/filepath"
+	"strings"
+)
+
+// KoolRebuildFlags holds the flags for updating the service's images
+type KoolRebuildFlags struct {
+	Services []string
+	NoPull   bool
+}
+
+// KoolRebuild holds handlers for updating the service's images; images
+// built from a local context are only rebuilt when their inputs changed
+type KoolRebuild struct {
+	DefaultKoolService
+	Flags *KoolRebuildFlags
+
+	env    environment.EnvStorage
+	files  compose.FilesAware
+	hashes cache.Cache
+
+	pull, build builder.Command
+	pullImage   builder.Command
+	imageID     builder.Command
+}
+
+// NewKoolRebuild creates a new handler for updating
+// the service's images with default dependencies
+func NewKoolRebuild(flags *KoolRebuildFlags) *KoolRebuild {
+	env := environment.NewEnvStorage()
+
+	return &KoolRebuild{
+		*newDefaultKoolService(),
+		flags,
+		env,
+		compose.NewDockerCompose("config"),
+		cache.NewFileCache(filepath.Join(env.Get("HOME"), ".kool", "cache", "builds.json")),
+		compose.NewDockerCompose("pull"),
+		compose.NewDockerCompose("build"),
+		builder.NewCommand("docker", "pull", "-q"),
+		builder.NewCommand("docker", "image", "inspect", "--format", "{{.Id}}"),
+	}
+}
+
+// Execute pulls the images of the given services (or all of them) and
+// builds the ones whose context, Dockerfile or base images changed
+func (r *KoolRebuild) Execute(args []string) (err error) {
+	var (
+		builds   []compose.ServiceBuild
+		pull     []string
+		build    []string
+		hashes   = make(map[string]string)
+		services = make(map[string]bool)
+		pulled   = make(map[string]bool)
+		dir      = r.env.Get("PWD")
+	)
+
+	if builds, err = r.servicesBuilds(); err != nil {
+		return
+	}
+
+	filter := r.Flags.Services
+	if len(filter) == 0 {
+		filter = args
+	}
+
+	for _, service := range filter {
+		services[service] = true
+	}
+
+	for i := range builds {
+		var (
+			b          = &builds[i]
+			key        = dir + ":" + b.Service
+			hash       string
+			cached     string
+			exists     bool
+			hashFailed error
+		)
+
+		if len(services) > 0 && !services[b.Service] {
+			continue
+		}
+
+		if !b.IsBuilt() {
+			pull = append(pull, b.Service)
+			continue
+		}
+
+		if hash, hashFailed = r.buildHash(b, dir, pulled); hashFailed != nil {
+			// let docker-compose build tell what is wrong
+			build = append(build, b.Service)
+			continue
+		}
+
+		if cached, exists = r.hashes.Get(key); exists && cached == hash && r.imageExists(b) {
+			r.Println("Image of service", b.Service, "is up to date")
+			continue
+		}
+
+		build = append(build, b.Service)
+		hashes[key] = hash
+	}
+
+	if len(pull) > 0 && !r.Flags.NoPull {
+		if err = r.Interactive(r.pull, pull...); err != nil {
+			return
+		}
+	}
+
+	if len(build) == 0 {
+		return
+	}
+
+	if err = r.Interactive(r.build, build...); err != nil {
+		return
+	}
+
+	for key, hash := range hashes {
+		if err = r.hashes.Set(key, hash); err != nil {
+			err = fmt.Errorf("failed to save images build cache: %v", err)
+			return
+		}
+	}
+
+	return
+}
+
+// servicesBuilds reads the image and build settings of
+// all services on the docker-compose files in use
+func (r *KoolRebuild) servicesBuilds() (builds []compose.ServiceBuild, err error) {
+	var (
+		files  []composeFile
+		parsed []compose.ServiceBuild
+		seen   = make(map[string]int)
+	)
+
+	if files, err = readComposeFiles(r.files, r.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if parsed, err = compose.ParseServicesBuilds(file.content); err != nil {
+			err = fmt.Errorf("failed to parse services from %s: %v", file.path, err)
+			return
+		}
+
+		for _, build := range parsed {
+			if i, exists := seen[build.Service]; exists {
+				builds[i].Merge(build)
+				continue
+			}
+
+			seen[build.Service] = len(builds)
+			builds = append(builds, build)
+		}
+	}
+
+	return
+}
+
+// buildHash computes the hash of the service image build inputs,
+// pulling its base images first unless asked not to
+func (r *KoolRebuild) buildHash(b *compose.ServiceBuild, dir string, pulled map[string]bool) (hash string, err error) {
+	var (
+		images []string
+		ids    = make(map[string]string)
+	)
+
+	if images, err = b.BaseImages(dir); err != nil {
+		return
+	}
+
+	for _, image := range images {
+		if !r.Flags.NoPull && !pulled[image] {
+			pulled[image] = true
+			r.Println("Pulling base image", image)
+			// base images built locally by other services cannot
+			// be pulled, so we just go with the local ones
+			_, _ = r.Exec(r.pullImage, image)
+		}
+
+		id, _ := r.Exec(r.imageID, image)
+		ids[image] = strings.TrimSpace(id)
+	}
+
+	hash, err = b.Hash(dir, ids)
+	return
+}
+
+// imageExists tells whether the service image was already built
+func (r *KoolRebuild) imageExists(b *compose.ServiceBuild) bool {
+	image := b.Image
+
+	if image == "" {
+		image = fmt.Sprintf("%s_%s", compose.ProjectName(r.env.Get("KOOL_NAME")), b.Service)
+	}
+
+	_, err := r.Exec(r.imageID, image)
+	return err == nil
+}
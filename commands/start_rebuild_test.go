@@ -0,0 +1,289 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const rebuildCompose = `services:
+  app:
+    build: .
+  database:
+    image: mysql:8.0
+`
+
+func newFakeKoolRebuild(flags *KoolRebuildFlags) *KoolRebuild {
+	env := environment.NewFakeEnvStorage()
+	env.Set("KOOL_NAME", "main")
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &KoolRebuild{
+		*newFakeKoolService(),
+		flags,
+		env,
+		files,
+		&cache.FakeCache{},
+		&builder.FakeCommand{MockCmd: "pull"},
+		&builder.FakeCommand{MockCmd: "build"},
+		&builder.FakeCommand{MockCmd: "pull-image"},
+		&builder.FakeCommand{MockCmd: "image-id", MockExecOut: "sha256:abc\n"},
+	}
+}
+
+func newFakeKoolRebuildProject(t *testing.T) *KoolRebuild {
+	rebuild := newFakeKoolRebuild(&KoolRebuildFlags{})
+	rebuild.shell.(*shell.FakeShell).MockOutStream = io.Discard
+
+	dir := t.TempDir()
+	rebuild.env.Set("PWD", dir)
+
+	for name, content := range map[string]string{
+		"docker-compose.yml": rebuildCompose,
+		"Dockerfile":         "FROM alpine:3.13\nCOPY . /app\n",
+		"main.go":            "package main\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return rebuild
+}
+
+func TestNewKoolRebuild(t *testing.T) {
+	flags := &KoolRebuildFlags{}
+	rebuild := NewKoolRebuild(flags)
+
+	if rebuild.Flags != flags {
+		t.Error("unexpected flags on default KoolRebuild instance")
+	}
+
+	if _, ok := rebuild.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolRebuild instance")
+	}
+
+	if _, ok := rebuild.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default KoolRebuild instance")
+	}
+
+	if _, ok := rebuild.hashes.(*cache.FileCache); !ok {
+		t.Error("unexpected cache.Cache on default KoolRebuild instance")
+	}
+
+	if strings.Contains(rebuild.build.String(), "--pull") {
+		t.Error("base images should be pulled only for the services being built")
+	}
+}
+
+func TestKoolRebuildOnlyBuildsChangedServices(t *testing.T) {
+	rebuild := newFakeKoolRebuildProject(t)
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := rebuild.shell.(*shell.FakeShell)
+
+	if args := fakeShell.ArgsInteractive["pull"]; len(args) != 1 || args[0] != "database" {
+		t.Errorf("should have pulled only the database image, got %v", args)
+	}
+
+	if args := fakeShell.ArgsInteractive["build"]; len(args) != 1 || args[0] != "app" {
+		t.Errorf("should have built the app image, got %v", args)
+	}
+
+	if !fakeShell.CalledExec["pull-image"] {
+		t.Error("should have pulled the base image")
+	}
+
+	key := rebuild.env.Get("PWD") + ":app"
+	if hash, exists := rebuild.hashes.Get(key); !exists || hash == "" {
+		t.Error("should have saved the app build hash")
+	}
+
+	// nothing changed
+	rebuild.shell = &shell.FakeShell{}
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell = rebuild.shell.(*shell.FakeShell)
+
+	if fakeShell.CalledInteractive["build"] {
+		t.Error("should not build an image whose inputs did not change")
+	}
+
+	if len(fakeShell.OutLines) == 0 || fakeShell.OutLines[len(fakeShell.OutLines)-1] != "Image of service app is up to date" {
+		t.Errorf("unexpected output: %v", fakeShell.OutLines)
+	}
+
+	// image missing
+	rebuild.shell = &shell.FakeShell{}
+	rebuild.imageID.(*builder.FakeCommand).MockExecError = errors.New("no such image")
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rebuild.shell.(*shell.FakeShell).CalledInteractive["build"] {
+		t.Error("should build an image that does not exist")
+	}
+
+	// context changed
+	rebuild.shell = &shell.FakeShell{}
+	rebuild.imageID.(*builder.FakeCommand).MockExecError = nil
+
+	if err := os.WriteFile(filepath.Join(rebuild.env.Get("PWD"), "main.go"), []byte("package app\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rebuild.shell.(*shell.FakeShell).CalledInteractive["build"] {
+		t.Error("should build an image whose context changed")
+	}
+
+	// base image changed
+	rebuild.shell = &shell.FakeShell{}
+	rebuild.imageID.(*builder.FakeCommand).MockExecOut = "sha256:def\n"
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rebuild.shell.(*shell.FakeShell).CalledInteractive["build"] {
+		t.Error("should build an image whose base image changed")
+	}
+}
+
+func TestKoolRebuildServicesFilter(t *testing.T) {
+	rebuild := newFakeKoolRebuildProject(t)
+	rebuild.Flags.Services = []string{"database"}
+
+	if err := rebuild.Execute([]string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := rebuild.shell.(*shell.FakeShell)
+
+	if args := fakeShell.ArgsInteractive["pull"]; len(args) != 1 || args[0] != "database" {
+		t.Errorf("should have pulled the database image, got %v", args)
+	}
+
+	if fakeShell.CalledInteractive["build"] || fakeShell.CalledExec["pull-image"] {
+		t.Error("should not update images of services not asked for")
+	}
+
+	rebuild = newFakeKoolRebuildProject(t)
+
+	if err := rebuild.Execute([]string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell = rebuild.shell.(*shell.FakeShell)
+
+	if fakeShell.CalledInteractive["pull"] || !fakeShell.CalledInteractive["build"] {
+		t.Error("should only update the images of the services given as arguments")
+	}
+}
+
+func TestKoolRebuildNoPull(t *testing.T) {
+	rebuild := newFakeKoolRebuildProject(t)
+	rebuild.Flags.NoPull = true
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := rebuild.shell.(*shell.FakeShell)
+
+	if fakeShell.CalledInteractive["pull"] || fakeShell.CalledExec["pull-image"] {
+		t.Error("should not pull images with --no-pull")
+	}
+
+	if !fakeShell.CalledInteractive["build"] {
+		t.Error("should still build the app image with --no-pull")
+	}
+}
+
+func TestKoolRebuildWithoutComposeFiles(t *testing.T) {
+	rebuild := newFakeKoolRebuild(&KoolRebuildFlags{})
+	rebuild.env.Set("PWD", t.TempDir())
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	fakeShell := rebuild.shell.(*shell.FakeShell)
+
+	if fakeShell.CalledInteractive["pull"] || fakeShell.CalledInteractive["build"] {
+		t.Error("should have nothing to update without docker-compose files")
+	}
+}
+
+func TestKoolRebuildErrors(t *testing.T) {
+	rebuild := newFakeKoolRebuildProject(t)
+	rebuild.pull.(*builder.FakeCommand).MockInteractiveError = errors.New("pull error")
+
+	if err := rebuild.Execute(nil); err == nil || err.Error() != "pull error" {
+		t.Errorf("expected pull error, got %v", err)
+	}
+
+	rebuild = newFakeKoolRebuildProject(t)
+	rebuild.build.(*builder.FakeCommand).MockInteractiveError = errors.New("build error")
+
+	if err := rebuild.Execute(nil); err == nil || err.Error() != "build error" {
+		t.Errorf("expected build error, got %v", err)
+	}
+
+	if rebuild.hashes.(*cache.FakeCache).CalledSet != nil {
+		t.Error("should not save the build hash after a failed build")
+	}
+
+	rebuild = newFakeKoolRebuildProject(t)
+	rebuild.hashes.(*cache.FakeCache).MockSetError = errors.New("cache error")
+
+	if err := rebuild.Execute(nil); err == nil || !strings.Contains(err.Error(), "cache error") {
+		t.Errorf("expected cache error, got %v", err)
+	}
+
+	rebuild = newFakeKoolRebuildProject(t)
+
+	if err := os.WriteFile(filepath.Join(rebuild.env.Get("PWD"), "docker-compose.yml"), []byte("services: ["), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rebuild.Execute(nil); err == nil || !strings.Contains(err.Error(), "failed to parse services") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+
+	rebuild = newFakeKoolRebuildProject(t)
+
+	if err := os.Remove(filepath.Join(rebuild.env.Get("PWD"), "Dockerfile")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rebuild.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if !rebuild.shell.(*shell.FakeShell).CalledInteractive["build"] || rebuild.hashes.(*cache.FakeCache).CalledSet != nil {
+		t.Error("should leave it to docker-compose build to fail on a missing Dockerfile")
+	}
+}
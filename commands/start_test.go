@@ -5,10 +5,12 @@ import (
 	"errors"
 	"io"
 	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/cache"
 	"kool-dev/kool/core/environment"
 	"kool-dev/kool/core/network"
 	"kool-dev/kool/core/shell"
 	"kool-dev/kool/services/checker"
+	"os"
 	"strings"
 	"testing"
 
@@ -16,18 +18,21 @@ import (
 )
 
 func newFakeKoolStart() *KoolStart {
+	flags := &KoolStartFlags{}
+
 	return &KoolStart{
 		*newFakeKoolService(),
-		&KoolStartFlags{},
+		flags,
 		&checker.FakeChecker{},
 		&network.FakeHandler{},
 		environment.NewFakeEnvStorage(),
 		&builder.FakeCommand{MockCmd: "start"},
-		&KoolRebuild{
-			*newFakeKoolService(),
-			&builder.FakeCommand{MockCmd: "pull"},
-			&builder.FakeCommand{MockCmd: "build"},
-		},
+		&cache.FakeCache{},
+		newFakeKoolRebuild(&flags.KoolRebuildFlags),
+		newFakeServiceGroups(),
+		&FakeKoolService{},
+		&FakeKoolService{},
+		&FakeKoolService{},
 	}
 }
 
@@ -40,6 +45,10 @@ func TestStartAllCommand(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if dir, _ := os.Getwd(); koolStart.projectDirs.(*cache.FakeCache).MockEntries[""] != dir {
+		t.Errorf("expected the project folder to be recorded, got %v", koolStart.projectDirs.(*cache.FakeCache).MockEntries)
+	}
+
 	interactiveArgs, ok := koolStart.shell.(*shell.FakeShell).ArgsInteractive["start"]
 
 	if ok && len(interactiveArgs) > 0 {
@@ -74,42 +83,51 @@ func TestStartForegroundFlag(t *testing.T) {
 
 func TestStartRebuildFlag(t *testing.T) {
 	koolStart := newFakeKoolStart()
+	koolStart.rebuilder = &FakeKoolService{}
 
 	if err := koolStart.Execute(nil); err != nil {
 		t.Fatal(err)
 	}
 
-	rebuilder := koolStart.rebuilder.(*KoolRebuild)
-	if rebuilder.pull.(*builder.FakeCommand).CalledCmd || rebuilder.build.(*builder.FakeCommand).CalledCmd {
-		t.Error("should not have executed pull or build")
+	if koolStart.rebuilder.(*FakeKoolService).CalledExecute {
+		t.Error("should not have updated the images")
 	}
 
 	koolStart = newFakeKoolStart()
-	rebuilder = koolStart.rebuilder.(*KoolRebuild)
-
+	koolStart.rebuilder = &FakeKoolService{}
 	koolStart.Flags.Rebuild = true
 
-	rebuilder.shell.(*shell.FakeShell).MockOutStream = io.Discard
+	if err := koolStart.Execute([]string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rebuilder := koolStart.rebuilder.(*FakeKoolService)
+	if !rebuilder.CalledExecute || len(rebuilder.ArgsExecute) != 1 || rebuilder.ArgsExecute[0] != "app" {
+		t.Error("should have updated the images of the services being started")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.rebuilder = &FakeKoolService{}
+	koolStart.Flags.Services = []string{"app"}
 
 	if err := koolStart.Execute(nil); err != nil {
 		t.Fatal(err)
 	}
 
-	if !rebuilder.pull.(*builder.FakeCommand).CalledCmd || !rebuilder.build.(*builder.FakeCommand).CalledCmd {
-		t.Error("should have executed pull and build")
+	if !koolStart.rebuilder.(*FakeKoolService).CalledExecute {
+		t.Error("--service should imply --rebuild")
 	}
 
-	rebuilder.pull.(*builder.FakeCommand).MockInteractiveError = errors.New("mock pull error")
+	koolStart = newFakeKoolStart()
+	koolStart.rebuilder = &FakeKoolService{MockExecError: errors.New("rebuild error")}
+	koolStart.Flags.Rebuild = true
 
-	if err := rebuilder.Execute(nil); !errors.Is(err, rebuilder.pull.(*builder.FakeCommand).MockInteractiveError) {
-		t.Error("expected pull error")
+	if err := koolStart.Execute(nil); err == nil || !strings.Contains(err.Error(), "rebuild error") {
+		t.Errorf("expected rebuild error, got %v", err)
 	}
 
-	rebuilder.pull.(*builder.FakeCommand).MockInteractiveError = nil
-	rebuilder.build.(*builder.FakeCommand).MockInteractiveError = errors.New("mock build error")
-
-	if err := rebuilder.Execute(nil); !errors.Is(err, rebuilder.build.(*builder.FakeCommand).MockInteractiveError) {
-		t.Error("expected build error")
+	if koolStart.shell.(*shell.FakeShell).CalledInteractive["start"] {
+		t.Error("should not start containers after failing to update the images")
 	}
 }
 
@@ -134,6 +152,91 @@ func TestStartServicesCommand(t *testing.T) {
 	}
 }
 
+func TestStartPortsCheck(t *testing.T) {
+	koolStart := newFakeKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"app"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	portsCheck := koolStart.portsCheck.(*FakeKoolService)
+	if !portsCheck.CalledExecute || len(portsCheck.ArgsExecute) != 1 || portsCheck.ArgsExecute[0] != "app" {
+		t.Error("did not check ports of the services being started")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.portsCheck.(*FakeKoolService).MockExecError = errors.New("ports")
+
+	cmd = NewStartCommand(koolStart)
+
+	assertExecGotError(t, cmd, "ports")
+
+	if koolStart.shell.(*shell.FakeShell).CalledInteractive["start"] {
+		t.Error("should not start containers after failing to check ports")
+	}
+}
+
+func TestStartNetworkAliasesAndProxy(t *testing.T) {
+	koolStart := newFakeKoolStart()
+
+	cmd := NewStartCommand(koolStart)
+	cmd.SetArgs([]string{"app"})
+
+	if _, err := execStartCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	aliases := koolStart.aliases.(*FakeKoolService)
+	if !aliases.CalledExecute || len(aliases.ArgsExecute) != 1 || aliases.ArgsExecute[0] != "app" {
+		t.Error("did not set the network aliases of the services started")
+	}
+
+	if !koolStart.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("did not route the services domains through the proxy")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.aliases.(*FakeKoolService).MockExecError = errors.New("aliases")
+
+	assertExecGotError(t, NewStartCommand(koolStart), "aliases")
+
+	if koolStart.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should not route domains after failing to set aliases")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.proxy.(*FakeKoolService).MockExecError = errors.New("proxy error")
+
+	if err := koolStart.Execute(nil); err != nil {
+		t.Errorf("should not fail starting the services when the proxy fails, got %v", err)
+	}
+
+	if !koolStart.shell.(*shell.FakeShell).CalledWarning {
+		t.Error("did not warn about failing to route the services domains")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.Flags.Foreground = true
+
+	if err := koolStart.Execute(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if koolStart.aliases.(*FakeKoolService).CalledExecute || koolStart.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should not set network aliases nor route domains after running on foreground")
+	}
+
+	koolStart = newFakeKoolStart()
+	koolStart.start.(*builder.FakeCommand).MockInteractiveError = errors.New("start")
+
+	if err := koolStart.Execute(nil); err == nil || koolStart.aliases.(*FakeKoolService).CalledExecute {
+		t.Error("should not set network aliases after failing to start")
+	}
+}
+
 func TestFailedDependenciesStartCommand(t *testing.T) {
 	koolStart := newFakeKoolStart()
 	koolStart.check.(*checker.FakeChecker).MockError = errors.New("dependencies")
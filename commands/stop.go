@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"fmt"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/services/checker"
 	"kool-dev/kool/services/compose"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -12,6 +14,7 @@ import (
 // KoolStopFlags holds the flags for the kool stop command
 type KoolStopFlags struct {
 	Purge bool
+	KoolServiceGroupsFlags
 }
 
 // KoolStop holds handlers and functions to implement the stop command logic
@@ -19,9 +22,11 @@ type KoolStop struct {
 	DefaultKoolService
 	Flags *KoolStopFlags
 
-	check checker.Checker
-	down  builder.Command
-	rm    builder.Command
+	check  checker.Checker
+	down   builder.Command
+	rm     builder.Command
+	groups *serviceGroups
+	proxy  KoolService
 }
 
 func AddKoolStop(root *cobra.Command) {
@@ -38,10 +43,12 @@ func NewKoolStop() *KoolStop {
 	defaultKoolService := newDefaultKoolService()
 	return &KoolStop{
 		*defaultKoolService,
-		&KoolStopFlags{false},
+		&KoolStopFlags{false, KoolServiceGroupsFlags{}},
 		checker.NewChecker(defaultKoolService.shell),
 		compose.NewDockerCompose("down"),
 		compose.NewDockerCompose("rm"),
+		newServiceGroups(),
+		&KoolProxyStop{NewKoolProxy()},
 	}
 }
 
@@ -53,6 +60,23 @@ func (s *KoolStop) Execute(args []string) (err error) {
 		return
 	}
 
+	if args, err = s.groups.Resolve(&s.Flags.KoolServiceGroupsFlags, args, s.down, s.rm); err != nil {
+		return
+	}
+
+	if len(args) == 0 && len(s.Flags.Profiles) > 0 {
+		// only the profiles services should go down, not the whole project
+		if args, err = s.groups.ProfileServices(s.Flags.Profiles); err != nil {
+			err = fmt.Errorf("failed to resolve profiles services: %v", err)
+			return
+		}
+
+		if len(args) == 0 {
+			err = fmt.Errorf("no services found for profiles %s", strings.Join(s.Flags.Profiles, ", "))
+			return
+		}
+	}
+
 	if len(args) == 0 {
 		s.down.AppendArgs("--remove-orphans")
 
@@ -76,7 +100,11 @@ func (s *KoolStop) Execute(args []string) (err error) {
 		stopCommand = s.rm
 	}
 
-	err = s.Interactive(stopCommand)
+	if err = s.Interactive(stopCommand); err == nil && len(args) == 0 {
+		// all services are gone, so are their domains
+		err = s.proxy.Execute(nil)
+	}
+
 	time.Sleep(time.Second * 2)
 	return
 }
@@ -90,12 +118,16 @@ func NewStopCommand(stop *KoolStop) (stopCmd *cobra.Command) {
 		Use:   "stop [SERVICE...]",
 		Short: "Stop and destroy running service containers",
 		Long: `Stop and destroy the specified [SERVICE] containers, which were started
-using 'kool start'. If no [SERVICE] is provided, all running containers are stopped.`,
+using 'kool start'. If no [SERVICE] is provided, all running containers are stopped.
+Services can also be targeted by groups defined in kool.yml or profiles
+declared in docker-compose.yml (--group), or by docker-compose profiles
+(--profile), in which case only the services of those profiles are stopped.`,
 		RunE: LongTaskCommandRunFunction(task),
 
 		DisableFlagsInUseLine: true,
 	}
 
 	stopCmd.Flags().BoolVarP(&stop.Flags.Purge, "purge", "", false, "Remove all persistent data from volume mounts on containers")
+	addServiceGroupsFlags(stopCmd, &stop.Flags.KoolServiceGroupsFlags)
 	return
 }
@@ -13,10 +13,12 @@ import (
 func newFakeKoolStop() *KoolStop {
 	fs := &KoolStop{
 		*newFakeKoolService(),
-		&KoolStopFlags{false},
+		&KoolStopFlags{false, KoolServiceGroupsFlags{}},
 		&checker.FakeChecker{},
 		&builder.FakeCommand{},
 		&builder.FakeCommand{},
+		newFakeServiceGroups(),
+		&FakeKoolService{},
 	}
 	fs.shell.(*shell.FakeShell).MockErrStream = io.Discard
 	fs.shell.(*shell.FakeShell).MockOutStream = io.Discard
@@ -68,6 +70,26 @@ func TestNewStopCommand(t *testing.T) {
 	if len(f.down.(*builder.FakeCommand).ArgsAppend) > 1 {
 		t.Errorf("did not expect to call 2 AppendArgs on KoolStop.down Command")
 	}
+
+	if !f.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should have removed the project domains from the proxy")
+	}
+}
+
+func TestNewStopCommandProxyError(t *testing.T) {
+	f := newFakeKoolStop()
+	f.proxy.(*FakeKoolService).MockExecError = errors.New("proxy error")
+
+	if err := f.Execute(nil); err == nil || err.Error() != "proxy error" {
+		t.Errorf("expected proxy error, got %v", err)
+	}
+
+	f = newFakeKoolStop()
+	f.down.(*builder.FakeCommand).MockInteractiveError = errors.New("down error")
+
+	if err := f.Execute(nil); err == nil || f.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should not touch the proxy after failing to stop the services")
+	}
 }
 
 func TestNewStopCommandWithArgument(t *testing.T) {
@@ -91,6 +113,10 @@ func TestNewStopCommandWithArgument(t *testing.T) {
 	if appended[2] != "a" && appended[3] != "b" {
 		t.Error("unexpected arguments on services list")
 	}
+
+	if f.proxy.(*FakeKoolService).CalledExecute {
+		t.Error("should keep the project domains on the proxy when stopping some services")
+	}
 }
 
 func TestNewStopPurgeCommand(t *testing.T) {
@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// VolumeArchiveImage holds the image of the throwaway
+// containers reading and writing volumes contents
+const VolumeArchiveImage = "alpine:3.13"
+
+// composeVolumeLabel is the label docker-compose sets on
+// the volumes it creates, holding their key on the project
+const composeVolumeLabel = "com.docker.compose.volume"
+
+// KoolVolume holds the shared logic of the kool volume
+// commands for handling the project named volumes
+type KoolVolume struct {
+	DefaultKoolService
+
+	env   environment.EnvStorage
+	files compose.FilesAware
+	table shell.TableWriter
+
+	listVolumes  builder.Command
+	inspect      builder.Command
+	create       builder.Command
+	inUse        builder.Command
+	runContainer builder.Command
+
+	now func() time.Time
+}
+
+// NewKoolVolume creates a new handler for volume logic with default dependencies
+func NewKoolVolume() *KoolVolume {
+	return &KoolVolume{
+		*newDefaultKoolService(),
+		environment.NewEnvStorage(),
+		compose.NewDockerCompose("config"),
+		shell.NewTableWriter(),
+		builder.NewCommand("docker", "volume", "ls", "--format", "{{.Name}}"),
+		builder.NewCommand("docker", "volume", "inspect"),
+		builder.NewCommand("docker", "volume", "create"),
+		builder.NewCommand("docker", "ps", "-q"),
+		builder.NewCommand("docker", "run", "--rm", "-i"),
+		time.Now,
+	}
+}
+
+// NewVolumeCommand initializes new kool volume command
+func NewVolumeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "volume",
+		Short: "Backup, restore and clone the project volumes",
+		Long: `Backup, restore and clone the named volumes declared on the docker-compose files,
+using throwaway containers for reading and writing their contents.`,
+		Args: cobra.NoArgs,
+
+		DisableFlagsInUseLine: true,
+	}
+}
+
+func AddKoolVolume(root *cobra.Command) {
+	var (
+		volume    = NewKoolVolume()
+		volumeCmd = NewVolumeCommand()
+	)
+
+	root.AddCommand(volumeCmd)
+	volumeCmd.AddCommand(NewVolumeBackupCommand(&KoolVolumeBackup{volume}))
+	volumeCmd.AddCommand(NewVolumeCloneCommand(&KoolVolumeClone{volume, &KoolVolumeCloneFlags{}}))
+	volumeCmd.AddCommand(NewVolumeLsCommand(&KoolVolumeLs{volume}))
+	volumeCmd.AddCommand(NewVolumeRestoreCommand(&KoolVolumeRestore{volume}))
+}
+
+// volumes reads the named volumes declared on the docker-compose files in use
+func (v *KoolVolume) volumes() (volumes []compose.Volume, err error) {
+	var (
+		files  []composeFile
+		parsed []compose.Volume
+		seen   = make(map[string]int)
+	)
+
+	if files, err = readComposeFiles(v.files, v.env.Get("PWD")); err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if parsed, err = compose.ParseVolumes(file.content); err != nil {
+			err = fmt.Errorf("failed to parse volumes from %s: %v", file.path, err)
+			return
+		}
+
+		for _, volume := range parsed {
+			if i, exists := seen[volume.Key]; exists {
+				// later files override the earlier definitions
+				volumes[i] = volume
+				continue
+			}
+
+			seen[volume.Key] = len(volumes)
+			volumes = append(volumes, volume)
+		}
+	}
+
+	return
+}
+
+// volume finds the named volume declared with the given key
+func (v *KoolVolume) volume(key string) (volume compose.Volume, err error) {
+	var volumes []compose.Volume
+
+	if volumes, err = v.volumes(); err != nil {
+		return
+	}
+
+	for _, volume = range volumes {
+		if volume.Key == key {
+			return
+		}
+	}
+
+	err = fmt.Errorf("volume %s is not declared on the docker-compose files", key)
+	return
+}
+
+// project returns the docker-compose project name
+func (v *KoolVolume) project() string {
+	return compose.ProjectName(v.env.Get("KOOL_NAME"))
+}
+
+// createVolume creates the docker volume of the given project with the
+// labels docker-compose sets, so it is taken as its own by docker-compose
+// and by the label-based commands (i.e. kool disk)
+func (v *KoolVolume) createVolume(volume compose.Volume, project string) (err error) {
+	name := volume.DockerName(project)
+
+	if _, err = v.Exec(v.create,
+		"--label", composeProjectLabel+"="+compose.ProjectName(project),
+		"--label", composeVolumeLabel+"="+volume.Key,
+		name,
+	); err != nil {
+		err = fmt.Errorf("failed to create volume %s: %v", name, err)
+	}
+
+	return
+}
+
+// exists tells whether the given docker volume exists
+func (v *KoolVolume) exists(name string) bool {
+	_, err := v.Exec(v.inspect, name)
+	return err == nil
+}
+
+// ensureNotInUse fails when running containers use the given docker volume,
+// as changing its contents under a running database would corrupt it
+func (v *KoolVolume) ensureNotInUse(name string) (err error) {
+	var output string
+
+	if output, err = v.Exec(v.inUse, "--filter", "volume="+name); err != nil {
+		return
+	}
+
+	if strings.TrimSpace(output) != "" {
+		err = fmt.Errorf("volume %s is in use by running containers; stop them first with 'kool stop'", name)
+	}
+
+	return
+}
+
+// runArchiver runs the given script within a throwaway container with the
+// given volumes mounts, streaming its input and output through in and out
+func (v *KoolVolume) runArchiver(in io.Reader, out io.Writer, mounts []string, script string) (err error) {
+	var (
+		args             []string
+		inStream, outStr = v.InStream(), v.OutStream()
+	)
+
+	for _, mount := range mounts {
+		args = append(args, "-v", mount)
+	}
+
+	args = append(args, VolumeArchiveImage, "sh", "-c", script)
+
+	if in != nil {
+		v.SetInStream(in)
+	}
+
+	if out != nil {
+		v.SetOutStream(out)
+	}
+
+	err = v.Interactive(v.runContainer, args...)
+
+	v.SetInStream(inStream)
+	v.SetOutStream(outStr)
+	return
+}
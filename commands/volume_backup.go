@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"kool-dev/kool/services/compose"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolVolumeBackup holds handlers and functions to implement the volume backup command logic
+type KoolVolumeBackup struct {
+	*KoolVolume
+}
+
+// Execute runs the volume backup logic with incoming arguments.
+func (b *KoolVolumeBackup) Execute(args []string) (err error) {
+	var (
+		key    = args[0]
+		volume compose.Volume
+		name   string
+		file   string
+	)
+
+	if volume, err = b.volume(key); err != nil {
+		return
+	}
+
+	if name = volume.DockerName(b.project()); !b.exists(name) {
+		err = fmt.Errorf("volume %s (%s) does not exist yet; start the project to create it", key, name)
+		return
+	}
+
+	if len(args) > 1 {
+		file = args[1]
+	} else {
+		file = filepath.Join(b.env.Get("PWD"), fmt.Sprintf("%s-%s.tar.gz", key, b.now().Format("20060102-150405")))
+	}
+
+	if err = writeArchiveFile(file, true, func(out io.Writer) error {
+		return b.runArchiver(nil, out, []string{name + ":/volume:ro"}, "tar -C /volume -cf - .")
+	}); err != nil {
+		return
+	}
+
+	b.Success("Volume ", key, " backed up to ", file)
+	return
+}
+
+// NewVolumeBackupCommand initializes new kool volume backup command
+func NewVolumeBackupCommand(backup *KoolVolumeBackup) *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup VOLUME [FILE]",
+		Short: "Archive a project volume into a tarball",
+		Long: `Archive the contents of the named VOLUME declared on the docker-compose files into
+a gzip compressed tarball FILE (VOLUME-<date>.tar.gz by default).`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: DefaultCommandRunFunction(backup),
+
+		DisableFlagsInUseLine: true,
+	}
+}
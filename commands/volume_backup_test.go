@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVolumeBackupCommand(t *testing.T) {
+	backup := &KoolVolumeBackup{newFakeKoolVolume(t)}
+	cmd := NewVolumeBackupCommand(backup)
+	cmd.SetArgs([]string{"database"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error backing up volume: %v", err)
+	}
+
+	file := filepath.Join(backup.env.Get("PWD"), "database-20210510-143015.tar.gz")
+
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("did not create the backup tarball: %v", err)
+	}
+
+	args := backup.shell.(*shell.FakeShell).ArgsInteractive["archiver"]
+
+	if len(args) < 2 || args[1] != "main_database:/volume:ro" {
+		t.Errorf("did not mount the project volume read-only: %v", args)
+	}
+
+	if !backup.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the backup")
+	}
+
+	backup = &KoolVolumeBackup{newFakeKoolVolume(t)}
+	file = filepath.Join(backup.env.Get("PWD"), "custom.tgz")
+	cmd = NewVolumeBackupCommand(backup)
+	cmd.SetArgs([]string{"shared", file})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error backing up volume: %v", err)
+	}
+
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("did not create the backup tarball on the given file: %v", err)
+	}
+
+	if args = backup.shell.(*shell.FakeShell).ArgsInteractive["archiver"]; len(args) < 2 || args[1] != "shared-data:/volume:ro" {
+		t.Errorf("did not mount the volume with fixed name: %v", args)
+	}
+}
+
+func TestVolumeBackupCommandErrors(t *testing.T) {
+	backup := &KoolVolumeBackup{newFakeKoolVolume(t)}
+	cmd := NewVolumeBackupCommand(backup)
+	cmd.SetArgs([]string{"missing"})
+
+	assertExecGotError(t, cmd, "volume missing is not declared")
+
+	backup = &KoolVolumeBackup{newFakeKoolVolume(t)}
+	backup.inspect.(*builder.FakeCommand).MockExecError = errors.New("no such volume")
+	cmd = NewVolumeBackupCommand(backup)
+	cmd.SetArgs([]string{"database"})
+
+	assertExecGotError(t, cmd, "volume database (main_database) does not exist yet")
+
+	backup = &KoolVolumeBackup{newFakeKoolVolume(t)}
+	backup.runContainer.(*builder.FakeCommand).MockInteractiveError = errors.New("archiver error")
+	cmd = NewVolumeBackupCommand(backup)
+	cmd.SetArgs([]string{"database"})
+
+	assertExecGotError(t, cmd, "archiver error")
+
+	if entries, _ := os.ReadDir(backup.env.Get("PWD")); len(entries) != 1 {
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("should not leave files behind on failure: %s", strings.Join(names, ", "))
+	}
+}
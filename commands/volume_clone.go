@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"kool-dev/kool/services/compose"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolVolumeCloneFlags holds the flags for the kool volume clone command
+type KoolVolumeCloneFlags struct {
+	To   string
+	From string
+}
+
+// KoolVolumeClone holds handlers and functions to implement the volume clone command logic
+type KoolVolumeClone struct {
+	*KoolVolume
+	Flags *KoolVolumeCloneFlags
+}
+
+// Execute runs the volume clone logic with incoming arguments.
+func (c *KoolVolumeClone) Execute(args []string) (err error) {
+	var (
+		key          = args[0]
+		volume       compose.Volume
+		source, dest string
+		destProject  string
+	)
+
+	if (c.Flags.To == "") == (c.Flags.From == "") {
+		err = errors.New("either --to or --from must be given")
+		return
+	}
+
+	if volume, err = c.volume(key); err != nil {
+		return
+	}
+
+	if volume.HasFixedName() {
+		err = fmt.Errorf("volume %s has a fixed name, so it is shared by all projects", key)
+		return
+	}
+
+	if c.Flags.To != "" {
+		source, destProject = volume.DockerName(c.project()), c.Flags.To
+	} else {
+		source, destProject = volume.DockerName(c.Flags.From), c.project()
+	}
+
+	dest = volume.DockerName(destProject)
+
+	if source == dest {
+		err = fmt.Errorf("cannot clone volume %s onto itself", source)
+		return
+	}
+
+	if !c.exists(source) {
+		err = fmt.Errorf("volume %s does not exist", source)
+		return
+	}
+
+	if err = c.ensureNotInUse(dest); err != nil {
+		return
+	}
+
+	if !c.exists(dest) {
+		if err = c.createVolume(volume, destProject); err != nil {
+			return
+		}
+	}
+
+	if err = c.runArchiver(nil, nil,
+		[]string{source + ":/from:ro", dest + ":/to"},
+		"find /to -mindepth 1 -delete && tar -C /from -cf - . | tar -C /to -xpf -",
+	); err != nil {
+		return
+	}
+
+	c.Success("Volume ", source, " cloned into ", dest)
+	return
+}
+
+// NewVolumeCloneCommand initializes new kool volume clone command
+func NewVolumeCloneCommand(clone *KoolVolumeClone) (cloneCmd *cobra.Command) {
+	cloneCmd = &cobra.Command{
+		Use:   "clone VOLUME --to PROJECT|--from PROJECT",
+		Short: "Clone a project volume into another project",
+		Long: `Clone the contents of the named VOLUME declared on the docker-compose files into
+the same volume of another PROJECT (its KOOL_NAME), or the other way around with --from.
+The contents of the target volume are replaced, so its containers must be stopped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: DefaultCommandRunFunction(clone),
+
+		DisableFlagsInUseLine: true,
+	}
+
+	cloneCmd.Flags().StringVarP(&clone.Flags.To, "to", "", "", "The project (KOOL_NAME) to clone the volume into.")
+	cloneCmd.Flags().StringVarP(&clone.Flags.From, "from", "", "", "The project (KOOL_NAME) to clone the volume from.")
+	return
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"strings"
+	"testing"
+)
+
+func newFakeKoolVolumeClone(t *testing.T) *KoolVolumeClone {
+	return &KoolVolumeClone{newFakeKoolVolume(t), &KoolVolumeCloneFlags{}}
+}
+
+func TestVolumeCloneCommand(t *testing.T) {
+	clone := newFakeKoolVolumeClone(t)
+	cmd := NewVolumeCloneCommand(clone)
+	cmd.SetArgs([]string{"database", "--to", "Feature-X"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error cloning volume: %v", err)
+	}
+
+	args := clone.shell.(*shell.FakeShell).ArgsInteractive["archiver"]
+
+	if len(args) < 4 || args[1] != "main_database:/from:ro" || args[3] != "feature-x_database:/to" {
+		t.Errorf("bad mounts cloning volume into another project: %v", args)
+	}
+
+	if !clone.shell.(*shell.FakeShell).CalledSuccess {
+		t.Error("did not tell about the cloned volume")
+	}
+
+	clone = newFakeKoolVolumeClone(t)
+	cmd = NewVolumeCloneCommand(clone)
+	cmd.SetArgs([]string{"database", "--from", "other"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error cloning volume: %v", err)
+	}
+
+	args = clone.shell.(*shell.FakeShell).ArgsInteractive["archiver"]
+
+	if len(args) < 4 || args[1] != "other_database:/from:ro" || args[3] != "main_database:/to" {
+		t.Errorf("bad mounts cloning volume from another project: %v", args)
+	}
+}
+
+func TestVolumeCloneCommandErrors(t *testing.T) {
+	cases := []struct {
+		args  []string
+		setup func(*KoolVolumeClone)
+		err   string
+	}{
+		{[]string{"database"}, nil, "either --to or --from must be given"},
+		{[]string{"database", "--to", "a", "--from", "b"}, nil, "either --to or --from must be given"},
+		{[]string{"missing", "--to", "other"}, nil, "volume missing is not declared"},
+		{[]string{"shared", "--to", "other"}, nil, "volume shared has a fixed name"},
+		{[]string{"database", "--to", "main"}, nil, "cannot clone volume main_database onto itself"},
+		{[]string{"database", "--to", "other"}, func(c *KoolVolumeClone) {
+			c.inspect.(*builder.FakeCommand).MockExecError = errors.New("no such volume")
+		}, "volume main_database does not exist"},
+		{[]string{"database", "--to", "other"}, func(c *KoolVolumeClone) {
+			c.inUse.(*builder.FakeCommand).MockExecOut = "abc123"
+		}, "volume other_database is in use by running containers"},
+		{[]string{"database", "--to", "other"}, func(c *KoolVolumeClone) {
+			c.runContainer.(*builder.FakeCommand).MockInteractiveError = errors.New("archiver error")
+		}, "archiver error"},
+	}
+
+	for _, tc := range cases {
+		clone := newFakeKoolVolumeClone(t)
+
+		if tc.setup != nil {
+			tc.setup(clone)
+		}
+
+		cmd := NewVolumeCloneCommand(clone)
+		cmd.SetArgs(tc.args)
+
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("expected error '%s' for %v, got %v", tc.err, tc.args, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"kool-dev/kool/services/compose"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolVolumeLs holds handlers and functions to implement the volume ls command logic
+type KoolVolumeLs struct {
+	*KoolVolume
+}
+
+// Execute runs the volume ls logic with incoming arguments.
+func (l *KoolVolumeLs) Execute(args []string) (err error) {
+	var (
+		volumes  []compose.Volume
+		output   string
+		existing = make(map[string]bool)
+	)
+
+	if volumes, err = l.volumes(); err != nil {
+		return
+	}
+
+	if output, err = l.Exec(l.listVolumes); err != nil {
+		return
+	}
+
+	for _, name := range strings.Fields(output) {
+		existing[name] = true
+	}
+
+	l.table.SetWriter(l.OutStream())
+	l.table.AppendHeader("Volume", "Docker Volume", "Created")
+
+	for _, volume := range volumes {
+		var (
+			name    = volume.DockerName(l.project())
+			created = "no"
+		)
+
+		if existing[name] {
+			created = "yes"
+		}
+
+		l.table.AppendRow(volume.Key, name, created)
+	}
+
+	l.table.Render()
+	return
+}
+
+// NewVolumeLsCommand initializes new kool volume ls command
+func NewVolumeLsCommand(ls *KoolVolumeLs) *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls",
+		Short: "List the project volumes",
+		Args:  cobra.NoArgs,
+		RunE:  DefaultCommandRunFunction(ls),
+
+		DisableFlagsInUseLine: true,
+	}
+}
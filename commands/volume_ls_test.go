@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"testing"
+)
+
+func TestVolumeLsCommand(t *testing.T) {
+	ls := &KoolVolumeLs{newFakeKoolVolume(t)}
+	ls.listVolumes.(*builder.FakeCommand).MockExecOut = "main_database\nother_database"
+
+	if err := NewVolumeLsCommand(ls).Execute(); err != nil {
+		t.Errorf("unexpected error listing volumes: %v", err)
+	}
+
+	rows := ls.table.(*shell.FakeTableWriter).Rows
+
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 volumes listed, got %v", rows)
+	}
+
+	if rows[0][0] != "database" || rows[0][1] != "main_database" || rows[0][2] != "yes" {
+		t.Errorf("unexpected database volume row: %v", rows[0])
+	}
+
+	if rows[1][0] != "shared" || rows[1][1] != "shared-data" || rows[1][2] != "no" {
+		t.Errorf("unexpected shared volume row: %v", rows[1])
+	}
+}
+
+func TestVolumeLsCommandError(t *testing.T) {
+	ls := &KoolVolumeLs{newFakeKoolVolume(t)}
+	ls.listVolumes.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+
+	assertExecGotError(t, NewVolumeLsCommand(ls), "docker error")
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"io"
+	"kool-dev/kool/services/compose"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// KoolVolumeRestore holds handlers and functions to implement the volume restore command logic
+type KoolVolumeRestore struct {
+	*KoolVolume
+}
+
+// Execute runs the volume restore logic with incoming arguments.
+func (r *KoolVolumeRestore) Execute(args []string) (err error) {
+	var (
+		key    = args[0]
+		file   = args[1]
+		volume compose.Volume
+		name   string
+	)
+
+	if _, err = os.Stat(file); err != nil {
+		return
+	}
+
+	if volume, err = r.volume(key); err != nil {
+		return
+	}
+
+	name = volume.DockerName(r.project())
+
+	if err = r.ensureNotInUse(name); err != nil {
+		return
+	}
+
+	if !r.exists(name) {
+		if err = r.createVolume(volume, r.project()); err != nil {
+			return
+		}
+	}
+
+	if err = readArchiveFile(file, func(in io.Reader) error {
+		return r.runArchiver(in, nil, []string{name + ":/volume"}, "find /volume -mindepth 1 -delete && tar -C /volume -xpf -")
+	}); err != nil {
+		return
+	}
+
+	r.Success("Volume ", key, " restored from ", file)
+	return
+}
+
+// NewVolumeRestoreCommand initializes new kool volume restore command
+func NewVolumeRestoreCommand(restore *KoolVolumeRestore) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore VOLUME FILE",
+		Short: "Restore a project volume from a tarball",
+		Long: `Replace the contents of the named VOLUME declared on the docker-compose files with
+the ones archived on the tarball FILE. The containers using the volume must be stopped.`,
+		Args: cobra.ExactArgs(2),
+		RunE: DefaultCommandRunFunction(restore),
+
+		DisableFlagsInUseLine: true,
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/shell"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newFakeKoolVolumeRestore(t *testing.T) (restore *KoolVolumeRestore, file string) {
+	restore = &KoolVolumeRestore{newFakeKoolVolume(t)}
+	file = filepath.Join(restore.env.Get("PWD"), "backup.tar.gz")
+
+	if err := writeArchiveFile(file, true, func(w io.Writer) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestVolumeRestoreCommand(t *testing.T) {
+	restore, file := newFakeKoolVolumeRestore(t)
+	cmd := NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error restoring volume: %v", err)
+	}
+
+	fakeShell := restore.shell.(*shell.FakeShell)
+
+	if args := fakeShell.ArgsInteractive["archiver"]; len(args) < 2 || args[1] != "main_database:/volume" {
+		t.Errorf("did not mount the project volume: %v", args)
+	}
+
+	if fakeShell.CalledExec["create"] {
+		t.Error("should not create an existing volume")
+	}
+
+	if !fakeShell.CalledSuccess {
+		t.Error("did not tell about the restored volume")
+	}
+
+	restore, file = newFakeKoolVolumeRestore(t)
+	restore.inspect.(*builder.FakeCommand).MockExecError = errors.New("no such volume")
+	cmd = NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file})
+
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("unexpected error restoring volume: %v", err)
+	}
+
+	if !restore.shell.(*shell.FakeShell).CalledExec["create"] {
+		t.Error("did not create the missing volume")
+	}
+
+	expected := []string{"--label", "com.docker.compose.project=main", "--label", "com.docker.compose.volume=database", "main_database"}
+
+	if args := restore.shell.(*shell.FakeShell).ArgsExec["create"]; !reflect.DeepEqual(args, expected) {
+		t.Errorf("should create the volume with the docker-compose labels; got %v", args)
+	}
+}
+
+func TestVolumeRestoreCommandErrors(t *testing.T) {
+	restore, file := newFakeKoolVolumeRestore(t)
+	cmd := NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file + ".missing"})
+
+	assertExecGotError(t, cmd, "no such file or directory")
+
+	restore, file = newFakeKoolVolumeRestore(t)
+	restore.inUse.(*builder.FakeCommand).MockExecOut = "abc123"
+	cmd = NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file})
+
+	assertExecGotError(t, cmd, "volume main_database is in use by running containers")
+
+	restore, file = newFakeKoolVolumeRestore(t)
+	restore.inspect.(*builder.FakeCommand).MockExecError = errors.New("no such volume")
+	restore.create.(*builder.FakeCommand).MockExecError = errors.New("create error")
+	cmd = NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file})
+
+	assertExecGotError(t, cmd, "failed to create volume main_database: create error")
+
+	restore, file = newFakeKoolVolumeRestore(t)
+	restore.runContainer.(*builder.FakeCommand).MockInteractiveError = errors.New("archiver error")
+	cmd = NewVolumeRestoreCommand(restore)
+	cmd.SetArgs([]string{"database", file})
+
+	assertExecGotError(t, cmd, "archiver error")
+
+	_ = os.Remove(file)
+}
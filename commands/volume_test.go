@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"kool-dev/kool/core/builder"
+	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
+	"kool-dev/kool/core/shell"
+	"kool-dev/kool/services/compose"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const volumeCompose = `services:
+  database:
+    image: mysql:8.0
+    volumes:
+      - database:/var/lib/mysql
+volumes:
+  database:
+  shared:
+    name: shared-data
+`
+
+func newFakeKoolVolume(t *testing.T) *KoolVolume {
+	env := environment.NewFakeEnvStorage()
+	env.Set("PWD", t.TempDir())
+	env.Set("KOOL_NAME", "main")
+
+	if err := os.WriteFile(filepath.Join(env.Get("PWD"), "docker-compose.yml"), []byte(volumeCompose), os.ModePerm); err != nil {
+		t.Fatal("failed creating docker-compose.yml for test", err)
+	}
+
+	files := compose.NewDockerCompose("config")
+	files.SetEnv(env)
+	files.SetKoolParser(&parser.FakeParser{})
+
+	return &KoolVolume{
+		*newFakeKoolService(),
+		env,
+		files,
+		&shell.FakeTableWriter{},
+		&builder.FakeCommand{MockCmd: "list"},
+		&builder.FakeCommand{MockCmd: "inspect"},
+		&builder.FakeCommand{MockCmd: "create"},
+		&builder.FakeCommand{MockCmd: "in-use"},
+		&builder.FakeCommand{MockCmd: "archiver"},
+		func() time.Time {
+			return time.Date(2021, 5, 10, 14, 30, 15, 0, time.UTC)
+		},
+	}
+}
+
+func TestNewKoolVolume(t *testing.T) {
+	v := NewKoolVolume()
+
+	if _, ok := v.env.(*environment.DefaultEnvStorage); !ok {
+		t.Error("unexpected environment.EnvStorage on default KoolVolume instance")
+	}
+
+	if _, ok := v.files.(*compose.DockerCompose); !ok {
+		t.Error("unexpected compose.FilesAware on default KoolVolume instance")
+	}
+
+	if _, ok := v.table.(*shell.DefaultTableWriter); !ok {
+		t.Error("unexpected shell.TableWriter on default KoolVolume instance")
+	}
+
+	if v.runContainer.(*builder.DefaultCommand).String() != "docker run --rm -i" {
+		t.Errorf("unexpected archiver command: %s", v.runContainer.(*builder.DefaultCommand).String())
+	}
+}
+
+func TestNewVolumeCommand(t *testing.T) {
+	cmd := NewVolumeCommand()
+
+	if cmd.Use != "volume" {
+		t.Errorf("expecting volume command Use, got %s", cmd.Use)
+	}
+}
+
+func TestVolumeVolumes(t *testing.T) {
+	v := newFakeKoolVolume(t)
+	_ = os.WriteFile(filepath.Join(v.env.Get("PWD"), "docker-compose.override.yml"), []byte("volumes:\n  database:\n    external: true\n  cache:\n"), os.ModePerm)
+
+	volumes, err := v.volumes()
+
+	if err != nil {
+		t.Fatalf("unexpected error reading volumes: %v", err)
+	}
+
+	if len(volumes) != 3 || volumes[0].Key != "database" || !volumes[0].External || volumes[2].Key != "cache" {
+		t.Errorf("unexpected volumes read: %v", volumes)
+	}
+
+	if _, err = v.volume("missing"); err == nil || !strings.Contains(err.Error(), "volume missing is not declared") {
+		t.Errorf("expected not declared volume error, got %v", err)
+	}
+
+	v = newFakeKoolVolume(t)
+	_ = os.WriteFile(filepath.Join(v.env.Get("PWD"), "docker-compose.yml"), []byte("\tinvalid"), os.ModePerm)
+
+	if _, err = v.volumes(); err == nil || !strings.Contains(err.Error(), "failed to parse volumes") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestVolumeChecks(t *testing.T) {
+	v := newFakeKoolVolume(t)
+
+	if !v.exists("main_database") {
+		t.Error("expected volume to exist")
+	}
+
+	v.inspect.(*builder.FakeCommand).MockExecError = errors.New("no such volume")
+
+	if v.exists("main_database") {
+		t.Error("expected volume to not exist")
+	}
+
+	if err := v.ensureNotInUse("main_database"); err != nil {
+		t.Errorf("unexpected error on volume not in use: %v", err)
+	}
+
+	v.inUse.(*builder.FakeCommand).MockExecOut = "abc123\n"
+
+	if err := v.ensureNotInUse("main_database"); err == nil || !strings.Contains(err.Error(), "in use by running containers") {
+		t.Errorf("expected volume in use error, got %v", err)
+	}
+
+	v.inUse.(*builder.FakeCommand).MockExecError = errors.New("docker error")
+
+	if err := v.ensureNotInUse("main_database"); err == nil || err.Error() != "docker error" {
+		t.Errorf("expected docker error, got %v", err)
+	}
+}
+
+func TestVolumeProject(t *testing.T) {
+	v := newFakeKoolVolume(t)
+	v.env.Set("KOOL_NAME", "My.App")
+
+	if project := v.project(); project != "myapp" {
+		t.Errorf("expected the normalized project name, got %s", project)
+	}
+
+	if err := v.createVolume(compose.Volume{Key: "database"}, "Feature-X"); err != nil {
+		t.Fatalf("unexpected error creating volume: %v", err)
+	}
+
+	expected := []string{"--label", "com.docker.compose.project=feature-x", "--label", "com.docker.compose.volume=database", "feature-x_database"}
+
+	if args := v.shell.(*shell.FakeShell).ArgsExec["create"]; !reflect.DeepEqual(args, expected) {
+		t.Errorf("bad arguments creating volume: %v", args)
+	}
+}
+
+func TestVolumeRunArchiver(t *testing.T) {
+	v := newFakeKoolVolume(t)
+
+	if err := v.runArchiver(&bytes.Buffer{}, &bytes.Buffer{}, []string{"main_database:/volume"}, "tar -C /volume -cf - ."); err != nil {
+		t.Errorf("unexpected error running archiver: %v", err)
+	}
+
+	args := v.shell.(*shell.FakeShell).ArgsInteractive["archiver"]
+	expected := []string{"-v", "main_database:/volume", VolumeArchiveImage, "sh", "-c", "tar -C /volume -cf - ."}
+
+	if strings.Join(args, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected archiver arguments %v, got %v", expected, args)
+	}
+}
@@ -28,6 +28,12 @@ type Command interface {
 	Copy() Command
 }
 
+// Checker holds a function for commands to report, before
+// running, whether they are able to build their arguments
+type Checker interface {
+	Check() error
+}
+
 // Parser holds available methods for parse commands
 type Parser interface {
 	Parse(string) error
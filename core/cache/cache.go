@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MaxEntries is the number of entries a cache file holds
+// before it gets wiped out on the next write
+const MaxEntries int = 500
+
+// Cache holds logic for a persistent key/value store of
+// values costly to compute
+type Cache interface {
+	Get(string) (string, bool)
+	Set(string, string) error
+}
+
+// FileCache implements Cache storing its entries as a JSON file
+type FileCache struct {
+	path string
+
+	mtx     sync.Mutex
+	loaded  bool
+	entries map[string]string
+}
+
+// NewFileCache creates a new cache backed by the given file
+func NewFileCache(path string) *FileCache {
+	return &FileCache{path: path}
+}
+
+// Get returns the cached value for the given key and whether it exists
+func (c *FileCache) Get(key string) (value string, exists bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.load()
+
+	value, exists = c.entries[key]
+	return
+}
+
+// Set stores the value for the given key, persisting the cache file
+func (c *FileCache) Set(key, value string) (err error) {
+	var raw []byte
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.load()
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= MaxEntries {
+		c.entries = make(map[string]string)
+	}
+
+	c.entries[key] = value
+
+	if raw, err = json.Marshal(c.entries); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return
+	}
+
+	err = os.WriteFile(c.path, raw, 0644)
+	return
+}
+
+// load reads the cache file only once; a missing or
+// corrupted file just means an empty cache
+func (c *FileCache) load() {
+	if c.loaded {
+		return
+	}
+
+	c.loaded = true
+	c.entries = make(map[string]string)
+
+	if raw, err := os.ReadFile(c.path); err == nil {
+		if err = json.Unmarshal(raw, &c.entries); err != nil {
+			c.entries = make(map[string]string)
+		}
+	}
+}
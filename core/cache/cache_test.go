@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "test.json")
+	c := NewFileCache(path)
+
+	if _, exists := c.Get("key"); exists {
+		t.Error("unexpected entry on empty cache")
+	}
+
+	if err := c.Set("key", "value"); err != nil {
+		t.Fatalf("unexpected error setting cache entry: %v", err)
+	}
+
+	if err := c.Set("empty", ""); err != nil {
+		t.Fatalf("unexpected error setting cache entry: %v", err)
+	}
+
+	c = NewFileCache(path)
+
+	if value, exists := c.Get("key"); !exists || value != "value" {
+		t.Errorf("expected persisted value 'value', got '%s'", value)
+	}
+
+	if value, exists := c.Get("empty"); !exists || value != "" {
+		t.Errorf("expected persisted empty value, got '%s'", value)
+	}
+}
+
+func TestFileCacheCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+
+	if err := os.WriteFile(path, []byte("{invalid"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewFileCache(path)
+
+	if _, exists := c.Get("key"); exists {
+		t.Error("unexpected entry on corrupted cache")
+	}
+
+	if err := c.Set("key", "value"); err != nil {
+		t.Errorf("unexpected error overwriting corrupted cache: %v", err)
+	}
+}
+
+func TestFileCacheMaxEntries(t *testing.T) {
+	c := NewFileCache(filepath.Join(t.TempDir(), "test.json"))
+	c.loaded = true
+	c.entries = make(map[string]string)
+
+	for i := 0; i < MaxEntries; i++ {
+		c.entries[fmt.Sprintf("key%d", i)] = "x"
+	}
+
+	if err := c.Set("new", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.entries) != 1 {
+		t.Errorf("expected cache to be wiped out when full, got %d entries", len(c.entries))
+	}
+}
+
+func TestFakeCache(t *testing.T) {
+	f := &FakeCache{}
+
+	if _, exists := f.Get("key"); exists || !f.CalledGet["key"] {
+		t.Error("failed asserting fake Get")
+	}
+
+	if err := f.Set("key", "value"); err != nil || !f.CalledSet["key"] || f.MockEntries["key"] != "value" {
+		t.Error("failed asserting fake Set")
+	}
+}
@@ -0,0 +1,41 @@
+package cache
+
+// FakeCache implements an in-memory Cache for tests
+type FakeCache struct {
+	CalledGet map[string]bool
+	CalledSet map[string]bool
+
+	MockEntries  map[string]string
+	MockSetError error
+}
+
+// Get implements fake Get behavior
+func (f *FakeCache) Get(key string) (value string, exists bool) {
+	if f.CalledGet == nil {
+		f.CalledGet = make(map[string]bool)
+	}
+
+	f.CalledGet[key] = true
+	value, exists = f.MockEntries[key]
+	return
+}
+
+// Set implements fake Set behavior
+func (f *FakeCache) Set(key, value string) (err error) {
+	if f.CalledSet == nil {
+		f.CalledSet = make(map[string]bool)
+	}
+
+	f.CalledSet[key] = true
+
+	if err = f.MockSetError; err != nil {
+		return
+	}
+
+	if f.MockEntries == nil {
+		f.MockEntries = make(map[string]string)
+	}
+
+	f.MockEntries[key] = value
+	return
+}
@@ -55,6 +55,8 @@ func InitEnvironmentVariables(envStorage EnvStorage) {
 		envStorage.Set("KOOL_NAME", pieces[len(pieces)-1])
 	}
 
+	initNamespace(envStorage)
+
 	if envStorage.Get("KOOL_GLOBAL_NETWORK") == "" {
 		envStorage.Set("KOOL_GLOBAL_NETWORK", "kool_global")
 	}
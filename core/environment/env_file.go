@@ -0,0 +1,53 @@
+package environment
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// PersistVariables sets the given variables on the environment file,
+// replacing their existing definitions and keeping the rest of the
+// file content untouched. The file is created if it does not exist.
+func PersistVariables(filename string, variables map[string]string) (err error) {
+	var (
+		content []byte
+		lines   []string
+		keys    []string
+		written = make(map[string]bool)
+	)
+
+	if content, err = os.ReadFile(filename); err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	}
+
+	for i, line := range lines {
+		key := strings.TrimSpace(strings.SplitN(line, "=", 2)[0])
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+
+		if value, ok := variables[key]; ok {
+			lines[i] = fmt.Sprintf("%s=%s", key, value)
+			written[key] = true
+		}
+	}
+
+	for key := range variables {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, variables[key]))
+	}
+
+	err = os.WriteFile(filename, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	return
+}
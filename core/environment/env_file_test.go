@@ -0,0 +1,36 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPersistVariables(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env.local")
+
+	if err := PersistVariables(envFile, map[string]string{"KOOL_APP_PORT": "8080"}); err != nil {
+		t.Fatalf("unexpected error creating environment file: %v", err)
+	}
+
+	if content, _ := os.ReadFile(envFile); string(content) != "KOOL_APP_PORT=8080\n" {
+		t.Errorf("unexpected environment file content: %s", content)
+	}
+
+	if err := os.WriteFile(envFile, []byte("# comment\nKOOL_APP_PORT=8080\nexport OTHER=value\n"), os.ModePerm); err != nil {
+		t.Fatal("failed writing environment file for test", err)
+	}
+
+	if err := PersistVariables(envFile, map[string]string{"KOOL_DATABASE_PORT": "3307", "KOOL_APP_PORT": "8081", "KOOL_CACHE_PORT": "6380"}); err != nil {
+		t.Fatalf("unexpected error updating environment file: %v", err)
+	}
+
+	expected := "# comment\nKOOL_APP_PORT=8081\nexport OTHER=value\nKOOL_CACHE_PORT=6380\nKOOL_DATABASE_PORT=3307\n"
+	if content, _ := os.ReadFile(envFile); string(content) != expected {
+		t.Errorf("expected environment file content '%s', got '%s'", expected, content)
+	}
+
+	if err := PersistVariables(t.TempDir(), map[string]string{"KOOL_APP_PORT": "8080"}); err == nil {
+		t.Error("expected error persisting variables to a directory")
+	}
+}
@@ -0,0 +1,131 @@
+package environment
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// NamespaceSeparator separates the project name from the
+// branch or worktree name on namespaced environments
+const NamespaceSeparator = "--"
+
+const (
+	// NamespaceBranch namespaces the project by its git branch
+	NamespaceBranch = "branch"
+	// NamespaceWorktree namespaces the project by its git worktree
+	NamespaceWorktree = "worktree"
+)
+
+var namespaceSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// initNamespace appends the git branch or worktree name to KOOL_NAME
+// when asked to by KOOL_NAMESPACE, keeping the original project name
+// on KOOL_NAME_BASE so child kool processes do not append it again
+func initNamespace(envStorage EnvStorage) {
+	var (
+		mode = envStorage.Get("KOOL_NAMESPACE")
+		base = envStorage.Get("KOOL_NAME")
+		name string
+	)
+
+	if mode == "" || envStorage.Get("KOOL_NAME_BASE") != "" {
+		return
+	}
+
+	gitDir, worktree := findGitDir(envStorage.Get("PWD"))
+
+	switch mode {
+	case NamespaceBranch:
+		if gitDir != "" {
+			name = gitBranch(gitDir)
+		}
+	case NamespaceWorktree:
+		name = worktree
+	default:
+		log.Fatal("Invalid KOOL_NAMESPACE value '", mode, "' - use '", NamespaceBranch, "' or '", NamespaceWorktree, "'")
+	}
+
+	envStorage.Set("KOOL_NAME_BASE", base)
+
+	if name = NamespaceSlug(name); name != "" {
+		envStorage.Set("KOOL_NAME", base+NamespaceSeparator+name)
+	}
+}
+
+// NamespaceSlug turns a branch or worktree name into a
+// suffix that is safe for docker-compose project names
+func NamespaceSlug(name string) string {
+	return strings.Trim(namespaceSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// findGitDir looks up the git directory of the repository holding dir,
+// and the name of the worktree when dir is on a linked worktree
+func findGitDir(dir string) (gitDir, worktree string) {
+	for dir != "" {
+		var (
+			path    = filepath.Join(dir, ".git")
+			info    os.FileInfo
+			content []byte
+			err     error
+		)
+
+		if info, err = os.Stat(path); err == nil {
+			if info.IsDir() {
+				gitDir = path
+				return
+			}
+
+			// linked worktrees have a .git file pointing
+			// to <repository>/.git/worktrees/<name>
+			if content, err = os.ReadFile(path); err != nil {
+				return
+			}
+
+			line := strings.TrimSpace(string(content))
+			if !strings.HasPrefix(line, "gitdir:") {
+				return
+			}
+
+			if gitDir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:")); !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+
+			if filepath.Base(filepath.Dir(gitDir)) == "worktrees" {
+				worktree = filepath.Base(gitDir)
+			}
+			return
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return
+}
+
+// gitBranch reads the current branch of the git directory,
+// or the abbreviated commit hash when on a detached HEAD
+func gitBranch(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	head := strings.TrimSpace(string(content))
+
+	if strings.HasPrefix(head, "ref:") {
+		return strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(head, "ref:")), "refs/heads/")
+	}
+
+	if len(head) > 7 {
+		head = head[:7]
+	}
+
+	return head
+}
@@ -0,0 +1,167 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newNamespaceRepository(t *testing.T, head string) (repo string) {
+	repo = filepath.Join(t.TempDir(), "app")
+
+	if err := os.MkdirAll(filepath.Join(repo, ".git", "worktrees", "review"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte(head), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestInitNamespaceBranch(t *testing.T) {
+	repo := newNamespaceRepository(t, "ref: refs/heads/Feature/Login_Page\n")
+	subdir := filepath.Join(repo, "src")
+
+	if err := os.Mkdir(subdir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFakeEnvStorage()
+	f.Set("PWD", subdir)
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceBranch)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app--feature-login-page" {
+		t.Errorf("expecting KOOL_NAME 'app--feature-login-page', got '%s'", name)
+	}
+
+	if base := f.Envs["KOOL_NAME_BASE"]; base != "app" {
+		t.Errorf("expecting KOOL_NAME_BASE 'app', got '%s'", base)
+	}
+
+	// child processes inherit the namespaced name
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app--feature-login-page" {
+		t.Errorf("should not namespace KOOL_NAME twice, got '%s'", name)
+	}
+}
+
+func TestInitNamespaceDetachedHead(t *testing.T) {
+	repo := newNamespaceRepository(t, "0123456789abcdef0123456789abcdef01234567\n")
+
+	f := NewFakeEnvStorage()
+	f.Set("PWD", repo)
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceBranch)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app--0123456" {
+		t.Errorf("expecting KOOL_NAME 'app--0123456', got '%s'", name)
+	}
+}
+
+func TestInitNamespaceWorktree(t *testing.T) {
+	var (
+		repo     = newNamespaceRepository(t, "ref: refs/heads/main\n")
+		worktree = filepath.Join(filepath.Dir(repo), "app-review")
+		gitFile  = "gitdir: " + filepath.Join(repo, ".git", "worktrees", "review") + "\n"
+	)
+
+	if err := os.Mkdir(worktree, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte(gitFile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, ".git", "worktrees", "review", "HEAD"), []byte("ref: refs/heads/pr-42\n"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFakeEnvStorage()
+	f.Set("PWD", worktree)
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceWorktree)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app--review" {
+		t.Errorf("expecting KOOL_NAME 'app--review', got '%s'", name)
+	}
+
+	f = NewFakeEnvStorage()
+	f.Set("PWD", worktree)
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceBranch)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app--pr-42" {
+		t.Errorf("expecting KOOL_NAME 'app--pr-42', got '%s'", name)
+	}
+
+	// the main worktree is not namespaced
+	f = NewFakeEnvStorage()
+	f.Set("PWD", repo)
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceWorktree)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app" {
+		t.Errorf("expecting KOOL_NAME 'app', got '%s'", name)
+	}
+
+	if base := f.Envs["KOOL_NAME_BASE"]; base != "app" {
+		t.Errorf("expecting KOOL_NAME_BASE 'app', got '%s'", base)
+	}
+}
+
+func TestInitNamespaceDisabled(t *testing.T) {
+	repo := newNamespaceRepository(t, "ref: refs/heads/feature\n")
+
+	f := NewFakeEnvStorage()
+	f.Set("PWD", repo)
+	f.Set("KOOL_NAME", "app")
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app" {
+		t.Errorf("expecting KOOL_NAME 'app', got '%s'", name)
+	}
+
+	if _, exists := f.Envs["KOOL_NAME_BASE"]; exists {
+		t.Error("should not set KOOL_NAME_BASE when not namespacing")
+	}
+
+	// outside of a git repository
+	f = NewFakeEnvStorage()
+	f.Set("PWD", t.TempDir())
+	f.Set("KOOL_NAME", "app")
+	f.Set("KOOL_NAMESPACE", NamespaceBranch)
+
+	initNamespace(f)
+
+	if name := f.Envs["KOOL_NAME"]; name != "app" {
+		t.Errorf("expecting KOOL_NAME 'app', got '%s'", name)
+	}
+}
+
+func TestNamespaceSlug(t *testing.T) {
+	for name, expected := range map[string]string{
+		"feature/JIRA-123_login": "feature-jira-123-login",
+		"--main--":               "main",
+		"":                       "",
+	} {
+		if slug := NamespaceSlug(name); slug != expected {
+			t.Errorf("expecting slug '%s' for '%s', got '%s'", expected, name, slug)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package network
+
+// FakePortChecker implements all fake behaviors for using port checker in tests.
+type FakePortChecker struct {
+	CalledIsAvailable map[string]bool
+	CalledFreePort    map[string]bool
+
+	MockUnavailable map[string]bool
+	MockFreePort    map[string]string
+	MockError       error
+}
+
+// IsAvailable implements fake IsAvailable behavior
+func (f *FakePortChecker) IsAvailable(port string) bool {
+	if f.CalledIsAvailable == nil {
+		f.CalledIsAvailable = make(map[string]bool)
+	}
+
+	f.CalledIsAvailable[port] = true
+	return !f.MockUnavailable[port]
+}
+
+// FreePort implements fake FreePort behavior
+func (f *FakePortChecker) FreePort(port string) (free string, err error) {
+	if f.CalledFreePort == nil {
+		f.CalledFreePort = make(map[string]bool)
+	}
+
+	f.CalledFreePort[port] = true
+	free = f.MockFreePort[port]
+	err = f.MockError
+	return
+}
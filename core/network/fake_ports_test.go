@@ -0,0 +1,31 @@
+package network
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFakePortChecker(t *testing.T) {
+	f := &FakePortChecker{
+		MockUnavailable: map[string]bool{"3306": true},
+		MockFreePort:    map[string]string{"3306": "3307"},
+	}
+
+	if f.IsAvailable("3306") || !f.CalledIsAvailable["3306"] {
+		t.Error("failed to use mocked IsAvailable function on FakePortChecker")
+	}
+
+	if !f.IsAvailable("80") {
+		t.Error("failed to use mocked IsAvailable function on FakePortChecker")
+	}
+
+	if free, err := f.FreePort("3306"); err != nil || free != "3307" || !f.CalledFreePort["3306"] {
+		t.Error("failed to use mocked FreePort function on FakePortChecker")
+	}
+
+	f.MockError = errors.New("fake error")
+
+	if _, err := f.FreePort("3306"); err == nil || err.Error() != "fake error" {
+		t.Error("failed to use mocked FreePort error on FakePortChecker")
+	}
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// maxFreePortAttempts holds how many subsequent ports are tried
+// before falling back to a random port given by the OS
+const maxFreePortAttempts = 100
+
+// PortChecker defines the host ports checking methods
+type PortChecker interface {
+	IsAvailable(string) bool
+	FreePort(string) (string, error)
+}
+
+// DefaultPortChecker holds logic for checking host ports
+type DefaultPortChecker struct{}
+
+// NewPortChecker initializes a host ports checker
+func NewPortChecker() *DefaultPortChecker {
+	return &DefaultPortChecker{}
+}
+
+// IsAvailable tells whether the given TCP port can be bound on the host
+func (c *DefaultPortChecker) IsAvailable(port string) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+
+	if err != nil {
+		return false
+	}
+
+	listener.Close()
+	return true
+}
+
+// FreePort finds an available TCP port on the host, starting
+// right after the given port
+func (c *DefaultPortChecker) FreePort(port string) (free string, err error) {
+	var (
+		listener net.Listener
+		start    int
+	)
+
+	if start, err = strconv.Atoi(port); err != nil {
+		err = fmt.Errorf("invalid port %s: %v", port, err)
+		return
+	}
+
+	for candidate := start + 1; candidate <= start+maxFreePortAttempts && candidate <= 65535; candidate++ {
+		if free = strconv.Itoa(candidate); c.IsAvailable(free) {
+			return
+		}
+	}
+
+	if listener, err = net.Listen("tcp", ":0"); err != nil {
+		free = ""
+		return
+	}
+
+	defer listener.Close()
+
+	free = strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	return
+}
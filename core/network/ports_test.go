@@ -0,0 +1,63 @@
+package network
+
+import (
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestNewPortChecker(t *testing.T) {
+	var c PortChecker = NewPortChecker()
+
+	if _, assert := c.(*DefaultPortChecker); !assert {
+		t.Errorf("NewPortChecker() did not return a *DefaultPortChecker")
+	}
+}
+
+func TestPortCheckerIsAvailable(t *testing.T) {
+	c := NewPortChecker()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal("failed to listen on a random port for test", err)
+	}
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	if c.IsAvailable(port) {
+		t.Errorf("port %s should not be available while in use", port)
+	}
+
+	listener.Close()
+
+	if !c.IsAvailable(port) {
+		t.Errorf("port %s should be available after being released", port)
+	}
+}
+
+func TestPortCheckerFreePort(t *testing.T) {
+	c := NewPortChecker()
+
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal("failed to listen on a random port for test", err)
+	}
+
+	defer listener.Close()
+
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+
+	free, err := c.FreePort(port)
+
+	if err != nil {
+		t.Errorf("unexpected error finding a free port: %v", err)
+	}
+
+	if free == "" || free == port {
+		t.Errorf("unexpected free port %s for busy port %s", free, port)
+	}
+
+	if _, err = c.FreePort("invalid"); err == nil {
+		t.Error("expected error finding free port for invalid port")
+	}
+}
@@ -18,6 +18,18 @@ func IsMultipleDefinedScriptError(err error) bool {
 // ErrKoolYmlNotFound means there was no kool.yml file in the targeted folders
 var ErrKoolYmlNotFound = errors.New("could not find any kool.yml file")
 
+// ErrGroupNotFound means the services group asked for was not
+// found within the kool.yml files targeted
+var ErrGroupNotFound = errors.New("group was not found in any kool.yml file")
+
+// ErrComposeEnvironmentNotFound means the docker-compose files environment
+// asked for was not found within the kool.yml files targeted
+var ErrComposeEnvironmentNotFound = errors.New("compose environment was not found in any kool.yml file")
+
+// ErrServiceNotFound means there are no settings for the service
+// asked for within the kool.yml files targeted
+var ErrServiceNotFound = errors.New("service was not found in any kool.yml file")
+
 // ErrPossibleTypo implements error interface and can be used
 // to determine specific situations of not-found scripts but
 // where similar names exist, indicating a possible typo
@@ -15,6 +15,20 @@ type FakeParser struct {
 	MockParseError                 map[string]error
 	MockScripts                    []string
 	MockParseAvailableScriptsError error
+	CalledParseGroup               bool
+	MockGroups                     map[string][]string
+	MockParseGroupError            map[string]error
+	CalledParseComposeFiles        bool
+	MockComposeFiles               map[string][]string
+	MockParseComposeFilesError     map[string]error
+	CalledParseService             bool
+	MockServices                   map[string]*KoolYamlService
+	MockParseServiceError          map[string]error
+	CalledParseServices            bool
+	MockParseServicesError         error
+	CalledParseDefaultService      bool
+	MockDefaultService             string
+	MockParseDefaultServiceError   error
 }
 
 // AddLookupPath implements fake AddLookupPath behavior
@@ -49,3 +63,43 @@ func (f *FakeParser) ParseAvailableScripts(filter string) (scripts []string, err
 	err = f.MockParseAvailableScriptsError
 	return
 }
+
+// ParseGroup implements fake ParseGroup behavior
+func (f *FakeParser) ParseGroup(group string) (services []string, err error) {
+	f.CalledParseGroup = true
+	services = f.MockGroups[group]
+	err = f.MockParseGroupError[group]
+	return
+}
+
+// ParseComposeFiles implements fake ParseComposeFiles behavior
+func (f *FakeParser) ParseComposeFiles(environment string) (files []string, err error) {
+	f.CalledParseComposeFiles = true
+	files = f.MockComposeFiles[environment]
+	err = f.MockParseComposeFilesError[environment]
+	return
+}
+
+// ParseService implements fake ParseService behavior
+func (f *FakeParser) ParseService(service string) (settings *KoolYamlService, err error) {
+	f.CalledParseService = true
+	settings = f.MockServices[service]
+	err = f.MockParseServiceError[service]
+	return
+}
+
+// ParseServices implements fake ParseServices behavior
+func (f *FakeParser) ParseServices() (services map[string]*KoolYamlService, err error) {
+	f.CalledParseServices = true
+	services = f.MockServices
+	err = f.MockParseServicesError
+	return
+}
+
+// ParseDefaultService implements fake ParseDefaultService behavior
+func (f *FakeParser) ParseDefaultService() (service string, err error) {
+	f.CalledParseDefaultService = true
+	service = f.MockDefaultService
+	err = f.MockParseDefaultServiceError
+	return
+}
@@ -74,3 +74,88 @@ func TestFakeFailedParser(t *testing.T) {
 		t.Error("failed to use mocked failing ParseAvailableScripts function on FakeParser")
 	}
 }
+
+func TestFakeParserParseGroup(t *testing.T) {
+	f := &FakeParser{
+		MockGroups: map[string][]string{
+			"group": {"app"},
+		},
+		MockParseGroupError: map[string]error{
+			"invalid": errors.New("group error"),
+		},
+	}
+
+	if services, err := f.ParseGroup("group"); !f.CalledParseGroup || err != nil || len(services) != 1 || services[0] != "app" {
+		t.Error("failed to use mocked ParseGroup function on FakeParser")
+	}
+
+	if _, err := f.ParseGroup("invalid"); err == nil || err.Error() != "group error" {
+		t.Error("failed to use mocked ParseGroup error on FakeParser")
+	}
+}
+
+func TestFakeParserParseComposeFiles(t *testing.T) {
+	f := &FakeParser{
+		MockComposeFiles: map[string][]string{
+			"ci": {"docker-compose.yml", "docker-compose.ci.yml"},
+		},
+		MockParseComposeFilesError: map[string]error{
+			"invalid": errors.New("compose error"),
+		},
+	}
+
+	if files, err := f.ParseComposeFiles("ci"); !f.CalledParseComposeFiles || err != nil || len(files) != 2 {
+		t.Error("failed to use mocked ParseComposeFiles function on FakeParser")
+	}
+
+	if _, err := f.ParseComposeFiles("invalid"); err == nil || err.Error() != "compose error" {
+		t.Error("failed to use mocked ParseComposeFiles error on FakeParser")
+	}
+}
+
+func TestFakeParserParseService(t *testing.T) {
+	f := &FakeParser{
+		MockServices: map[string]*KoolYamlService{
+			"app": {User: "kool"},
+		},
+		MockParseServiceError: map[string]error{
+			"invalid": errors.New("service error"),
+		},
+	}
+
+	if settings, err := f.ParseService("app"); !f.CalledParseService || err != nil || settings.User != "kool" {
+		t.Error("failed to use mocked ParseService function on FakeParser")
+	}
+
+	if _, err := f.ParseService("invalid"); err == nil || err.Error() != "service error" {
+		t.Error("failed to use mocked ParseService error on FakeParser")
+	}
+}
+
+func TestFakeParserParseServices(t *testing.T) {
+	f := &FakeParser{MockServices: map[string]*KoolYamlService{"app": {User: "kool"}}}
+
+	if services, err := f.ParseServices(); !f.CalledParseServices || err != nil || len(services) != 1 || services["app"].User != "kool" {
+		t.Error("failed to use mocked ParseServices function on FakeParser")
+	}
+
+	f.MockParseServicesError = errors.New("services error")
+
+	if _, err := f.ParseServices(); err == nil || err.Error() != "services error" {
+		t.Error("failed to use mocked ParseServices error on FakeParser")
+	}
+}
+
+func TestFakeParserParseDefaultService(t *testing.T) {
+	f := &FakeParser{MockDefaultService: "app"}
+
+	if service, err := f.ParseDefaultService(); !f.CalledParseDefaultService || err != nil || service != "app" {
+		t.Error("failed to use mocked ParseDefaultService function on FakeParser")
+	}
+
+	f.MockParseDefaultServiceError = errors.New("default service error")
+
+	if _, err := f.ParseDefaultService(); err == nil || err.Error() != "default service error" {
+		t.Error("failed to use mocked ParseDefaultService error on FakeParser")
+	}
+}
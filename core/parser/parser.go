@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"errors"
 	"os"
 	"path"
 	"sort"
@@ -15,6 +14,11 @@ type Parser interface {
 	AddLookupPath(string) error
 	Parse(string) ([]builder.Command, error)
 	ParseAvailableScripts(string) ([]string, error)
+	ParseGroup(string) ([]string, error)
+	ParseComposeFiles(string) ([]string, error)
+	ParseService(string) (*KoolYamlService, error)
+	ParseServices() (map[string]*KoolYamlService, error)
+	ParseDefaultService() (string, error)
 }
 
 // DefaultParser implements all default behavior for using kool.yml files.
@@ -72,7 +76,7 @@ func (p *DefaultParser) Parse(script string) (commands []builder.Command, err er
 	)
 
 	if len(p.targetFiles) == 0 {
-		err = errors.New("kool.yml not found")
+		err = ErrKoolYmlNotFound
 		return
 	}
 
@@ -118,7 +122,7 @@ func (p *DefaultParser) ParseAvailableScripts(filter string) (scripts []string,
 	)
 
 	if len(p.targetFiles) == 0 {
-		err = errors.New("kool.yml not found")
+		err = ErrKoolYmlNotFound
 		return
 	}
 
@@ -142,3 +146,146 @@ func (p *DefaultParser) ParseAvailableScripts(filter string) (scripts []string,
 
 	return
 }
+
+// ParseGroup looks up for the given services group on all of the kool.yml files
+// available on the configured lookup paths, returning the services it holds.
+// If the group exists in more than one file the first occurrence is used.
+func (p *DefaultParser) ParseGroup(group string) (services []string, err error) {
+	var (
+		koolFile   string
+		parsedFile *KoolYaml
+	)
+
+	if len(p.targetFiles) == 0 {
+		err = ErrKoolYmlNotFound
+		return
+	}
+
+	for _, koolFile = range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		if parsedFile.HasGroup(group) {
+			services = parsedFile.Groups[group]
+			return
+		}
+	}
+
+	err = ErrGroupNotFound
+	return
+}
+
+// ParseComposeFiles looks up for the given environment on the compose section of
+// all kool.yml files available on the configured lookup paths, returning the list
+// of docker-compose files to be layered. The first occurrence found is used.
+func (p *DefaultParser) ParseComposeFiles(environment string) (files []string, err error) {
+	var (
+		koolFile   string
+		parsedFile *KoolYaml
+	)
+
+	if len(p.targetFiles) == 0 {
+		err = ErrKoolYmlNotFound
+		return
+	}
+
+	for _, koolFile = range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		if parsedFile.HasComposeEnvironment(environment) {
+			files = parsedFile.Compose[environment]
+			return
+		}
+	}
+
+	err = ErrComposeEnvironmentNotFound
+	return
+}
+
+// ParseService looks up for the settings of the given service on all of the
+// kool.yml files available on the configured lookup paths. The first
+// occurrence found is used.
+func (p *DefaultParser) ParseService(service string) (settings *KoolYamlService, err error) {
+	var (
+		koolFile   string
+		parsedFile *KoolYaml
+	)
+
+	if len(p.targetFiles) == 0 {
+		err = ErrKoolYmlNotFound
+		return
+	}
+
+	for _, koolFile = range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		if parsedFile.HasService(service) {
+			settings = parsedFile.Services[service]
+			return
+		}
+	}
+
+	err = ErrServiceNotFound
+	return
+}
+
+// ParseServices looks up for the settings of all services on all of the kool.yml
+// files available on the configured lookup paths. The first occurrence of each service is used.
+func (p *DefaultParser) ParseServices() (services map[string]*KoolYamlService, err error) {
+	var (
+		koolFile   string
+		parsedFile *KoolYaml
+	)
+
+	if len(p.targetFiles) == 0 {
+		err = ErrKoolYmlNotFound
+		return
+	}
+
+	services = make(map[string]*KoolYamlService)
+
+	for _, koolFile = range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		for service := range parsedFile.Services {
+			if _, exists := services[service]; !exists && parsedFile.HasService(service) {
+				services[service] = parsedFile.Services[service]
+			}
+		}
+	}
+
+	return
+}
+
+// ParseDefaultService looks up for the default service on all of the kool.yml
+// files available on the configured lookup paths. The first occurrence found is used.
+func (p *DefaultParser) ParseDefaultService() (service string, err error) {
+	var (
+		koolFile   string
+		parsedFile *KoolYaml
+	)
+
+	if len(p.targetFiles) == 0 {
+		err = ErrKoolYmlNotFound
+		return
+	}
+
+	for _, koolFile = range p.targetFiles {
+		if parsedFile, err = ParseKoolYaml(koolFile); err != nil {
+			return
+		}
+
+		if service = parsedFile.DefaultService; service != "" {
+			return
+		}
+	}
+
+	return
+}
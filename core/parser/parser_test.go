@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"kool-dev/kool/core/builder"
 	"os"
 	"path"
@@ -67,8 +68,8 @@ func TestParserParse(t *testing.T) {
 		t.Error("expecting 'kool.yml not found' error, got none")
 	}
 
-	if err != nil && err.Error() != "kool.yml not found" {
-		t.Errorf("expecting error 'kool.yml not found', got '%s'", err.Error())
+	if err != nil && !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got '%s'", err.Error())
 	}
 
 	workDir, _ := os.Getwd()
@@ -108,8 +109,8 @@ func TestParserParseAvailableScripts(t *testing.T) {
 		t.Error("expecting 'kool.yml not found' error, got none")
 	}
 
-	if err != nil && err.Error() != "kool.yml not found" {
-		t.Errorf("expecting error 'kool.yml not found', got '%s'", err.Error())
+	if err != nil && !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got '%s'", err.Error())
 	}
 
 	workDir, _ := os.Getwd()
@@ -150,3 +151,130 @@ func TestParserParseAvailableScriptsFilter(t *testing.T) {
 		t.Error("failed to get filtered scripts from kool.yml")
 	}
 }
+
+func TestParserParseGroup(t *testing.T) {
+	var (
+		p        Parser = NewParser()
+		services []string
+		err      error
+	)
+
+	if _, err = p.ParseGroup("backend"); !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got %v", err)
+	}
+
+	workDir, _ := os.Getwd()
+	_ = p.AddLookupPath(path.Join(workDir, "testing_files"))
+
+	if services, err = p.ParseGroup("backend"); err != nil {
+		t.Errorf("unexpected error; error: %s", err)
+	}
+
+	if len(services) != 2 || services[0] != "app" || services[1] != "database" {
+		t.Errorf("failed to parse group from kool.yml; got %v", services)
+	}
+
+	if _, err = p.ParseGroup("invalid"); err != ErrGroupNotFound {
+		t.Errorf("expecting ErrGroupNotFound; got %v", err)
+	}
+}
+
+func TestParserParseComposeFiles(t *testing.T) {
+	var (
+		p     Parser = NewParser()
+		files []string
+		err   error
+	)
+
+	if _, err = p.ParseComposeFiles("ci"); !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got %v", err)
+	}
+
+	workDir, _ := os.Getwd()
+	_ = p.AddLookupPath(path.Join(workDir, "testing_files"))
+
+	if files, err = p.ParseComposeFiles("ci"); err != nil {
+		t.Errorf("unexpected error; error: %s", err)
+	}
+
+	if len(files) != 2 || files[0] != "docker-compose.yml" || files[1] != "docker-compose.ci.yml" {
+		t.Errorf("failed to parse compose files from kool.yml; got %v", files)
+	}
+
+	if _, err = p.ParseComposeFiles("debug"); err != ErrComposeEnvironmentNotFound {
+		t.Errorf("expecting ErrComposeEnvironmentNotFound; got %v", err)
+	}
+}
+
+func TestParserParseService(t *testing.T) {
+	var (
+		p        Parser = NewParser()
+		settings *KoolYamlService
+		err      error
+	)
+
+	if _, err = p.ParseService("app"); !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got %v", err)
+	}
+
+	workDir, _ := os.Getwd()
+	_ = p.AddLookupPath(path.Join(workDir, "testing_files"))
+
+	if settings, err = p.ParseService("app"); err != nil {
+		t.Errorf("unexpected error; error: %s", err)
+	}
+
+	if settings == nil || settings.User != "kool" || settings.Shell != "zsh" {
+		t.Errorf("failed to parse service settings from kool.yml; got %v", settings)
+	}
+
+	if _, err = p.ParseService("database"); err != ErrServiceNotFound {
+		t.Errorf("expecting ErrServiceNotFound; got %v", err)
+	}
+}
+
+func TestParserParseServices(t *testing.T) {
+	var (
+		p        Parser = NewParser()
+		services map[string]*KoolYamlService
+		err      error
+	)
+
+	if _, err = p.ParseServices(); err != ErrKoolYmlNotFound {
+		t.Errorf("expecting ErrKoolYmlNotFound, got %v", err)
+	}
+
+	workDir, _ := os.Getwd()
+	_ = p.AddLookupPath(path.Join(workDir, "testing_files"))
+
+	if services, err = p.ParseServices(); err != nil {
+		t.Errorf("unexpected error; error: %s", err)
+	}
+
+	if len(services) != 1 || services["app"] == nil || len(services["app"].Aliases) != 1 || services["app"].Aliases[0] != "api.kool.local" {
+		t.Errorf("failed to parse services settings from kool.yml; got %v", services)
+	}
+}
+
+func TestParserParseDefaultService(t *testing.T) {
+	var (
+		p       Parser = NewParser()
+		service string
+		err     error
+	)
+
+	if _, err = p.ParseDefaultService(); !errors.Is(err, ErrKoolYmlNotFound) {
+		t.Errorf("expecting ErrKoolYmlNotFound, got %v", err)
+	}
+
+	workDir, _ := os.Getwd()
+	_ = p.AddLookupPath(path.Join(workDir, "testing_files"))
+
+	if service, err = p.ParseDefaultService(); err != nil {
+		t.Errorf("unexpected error; error: %s", err)
+	}
+
+	if service != "app" {
+		t.Errorf("failed to parse default service from kool.yml; got %s", service)
+	}
+}
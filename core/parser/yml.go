@@ -18,7 +18,22 @@ type yamlMarshalFnType func(interface{}) ([]byte, error)
 
 // KoolYaml holds the structure for parsing the custom commands file
 type KoolYaml struct {
-	Scripts map[string]interface{} `yaml:"scripts"`
+	Scripts  map[string]interface{}      `yaml:"scripts"`
+	Groups   map[string][]string         `yaml:"groups,omitempty"`
+	Compose  map[string][]string         `yaml:"compose,omitempty"`
+	Services map[string]*KoolYamlService `yaml:"services,omitempty"`
+
+	DefaultService string `yaml:"default_service,omitempty"`
+
+	// Others holds the remaining top-level keys, so they are kept when writing kool.yml back
+	Others map[string]interface{} `yaml:",inline"`
+}
+
+// KoolYamlService holds kool settings for a single docker-compose service
+type KoolYamlService struct {
+	User    string   `yaml:"user,omitempty"`
+	Shell   string   `yaml:"shell,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
 }
 
 // KoolYamlParser holds logic for handling kool yaml
@@ -64,6 +79,11 @@ func (y *KoolYaml) Parse(filePath string) (err error) {
 	}
 
 	y.Scripts = parsed.Scripts
+	y.Groups = parsed.Groups
+	y.Compose = parsed.Compose
+	y.Services = parsed.Services
+	y.DefaultService = parsed.DefaultService
+	y.Others = parsed.Others
 	return
 }
 
@@ -75,6 +95,31 @@ func (y *KoolYaml) HasScript(script string) (has bool) {
 	return
 }
 
+// HasGroup tells if the given services group exists on this parsed YAML.
+func (y *KoolYaml) HasGroup(group string) (has bool) {
+	if y.Groups != nil {
+		_, has = y.Groups[group]
+	}
+	return
+}
+
+// HasComposeEnvironment tells if the given docker-compose files
+// environment exists on this parsed YAML.
+func (y *KoolYaml) HasComposeEnvironment(environment string) (has bool) {
+	if y.Compose != nil {
+		_, has = y.Compose[environment]
+	}
+	return
+}
+
+// HasService tells if the given service settings exist on this parsed YAML.
+func (y *KoolYaml) HasService(service string) (has bool) {
+	if y.Services != nil {
+		has = y.Services[service] != nil
+	}
+	return
+}
+
 // GetSimilars checks for scripts with similar name.
 func (y *KoolYaml) GetSimilars(script string) (has bool, similars []string) {
 	var name string
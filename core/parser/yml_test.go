@@ -167,6 +167,30 @@ func TestParseKoolYamlStruct(t *testing.T) {
 	}
 }
 
+func TestOthersKoolYamlStruct(t *testing.T) {
+	tmpPath := path.Join(t.TempDir(), "kool.yml")
+
+	if err := os.WriteFile(tmpPath, []byte("scripts:\n  test: echo\nx-custom:\n  key: value\n"), os.ModePerm); err != nil {
+		t.Fatal("failed creating temporary file for test", err)
+	}
+
+	parsed := new(KoolYaml)
+
+	if err := parsed.Parse(tmpPath); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := parsed.String()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(content, "x-custom:\n  key: value") {
+		t.Errorf("unknown top-level keys should be kept; got %s", content)
+	}
+}
+
 func TestErrorParseKoolYamlStruct(t *testing.T) {
 	var (
 		err     error
@@ -226,3 +250,57 @@ func TestErrorStringKoolYmlParser(t *testing.T) {
 		t.Errorf("expecting error 'marshal error' on String, got '%v'", err)
 	}
 }
+
+func TestHasGroupKoolYaml(t *testing.T) {
+	parsed := new(KoolYaml)
+
+	if parsed.HasGroup("backend") {
+		t.Error("unexpected group on empty kool.yml")
+	}
+
+	parsed.Groups = map[string][]string{"backend": {"app", "database"}}
+
+	if !parsed.HasGroup("backend") {
+		t.Error("expected to have backend group")
+	}
+
+	if parsed.HasGroup("frontend") {
+		t.Error("unexpected frontend group")
+	}
+}
+
+func TestHasComposeEnvironmentKoolYaml(t *testing.T) {
+	parsed := new(KoolYaml)
+
+	if parsed.HasComposeEnvironment("ci") {
+		t.Error("unexpected compose environment on empty kool.yml")
+	}
+
+	parsed.Compose = map[string][]string{"ci": {"docker-compose.yml", "docker-compose.ci.yml"}}
+
+	if !parsed.HasComposeEnvironment("ci") {
+		t.Error("expected to have ci compose environment")
+	}
+
+	if parsed.HasComposeEnvironment("debug") {
+		t.Error("unexpected debug compose environment")
+	}
+}
+
+func TestHasServiceKoolYaml(t *testing.T) {
+	parsed := new(KoolYaml)
+
+	if parsed.HasService("app") {
+		t.Error("unexpected service on empty kool.yml")
+	}
+
+	parsed.Services = map[string]*KoolYamlService{"app": {User: "kool"}, "database": nil}
+
+	if !parsed.HasService("app") {
+		t.Error("expected to have app service")
+	}
+
+	if parsed.HasService("database") {
+		t.Error("unexpected database service without settings")
+	}
+}
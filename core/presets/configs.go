@@ -63,10 +63,12 @@ questions:
       default_answer: PHP 8.0
       message: Which app service do you want to use
       options:
+        - name: PHP 8.1
+          template: php.yml
         - name: PHP 8.0
-          template: php8.yml
+          template: php.yml
         - name: PHP 7.4
-          template: php74.yml
+          template: php.yml
     - key: database
       default_answer: MySQL 8.0
       message: Which database service do you want to use
@@ -202,9 +204,11 @@ questions:
       message: Which version of PHP do you want to use
       options:
         - name: PHP 7.4
-          template: php74.yml
+          template: php.yml
         - name: PHP 8.0
-          template: php8.yml
+          template: php.yml
+        - name: PHP 8.1
+          template: php.yml
   kool:
     - key: scripts
       default_answer: 1.x
@@ -228,10 +232,12 @@ questions:
       default_answer: PHP 8.0
       message: Which app service do you want to use
       options:
+        - name: PHP 8.1
+          template: php.yml
         - name: PHP 8.0
-          template: php8.yml
+          template: php.yml
         - name: PHP 7.4
-          template: php74.yml
+          template: php.yml
     - key: database
       default_answer: MySQL 8.0
       message: Which database service do you want to use
@@ -280,9 +286,9 @@ questions:
       message: Which PHP version do you want to use
       options:
         - name: PHP 8.0
-          template: wordpress80.yml
+          template: wordpress.yml
         - name: PHP 7.4
-          template: wordpress74.yml
+          template: wordpress.yml
     - key: database
       default_answer: MySQL 8.0
       message: Which database service do you want to use
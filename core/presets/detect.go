@@ -0,0 +1,187 @@
+package presets
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// languageFiles holds the files telling the language of a project, in
+// the order they are looked up
+var languageFiles = [][2]string{
+	{"php", "composer.json"},
+	{"javascript", "package.json"},
+	{"golang", "go.mod"},
+}
+
+// versionDetectors holds, by preset language, how to detect the language
+// version the project in the current working directory requires
+var versionDetectors = map[string]func() string{
+	"php": func() string {
+		var composer struct {
+			Require map[string]string `json:"require"`
+		}
+
+		if !readJSON("composer.json", &composer) {
+			return ""
+		}
+
+		return Version(composer.Require["php"])
+	},
+	"javascript": func() string {
+		var pkg struct {
+			Engines map[string]string `json:"engines"`
+		}
+
+		if !readJSON("package.json", &pkg) {
+			return ""
+		}
+
+		return Version(pkg.Engines["node"])
+	},
+	"golang": func() string {
+		content, err := os.ReadFile("go.mod")
+
+		if err != nil {
+			return ""
+		}
+
+		for _, line := range strings.Split(string(content), "\n") {
+			if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "go" {
+				return Version(fields[1])
+			}
+		}
+
+		return ""
+	},
+}
+
+// presetDetectors holds, in the order they are tried, how to detect
+// the preset matching the project in the current working directory
+var presetDetectors = []func() string{
+	func() string {
+		if _, err := os.Stat("wp-config.php"); err == nil {
+			return "wordpress"
+		}
+
+		return ""
+	},
+	func() string {
+		var composer struct {
+			Require    map[string]string `json:"require"`
+			RequireDev map[string]string `json:"require-dev"`
+		}
+
+		if !readJSON("composer.json", &composer) {
+			return ""
+		}
+
+		switch {
+		case hasPackage(composer.Require, "laravel/framework"):
+			return "laravel"
+		case hasPackage(composer.Require, "symfony/"):
+			return "symfony"
+		}
+
+		return "php"
+	},
+	func() string {
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+
+		if !readJSON("package.json", &pkg) {
+			return ""
+		}
+
+		for _, framework := range [][2]string{{"nextjs", "next"}, {"nuxtjs", "nuxt"}, {"nestjs", "@nestjs/core"}, {"adonis", "@adonisjs/"}} {
+			if hasPackage(pkg.Dependencies, framework[1]) || hasPackage(pkg.DevDependencies, framework[1]) {
+				return framework[0]
+			}
+		}
+
+		return "nodejs"
+	},
+	func() string {
+		// Hugo modules have a go.mod too, so Hugo config is checked first
+		for _, config := range []string{"hugo.toml", "hugo.yaml", "hugo.json"} {
+			if _, err := os.Stat(config); err == nil {
+				return "hugo"
+			}
+		}
+
+		// Hugo sites are told apart from other config files by their baseURL
+		for _, config := range []string{"config.toml", "config.yaml", "config.yml", "config.json"} {
+			if content, err := os.ReadFile(config); err == nil && strings.Contains(strings.ToLower(string(content)), "baseurl") {
+				return "hugo"
+			}
+		}
+
+		return ""
+	},
+	func() string {
+		if _, err := os.Stat("go.mod"); err == nil {
+			return "golang-cli"
+		}
+
+		return ""
+	},
+}
+
+// DetectPreset detects the preset matching the project in the current
+// working directory by its files, or empty if unknown
+func DetectPreset() string {
+	for _, detector := range presetDetectors {
+		if preset := detector(); preset != "" {
+			return preset
+		}
+	}
+
+	return ""
+}
+
+// DetectVersion detects the language version required by the project in
+// the current working directory (from composer.json, package.json or
+// go.mod), or empty if unknown
+func DetectVersion(language string) string {
+	if detector, exists := versionDetectors[language]; exists {
+		return detector()
+	}
+
+	return ""
+}
+
+// DetectLanguage detects the language of the project in the current
+// working directory by its files, or empty if unknown
+func DetectLanguage() string {
+	for _, languageFile := range languageFiles {
+		if _, err := os.Stat(languageFile[1]); err == nil {
+			return languageFile[0]
+		}
+	}
+
+	return ""
+}
+
+// hasPackage tells whether the package (or, when ending with /, any
+// package of the vendor) is on the dependencies
+func hasPackage(dependencies map[string]string, name string) bool {
+	for dependency := range dependencies {
+		if dependency == name || strings.HasSuffix(name, "/") && strings.HasPrefix(dependency, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readJSON(file string, v interface{}) bool {
+	content, err := os.ReadFile(file)
+
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(content, v) == nil
+}
@@ -0,0 +1,96 @@
+package presets
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDetectVersion(t *testing.T) {
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if language := DetectLanguage(); language != "" {
+		t.Errorf("expected no language without project files, got %s", language)
+	}
+
+	for _, language := range []string{"php", "javascript", "golang", "static"} {
+		if version := DetectVersion(language); version != "" {
+			t.Errorf("expected no %s version without project files, got %s", language, version)
+		}
+	}
+
+	files := map[string]string{
+		"composer.json": `{"require": {"php": "^8.1", "laravel/framework": "^9.0"}}`,
+		"package.json":  `{"engines": {"node": ">=14"}}`,
+		"go.mod":        "module app\n\ngo 1.16\n",
+	}
+
+	for file, content := range files {
+		if err := os.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for language, expected := range map[string]string{"php": "8.1", "javascript": "14", "golang": "1.16", "static": ""} {
+		if version := DetectVersion(language); version != expected {
+			t.Errorf("expected %s version '%s', got '%s'", language, expected, version)
+		}
+	}
+
+	if language := DetectLanguage(); language != "php" {
+		t.Errorf("expected php language, got %s", language)
+	}
+
+	if err := os.WriteFile("composer.json", []byte("{invalid"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if version := DetectVersion("php"); version != "" {
+		t.Errorf("expected no php version from invalid composer.json, got %s", version)
+	}
+}
+
+func TestDetectPreset(t *testing.T) {
+	wd, _ := os.Getwd()
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	for _, tc := range []struct {
+		files    map[string]string
+		expected string
+	}{
+		{map[string]string{}, ""},
+		{map[string]string{"composer.json": `{"require": {"laravel/framework": "^9.0"}}`, "package.json": `{}`}, "laravel"},
+		{map[string]string{"composer.json": `{"require": {"symfony/framework-bundle": "6.0.*"}}`}, "symfony"},
+		{map[string]string{"composer.json": `{"require": {"php": "^8.0"}}`}, "php"},
+		{map[string]string{"composer.json": `{"require": {}}`, "wp-config.php": "<?php"}, "wordpress"},
+		{map[string]string{"package.json": `{"dependencies": {"next": "12.0.0", "react": "17.0.2"}}`}, "nextjs"},
+		{map[string]string{"package.json": `{"dependencies": {"nuxt": "^2.15"}}`}, "nuxtjs"},
+		{map[string]string{"package.json": `{"dependencies": {"@nestjs/core": "^8.0.0"}}`}, "nestjs"},
+		{map[string]string{"package.json": `{"devDependencies": {"@adonisjs/assembler": "^5.0"}}`}, "adonis"},
+		{map[string]string{"package.json": `{"dependencies": {"express": "^4.17"}}`}, "nodejs"},
+		{map[string]string{"go.mod": "module app\n"}, "golang-cli"},
+		{map[string]string{"config.toml": "baseURL = 'https://example.org/'\n"}, "hugo"},
+		{map[string]string{"config.toml": "[database]\n"}, ""},
+		{map[string]string{"config.toml": "baseURL = 'https://example.org/'\n", "go.mod": "module example.org/site\n"}, "hugo"},
+		{map[string]string{"hugo.toml": "title = 'Site'\n", "go.mod": "module example.org/site\n"}, "hugo"},
+		{map[string]string{"config.yaml": "baseURL: https://example.org/\n"}, "hugo"},
+	} {
+		if err := os.Chdir(t.TempDir()); err != nil {
+			t.Fatal(err)
+		}
+
+		for file, content := range tc.files {
+			if err := os.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if preset := DetectPreset(); preset != tc.expected {
+			t.Errorf("expected preset '%s' for files %v, got '%s'", tc.expected, tc.files, preset)
+		}
+	}
+}
@@ -5,14 +5,23 @@ type FakeParser struct {
 	CalledExists              bool
 	CalledLookUpFiles         bool
 	CalledWriteFiles          map[string]bool
+	ArgsWriteFilesInPlace     []string
 	CalledGetPresets          bool
 	CalledGetLanguages        bool
 	CalledSetPresetKeyContent map[string]map[string]map[string]bool
+	CalledGetPresetKeyContent map[string]map[string]bool
 	CalledGetTemplates        bool
 	CalledLoadPresets         bool
 	CalledLoadTemplates       bool
 	CalledLoadConfigs         bool
 	CalledGetConfig           map[string]bool
+	CalledLoadDir             map[string]bool
+	CalledLoadPresetDir       map[string]bool
+	CalledRenderFiles         map[string]bool
+	CalledGetPresetFiles      map[string]bool
+	CalledReadLock            bool
+	CalledWriteLock           bool
+	CalledValidate            map[string]bool
 
 	MockExists         bool
 	MockFoundFiles     []string
@@ -26,6 +35,14 @@ type FakeParser struct {
 	MockAllConfigs     map[string]string
 	MockConfig         map[string]*PresetConfig
 	MockGetConfigError map[string]error
+	MockLoadDirError   map[string]error
+	MockPresetContent  map[string]map[string]string
+	MockRenderData     RenderData
+	MockRenderError    error
+	MockLock           *Lock
+	MockReadLockError  error
+	MockWriteLockError error
+	MockProblems       map[string][]string
 }
 
 // Exists check if preset exists
@@ -57,12 +74,13 @@ func (f *FakeParser) LookUpFiles(preset string) (foundFiles []string) {
 }
 
 // WriteFiles write preset files
-func (f *FakeParser) WriteFiles(preset string) (fileError string, err error) {
+func (f *FakeParser) WriteFiles(preset string, inPlace ...string) (fileError string, err error) {
 	if f.CalledWriteFiles == nil {
 		f.CalledWriteFiles = make(map[string]bool)
 	}
 
 	f.CalledWriteFiles[preset] = true
+	f.ArgsWriteFilesInPlace = inPlace
 	fileError = f.MockFileError
 	err = f.MockError
 	return
@@ -85,6 +103,81 @@ func (f *FakeParser) SetPresetKeyContent(preset string, key string, content stri
 	f.CalledSetPresetKeyContent[preset][key][content] = true
 }
 
+// GetPresetFiles get the preset files contents
+func (f *FakeParser) GetPresetFiles(preset string) (files map[string]string) {
+	if f.CalledGetPresetFiles == nil {
+		f.CalledGetPresetFiles = make(map[string]bool)
+	}
+
+	f.CalledGetPresetFiles[preset] = true
+	files = make(map[string]string)
+
+	for fileName, fileContent := range f.MockPresetContent[preset] {
+		files[fileName] = fileContent
+	}
+
+	return
+}
+
+// ReadLock reads the preset lock file
+func (f *FakeParser) ReadLock() (lock *Lock, err error) {
+	f.CalledReadLock = true
+	lock = f.MockLock
+	err = f.MockReadLockError
+	return
+}
+
+// WriteLock writes the preset lock file
+func (f *FakeParser) WriteLock(lock *Lock) (err error) {
+	f.CalledWriteLock = true
+
+	if err = f.MockWriteLockError; err == nil {
+		f.MockLock = lock
+	}
+	return
+}
+
+// Validate checks the preset config
+func (f *FakeParser) Validate(preset string) (problems []string) {
+	if f.CalledValidate == nil {
+		f.CalledValidate = make(map[string]bool)
+	}
+
+	f.CalledValidate[preset] = true
+	problems = f.MockProblems[preset]
+	return
+}
+
+// RenderFiles renders the preset files templates
+func (f *FakeParser) RenderFiles(preset string, data RenderData) (fileError string, err error) {
+	if f.CalledRenderFiles == nil {
+		f.CalledRenderFiles = make(map[string]bool)
+	}
+
+	f.CalledRenderFiles[preset] = true
+	f.MockRenderData = data
+
+	if err = f.MockRenderError; err != nil {
+		fileError = f.MockFileError
+	}
+	return
+}
+
+// GetPresetKeyContent get preset key value
+func (f *FakeParser) GetPresetKeyContent(preset string, key string) (content string, found bool) {
+	if f.CalledGetPresetKeyContent == nil {
+		f.CalledGetPresetKeyContent = make(map[string]map[string]bool)
+	}
+
+	if _, ok := f.CalledGetPresetKeyContent[preset]; !ok {
+		f.CalledGetPresetKeyContent[preset] = make(map[string]bool)
+	}
+
+	f.CalledGetPresetKeyContent[preset][key] = true
+	content, found = f.MockPresetContent[preset][key]
+	return
+}
+
 // GetTemplates get all templates
 func (f *FakeParser) GetTemplates() (templates map[string]map[string]string) {
 	f.CalledGetTemplates = true
@@ -113,6 +206,28 @@ func (f *FakeParser) LoadConfigs(configs map[string]string) {
 	f.MockAllConfigs = configs
 }
 
+// LoadDir loads presets and templates from a folder
+func (f *FakeParser) LoadDir(dir string) (err error) {
+	if f.CalledLoadDir == nil {
+		f.CalledLoadDir = make(map[string]bool)
+	}
+
+	f.CalledLoadDir[dir] = true
+	err = f.MockLoadDirError[dir]
+	return
+}
+
+// LoadPresetDir loads a single preset and its sibling templates from a folder
+func (f *FakeParser) LoadPresetDir(dir string) (err error) {
+	if f.CalledLoadPresetDir == nil {
+		f.CalledLoadPresetDir = make(map[string]bool)
+	}
+
+	f.CalledLoadPresetDir[dir] = true
+	err = f.MockLoadDirError[dir]
+	return
+}
+
 // GetConfig get preset config
 func (f *FakeParser) GetConfig(preset string) (config *PresetConfig, err error) {
 	if f.CalledGetConfig == nil {
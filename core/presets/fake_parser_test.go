@@ -31,6 +31,41 @@ func TestFakeParser(t *testing.T) {
 		t.Error("failed to use mocked WriteFiles function on FakeParser")
 	}
 
+	f.MockPresetContent = map[string]map[string]string{"preset": {"kool.yml": "scripts: {}"}}
+
+	if files := f.GetPresetFiles("preset"); !f.CalledGetPresetFiles["preset"] || !reflect.DeepEqual(files, f.MockPresetContent["preset"]) {
+		t.Error("failed to use mocked GetPresetFiles function on FakeParser")
+	}
+
+	lock := &Lock{Preset: "preset"}
+
+	if err = f.WriteLock(lock); !f.CalledWriteLock || err != nil || f.MockLock != lock {
+		t.Error("failed to use mocked WriteLock function on FakeParser")
+	}
+
+	if read, err := f.ReadLock(); !f.CalledReadLock || err != nil || read != lock {
+		t.Error("failed to use mocked ReadLock function on FakeParser")
+	}
+
+	f.MockReadLockError = errors.New("read lock error")
+
+	if _, err := f.ReadLock(); err != f.MockReadLockError {
+		t.Error("failed to use mocked ReadLock error on FakeParser")
+	}
+
+	f.MockProblems = map[string][]string{"preset": {"missing language"}}
+
+	if problems := f.Validate("preset"); !f.CalledValidate["preset"] || len(problems) != 1 {
+		t.Error("failed to use mocked Validate function on FakeParser")
+	}
+
+	f.MockRenderError = errors.New("render error")
+	fileError, err = f.RenderFiles("preset", RenderData{Name: "app"})
+
+	if !f.CalledRenderFiles["preset"] || fileError != f.MockFileError || err != f.MockRenderError || f.MockRenderData.Name != "app" {
+		t.Error("failed to use mocked RenderFiles function on FakeParser")
+	}
+
 	f.MockPresets = []string{"preset"}
 	presets := f.GetPresets("")
 
@@ -122,4 +157,28 @@ func TestFakeParser(t *testing.T) {
 	if !f.CalledGetConfig["preset"] || config != f.MockConfig["preset"] || configErr != f.MockGetConfigError["preset"] {
 		t.Error("failed to use mocked GetConfig function on FakeParser")
 	}
+
+	f.MockLoadDirError = map[string]error{
+		"/broken": errors.New("load dir error"),
+	}
+
+	if err := f.LoadDir("/presets"); err != nil || !f.CalledLoadDir["/presets"] {
+		t.Error("failed to use mocked LoadDir function on FakeParser")
+	}
+
+	if err := f.LoadDir("/broken"); err == nil || err.Error() != "load dir error" {
+		t.Errorf("expected mocked LoadDir error on FakeParser, got %v", err)
+	}
+
+	if err := f.LoadPresetDir("/presets/acme"); err != nil || !f.CalledLoadPresetDir["/presets/acme"] {
+		t.Error("failed to use mocked LoadPresetDir function on FakeParser")
+	}
+
+	f.MockPresetContent = map[string]map[string]string{
+		"preset": {"kool.yml": "content"},
+	}
+
+	if content, found := f.GetPresetKeyContent("preset", "kool.yml"); !found || content != "content" || !f.CalledGetPresetKeyContent["preset"]["kool.yml"] {
+		t.Error("failed to use mocked GetPresetKeyContent function on FakeParser")
+	}
 }
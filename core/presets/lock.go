@@ -0,0 +1,68 @@
+package presets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+)
+
+// LockFile holds the name of the file recording the preset a project was built from
+const LockFile = "kool-preset.lock"
+
+// Lock holds the metadata of the preset applied to a project
+type Lock struct {
+	Preset      string                `yaml:"preset"`
+	KoolVersion string                `yaml:"kool_version"`
+	Answers     map[string]string     `yaml:"answers,omitempty"`
+	Files       map[string]LockedFile `yaml:"files"`
+}
+
+// LockedFile holds the hash of a file written by the preset and, for the
+// files merged on upgrades, the content the preset rendered for it
+type LockedFile struct {
+	Hash    string `yaml:"hash"`
+	Content string `yaml:"content,omitempty"`
+}
+
+// ReadLock reads the preset lock file of the project
+func (p *DefaultParser) ReadLock() (lock *Lock, err error) {
+	var content []byte
+
+	if content, err = afero.ReadFile(p.fs, LockFile); err != nil {
+		return
+	}
+
+	lock = &Lock{}
+
+	if err = yaml.Unmarshal(content, lock); err != nil {
+		return
+	}
+
+	if lock.Files == nil {
+		lock.Files = make(map[string]LockedFile)
+	}
+
+	return
+}
+
+// WriteLock writes the preset lock file of the project
+func (p *DefaultParser) WriteLock(lock *Lock) (err error) {
+	var content []byte
+
+	if content, err = yaml.Marshal(lock); err != nil {
+		return
+	}
+
+	err = afero.WriteFile(p.fs, LockFile, content, os.ModePerm)
+	return
+}
+
+// Hash returns the hash of a preset file content
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
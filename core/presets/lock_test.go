@@ -0,0 +1,61 @@
+package presets
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestLockParser(t *testing.T) {
+	var (
+		fs   = afero.NewMemMapFs()
+		p    = NewParserFS(fs)
+		lock = &Lock{
+			Preset:      "laravel",
+			KoolVersion: "1.0.0",
+			Answers:     map[string]string{"database": "MySQL 8.0"},
+			Files: map[string]LockedFile{
+				"docker-compose.yml": {Hash: Hash("services:\n  app: {}\n"), Content: "services:\n  app: {}\n"},
+				".env.example":       {Hash: Hash("APP_ENV=local\n")},
+			},
+		}
+	)
+
+	if _, err := p.ReadLock(); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error reading missing lock, got %v", err)
+	}
+
+	if err := p.WriteLock(lock); err != nil {
+		t.Fatalf("unexpected error writing lock: %v", err)
+	}
+
+	read, err := p.ReadLock()
+
+	if err != nil {
+		t.Fatalf("unexpected error reading lock: %v", err)
+	}
+
+	if !reflect.DeepEqual(read, lock) {
+		t.Errorf("expected lock %v, got %v", lock, read)
+	}
+
+	_ = afero.WriteFile(fs, LockFile, []byte("files: ["), os.ModePerm)
+
+	if _, err = p.ReadLock(); err == nil {
+		t.Error("expected error reading invalid lock")
+	}
+
+	_ = afero.WriteFile(fs, LockFile, []byte("preset: php\n"), os.ModePerm)
+
+	if read, _ = p.ReadLock(); read.Files == nil {
+		t.Error("expected initialized files on lock without files")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("content") != Hash("content") || Hash("content") == Hash("other content") || len(Hash("")) != 64 {
+		t.Error("unexpected preset file hash")
+	}
+}
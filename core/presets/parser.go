@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 // PresetConfigQuestion preset config question
 type PresetConfigQuestion struct {
 	Key           string                       `yaml:"key"`
+	Type          string                       `yaml:"type"`
+	When          string                       `yaml:"when"`
 	DefaultAnswer string                       `yaml:"default_answer"`
 	Message       string                       `yaml:"message"`
 	Options       []PresetConfigQuestionOption `yaml:"options"`
@@ -56,8 +59,16 @@ type Parser interface {
 	LoadPresets(map[string]map[string]string)
 	LoadTemplates(map[string]map[string]string)
 	LoadConfigs(map[string]string)
-	WriteFiles(string) (string, error)
+	LoadDir(string) error
+	LoadPresetDir(string) error
+	WriteFiles(string, ...string) (string, error)
+	RenderFiles(string, RenderData) (string, error)
 	SetPresetKeyContent(string, string, string)
+	GetPresetKeyContent(string, string) (string, bool)
+	GetPresetFiles(string) map[string]string
+	ReadLock() (*Lock, error)
+	WriteLock(*Lock) error
+	Validate(string) []string
 	GetTemplates() map[string]map[string]string
 	GetConfig(string) (*PresetConfig, error)
 }
@@ -136,8 +147,9 @@ func (p *DefaultParser) LookUpFiles(preset string) (foundFiles []string) {
 	return
 }
 
-// WriteFiles write preset files
-func (p *DefaultParser) WriteFiles(preset string) (fileError string, err error) {
+// WriteFiles write preset files; existing files are renamed to a backup
+// first, except for the given ones, which are overwritten in place
+func (p *DefaultParser) WriteFiles(preset string, inPlace ...string) (fileError string, err error) {
 	presetFiles := p.Presets[preset]
 
 	for fileName, fileContent := range presetFiles {
@@ -146,7 +158,7 @@ func (p *DefaultParser) WriteFiles(preset string) (fileError string, err error)
 			lines int
 		)
 
-		if _, statErr := p.fs.Stat(fileName); !os.IsNotExist(statErr) {
+		if _, statErr := p.fs.Stat(fileName); !os.IsNotExist(statErr) && !contains(inPlace, fileName) {
 			if err = p.fs.Rename(fileName, fmt.Sprintf("%s.bak.%s", fileName, time.Now().Format("20060102"))); err != nil {
 				fileError = fileName
 				return
@@ -182,6 +194,22 @@ func (p *DefaultParser) WriteFiles(preset string) (fileError string, err error)
 	return
 }
 
+// RenderFiles renders the preset files templates with the given data
+func (p *DefaultParser) RenderFiles(preset string, data RenderData) (fileError string, err error) {
+	for fileName, fileContent := range p.Presets[preset] {
+		var rendered string
+
+		if rendered, err = Render(fileContent, data); err != nil {
+			fileError = fileName
+			return
+		}
+
+		p.Presets[preset][fileName] = rendered
+	}
+
+	return
+}
+
 // SetPresetKeyContent set preset key value
 func (p *DefaultParser) SetPresetKeyContent(preset string, key string, content string) {
 	if _, found := p.Presets[preset]; !found {
@@ -191,6 +219,23 @@ func (p *DefaultParser) SetPresetKeyContent(preset string, key string, content s
 	p.Presets[preset][key] = content
 }
 
+// GetPresetKeyContent get preset key value
+func (p *DefaultParser) GetPresetKeyContent(preset string, key string) (content string, found bool) {
+	content, found = p.Presets[preset][key]
+	return
+}
+
+// GetPresetFiles get a copy of the preset files contents
+func (p *DefaultParser) GetPresetFiles(preset string) (files map[string]string) {
+	files = make(map[string]string)
+
+	for fileName, fileContent := range p.Presets[preset] {
+		files[fileName] = fileContent
+	}
+
+	return
+}
+
 // GetTemplates get all templates
 func (p *DefaultParser) GetTemplates() map[string]map[string]string {
 	return p.Templates
@@ -211,6 +256,150 @@ func (p *DefaultParser) LoadConfigs(allConfigs map[string]string) {
 	p.Configs = allConfigs
 }
 
+// presetConfigFile holds the name of the file with the preset configuration
+const presetConfigFile = "preset-config.yml"
+
+// LoadDir loads the presets and templates from the given folder, merging
+// them with the loaded ones (and replacing those with the same name). The
+// folder holds one folder per preset and an optional templates folder with
+// one folder per service; a folder laid out like the kool repository, with
+// presets and templates folders, works as well.
+func (p *DefaultParser) LoadDir(dir string) (err error) {
+	var (
+		entries    []os.FileInfo
+		presetsDir = dir
+	)
+
+	if info, statErr := p.fs.Stat(filepath.Join(dir, "presets")); statErr == nil && info.IsDir() {
+		presetsDir = filepath.Join(dir, "presets")
+	}
+
+	if entries, err = afero.ReadDir(p.fs, presetsDir); err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "templates" || entry.Name() == "presets" {
+			continue
+		}
+
+		if err = p.loadPreset(entry.Name(), filepath.Join(presetsDir, entry.Name())); err != nil {
+			return
+		}
+	}
+
+	err = p.loadTemplates(filepath.Join(dir, "templates"))
+	return
+}
+
+// LoadPresetDir loads the single preset on the given folder, named after
+// it, along with the templates on the templates folder next to it (or next
+// to the presets folder holding it, as on the kool repository)
+func (p *DefaultParser) LoadPresetDir(dir string) (err error) {
+	parent := filepath.Dir(dir)
+
+	if err = p.loadPreset(filepath.Base(dir), dir); err != nil {
+		return
+	}
+
+	if filepath.Base(parent) == "presets" {
+		parent = filepath.Dir(parent)
+	}
+
+	err = p.loadTemplates(filepath.Join(parent, "templates"))
+	return
+}
+
+// loadPreset loads the files of the preset on the given folder
+func (p *DefaultParser) loadPreset(name, dir string) (err error) {
+	var files map[string]string
+
+	if files, err = p.readFiles(dir); err != nil {
+		return
+	}
+
+	if p.Presets == nil {
+		p.Presets = make(map[string]map[string]string)
+	}
+
+	if p.Configs == nil {
+		p.Configs = make(map[string]string)
+	}
+
+	if config, hasConfig := files[presetConfigFile]; hasConfig {
+		p.Configs[name] = config
+		delete(files, presetConfigFile)
+	}
+
+	p.Presets[name] = files
+	return
+}
+
+// loadTemplates loads the templates on the given folder, if it exists
+func (p *DefaultParser) loadTemplates(dir string) (err error) {
+	var entries []os.FileInfo
+
+	if p.Templates == nil {
+		p.Templates = make(map[string]map[string]string)
+	}
+
+	if entries, err = afero.ReadDir(p.fs, dir); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		var files map[string]string
+
+		if files, err = p.readFiles(filepath.Join(dir, entry.Name())); err != nil {
+			return
+		}
+
+		if _, exists := p.Templates[entry.Name()]; !exists {
+			p.Templates[entry.Name()] = make(map[string]string)
+		}
+
+		for name, content := range files {
+			p.Templates[entry.Name()][name] = content
+		}
+	}
+
+	return
+}
+
+// readFiles reads the contents of the files within the folder, skipping subfolders
+func (p *DefaultParser) readFiles(dir string) (files map[string]string, err error) {
+	var entries []os.FileInfo
+
+	if entries, err = afero.ReadDir(p.fs, dir); err != nil {
+		return
+	}
+
+	files = make(map[string]string)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var content []byte
+
+		if content, err = afero.ReadFile(p.fs, filepath.Join(dir, entry.Name())); err != nil {
+			return
+		}
+
+		files[entry.Name()] = string(content)
+	}
+
+	return
+}
+
 // GetConfig get preset config
 func (p *DefaultParser) GetConfig(preset string) (config *PresetConfig, err error) {
 	var (
@@ -227,3 +416,13 @@ func (p *DefaultParser) GetConfig(preset string) (config *PresetConfig, err erro
 	err = yaml.Unmarshal([]byte(configValue), config)
 	return
 }
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -240,6 +240,71 @@ func TestSetPresetKeyContentParser(t *testing.T) {
 	}
 }
 
+func TestGetPresetKeyContentParser(t *testing.T) {
+	p := NewParser()
+	p.LoadPresets(map[string]map[string]string{
+		"preset": {"kool.yml": "scripts: {}"},
+	})
+
+	if content, found := p.GetPresetKeyContent("preset", "kool.yml"); !found || content != "scripts: {}" {
+		t.Errorf("unexpected preset key content: %s %v", content, found)
+	}
+
+	if _, found := p.GetPresetKeyContent("preset", "docker-compose.yml"); found {
+		t.Error("unexpected content for missing preset key")
+	}
+
+	if _, found := p.GetPresetKeyContent("invalid_preset", "kool.yml"); found {
+		t.Error("unexpected content for missing preset")
+	}
+}
+
+func TestRenderFilesParser(t *testing.T) {
+	p := NewParser()
+	p.LoadPresets(map[string]map[string]string{
+		"preset": {
+			"kool.yml":           "scripts: {}",
+			"docker-compose.yml": "image: kooldev/php:{{ .Version }}-nginx # {{ .Name }} {{ .Answers.app }}",
+		},
+	})
+
+	if fileError, err := p.RenderFiles("preset", RenderData{Name: "blog", Version: "8.1", Answers: map[string]string{"app": "PHP 8.1"}}); err != nil {
+		t.Fatalf("unexpected error rendering %s: %v", fileError, err)
+	}
+
+	if content, _ := p.GetPresetKeyContent("preset", "docker-compose.yml"); content != "image: kooldev/php:8.1-nginx # blog PHP 8.1" {
+		t.Errorf("unexpected rendered content: %s", content)
+	}
+
+	if content, _ := p.GetPresetKeyContent("preset", "kool.yml"); content != "scripts: {}" {
+		t.Errorf("unexpected rendered content: %s", content)
+	}
+
+	p.SetPresetKeyContent("preset", "kool.yml", "{{ .Name")
+
+	if fileError, err := p.RenderFiles("preset", RenderData{}); err == nil || fileError != "kool.yml" {
+		t.Errorf("expected error rendering kool.yml, got %s %v", fileError, err)
+	}
+}
+
+func TestGetPresetFilesParser(t *testing.T) {
+	p := NewParser()
+	p.LoadPresets(map[string]map[string]string{
+		"preset": {"kool.yml": "scripts: {}"},
+	})
+
+	files := p.GetPresetFiles("preset")
+	files["kool.yml"] = "changed"
+
+	if content, _ := p.GetPresetKeyContent("preset", "kool.yml"); content != "scripts: {}" {
+		t.Errorf("expected a copy of the preset files, got the preset changed to %s", content)
+	}
+
+	if files = p.GetPresetFiles("invalid_preset"); len(files) != 0 {
+		t.Errorf("unexpected files for missing preset: %v", files)
+	}
+}
+
 func TestGetTemplatesParser(t *testing.T) {
 	var allTemplates map[string]map[string]string
 	p := NewParser()
@@ -331,6 +396,39 @@ func TestWriteExistingFilesParser(t *testing.T) {
 	}
 }
 
+func TestWriteFilesInPlaceParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	for _, file := range []string{"kool.yml", ".env"} {
+		if err := afero.WriteFile(fs, file, []byte("old"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := NewParserFS(fs)
+	p.LoadPresets(map[string]map[string]string{
+		"preset": {"kool.yml": "value1", ".env": "value2"},
+	})
+
+	if _, err := p.WriteFiles("preset", "kool.yml"); err != nil {
+		t.Errorf("unexpected error writing file, err: %v", err)
+	}
+
+	if content, _ := afero.ReadFile(fs, "kool.yml"); string(content) != "value1" {
+		t.Errorf("did not write kool.yml in place; got %s", content)
+	}
+
+	backupDate := time.Now().Format("20060102")
+
+	if exists, _ := afero.Exists(fs, "kool.yml.bak."+backupDate); exists {
+		t.Error("should not back up the files written in place")
+	}
+
+	if exists, _ := afero.Exists(fs, ".env.bak."+backupDate); !exists {
+		t.Error("did not back up the other existing files")
+	}
+}
+
 func TestLoadPresetsParser(t *testing.T) {
 	presets := map[string]map[string]string{
 		"laravel": {"file": "content"},
@@ -370,6 +468,121 @@ func TestLoadConfigsParser(t *testing.T) {
 	}
 }
 
+func TestLoadDirParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "/custom/acme-api/kool.yml", []byte("scripts: {}"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/acme-api/preset-config.yml", []byte("language: go"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/laravel/kool.yml", []byte("custom laravel"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/templates/app/acme.yml", []byte("acme app"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/templates/queue/rabbitmq.yml", []byte("rabbitmq"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/repo/presets/acme-web/kool.yml", []byte("web"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/repo/templates/app/web.yml", []byte("web app"), os.ModePerm)
+
+	p := NewParserFS(fs)
+	p.LoadPresets(map[string]map[string]string{"laravel": {"kool.yml": "laravel"}, "php": {"kool.yml": "php"}})
+	p.LoadTemplates(map[string]map[string]string{"app": {"php8.yml": "php8"}})
+	p.LoadConfigs(map[string]string{"laravel": "language: php", "php": "language: php"})
+
+	if err := p.LoadDir("/custom"); err != nil {
+		t.Fatalf("unexpected error loading presets folder: %v", err)
+	}
+
+	if err := p.LoadDir("/repo"); err != nil {
+		t.Fatalf("unexpected error loading repository folder: %v", err)
+	}
+
+	expectedPresets := map[string]map[string]string{
+		"acme-api": {"kool.yml": "scripts: {}"},
+		"acme-web": {"kool.yml": "web"},
+		"laravel":  {"kool.yml": "custom laravel"},
+		"php":      {"kool.yml": "php"},
+	}
+
+	if presets := p.(*DefaultParser).Presets; !reflect.DeepEqual(presets, expectedPresets) {
+		t.Errorf("unexpected presets after loading folders: %v", presets)
+	}
+
+	expectedTemplates := map[string]map[string]string{
+		"app":   {"php8.yml": "php8", "acme.yml": "acme app", "web.yml": "web app"},
+		"queue": {"rabbitmq.yml": "rabbitmq"},
+	}
+
+	if templates := p.GetTemplates(); !reflect.DeepEqual(templates, expectedTemplates) {
+		t.Errorf("unexpected templates after loading folders: %v", templates)
+	}
+
+	if languages := p.GetLanguages(); !reflect.DeepEqual(languages, []string{"go", "php"}) {
+		t.Errorf("unexpected languages after loading folders: %v", languages)
+	}
+
+	if err := p.LoadDir("/missing"); err == nil {
+		t.Error("expected error loading a missing folder")
+	}
+}
+
+func TestLoadPresetDirParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+
+	_ = afero.WriteFile(fs, "/custom/acme/kool.yml", []byte("acme"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/acme/preset-config.yml", []byte("language: php"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/broken/preset-config.yml", []byte("\tinvalid"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/custom/templates/app/acme.yml", []byte("acme app"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/repo/presets/web/kool.yml", []byte("web"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/repo/presets/other/kool.yml", []byte("other"), os.ModePerm)
+	_ = afero.WriteFile(fs, "/repo/templates/app/web.yml", []byte("web app"), os.ModePerm)
+
+	p := &DefaultParser{fs: fs}
+
+	if err := p.LoadPresetDir("/custom/acme"); err != nil {
+		t.Fatalf("unexpected error loading preset folder: %v", err)
+	}
+
+	if err := p.LoadPresetDir("/repo/presets/web"); err != nil {
+		t.Fatalf("unexpected error loading repository preset folder: %v", err)
+	}
+
+	expectedPresets := map[string]map[string]string{
+		"acme": {"kool.yml": "acme"},
+		"web":  {"kool.yml": "web"},
+	}
+
+	if !reflect.DeepEqual(p.Presets, expectedPresets) {
+		t.Errorf("should load only the given presets; got %v", p.Presets)
+	}
+
+	if config := p.Configs["acme"]; config != "language: php" {
+		t.Errorf("unexpected preset config: %s", config)
+	}
+
+	expectedTemplates := map[string]map[string]string{
+		"app": {"acme.yml": "acme app", "web.yml": "web app"},
+	}
+
+	if !reflect.DeepEqual(p.Templates, expectedTemplates) {
+		t.Errorf("unexpected templates after loading preset folders: %v", p.Templates)
+	}
+
+	if err := p.LoadPresetDir("/missing/preset"); err == nil {
+		t.Error("expected error loading a missing folder")
+	}
+}
+
+func TestLoadDirEmptyParser(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "/custom/acme/kool.yml", []byte("acme"), os.ModePerm)
+
+	p := &DefaultParser{fs: fs}
+
+	if err := p.LoadDir("/custom"); err != nil {
+		t.Fatalf("unexpected error loading presets folder: %v", err)
+	}
+
+	if !p.Exists("acme") || len(p.Templates) != 0 || len(p.Configs) != 0 {
+		t.Errorf("unexpected presets loaded onto empty parser: %v %v %v", p.Presets, p.Templates, p.Configs)
+	}
+}
+
 func TestGetConfigParser(t *testing.T) {
 	configs := map[string]string{
 		"preset": `language: php
@@ -0,0 +1,154 @@
+package presets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preset config question types
+const (
+	QuestionSelect      = "select"
+	QuestionMultiSelect = "multiselect"
+	QuestionInput       = "input"
+	QuestionConfirm     = "confirm"
+)
+
+// QuestionType returns the type of the question, select by default
+func (q PresetConfigQuestion) QuestionType() string {
+	if q.Type == "" {
+		return QuestionSelect
+	}
+
+	return q.Type
+}
+
+// whenCondition holds one of the conditions of a question when
+type whenCondition struct {
+	key      string
+	operator string
+	value    string
+}
+
+// conditions parses the question when conditions
+func (q PresetConfigQuestion) conditions() (conditions []whenCondition, err error) {
+	if strings.TrimSpace(q.When) == "" {
+		return
+	}
+
+	for _, condition := range strings.Split(q.When, "&&") {
+		var fields = strings.Fields(condition)
+
+		switch {
+		case len(fields) == 1 && strings.HasPrefix(fields[0], "!"):
+			conditions = append(conditions, whenCondition{strings.TrimPrefix(fields[0], "!"), "!", ""})
+		case len(fields) == 1:
+			conditions = append(conditions, whenCondition{fields[0], "", ""})
+		case len(fields) >= 3 && (fields[1] == "==" || fields[1] == "!=" || fields[1] == "contains"):
+			conditions = append(conditions, whenCondition{fields[0], fields[1], unquote(strings.Join(fields[2:], " "))})
+		default:
+			err = fmt.Errorf("invalid when condition '%s' on question %s", strings.TrimSpace(condition), q.Key)
+			return
+		}
+	}
+
+	return
+}
+
+// Applies evaluates the question when condition against the earlier
+// answers. Conditions are joined by && and each one is either KEY
+// (answered with something other than empty, no or none), !KEY,
+// KEY == VALUE, KEY != VALUE or KEY contains VALUE (for multiselect).
+func (q PresetConfigQuestion) Applies(answers map[string]string) (applies bool, err error) {
+	var conditions []whenCondition
+
+	if conditions, err = q.conditions(); err != nil {
+		return
+	}
+
+	applies = true
+
+	for _, condition := range conditions {
+		answer := answers[condition.key]
+
+		switch condition.operator {
+		case "!":
+			applies = !isTruthyAnswer(answer)
+		case "":
+			applies = isTruthyAnswer(answer)
+		case "==":
+			applies = strings.EqualFold(answer, condition.value)
+		case "!=":
+			applies = !strings.EqualFold(answer, condition.value)
+		case "contains":
+			applies = false
+
+			for _, option := range SplitAnswer(answer) {
+				if strings.EqualFold(option, condition.value) {
+					applies = true
+				}
+			}
+		}
+
+		if !applies {
+			return
+		}
+	}
+
+	return
+}
+
+// WhenKeys returns the keys of the questions the when condition refers to
+func (q PresetConfigQuestion) WhenKeys() (keys []string, err error) {
+	var conditions []whenCondition
+
+	if conditions, err = q.conditions(); err != nil {
+		return
+	}
+
+	for _, condition := range conditions {
+		keys = append(keys, condition.key)
+	}
+
+	return
+}
+
+// SplitAnswer splits the answer of a multiselect question into the selected options
+func SplitAnswer(answer string) (options []string) {
+	for _, option := range strings.Split(answer, ",") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+
+	return
+}
+
+// ParseConfirmAnswer parses a yes/no answer
+func ParseConfirmAnswer(answer string) (yes bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "yes", "y", "true":
+		yes = true
+	case "no", "n", "false", "":
+	default:
+		err = fmt.Errorf("invalid yes/no answer '%s'", answer)
+	}
+
+	return
+}
+
+func isTruthyAnswer(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "no", "none", "false":
+		return false
+	}
+
+	return true
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
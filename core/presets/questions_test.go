@@ -0,0 +1,78 @@
+package presets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuestionType(t *testing.T) {
+	if q := (PresetConfigQuestion{}); q.QuestionType() != QuestionSelect {
+		t.Errorf("expected select as default question type, got %s", q.QuestionType())
+	}
+
+	if q := (PresetConfigQuestion{Type: QuestionInput}); q.QuestionType() != QuestionInput {
+		t.Errorf("expected input question type, got %s", q.QuestionType())
+	}
+}
+
+func TestApplies(t *testing.T) {
+	answers := map[string]string{
+		"database": "MySQL 8.0",
+		"mailhog":  "yes",
+		"cache":    "none",
+		"extras":   "Mailhog,Elasticsearch",
+	}
+
+	for when, expected := range map[string]bool{
+		"":                                    true,
+		"mailhog":                             true,
+		"cache":                               false,
+		"missing":                             false,
+		"!cache":                              true,
+		"!mailhog":                            false,
+		"database == MySQL 8.0":               true,
+		"database == \"mysql 8.0\"":           true,
+		"database != MySQL 8.0":               false,
+		"database != PostgreSQL 13.0":         true,
+		"extras contains elasticsearch":       true,
+		"extras contains Redis":               false,
+		"mailhog && database == MySQL 8.0":    true,
+		"mailhog && database == 'MariaDB 10'": false,
+	} {
+		applies, err := PresetConfigQuestion{Key: "question", When: when}.Applies(answers)
+
+		if err != nil {
+			t.Errorf("unexpected error evaluating '%s': %v", when, err)
+		}
+
+		if applies != expected {
+			t.Errorf("expected '%s' to evaluate to %v", when, expected)
+		}
+	}
+
+	if _, err := (PresetConfigQuestion{Key: "question", When: "database > 1"}).Applies(answers); err == nil || err.Error() != "invalid when condition 'database > 1' on question question" {
+		t.Errorf("expected invalid condition error, got %v", err)
+	}
+}
+
+func TestSplitAnswer(t *testing.T) {
+	if options := SplitAnswer(" Mailhog, ,Elasticsearch "); !reflect.DeepEqual(options, []string{"Mailhog", "Elasticsearch"}) {
+		t.Errorf("unexpected split answer: %v", options)
+	}
+
+	if options := SplitAnswer(""); len(options) != 0 {
+		t.Errorf("unexpected split of empty answer: %v", options)
+	}
+}
+
+func TestParseConfirmAnswer(t *testing.T) {
+	for answer, expected := range map[string]bool{"yes": true, "Y": true, "true": true, "no": false, "n": false, "": false} {
+		if yes, err := ParseConfirmAnswer(answer); err != nil || yes != expected {
+			t.Errorf("unexpected result parsing '%s': %v %v", answer, yes, err)
+		}
+	}
+
+	if _, err := ParseConfirmAnswer("maybe"); err == nil {
+		t.Error("expected error parsing invalid yes/no answer")
+	}
+}
@@ -0,0 +1,51 @@
+package presets
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// RenderData holds the variables available to preset templates
+type RenderData struct {
+	Answers  map[string]string
+	Name     string
+	Language string
+	Version  string
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+var renderFuncs = template.FuncMap{
+	"version": Version,
+}
+
+// Render renders the preset template content with the given data
+func Render(content string, data RenderData) (rendered string, err error) {
+	var (
+		tmpl *template.Template
+		b    strings.Builder
+	)
+
+	if !strings.Contains(content, "{{") {
+		rendered = content
+		return
+	}
+
+	if tmpl, err = template.New("preset").Funcs(renderFuncs).Option("missingkey=zero").Parse(content); err != nil {
+		return
+	}
+
+	if err = tmpl.Execute(&b, data); err != nil {
+		return
+	}
+
+	rendered = b.String()
+	return
+}
+
+// Version returns the first MAJOR.MINOR version found on the text
+// (i.e. 8.0 from PHP 8.0 or 7.4 from ^7.4|^8.0), or empty if none
+func Version(text string) string {
+	return versionPattern.FindString(text)
+}
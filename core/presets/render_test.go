@@ -0,0 +1,75 @@
+package presets
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	data := RenderData{Answers: map[string]string{"app_port": "8080", "mailhog": "yes"}}
+
+	rendered, err := Render(`ports:
+  - "{{ .Answers.app_port }}:80"
+{{- if eq .Answers.mailhog "yes" }}
+mail: true
+{{- end }}
+missing: "{{ .Answers.missing }}"
+`, data)
+
+	if err != nil {
+		t.Fatalf("unexpected error rendering: %v", err)
+	}
+
+	expected := `ports:
+  - "8080:80"
+mail: true
+missing: ""
+`
+
+	if rendered != expected {
+		t.Errorf("expected '%s', got '%s'", expected, rendered)
+	}
+
+	if rendered, _ = Render("plain: content", data); rendered != "plain: content" {
+		t.Errorf("unexpected rendering of plain content: %s", rendered)
+	}
+
+	if _, err = Render("{{ .Answers.app_port", data); err == nil {
+		t.Error("expected error rendering invalid template")
+	}
+
+	if _, err = Render("{{ .Unknown }}", data); err == nil {
+		t.Error("expected error rendering unknown field")
+	}
+}
+
+func TestRenderVersion(t *testing.T) {
+	template := `image: kooldev/php:{{ or (version .Answers.app) .Version "8.0" }}-nginx`
+
+	for _, tc := range []struct {
+		data     RenderData
+		expected string
+	}{
+		{RenderData{Answers: map[string]string{"app": "PHP 7.4"}, Version: "8.1"}, "image: kooldev/php:7.4-nginx"},
+		{RenderData{Version: "8.1"}, "image: kooldev/php:8.1-nginx"},
+		{RenderData{}, "image: kooldev/php:8.0-nginx"},
+	} {
+		if rendered, err := Render(template, tc.data); err != nil || rendered != tc.expected {
+			t.Errorf("expected '%s', got '%s' (error: %v)", tc.expected, rendered, err)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	for text, expected := range map[string]string{
+		"PHP 8.0":     "8.0",
+		"^7.4|^8.0":   "7.4",
+		">=14.17.0":   "14.17",
+		"1.16":        "1.16",
+		"go 1":        "1",
+		"no versions": "",
+	} {
+		if version := Version(text); version != expected {
+			t.Errorf("expected version '%s' from '%s', got '%s'", expected, text, version)
+		}
+	}
+}
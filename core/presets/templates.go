@@ -35,6 +35,20 @@ func GetTemplates() map[string]map[string]string {
     networks:
       - kool_local
       - kool_global
+`,
+		"php.yml": `services:
+  app:
+    image: kooldev/php:{{ or (version .Answers.app) .Version "8.0" }}-nginx
+    ports:
+      - "${KOOL_APP_PORT:-80}:80"
+    environment:
+      ASUSER: "${KOOL_ASUSER:-0}"
+      UID: "${UID:-0}"
+    volumes:
+      - .:/app:delegated
+    networks:
+      - kool_local
+      - kool_global
 `,
 		"php74.yml": `services:
   app:
@@ -63,6 +77,20 @@ func GetTemplates() map[string]map[string]string {
     networks:
       - kool_local
       - kool_global
+`,
+		"wordpress.yml": `services:
+  app:
+    image: kooldev/wordpress:{{ or (version .Answers.app) .Version "8.0" }}-nginx
+    ports:
+      - "${KOOL_APP_PORT:-80}:80"
+    environment:
+      ASUSER: "${KOOL_ASUSER:-0}"
+      UID: "${UID:-0}"
+    volumes:
+      - .:/app:delegated
+    networks:
+      - kool_local
+      - kool_global
 `,
 		"wordpress74.yml": `services:
   app:
@@ -290,6 +318,12 @@ scripts:
 `,
 	}
 	templates["scripts"] = map[string]string{
+		"composer.yml": `scripts:
+  composer: kool exec app composer
+`,
+		"composer2.yml": `scripts:
+  composer: kool exec app composer2
+`,
 		"laravel.yml": `scripts:
   composer: kool exec app composer
   artisan: kool exec app php artisan
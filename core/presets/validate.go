@@ -0,0 +1,144 @@
+package presets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Validate checks the preset config against the PresetConfig schema and
+// the templates it refers to, returning the problems found
+func (p *DefaultParser) Validate(preset string) (problems []string) {
+	var (
+		config  PresetConfig
+		content string
+		exists  bool
+		keys    = make(map[string]bool)
+		groups  []string
+	)
+
+	if content, exists = p.Configs[preset]; !exists {
+		problems = append(problems, "missing "+presetConfigFile)
+		return
+	}
+
+	if err := yaml.UnmarshalStrict([]byte(content), &config); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid %s: %v", presetConfigFile, err))
+		return
+	}
+
+	if config.Language == "" {
+		problems = append(problems, "missing language")
+	}
+
+	for group := range config.Questions {
+		groups = append(groups, group)
+	}
+
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		if group != "compose" && group != "kool" {
+			problems = append(problems, fmt.Sprintf("unknown questions group '%s'; use compose or kool", group))
+			continue
+		}
+
+		for _, question := range config.Questions[group] {
+			problems = append(problems, p.validateQuestion(group, question, keys)...)
+			keys[question.Key] = true
+		}
+	}
+
+	for _, template := range config.Templates {
+		if _, exists = p.Templates[template.Key][template.Template]; !exists {
+			problems = append(problems, fmt.Sprintf("template %s/%s not found", template.Key, template.Template))
+		}
+	}
+
+	return
+}
+
+// validateQuestion checks the question against the PresetConfig schema,
+// the earlier questions keys and the templates its options refer to
+func (p *DefaultParser) validateQuestion(group string, question PresetConfigQuestion, keys map[string]bool) (problems []string) {
+	var (
+		options []string
+		folder  = question.Key
+	)
+
+	if group == "kool" {
+		folder = "scripts"
+	}
+
+	if question.Key == "" {
+		problems = append(problems, fmt.Sprintf("question without key on %s questions", group))
+		return
+	}
+
+	if keys[question.Key] {
+		problems = append(problems, fmt.Sprintf("duplicated question key %s", question.Key))
+	}
+
+	if question.Message == "" {
+		problems = append(problems, fmt.Sprintf("question %s has no message", question.Key))
+	}
+
+	whenKeys, err := question.WhenKeys()
+
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	for _, key := range whenKeys {
+		if !keys[key] {
+			problems = append(problems, fmt.Sprintf("question %s when refers to %s, which is not an earlier question", question.Key, key))
+		}
+	}
+
+	for _, option := range question.Options {
+		options = append(options, option.Name)
+
+		if option.Name == "none" {
+			continue
+		}
+
+		if _, exists := p.Templates[folder][option.Template]; !exists {
+			problems = append(problems, fmt.Sprintf("template %s/%s of question %s option %s not found", folder, option.Template, question.Key, option.Name))
+		}
+	}
+
+	switch question.QuestionType() {
+	case QuestionSelect, QuestionMultiSelect:
+		if len(options) == 0 {
+			problems = append(problems, fmt.Sprintf("question %s has no options", question.Key))
+			return
+		}
+
+		for _, answer := range SplitAnswer(question.DefaultAnswer) {
+			if !containsFold(options, answer) {
+				problems = append(problems, fmt.Sprintf("default answer '%s' of question %s is not one of its options: %s", answer, question.Key, strings.Join(options, ", ")))
+			}
+		}
+	case QuestionConfirm:
+		if _, err = ParseConfirmAnswer(question.DefaultAnswer); err != nil {
+			problems = append(problems, fmt.Sprintf("default answer '%s' of question %s is not yes or no", question.DefaultAnswer, question.Key))
+		}
+	case QuestionInput:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown type '%s' for question %s; use %s, %s, %s or %s", question.Type, question.Key, QuestionSelect, QuestionMultiSelect, QuestionInput, QuestionConfirm))
+	}
+
+	return
+}
+
+func containsFold(options []string, value string) bool {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,119 @@
+package presets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateBuiltInPresets(t *testing.T) {
+	p := NewParser()
+	p.LoadPresets(GetAll())
+	p.LoadTemplates(GetTemplates())
+	p.LoadConfigs(GetConfigs())
+
+	for preset := range GetAll() {
+		if problems := p.Validate(preset); len(problems) > 0 {
+			t.Errorf("unexpected problems on built-in preset %s: %v", preset, problems)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	p := NewParser()
+	p.LoadTemplates(map[string]map[string]string{
+		"app":     {"acme.yml": "services: {}"},
+		"scripts": {"npm.yml": "scripts: {}"},
+	})
+
+	for _, tc := range []struct {
+		config   string
+		expected []string
+	}{
+		{"", []string{"missing language"}},
+		{"language: php\nversion: 2\n", []string{"invalid preset-config.yml: yaml: unmarshal errors:\n  line 2: field version not found in type presets.PresetConfig"}},
+		{`language: php
+questions:
+  compose:
+    - key: app
+      message: Which app
+      default_answer: acme
+      options:
+        - name: acme
+          template: acme.yml
+        - name: none
+          template: none
+  kool:
+    - key: scripts
+      when: app != none
+      message: Which scripts
+      default_answer: npm
+      options:
+        - name: npm
+          template: npm.yml
+templates:
+  - key: scripts
+    template: npm.yml
+`, nil},
+		{`language: php
+questions:
+  compose:
+    - key: app
+      default_answer: other
+      options:
+        - name: acme
+          template: missing.yml
+    - key: app
+      type: confirm
+      message: Again
+      default_answer: maybe
+    - message: No key
+  services:
+    - key: db
+templates:
+  - key: scripts
+    template: missing.yml
+`, []string{
+			"question app has no message",
+			"template app/missing.yml of question app option acme not found",
+			"default answer 'other' of question app is not one of its options: acme",
+			"duplicated question key app",
+			"default answer 'maybe' of question app is not yes or no",
+			"question without key on compose questions",
+			"unknown questions group 'services'; use compose or kool",
+			"template scripts/missing.yml not found",
+		}},
+		{`language: php
+questions:
+  kool:
+    - key: worker
+      type: text
+      message: Worker
+      when: queue && app ~ x
+    - key: names
+      type: multiselect
+      message: Names
+`, []string{
+			"invalid when condition 'app ~ x' on question worker",
+			"unknown type 'text' for question worker; use select, multiselect, input or confirm",
+			"question names has no options",
+		}},
+		{`language: php
+questions:
+  kool:
+    - key: worker
+      type: input
+      message: Worker
+      when: queue
+`, []string{"question worker when refers to queue, which is not an earlier question"}},
+	} {
+		p.LoadConfigs(map[string]string{"acme": tc.config})
+
+		if problems := p.Validate("acme"); !reflect.DeepEqual(problems, tc.expected) {
+			t.Errorf("expected problems %q, got %q", tc.expected, problems)
+		}
+	}
+
+	if problems := p.Validate("missing"); !reflect.DeepEqual(problems, []string{"missing preset-config.yml"}) {
+		t.Errorf("unexpected problems on preset without config: %v", problems)
+	}
+}
@@ -0,0 +1,70 @@
+package qrcode
+
+import (
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Code holds a QR code modules matrix
+type Code struct {
+	size    int
+	modules [][]bool
+}
+
+// Encode encodes the given text as a QR code with error correction
+// level M, using the smallest version that fits it
+func Encode(text string) (code *Code, err error) {
+	var encoded *qrcode.QRCode
+
+	if encoded, err = qrcode.New(text, qrcode.Medium); err != nil {
+		return
+	}
+
+	// String renders its own quiet zone
+	encoded.DisableBorder = true
+
+	modules := encoded.Bitmap()
+	code = &Code{len(modules), modules}
+	return
+}
+
+// Size returns the width (and height) of the code in modules
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Dark tells whether the module at the given column and row is dark
+func (c *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.size && y < c.size && c.modules[y][x]
+}
+
+// String renders the code for terminals, two rows of modules per line,
+// forcing dark modules over a light background with ANSI colors so it
+// can be scanned regardless of the terminal theme
+func (c *Code) String() string {
+	const quiet = 2
+
+	var b strings.Builder
+
+	for y := -quiet; y < c.size+quiet; y += 2 {
+		b.WriteString("\x1b[30;47m")
+
+		for x := -quiet; x < c.size+quiet; x++ {
+			switch top, bottom := c.Dark(x, y), c.Dark(x, y+1); {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+
+		b.WriteString("\x1b[0m\n")
+	}
+
+	return b.String()
+}
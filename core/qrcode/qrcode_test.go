@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	for _, text := range []string{
+		"https://app.kool.live",
+		"https://my-feature-branch-preview.kool.live/some/path?with=query",
+		strings.Repeat("kool", 30),
+	} {
+		code, err := Encode(text)
+
+		if err != nil {
+			t.Fatalf("unexpected error encoding %d bytes: %v", len(text), err)
+		}
+
+		if size := code.Size(); size < 21 || (size-17)%4 != 0 {
+			t.Errorf("unexpected QR code size %d encoding '%s'", size, text)
+		}
+	}
+}
+
+func TestEncodeVersions(t *testing.T) {
+	code, _ := Encode("https://app.kool.live")
+
+	if code.Size() != 25 {
+		t.Errorf("expected version 2 (25 modules) code, got %d modules", code.Size())
+	}
+
+	if _, err := Encode(strings.Repeat("x", 3000)); err == nil {
+		t.Error("expected error encoding a text too long for a QR code")
+	}
+}
+
+func TestFinderPatterns(t *testing.T) {
+	code, _ := Encode("kool")
+
+	for _, corner := range [][2]int{{0, 0}, {code.Size() - 7, 0}, {0, code.Size() - 7}} {
+		for i := 0; i < 7; i++ {
+			if !code.Dark(corner[0]+i, corner[1]) || !code.Dark(corner[0], corner[1]+i) {
+				t.Errorf("missing finder pattern border at %v", corner)
+			}
+		}
+
+		if code.Dark(corner[0]+1, corner[1]+1) || !code.Dark(corner[0]+3, corner[1]+3) {
+			t.Errorf("bad finder pattern at %v", corner)
+		}
+	}
+
+	if code.Dark(-1, 0) || code.Dark(0, code.Size()) {
+		t.Error("modules out of the code should be light")
+	}
+}
+
+func TestString(t *testing.T) {
+	code, _ := Encode("kool")
+	lines := strings.Split(strings.TrimSuffix(code.String(), "\n"), "\n")
+
+	if len(lines) != (code.Size()+4+1)/2 {
+		t.Errorf("unexpected number of lines: %d", len(lines))
+	}
+
+	if !strings.Contains(lines[1], "█") || !strings.HasPrefix(lines[0], "\x1b[30;47m") {
+		t.Errorf("unexpected rendering: %q", lines[1])
+	}
+}
@@ -0,0 +1,37 @@
+package shell
+
+// FakePrompt holds data for fake prompt behavior
+type FakePrompt struct {
+	CalledInput       bool
+	CalledConfirm     bool
+	CalledMultiSelect bool
+
+	MockInput       map[string]string
+	MockConfirm     map[string]bool
+	MockMultiSelect map[string][]string
+	MockError       map[string]error
+}
+
+// Input fake behavior for prompting a free-text question
+func (f *FakePrompt) Input(question string, defaultAnswer string) (answer string, err error) {
+	f.CalledInput = true
+	answer = f.MockInput[question]
+	err = f.MockError[question]
+	return
+}
+
+// Confirm fake behavior for prompting a yes/no question
+func (f *FakePrompt) Confirm(question string, defaultAnswer bool) (answer bool, err error) {
+	f.CalledConfirm = true
+	answer = f.MockConfirm[question]
+	err = f.MockError[question]
+	return
+}
+
+// MultiSelect fake behavior for prompting a multiple choice question
+func (f *FakePrompt) MultiSelect(question string, options []string, defaultAnswers []string) (answers []string, err error) {
+	f.CalledMultiSelect = true
+	answers = f.MockMultiSelect[question]
+	err = f.MockError[question]
+	return
+}
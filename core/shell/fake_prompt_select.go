@@ -2,14 +2,21 @@ package shell
 
 // FakePromptSelect holds data for fake prompt select behavior
 type FakePromptSelect struct {
-	CalledAsk  bool
-	MockAnswer map[string]string
-	MockError  map[string]error
+	CalledAsk    bool
+	AskedOptions map[string][]string
+	MockAnswer   map[string]string
+	MockError    map[string]error
 }
 
 // Ask fake behavior for prompting a select question
 func (f *FakePromptSelect) Ask(question string, options []string) (answer string, err error) {
 	f.CalledAsk = true
+
+	if f.AskedOptions == nil {
+		f.AskedOptions = make(map[string][]string)
+	}
+
+	f.AskedOptions[question] = options
 	answer = f.MockAnswer[question]
 	err = f.MockError[question]
 	return
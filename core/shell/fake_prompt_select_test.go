@@ -20,6 +20,10 @@ func TestFakePromptSelect(t *testing.T) {
 		t.Errorf("expecting answer 'answer', got %s", answer)
 	}
 
+	if options := f.AskedOptions["question"]; len(options) != 1 || options[0] != "option" {
+		t.Errorf("expecting asked options [option], got %v", options)
+	}
+
 	f.MockError = make(map[string]error)
 	f.MockError["question"] = errors.New("error")
 
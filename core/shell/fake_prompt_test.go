@@ -0,0 +1,33 @@
+package shell
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFakePrompt(t *testing.T) {
+	f := &FakePrompt{
+		MockInput:       map[string]string{"name": "my-app"},
+		MockConfirm:     map[string]bool{"mailhog": true},
+		MockMultiSelect: map[string][]string{"extras": {"a", "b"}},
+	}
+
+	if answer, err := f.Input("name", ""); err != nil || answer != "my-app" || !f.CalledInput {
+		t.Errorf("unexpected Input answer: %s %v", answer, err)
+	}
+
+	if answer, err := f.Confirm("mailhog", false); err != nil || !answer || !f.CalledConfirm {
+		t.Errorf("unexpected Confirm answer: %v %v", answer, err)
+	}
+
+	if answers, err := f.MultiSelect("extras", []string{"a", "b", "c"}, nil); err != nil || !reflect.DeepEqual(answers, []string{"a", "b"}) || !f.CalledMultiSelect {
+		t.Errorf("unexpected MultiSelect answers: %v %v", answers, err)
+	}
+
+	f.MockError = map[string]error{"name": errors.New("error")}
+
+	if _, err := f.Input("name", ""); err == nil {
+		t.Error("should throw an error on Input")
+	}
+}
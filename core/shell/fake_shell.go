@@ -5,6 +5,7 @@ import (
 	"io"
 	"kool-dev/kool/core/builder"
 	"strings"
+	"sync"
 )
 
 // FakeShell fake shell data
@@ -19,6 +20,7 @@ type FakeShell struct {
 	CalledInteractive  map[string]bool
 	CalledLookPath     map[string]bool
 	ArgsInteractive    map[string][]string
+	ArgsExec           map[string][]string
 
 	Err           error
 	OutLines      []string
@@ -32,6 +34,9 @@ type FakeShell struct {
 	MockErrStream io.Writer
 	MockInStream  io.Reader
 	MockLookPath  error
+
+	// mtx guards the commands calls made concurrently
+	mtx sync.Mutex
 }
 
 // InStream is a mocked testing function
@@ -69,11 +74,19 @@ func (f *FakeShell) SetErrStream(errStream io.Writer) {
 
 // Exec is a mocked testing function
 func (f *FakeShell) Exec(command builder.Command, extraArgs ...string) (outStr string, err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
 	if f.CalledExec == nil {
 		f.CalledExec = make(map[string]bool)
 	}
 
+	if f.ArgsExec == nil {
+		f.ArgsExec = make(map[string][]string)
+	}
+
 	f.CalledExec[command.Cmd()] = true
+	f.ArgsExec[command.Cmd()] = extraArgs
 
 	if _, ok := command.(*builder.FakeCommand); ok {
 		err = command.(*builder.FakeCommand).MockExecError
@@ -84,6 +97,9 @@ func (f *FakeShell) Exec(command builder.Command, extraArgs ...string) (outStr s
 
 // Interactive is a mocked testing function
 func (f *FakeShell) Interactive(command builder.Command, extraArgs ...string) (err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
 	if f.CalledInteractive == nil {
 		f.CalledInteractive = make(map[string]bool)
 	}
@@ -0,0 +1,60 @@
+package shell
+
+import (
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+)
+
+// Prompt contract that holds logic for prompting free-text,
+// yes/no and multiple choice questions
+type Prompt interface {
+	Input(string, string) (string, error)
+	Confirm(string, bool) (bool, error)
+	MultiSelect(string, []string, []string) ([]string, error)
+}
+
+// DefaultPrompt holds data for prompting questions
+type DefaultPrompt struct{}
+
+// NewPrompt creates a new prompt
+func NewPrompt() Prompt {
+	return &DefaultPrompt{}
+}
+
+// Input prompt to the user a free-text question
+func (p *DefaultPrompt) Input(question string, defaultAnswer string) (answer string, err error) {
+	prompt := &survey.Input{
+		Message: question,
+		Default: defaultAnswer,
+	}
+	err = askOne(prompt, &answer)
+	return
+}
+
+// Confirm prompt to the user a yes/no question
+func (p *DefaultPrompt) Confirm(question string, defaultAnswer bool) (answer bool, err error) {
+	prompt := &survey.Confirm{
+		Message: question,
+		Default: defaultAnswer,
+	}
+	err = askOne(prompt, &answer)
+	return
+}
+
+// MultiSelect prompt to the user a multiple choice question
+func (p *DefaultPrompt) MultiSelect(question string, options []string, defaultAnswers []string) (answers []string, err error) {
+	prompt := &survey.MultiSelect{
+		Message: question,
+		Options: options,
+		Default: defaultAnswers,
+	}
+	err = askOne(prompt, &answers)
+	return
+}
+
+func askOne(prompt survey.Prompt, response interface{}) (err error) {
+	if err = survey.AskOne(prompt, response); err != nil && err == terminal.InterruptErr {
+		err = ErrUserCancelled
+	}
+	return
+}
@@ -0,0 +1,13 @@
+package shell
+
+import (
+	"testing"
+)
+
+func TestNewPrompt(t *testing.T) {
+	p := NewPrompt()
+
+	if _, ok := p.(*DefaultPrompt); !ok {
+		t.Errorf("unexpected Prompt on NewPrompt")
+	}
+}
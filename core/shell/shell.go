@@ -120,6 +120,12 @@ func (s *DefaultShell) Exec(command builder.Command, extraArgs ...string) (outSt
 		verbose bool     = s.env.IsTrue("KOOL_VERBOSE")
 	)
 
+	if checker, ok := command.(builder.Checker); ok {
+		if err = checker.Check(); err != nil {
+			return
+		}
+	}
+
 	if len(extraArgs) > 0 {
 		args = append(args, extraArgs...)
 	}
@@ -150,9 +156,16 @@ func (s *DefaultShell) Interactive(originalCmd builder.Command, extraArgs ...str
 	var (
 		cmdptr  *CommandWithPointers
 		verbose bool = s.env.IsTrue("KOOL_VERBOSE")
-		command      = originalCmd.Copy()
+		command builder.Command
 	)
 
+	if checker, ok := originalCmd.(builder.Checker); ok {
+		if err = checker.Check(); err != nil {
+			return
+		}
+	}
+
+	command = originalCmd.Copy()
 	command.AppendArgs(extraArgs...)
 
 	// soon should refactor this onto a struct with methods
@@ -309,6 +309,27 @@ func TestInteractiveLookPathErrorDefaultShell(t *testing.T) {
 	}
 }
 
+type failingCheckCommand struct {
+	*builder.DefaultCommand
+}
+
+func (c *failingCheckCommand) Check() error {
+	return errors.New("check error")
+}
+
+func TestCheckerDefaultShell(t *testing.T) {
+	s := NewShell()
+	command := &failingCheckCommand{builder.NewCommand("echo", "x")}
+
+	if _, err := s.Exec(command); err == nil || err.Error() != "check error" {
+		t.Errorf("expecting check error on Exec, got '%v'", err)
+	}
+
+	if err := s.Interactive(command); err == nil || err.Error() != "check error" {
+		t.Errorf("expecting check error on Interactive, got '%v'", err)
+	}
+}
+
 func TestPrintlnShell(t *testing.T) {
 	o, b := newTestingOutputShell()
 
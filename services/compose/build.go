@@ -0,0 +1,404 @@
+package compose
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var dockerfileArgRegex = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+
+// ServiceBuild holds the image and build settings of a docker-compose service
+type ServiceBuild struct {
+	Service    string
+	Image      string
+	Context    string
+	Dockerfile string
+	Target     string
+	Args       map[string]string
+}
+
+// IsBuilt tells whether the service image is built from a local context
+func (b *ServiceBuild) IsBuilt() bool {
+	return b.Context != ""
+}
+
+// Merge overrides the settings with the ones defined by a later docker-compose file
+func (b *ServiceBuild) Merge(other ServiceBuild) {
+	if other.Image != "" {
+		b.Image = other.Image
+	}
+
+	if other.Context != "" {
+		b.Context = other.Context
+	}
+
+	if other.Dockerfile != "" {
+		b.Dockerfile = other.Dockerfile
+	}
+
+	if other.Target != "" {
+		b.Target = other.Target
+	}
+
+	for key, value := range other.Args {
+		if b.Args == nil {
+			b.Args = make(map[string]string)
+		}
+
+		b.Args[key] = value
+	}
+}
+
+type buildsCompose struct {
+	Services map[string]struct {
+		Image string      `yaml:"image"`
+		Build interface{} `yaml:"build"`
+	} `yaml:"services"`
+}
+
+// ParseServicesBuilds reads the image and build settings of the
+// services of the given docker-compose file content, sorted by name
+func ParseServicesBuilds(content string) (builds []ServiceBuild, err error) {
+	var parsed = new(buildsCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	for service, definition := range parsed.Services {
+		build := ServiceBuild{Service: service, Image: definition.Image}
+
+		switch value := definition.Build.(type) {
+		case string:
+			build.Context = value
+		case map[interface{}]interface{}:
+			build.Context = stringValue(value["context"])
+			build.Dockerfile = stringValue(value["dockerfile"])
+			build.Target = stringValue(value["target"])
+			build.Args = parseKeyValues(value["args"])
+
+			if build.Context == "" {
+				build.Context = "."
+			}
+		}
+
+		builds = append(builds, build)
+	}
+
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].Service < builds[j].Service
+	})
+
+	return
+}
+
+// parseKeyValues reads build args or labels in both map and list (KEY=value) syntaxes
+func parseKeyValues(value interface{}) (args map[string]string) {
+	args = make(map[string]string)
+
+	switch entries := value.(type) {
+	case map[interface{}]interface{}:
+		for key, arg := range entries {
+			args[fmt.Sprintf("%v", key)] = stringValue(arg)
+		}
+	case []interface{}:
+		for _, entry := range entries {
+			parts := strings.SplitN(fmt.Sprintf("%v", entry), "=", 2)
+
+			if len(parts) == 2 {
+				args[parts[0]] = parts[1]
+			} else {
+				args[parts[0]] = ""
+			}
+		}
+	}
+
+	return
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// ContextPath returns the build context path, relative paths
+// being resolved from the given project folder
+func (b *ServiceBuild) ContextPath(dir string) string {
+	if filepath.IsAbs(b.Context) {
+		return b.Context
+	}
+
+	return filepath.Join(dir, b.Context)
+}
+
+// DockerfilePath returns the Dockerfile path, which is relative to the build context
+func (b *ServiceBuild) DockerfilePath(dir string) string {
+	dockerfile := b.Dockerfile
+
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	if filepath.IsAbs(dockerfile) {
+		return dockerfile
+	}
+
+	return filepath.Join(b.ContextPath(dir), dockerfile)
+}
+
+// BaseImages reads the images the service Dockerfile is built FROM,
+// leaving out references to its own build stages and scratch
+func (b *ServiceBuild) BaseImages(dir string) (images []string, err error) {
+	var (
+		file    *os.File
+		stages  = map[string]bool{"scratch": true}
+		seen    = make(map[string]bool)
+		args    = make(map[string]string)
+		scanner *bufio.Scanner
+	)
+
+	if file, err = os.Open(b.DockerfilePath(dir)); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	scanner = bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "ARG":
+			// only the global args declared before the first FROM can
+			// be used on FROM instructions; later ones are harmless here
+			parts := strings.SplitN(fields[1], "=", 2)
+
+			if value, isBuildArg := b.Args[parts[0]]; isBuildArg {
+				args[parts[0]] = value
+			} else if len(parts) == 2 {
+				args[parts[0]] = strings.Trim(parts[1], `"'`)
+			}
+		case "FROM":
+			var image string
+
+			for i := 1; i < len(fields); i++ {
+				if strings.HasPrefix(fields[i], "--") {
+					continue
+				}
+
+				image = dockerfileArgRegex.ReplaceAllStringFunc(fields[i], func(ref string) string {
+					return args[dockerfileArgRegex.FindStringSubmatch(ref)[1]]
+				})
+
+				if i+2 < len(fields) && strings.EqualFold(fields[i+1], "as") {
+					stages[strings.ToLower(fields[i+2])] = true
+				}
+				break
+			}
+
+			if image != "" && !stages[strings.ToLower(image)] && !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+
+	err = scanner.Err()
+	return
+}
+
+// Hash computes a content hash of all the inputs of the service image
+// build: the context files (respecting .dockerignore), the Dockerfile,
+// the build settings and the IDs of the base images
+func (b *ServiceBuild) Hash(dir string, baseImageIDs map[string]string) (hash string, err error) {
+	var (
+		h       = sha256.New()
+		context = b.ContextPath(dir)
+		ignore  *dockerIgnore
+		keys    []string
+	)
+
+	fmt.Fprintf(h, "dockerfile:%s\ntarget:%s\n", b.Dockerfile, b.Target)
+
+	for key := range b.Args {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(h, "arg:%s=%s\n", key, b.Args[key])
+	}
+
+	keys = nil
+	for image := range baseImageIDs {
+		keys = append(keys, image)
+	}
+
+	sort.Strings(keys)
+
+	for _, image := range keys {
+		fmt.Fprintf(h, "base:%s=%s\n", image, baseImageIDs[image])
+	}
+
+	if err = hashFile(h, "Dockerfile", b.DockerfilePath(dir)); err != nil {
+		return
+	}
+
+	if ignore, err = readDockerIgnore(context); err != nil {
+		return
+	}
+
+	if err = filepath.Walk(context, func(path string, info os.FileInfo, walkErr error) error {
+		var rel string
+
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if rel, walkErr = filepath.Rel(context, path); walkErr != nil || rel == "." {
+			return walkErr
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if ignore.matches(rel) {
+			if info.IsDir() && !ignore.hasExceptions {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case info.IsDir():
+			fmt.Fprintf(h, "dir:%s\n", rel)
+		case info.Mode()&os.ModeSymlink != 0:
+			target, _ := os.Readlink(path)
+			fmt.Fprintf(h, "link:%s=%s\n", rel, target)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "mode:%s=%o\n", rel, info.Mode().Perm())
+			return hashFile(h, rel, path)
+		}
+
+		return nil
+	}); err != nil {
+		return
+	}
+
+	hash = fmt.Sprintf("%x", h.Sum(nil))
+	return
+}
+
+func hashFile(h io.Writer, name, path string) (err error) {
+	var file *os.File
+
+	if file, err = os.Open(path); err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	fmt.Fprintf(h, "file:%s\n", name)
+	_, err = io.Copy(h, file)
+	return
+}
+
+// dockerIgnore holds the .dockerignore patterns of a build context
+type dockerIgnore struct {
+	patterns      []*regexp.Regexp
+	exceptions    []bool
+	hasExceptions bool
+}
+
+func readDockerIgnore(context string) (ignore *dockerIgnore, err error) {
+	var content []byte
+
+	ignore = new(dockerIgnore)
+
+	if content, err = os.ReadFile(filepath.Join(context, ".dockerignore")); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		var exception bool
+
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			exception = true
+			ignore.hasExceptions = true
+			line = strings.TrimSpace(line[1:])
+		}
+
+		line = strings.Trim(filepath.ToSlash(filepath.Clean(line)), "/")
+
+		ignore.patterns = append(ignore.patterns, ignorePatternRegex(line))
+		ignore.exceptions = append(ignore.exceptions, exception)
+	}
+
+	return
+}
+
+// ignorePatternRegex converts a .dockerignore pattern into a regular expression
+func ignorePatternRegex(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// **/ matches zero or more folders
+					i++
+					expr.WriteString("(.*/)?")
+				} else {
+					expr.WriteString(".*")
+				}
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return regexp.MustCompile("^" + expr.String() + "$")
+}
+
+// matches tells whether the given path (or one of its parent folders) is
+// ignored; as in docker, the last matching pattern decides
+func (d *dockerIgnore) matches(rel string) (ignored bool) {
+	for i, pattern := range d.patterns {
+		for path := rel; path != "." && path != "/" && path != ""; path = filepath.ToSlash(filepath.Dir(path)) {
+			if pattern.MatchString(path) {
+				ignored = !d.exceptions[i]
+				break
+			}
+		}
+	}
+
+	return
+}
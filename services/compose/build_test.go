@@ -0,0 +1,224 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseServicesBuilds(t *testing.T) {
+	content := `services:
+  app:
+    build: .
+  worker:
+    image: my/worker
+    build:
+      context: ./worker
+      dockerfile: Dockerfile.dev
+      target: dev
+      args:
+        VERSION: "1.0"
+  node:
+    build:
+      args:
+        - NODE_ENV=development
+        - EMPTY
+  database:
+    image: mysql:8.0
+`
+
+	builds, err := ParseServicesBuilds(content)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing builds: %v", err)
+	}
+
+	if len(builds) != 4 {
+		t.Fatalf("expected 4 services, got %v", builds)
+	}
+
+	if builds[0].Service != "app" || builds[0].Context != "." || !builds[0].IsBuilt() {
+		t.Errorf("unexpected app build: %v", builds[0])
+	}
+
+	if builds[1].Service != "database" || builds[1].IsBuilt() || builds[1].Image != "mysql:8.0" {
+		t.Errorf("unexpected database build: %v", builds[1])
+	}
+
+	if builds[2].Context != "." || builds[2].Args["NODE_ENV"] != "development" || builds[2].Args["EMPTY"] != "" {
+		t.Errorf("unexpected node build: %v", builds[2])
+	}
+
+	worker := builds[3]
+	if worker.Image != "my/worker" || worker.Context != "./worker" || worker.Dockerfile != "Dockerfile.dev" || worker.Target != "dev" || worker.Args["VERSION"] != "1.0" {
+		t.Errorf("unexpected worker build: %v", worker)
+	}
+
+	if _, err = ParseServicesBuilds("\tinvalid"); err == nil {
+		t.Error("expected error parsing invalid docker-compose content")
+	}
+}
+
+func TestServiceBuildMerge(t *testing.T) {
+	build := ServiceBuild{Service: "app", Context: ".", Args: map[string]string{"A": "1"}}
+	build.Merge(ServiceBuild{Service: "app", Image: "my/app", Target: "dev", Args: map[string]string{"B": "2"}})
+
+	if build.Image != "my/app" || build.Context != "." || build.Target != "dev" || build.Args["A"] != "1" || build.Args["B"] != "2" {
+		t.Errorf("unexpected merged build: %v", build)
+	}
+}
+
+func TestServiceBuildPaths(t *testing.T) {
+	build := ServiceBuild{Context: "docker"}
+
+	if build.ContextPath("/project") != filepath.Join("/project", "docker") {
+		t.Errorf("unexpected context path: %s", build.ContextPath("/project"))
+	}
+
+	if build.DockerfilePath("/project") != filepath.Join("/project", "docker", "Dockerfile") {
+		t.Errorf("unexpected Dockerfile path: %s", build.DockerfilePath("/project"))
+	}
+
+	build = ServiceBuild{Context: "/abs", Dockerfile: "/other/Dockerfile"}
+
+	if build.ContextPath("/project") != "/abs" || build.DockerfilePath("/project") != "/other/Dockerfile" {
+		t.Error("unexpected absolute paths")
+	}
+}
+
+func TestServiceBuildBaseImages(t *testing.T) {
+	dir := t.TempDir()
+	dockerfile := `ARG PHP_VERSION=7.4
+ARG NODE="14"
+FROM --platform=linux/amd64 node:${NODE}-alpine AS assets
+RUN yarn build
+
+FROM kooldev/php:$PHP_VERSION AS app
+COPY --from=assets /app/public /app/public
+
+FROM app
+FROM scratch
+FROM kooldev/php:$PHP_VERSION
+`
+
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(dockerfile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	build := ServiceBuild{Context: ".", Args: map[string]string{"PHP_VERSION": "8.0"}}
+	images, err := build.BaseImages(dir)
+
+	if err != nil {
+		t.Fatalf("unexpected error reading base images: %v", err)
+	}
+
+	if strings.Join(images, ",") != "node:14-alpine,kooldev/php:8.0" {
+		t.Errorf("unexpected base images: %v", images)
+	}
+
+	build = ServiceBuild{Context: "missing"}
+
+	if _, err = build.BaseImages(dir); err == nil {
+		t.Error("expected error reading missing Dockerfile")
+	}
+}
+
+func TestServiceBuildHash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("Dockerfile", "FROM alpine\n")
+	write(".dockerignore", "node_modules\n*.log\n!important.log\n")
+	write("src/main.go", "package main")
+	write("node_modules/lib/index.js", "module.exports = {}")
+	write("debug.log", "debug")
+	write("important.log", "important")
+
+	build := ServiceBuild{Context: "."}
+	hash := func(ids map[string]string) string {
+		h, err := build.Hash(dir, ids)
+		if err != nil {
+			t.Fatalf("unexpected error hashing build: %v", err)
+		}
+		return h
+	}
+
+	base := hash(map[string]string{"alpine": "sha256:1"})
+
+	if again := hash(map[string]string{"alpine": "sha256:1"}); again != base {
+		t.Error("hash should be stable")
+	}
+
+	write("node_modules/lib/index.js", "module.exports = {changed: true}")
+	write("debug.log", "more debug")
+
+	if hash(map[string]string{"alpine": "sha256:1"}) != base {
+		t.Error("hash should not change with ignored files")
+	}
+
+	write("important.log", "changed")
+
+	changed := hash(map[string]string{"alpine": "sha256:1"})
+	if changed == base {
+		t.Error("hash should change with files excepted from .dockerignore")
+	}
+
+	write("src/main.go", "package main // changed")
+
+	if hash(map[string]string{"alpine": "sha256:1"}) == changed {
+		t.Error("hash should change with context files")
+	}
+
+	changed = hash(map[string]string{"alpine": "sha256:1"})
+
+	if hash(map[string]string{"alpine": "sha256:2"}) == changed {
+		t.Error("hash should change with base image ID")
+	}
+
+	build.Args = map[string]string{"VERSION": "2"}
+
+	if hash(map[string]string{"alpine": "sha256:1"}) == changed {
+		t.Error("hash should change with build args")
+	}
+
+	build = ServiceBuild{Context: "missing"}
+
+	if _, err := build.Hash(dir, nil); err == nil {
+		t.Error("expected error hashing missing context")
+	}
+}
+
+func TestDockerIgnoreMatches(t *testing.T) {
+	ignore := &dockerIgnore{}
+
+	for _, pattern := range []string{"**/*.tmp", "build", "docs/*.md", "/vendor/"} {
+		pattern = strings.Trim(filepath.ToSlash(filepath.Clean(pattern)), "/")
+		ignore.patterns = append(ignore.patterns, ignorePatternRegex(pattern))
+		ignore.exceptions = append(ignore.exceptions, false)
+	}
+
+	paths := map[string]bool{
+		"a.tmp":           true,
+		"deep/down/b.tmp": true,
+		"build/out.bin":   true,
+		"src/build":       false,
+		"docs/readme.md":  true,
+		"docs/sub/x.md":   false,
+		"vendor/lib.go":   true,
+		"main.go":         false,
+	}
+
+	for path, expected := range paths {
+		if ignore.matches(path) != expected {
+			t.Errorf("expected ignored=%v for %s", expected, path)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package compose
+
+import (
+	"sort"
+	"strings"
+)
+
+// Database engines detected from the docker-compose services images
+const (
+	EngineMySQL      = "mysql"
+	EngineMariaDB    = "mariadb"
+	EnginePostgreSQL = "postgresql"
+)
+
+// DatabaseService holds a docker-compose service running a known database engine
+type DatabaseService struct {
+	Service string
+	Engine  string
+	Image   string
+}
+
+type databasesCompose struct {
+	Services map[string]struct {
+		Image string `yaml:"image"`
+	} `yaml:"services"`
+}
+
+// ParseDatabaseServices reads the services of the given docker-compose file
+// content whose images run a known database engine, sorted by service name
+func ParseDatabaseServices(content string) (databases []DatabaseService, err error) {
+	var parsed = new(databasesCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	for service, definition := range parsed.Services {
+		if engine := imageEngine(definition.Image); engine != "" {
+			databases = append(databases, DatabaseService{service, engine, definition.Image})
+		}
+	}
+
+	sort.Slice(databases, func(i, j int) bool {
+		return databases[i].Service < databases[j].Service
+	})
+
+	return
+}
+
+// imageEngine tells the database engine of an image like mysql:8.0,
+// library/postgres:13-alpine or registry.example.com/mariadb:10.5
+func imageEngine(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+
+	if i := strings.IndexAny(name, ":@"); i >= 0 {
+		name = name[:i]
+	}
+
+	switch name {
+	case "mysql", "percona":
+		return EngineMySQL
+	case "mariadb":
+		return EngineMariaDB
+	case "postgres", "postgis":
+		return EnginePostgreSQL
+	}
+
+	return ""
+}
@@ -0,0 +1,68 @@
+package compose
+
+import (
+	"testing"
+)
+
+func TestParseDatabaseServices(t *testing.T) {
+	content := `services:
+  app:
+    image: kooldev/php:8.0-nginx
+  mysql:
+    image: mysql:8.0
+  maria:
+    image: mariadb:10.5
+  pg:
+    image: library/postgres:13-alpine
+  cache:
+    image: redis:6-alpine
+  built:
+    build: .
+`
+
+	databases, err := ParseDatabaseServices(content)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing database services: %v", err)
+	}
+
+	expected := []DatabaseService{
+		{"maria", EngineMariaDB, "mariadb:10.5"},
+		{"mysql", EngineMySQL, "mysql:8.0"},
+		{"pg", EnginePostgreSQL, "library/postgres:13-alpine"},
+	}
+
+	if len(databases) != len(expected) {
+		t.Fatalf("expected %d database services, got %v", len(expected), databases)
+	}
+
+	for i := range expected {
+		if databases[i] != expected[i] {
+			t.Errorf("expected database service %v, got %v", expected[i], databases[i])
+		}
+	}
+}
+
+func TestParseDatabaseServicesError(t *testing.T) {
+	if _, err := ParseDatabaseServices("\tinvalid"); err == nil {
+		t.Error("expected error parsing invalid docker-compose content")
+	}
+}
+
+func TestImageEngine(t *testing.T) {
+	images := map[string]string{
+		"mysql":                               EngineMySQL,
+		"mysql:5.7":                           EngineMySQL,
+		"registry.example.com/mariadb:10.5":   EngineMariaDB,
+		"postgres@sha256:abc":                 EnginePostgreSQL,
+		"mysql-client:latest":                 "",
+		"kooldev/php:8.0":                     "",
+		"registry.example.com:5000/redis:6.0": "",
+	}
+
+	for image, engine := range images {
+		if got := imageEngine(image); got != engine {
+			t.Errorf("expected engine '%s' for image %s, got '%s'", engine, image, got)
+		}
+	}
+}
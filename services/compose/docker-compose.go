@@ -1,11 +1,14 @@
 package compose
 
 import (
+	"errors"
 	"fmt"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
 	"kool-dev/kool/core/shell"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -17,6 +20,18 @@ type TtyAware interface {
 	SetIsTTY(bool)
 }
 
+// ProfileAware interface holds functions for enabling
+// docker-compose profiles
+type ProfileAware interface {
+	SetProfiles([]string)
+}
+
+// FilesAware interface holds functions for resolving the
+// docker-compose files to be layered
+type FilesAware interface {
+	Files() ([]string, error)
+}
+
 // DockerCompose holds data and logic to wrap docker-compose command
 // within a container for flexibility
 type DockerCompose struct {
@@ -24,7 +39,13 @@ type DockerCompose struct {
 	localDockerCompose builder.Command
 	env                environment.EnvStorage
 	sh                 shell.Shell
+	koolParser         parser.Parser
 	isTTY              bool
+	profiles           []string
+
+	files         []string
+	filesErr      error
+	filesResolved bool
 }
 
 // NewDockerCompose creates a new instance of DockerCompose
@@ -33,6 +54,7 @@ func NewDockerCompose(cmd string, args ...string) *DockerCompose {
 		Command:            builder.NewCommand(cmd, args...),
 		env:                environment.NewEnvStorage(),
 		sh:                 shell.NewShell(),
+		koolParser:         parser.NewParser(),
 		localDockerCompose: builder.NewCommand("docker-compose"),
 	}
 }
@@ -42,6 +64,11 @@ func (c *DockerCompose) SetIsTTY(tty bool) {
 	c.isTTY = tty
 }
 
+// SetProfiles sets the docker-compose profiles to be enabled
+func (c *DockerCompose) SetProfiles(profiles []string) {
+	c.profiles = profiles
+}
+
 // SetShell sets the shell.Shell to be used
 func (c *DockerCompose) SetShell(sh shell.Shell) *DockerCompose {
 	c.sh = sh
@@ -49,6 +76,21 @@ func (c *DockerCompose) SetShell(sh shell.Shell) *DockerCompose {
 	return c
 }
 
+// SetEnv sets the environment.EnvStorage to be used
+func (c *DockerCompose) SetEnv(env environment.EnvStorage) *DockerCompose {
+	c.env = env
+
+	return c
+}
+
+// SetKoolParser sets the parser.Parser to be used for reading
+// the compose files settings from kool.yml
+func (c *DockerCompose) SetKoolParser(koolParser parser.Parser) *DockerCompose {
+	c.koolParser = koolParser
+
+	return c
+}
+
 // SetLocalDockerCompose sets the builder.Command to be used for checking
 // docker-compose on PATH
 func (c *DockerCompose) SetLocalDockerCompose(cmd builder.Command) *DockerCompose {
@@ -60,7 +102,8 @@ func (c *DockerCompose) SetLocalDockerCompose(cmd builder.Command) *DockerCompos
 // Args returns the command arguments
 func (c *DockerCompose) Args() (args []string) {
 	if c.sh.LookPath(c.localDockerCompose) == nil {
-		return append([]string{c.Command.Cmd()}, c.Command.Args()...)
+		args = append(c.globalArgs(), c.Command.Cmd())
+		return append(args, c.Command.Args()...)
 	}
 
 	args = append(args, "run", "--rm", "-i")
@@ -102,10 +145,92 @@ func (c *DockerCompose) Args() (args []string) {
 		args = append(args, "-e", key)
 	}
 
-	args = append(args, DockerComposeImage, "-p", c.env.Get("KOOL_NAME"), c.Command.Cmd())
+	args = append(args, DockerComposeImage, "-p", c.env.Get("KOOL_NAME"))
+	args = append(args, c.globalArgs()...)
+	args = append(args, c.Command.Cmd())
 	return append(args, c.Command.Args()...)
 }
 
+// Files returns the docker-compose files to be layered. They come from
+// KOOL_COMPOSE_FILES or from the kool.yml compose section environment set
+// by KOOL_COMPOSE_ENV (or "default"); an environment not present in kool.yml
+// layers docker-compose.<environment>.yml over docker-compose.yml and
+// docker-compose.override.yml (when present). An empty list means relying
+// on docker-compose defaults. The files are resolved only once.
+func (c *DockerCompose) Files() (files []string, err error) {
+	if !c.filesResolved {
+		c.files, c.filesErr = c.resolveFiles()
+		c.filesResolved = true
+	}
+
+	files, err = c.files, c.filesErr
+	return
+}
+
+// Check tells whether the docker-compose files could be resolved
+func (c *DockerCompose) Check() (err error) {
+	_, err = c.Files()
+	return
+}
+
+func (c *DockerCompose) resolveFiles() (files []string, err error) {
+	var environment string
+
+	if list := c.env.Get("KOOL_COMPOSE_FILES"); list != "" {
+		for _, file := range filepath.SplitList(list) {
+			if file = strings.TrimSpace(file); file != "" {
+				files = append(files, file)
+			}
+		}
+		return
+	}
+
+	if environment = c.env.Get("KOOL_COMPOSE_ENV"); environment == "" {
+		environment = "default"
+	}
+
+	if err = c.koolParser.AddLookupPath(c.env.Get("PWD")); err == nil {
+		if files, err = c.koolParser.ParseComposeFiles(environment); err == nil {
+			return
+		}
+	}
+
+	if !errors.Is(err, parser.ErrKoolYmlNotFound) && !errors.Is(err, parser.ErrComposeEnvironmentNotFound) {
+		return
+	}
+
+	err = nil
+
+	if environment != "default" {
+		files = []string{"docker-compose.yml"}
+
+		if _, statErr := os.Stat(filepath.Join(c.env.Get("PWD"), "docker-compose.override.yml")); statErr == nil {
+			files = append(files, "docker-compose.override.yml")
+		}
+
+		files = append(files, fmt.Sprintf("docker-compose.%s.yml", environment))
+	}
+
+	return
+}
+
+// globalArgs returns the docker-compose options that must
+// come before the actual docker-compose command
+func (c *DockerCompose) globalArgs() (args []string) {
+	// errors are surfaced by Check before running,
+	// here we just fallback to docker-compose defaults
+	files, _ := c.Files()
+
+	for _, file := range files {
+		args = append(args, "-f", file)
+	}
+
+	for _, profile := range c.profiles {
+		args = append(args, "--profile", profile)
+	}
+	return
+}
+
 // Cmd returns the command executable
 func (c *DockerCompose) Cmd() string {
 	if c.sh.LookPath(c.localDockerCompose) == nil {
@@ -121,7 +246,11 @@ func (c *DockerCompose) String() string {
 
 // Copy clones the pointer to avoid unintended modifications
 func (c *DockerCompose) Copy() (copied builder.Command) {
-	copied = NewDockerCompose(c.Command.Cmd(), c.Command.Args()...)
-	copied.(*DockerCompose).SetIsTTY(c.isTTY)
+	cp := NewDockerCompose(c.Command.Cmd(), c.Command.Args()...)
+	cp.SetShell(c.sh).SetEnv(c.env).SetKoolParser(c.koolParser).SetLocalDockerCompose(c.localDockerCompose)
+	cp.SetIsTTY(c.isTTY)
+	cp.SetProfiles(c.profiles)
+	cp.files, cp.filesErr, cp.filesResolved = c.files, c.filesErr, c.filesResolved
+	copied = cp
 	return
 }
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"kool-dev/kool/core/builder"
 	"kool-dev/kool/core/environment"
+	"kool-dev/kool/core/parser"
 	"kool-dev/kool/core/shell"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -35,6 +37,8 @@ func TestNewDockerCompose(t *testing.T) {
 	dc.SetLocalDockerCompose(&builder.FakeCommand{
 		MockLookPathError: errors.New("some error"),
 	})
+	dc.SetEnv(environment.NewFakeEnvStorage())
+	dc.SetKoolParser(&parser.FakeParser{})
 	if !strings.HasPrefix(dc.String(), "docker run --rm -i") {
 		t.Errorf("unexpected DockerCompose.String() prefix: %s", dc.String())
 	}
@@ -110,3 +114,132 @@ func TestDockerComposeArgsParsing(t *testing.T) {
 		t.Error("bad copy - failed passing isTTY")
 	}
 }
+
+func TestDockerComposeProfiles(t *testing.T) {
+	dc := NewDockerCompose("up", "-d")
+	dc.sh = &shell.FakeShell{}
+	dc.env = environment.NewFakeEnvStorage()
+	dc.localDockerCompose = &builder.FakeCommand{}
+
+	if strings.Contains(dc.String(), "--profile") {
+		t.Error("unexpected --profile flag without profiles")
+	}
+
+	dc.SetProfiles([]string{"backend", "tools"})
+
+	if !strings.HasPrefix(dc.String(), "docker-compose --profile backend --profile tools up -d") {
+		t.Errorf("unexpected DockerCompose.String() with profiles: %s", dc.String())
+	}
+
+	dc.localDockerCompose = &builder.FakeCommand{
+		MockLookPathError: errors.New("some error"),
+	}
+
+	if !strings.HasSuffix(dc.String(), "--profile backend --profile tools up -d") {
+		t.Errorf("unexpected containerized DockerCompose.String() with profiles: %s", dc.String())
+	}
+
+	if cp, ok := dc.Copy().(*DockerCompose); !ok || len(cp.profiles) != 2 {
+		t.Error("bad copy - failed passing profiles")
+	}
+}
+
+func newFilesDockerCompose(envs map[string]string) *DockerCompose {
+	dc := NewDockerCompose("up")
+	dc.sh = &shell.FakeShell{}
+	dc.env = environment.NewFakeEnvStorage()
+	dc.localDockerCompose = &builder.FakeCommand{}
+	dc.koolParser = &parser.FakeParser{
+		MockParseComposeFilesError: map[string]error{
+			"default": parser.ErrComposeEnvironmentNotFound,
+			"debug":   parser.ErrComposeEnvironmentNotFound,
+		},
+		MockComposeFiles: map[string][]string{
+			"ci": {"docker-compose.yml", "docker-compose.ci.yml"},
+		},
+	}
+
+	for key, value := range envs {
+		dc.env.Set(key, value)
+	}
+
+	return dc
+}
+
+func TestDockerComposeFiles(t *testing.T) {
+	dc := newFilesDockerCompose(nil)
+
+	if files, err := dc.Files(); err != nil || len(files) != 0 {
+		t.Errorf("expected no files for docker-compose defaults; got %v (%v)", files, err)
+	}
+
+	if dc.String() != "docker-compose up" {
+		t.Errorf("unexpected DockerCompose.String() without compose files: %s", dc.String())
+	}
+
+	dc = newFilesDockerCompose(map[string]string{"KOOL_COMPOSE_ENV": "ci"})
+
+	if dc.String() != "docker-compose -f docker-compose.yml -f docker-compose.ci.yml up" {
+		t.Errorf("unexpected DockerCompose.String() for kool.yml environment: %s", dc.String())
+	}
+
+	// resolved only once
+	dc.env.Set("KOOL_COMPOSE_ENV", "debug")
+
+	if dc.String() != "docker-compose -f docker-compose.yml -f docker-compose.ci.yml up" {
+		t.Errorf("unexpected DockerCompose.String() after resolving the compose files: %s", dc.String())
+	}
+
+	if cp, ok := dc.Copy().(*DockerCompose); !ok || cp.String() != dc.String() {
+		t.Error("bad copy - failed passing the compose files")
+	}
+
+	pwd := t.TempDir()
+	dc = newFilesDockerCompose(map[string]string{"KOOL_COMPOSE_ENV": "debug", "PWD": pwd})
+
+	if dc.String() != "docker-compose -f docker-compose.yml -f docker-compose.debug.yml up" {
+		t.Errorf("unexpected DockerCompose.String() for conventional environment: %s", dc.String())
+	}
+
+	if err := os.WriteFile(filepath.Join(pwd, "docker-compose.override.yml"), []byte("services: {}"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	dc = newFilesDockerCompose(map[string]string{"KOOL_COMPOSE_ENV": "debug", "PWD": pwd})
+
+	if dc.String() != "docker-compose -f docker-compose.yml -f docker-compose.override.yml -f docker-compose.debug.yml up" {
+		t.Errorf("unexpected DockerCompose.String() for conventional environment with override: %s", dc.String())
+	}
+
+	dc = newFilesDockerCompose(map[string]string{
+		"KOOL_COMPOSE_FILES": strings.Join([]string{"a.yml", "b.yml", ""}, string(filepath.ListSeparator)),
+	})
+
+	if dc.String() != "docker-compose -f a.yml -f b.yml up" {
+		t.Errorf("unexpected DockerCompose.String() for KOOL_COMPOSE_FILES: %s", dc.String())
+	}
+}
+
+func TestDockerComposeFilesError(t *testing.T) {
+	dc := NewDockerCompose("up")
+	dc.sh = &shell.FakeShell{}
+	dc.env = environment.NewFakeEnvStorage()
+	dc.localDockerCompose = &builder.FakeCommand{}
+	dc.koolParser = &parser.FakeParser{
+		MockParseComposeFilesError: map[string]error{
+			"default": errors.New("parse error"),
+		},
+	}
+
+	if _, err := dc.Files(); err == nil || err.Error() != "parse error" {
+		t.Errorf("expected parse error; got %v", err)
+	}
+
+	if err := dc.Check(); err == nil || err.Error() != "parse error" {
+		t.Errorf("expected parse error on Check; got %v", err)
+	}
+
+	if dc.String() != "docker-compose up" {
+		t.Errorf("unexpected DockerCompose.String() on compose files error: %s", dc.String())
+	}
+}
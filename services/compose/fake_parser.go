@@ -3,11 +3,14 @@ package compose
 // FakeParser implements all fake behaviors for using parser in tests.
 type FakeParser struct {
 	CalledParse      map[string]bool
+	CalledHasService map[string]bool
 	CalledSetService map[string]bool
 	CalledSetVolume  map[string]bool
+	CalledSetNetwork map[string]bool
 	CalledString     bool
 	MockParseError   error
 	MockStringError  error
+	MockHasService   map[string]bool
 }
 
 // Parse implements fake Parse behavior
@@ -21,6 +24,16 @@ func (f *FakeParser) Parse(content string) (err error) {
 	return
 }
 
+// HasService implements fake HasService behavior
+func (f *FakeParser) HasService(service string) bool {
+	if f.CalledHasService == nil {
+		f.CalledHasService = make(map[string]bool)
+	}
+
+	f.CalledHasService[service] = true
+	return f.MockHasService[service]
+}
+
 // SetService implements fake SetService behavior
 func (f *FakeParser) SetService(service string, content interface{}) {
 	if f.CalledSetService == nil {
@@ -31,7 +44,7 @@ func (f *FakeParser) SetService(service string, content interface{}) {
 }
 
 // SetVolume implements fake SetVolume behavior
-func (f *FakeParser) SetVolume(volume string) {
+func (f *FakeParser) SetVolume(volume string, content interface{}) {
 	if f.CalledSetVolume == nil {
 		f.CalledSetVolume = make(map[string]bool)
 	}
@@ -39,6 +52,15 @@ func (f *FakeParser) SetVolume(volume string) {
 	f.CalledSetVolume[volume] = true
 }
 
+// SetNetwork implements fake SetNetwork behavior
+func (f *FakeParser) SetNetwork(network string, content interface{}) {
+	if f.CalledSetNetwork == nil {
+		f.CalledSetNetwork = make(map[string]bool)
+	}
+
+	f.CalledSetNetwork[network] = true
+}
+
 // String implements fake String behavior
 func (f *FakeParser) String() (content string, err error) {
 	f.CalledString = true
@@ -23,18 +23,30 @@ func TestFakeParser(t *testing.T) {
 		t.Error("failed calling Parse")
 	}
 
+	f.MockHasService = map[string]bool{"service": true}
+
+	if !f.HasService("service") || f.HasService("other") || !f.CalledHasService["other"] {
+		t.Error("failed calling HasService")
+	}
+
 	f.SetService("service", "content")
 
 	if val, ok := f.CalledSetService["service"]; !ok || !val {
 		t.Error("failed calling SetService")
 	}
 
-	f.SetVolume("volume")
+	f.SetVolume("volume", nil)
 
 	if val, ok := f.CalledSetVolume["volume"]; !ok || !val {
 		t.Error("failed calling SetVolume")
 	}
 
+	f.SetNetwork("network", nil)
+
+	if val, ok := f.CalledSetNetwork["network"]; !ok || !val {
+		t.Error("failed calling SetNetwork")
+	}
+
 	f.MockStringError = errors.New("string error")
 
 	_, err = f.String()
@@ -7,17 +7,22 @@ type yamlMarshalFnType func(interface{}) ([]byte, error)
 
 // Compose represents a docker-compose file
 type Compose struct {
-	Version  string        `yaml:"version"`
+	Version  string        `yaml:"version,omitempty"`
 	Services yaml.MapSlice `yaml:"services"`
 	Volumes  yaml.MapSlice `yaml:"volumes,omitempty"`
 	Networks yaml.MapSlice `yaml:"networks,omitempty"`
+
+	// Others holds the remaining top-level keys (i.e. secrets, x-* extensions)
+	Others map[string]interface{} `yaml:",inline"`
 }
 
 // Parser holds logic for handling docker-compose
 type Parser interface {
 	Parse(string) error
+	HasService(string) bool
 	SetService(string, interface{})
-	SetVolume(string)
+	SetVolume(string, interface{})
+	SetNetwork(string, interface{})
 	String() (string, error)
 }
 
@@ -31,20 +36,26 @@ var (
 	yamlMarshalFn   yamlMarshalFnType   = yaml.Marshal
 )
 
+// DefaultNetworks returns the networks of kool projects: the project
+// local network and the global one shared by all projects
+func DefaultNetworks() yaml.MapSlice {
+	return yaml.MapSlice{
+		yaml.MapItem{Key: "kool_local"},
+		yaml.MapItem{
+			Key: "kool_global",
+			Value: yaml.MapSlice{
+				yaml.MapItem{Key: "external", Value: true},
+				yaml.MapItem{Key: "name", Value: "${KOOL_GLOBAL_NETWORK:-kool_global}"},
+			},
+		},
+	}
+}
+
 // NewParser creates new docker-compose parser
 func NewParser() Parser {
 	compose := &Compose{
-		Version: "3.7",
-		Networks: yaml.MapSlice{
-			yaml.MapItem{Key: "kool_local"},
-			yaml.MapItem{
-				Key: "kool_global",
-				Value: yaml.MapSlice{
-					yaml.MapItem{Key: "external", Value: true},
-					yaml.MapItem{Key: "name", Value: "${KOOL_GLOBAL_NETWORK:-kool_global}"},
-				},
-			},
-		},
+		Version:  "3.7",
+		Networks: DefaultNetworks(),
 	}
 	return &DefaultParser{compose}
 }
@@ -60,6 +71,17 @@ func (p *DefaultParser) Parse(content string) (err error) {
 	return
 }
 
+// HasService tells if the given service exists on docker-compose
+func (p *DefaultParser) HasService(serviceName string) bool {
+	for _, service := range p.compose.Services {
+		if service.Key == serviceName {
+			return true
+		}
+	}
+
+	return false
+}
+
 // SetService set docker-compose service
 func (p *DefaultParser) SetService(serviceName string, serviceContent interface{}) {
 	for index, service := range p.compose.Services {
@@ -76,7 +98,7 @@ func (p *DefaultParser) SetService(serviceName string, serviceContent interface{
 }
 
 // SetVolume remove a docker-compose volume
-func (p *DefaultParser) SetVolume(volume string) {
+func (p *DefaultParser) SetVolume(volume string, volumeContent interface{}) {
 	for _, v := range p.compose.Volumes {
 		if v.Key == volume {
 			return
@@ -84,7 +106,22 @@ func (p *DefaultParser) SetVolume(volume string) {
 	}
 
 	p.compose.Volumes = append(p.compose.Volumes, yaml.MapItem{
-		Key: volume,
+		Key:   volume,
+		Value: volumeContent,
+	})
+}
+
+// SetNetwork add a docker-compose network, if missing
+func (p *DefaultParser) SetNetwork(network string, networkContent interface{}) {
+	for _, n := range p.compose.Networks {
+		if n.Key == network {
+			return
+		}
+	}
+
+	p.compose.Networks = append(p.compose.Networks, yaml.MapItem{
+		Key:   network,
+		Value: networkContent,
 	})
 }
 
@@ -103,26 +103,103 @@ func TestServicesDefaultParser(t *testing.T) {
 	}
 }
 
+func TestHasServiceDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Parse(composeFile)
+
+	if !p.HasService("service2") {
+		t.Error("expected service2 to exist")
+	}
+
+	if p.HasService("database") {
+		t.Error("unexpected database service")
+	}
+}
+
+func TestKeepOtherKeysDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	content := `services:
+  app:
+    image: app
+secrets:
+  token:
+    file: ./token.txt
+x-logging:
+  driver: none
+`
+
+	if err := p.Parse(content); err != nil {
+		t.Fatalf("unexpected error parsing docker compose; error: %v", err)
+	}
+
+	p.SetService("cache", yaml.MapSlice{yaml.MapItem{Key: "image", Value: "redis"}})
+
+	output, err := p.String()
+
+	if err != nil {
+		t.Fatalf("unexpected error getting docker compose content; error: %v", err)
+	}
+
+	expected := `services:
+  app:
+    image: app
+  cache:
+    image: redis
+secrets:
+  token:
+    file: ./token.txt
+x-logging:
+  driver: none
+`
+
+	if output != expected {
+		t.Errorf("expecting content '%s', got '%s'", expected, output)
+	}
+}
+
 func TestVolumesDefaultParser(t *testing.T) {
 	p := NewParser()
 
 	volumes := yaml.MapSlice{
 		yaml.MapItem{Key: "database"},
+		yaml.MapItem{Key: "cache", Value: yaml.MapSlice{{Key: "driver", Value: "local"}}},
 	}
 
-	p.SetVolume("database")
+	p.SetVolume("database", nil)
+	p.SetVolume("cache", yaml.MapSlice{{Key: "driver", Value: "local"}})
 
 	if yamlData := getYamlData(p.(*DefaultParser)); !reflect.DeepEqual(volumes, yamlData.Volumes) {
 		t.Error("failed handling volumes")
 	}
 
-	p.SetVolume("database")
+	p.SetVolume("database", yaml.MapSlice{{Key: "driver", Value: "other"}})
 
 	if yamlData := getYamlData(p.(*DefaultParser)); !reflect.DeepEqual(volumes, yamlData.Volumes) {
 		t.Error("failed handling volumes")
 	}
 }
 
+func TestNetworksDefaultParser(t *testing.T) {
+	p := NewParser()
+
+	_ = p.Parse("services: {}")
+
+	p.SetNetwork("kool_local", nil)
+	p.SetNetwork("kool_global", yaml.MapSlice{yaml.MapItem{Key: "external", Value: true}})
+	p.SetNetwork("kool_local", "ignored")
+
+	networks := yaml.MapSlice{
+		yaml.MapItem{Key: "kool_local"},
+		yaml.MapItem{Key: "kool_global", Value: yaml.MapSlice{yaml.MapItem{Key: "external", Value: true}}},
+	}
+
+	if yamlData := getYamlData(p.(*DefaultParser)); !reflect.DeepEqual(networks, yamlData.Networks) {
+		t.Errorf("failed handling networks: %v", yamlData.Networks)
+	}
+}
+
 func TestErrorStringDefaultParser(t *testing.T) {
 	p := NewParser()
 	_ = p.Parse(composeFile)
@@ -0,0 +1,137 @@
+package compose
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var portVariableRegex = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)(?::?-([^}]*))?\}$`)
+
+// PublishedPort holds a host port published by a docker-compose service.
+// When the host port is set through an environment variable (i.e.
+// ${KOOL_DATABASE_PORT:-3306}) Variable and Default hold its parts.
+type PublishedPort struct {
+	Service  string
+	HostIP   string
+	HostPort string
+	Variable string
+	Default  string
+}
+
+// IsVariable tells whether the host port comes from an environment variable
+func (p *PublishedPort) IsVariable() bool {
+	return p.Variable != ""
+}
+
+// Resolve returns the actual host port, looking up the
+// environment variable value with the given function
+func (p *PublishedPort) Resolve(getenv func(string) string) string {
+	if !p.IsVariable() {
+		return p.HostPort
+	}
+
+	if value := getenv(p.Variable); value != "" {
+		return value
+	}
+
+	return p.Default
+}
+
+type portsCompose struct {
+	Services map[string]struct {
+		Ports []interface{} `yaml:"ports"`
+	} `yaml:"services"`
+}
+
+// ParsePublishedPorts reads the host ports published by the
+// services of the given docker-compose file content
+func ParsePublishedPorts(content string) (ports []PublishedPort, err error) {
+	var parsed = new(portsCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	for service, definition := range parsed.Services {
+		for _, entry := range definition.Ports {
+			var port *PublishedPort
+
+			switch value := entry.(type) {
+			case string:
+				port = parseShortPort(value)
+			case int:
+				// only the container port; docker picks a random host port
+				continue
+			case map[interface{}]interface{}:
+				if published, ok := value["published"]; ok {
+					port = &PublishedPort{HostPort: fmt.Sprintf("%v", published)}
+
+					if hostIP, ok := value["host_ip"].(string); ok {
+						port.HostIP = hostIP
+					}
+
+					port.parseVariable()
+				}
+			}
+
+			if port == nil || port.HostPort == "" || (strings.Contains(port.HostPort, "-") && !port.IsVariable()) {
+				// no host port published or a range of ports
+				continue
+			}
+
+			port.Service = service
+			ports = append(ports, *port)
+		}
+	}
+
+	return
+}
+
+func parseShortPort(value string) (port *PublishedPort) {
+	var (
+		parts []string
+		part  strings.Builder
+		depth int
+	)
+
+	value = strings.SplitN(value, "/", 2)[0]
+
+	for _, r := range value {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case r == ':' && depth == 0:
+			parts = append(parts, part.String())
+			part.Reset()
+			continue
+		}
+
+		part.WriteRune(r)
+	}
+
+	parts = append(parts, part.String())
+	port = new(PublishedPort)
+
+	switch len(parts) {
+	case 2:
+		port.HostPort = parts[0]
+	case 3:
+		port.HostIP, port.HostPort = parts[0], parts[1]
+	default:
+		return nil
+	}
+
+	port.parseVariable()
+	return
+}
+
+// parseVariable sets the environment variable and its default
+// when the host port is set through one (i.e. ${KOOL_APP_PORT:-80})
+func (p *PublishedPort) parseVariable() {
+	if matches := portVariableRegex.FindStringSubmatch(p.HostPort); matches != nil {
+		p.Variable, p.Default = matches[1], matches[2]
+	}
+}
@@ -0,0 +1,95 @@
+package compose
+
+import (
+	"sort"
+	"testing"
+)
+
+const composePorts = `services:
+  app:
+    ports:
+      - "${KOOL_APP_PORT:-80}:80"
+      - "9000"
+  database:
+    ports:
+      - "127.0.0.1:${KOOL_DATABASE_PORT:-3306}:3306/tcp"
+  cache:
+    ports:
+      - "6379:6379"
+      - "8000-8010:8000-8010"
+  mail:
+    ports:
+      - target: 1025
+        published: 1025
+      - target: 8025
+  web:
+    ports:
+      - target: 443
+        host_ip: 127.0.0.1
+        published: "${KOOL_WEB_PORT:-443}"
+  worker:
+    image: some-image
+`
+
+func TestParsePublishedPorts(t *testing.T) {
+	ports, err := ParsePublishedPorts(composePorts)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing published ports: %v", err)
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Service < ports[j].Service
+	})
+
+	if len(ports) != 5 {
+		t.Fatalf("expected 5 published ports; got %d (%v)", len(ports), ports)
+	}
+
+	if app := ports[0]; app.Service != "app" || app.Variable != "KOOL_APP_PORT" || app.Default != "80" || !app.IsVariable() {
+		t.Errorf("unexpected app published port: %v", app)
+	}
+
+	if cache := ports[1]; cache.Service != "cache" || cache.HostPort != "6379" || cache.IsVariable() {
+		t.Errorf("unexpected cache published port: %v", cache)
+	}
+
+	if database := ports[2]; database.HostIP != "127.0.0.1" || database.Variable != "KOOL_DATABASE_PORT" || database.Default != "3306" {
+		t.Errorf("unexpected database published port: %v", database)
+	}
+
+	if mail := ports[3]; mail.Service != "mail" || mail.HostPort != "1025" {
+		t.Errorf("unexpected mail published port: %v", mail)
+	}
+
+	if web := ports[4]; web.HostIP != "127.0.0.1" || web.Variable != "KOOL_WEB_PORT" || web.Default != "443" {
+		t.Errorf("unexpected web published port: %v", web)
+	}
+
+	if _, err = ParsePublishedPorts("\tinvalid"); err == nil {
+		t.Error("expected error parsing invalid content")
+	}
+}
+
+func TestPublishedPortResolve(t *testing.T) {
+	envs := map[string]string{"KOOL_APP_PORT": "8080"}
+	getenv := func(key string) string { return envs[key] }
+
+	port := &PublishedPort{HostPort: "${KOOL_APP_PORT:-80}", Variable: "KOOL_APP_PORT", Default: "80"}
+
+	if resolved := port.Resolve(getenv); resolved != "8080" {
+		t.Errorf("expected to resolve port from environment; got %s", resolved)
+	}
+
+	delete(envs, "KOOL_APP_PORT")
+
+	if resolved := port.Resolve(getenv); resolved != "80" {
+		t.Errorf("expected to resolve default port; got %s", resolved)
+	}
+
+	port = &PublishedPort{HostPort: "6379"}
+
+	if resolved := port.Resolve(getenv); resolved != "6379" {
+		t.Errorf("expected to resolve literal port; got %s", resolved)
+	}
+}
@@ -0,0 +1,27 @@
+package compose
+
+type profilesCompose struct {
+	Services map[string]struct {
+		Profiles []string `yaml:"profiles"`
+	} `yaml:"services"`
+}
+
+// ParseProfiles reads the profiles each service declares on the given
+// docker-compose file content; services without profiles are left out
+func ParseProfiles(content string) (profiles map[string][]string, err error) {
+	var parsed = new(profilesCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	profiles = make(map[string][]string)
+
+	for service, definition := range parsed.Services {
+		if len(definition.Profiles) > 0 {
+			profiles[service] = definition.Profiles
+		}
+	}
+
+	return
+}
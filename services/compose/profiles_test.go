@@ -0,0 +1,43 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProfiles(t *testing.T) {
+	content := `services:
+  app:
+    image: kooldev/php:8.0
+  mailhog:
+    image: mailhog/mailhog
+    profiles: [tools]
+  adminer:
+    image: adminer
+    profiles:
+      - tools
+      - debug
+`
+
+	profiles, err := ParseProfiles(content)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing profiles: %v", err)
+	}
+
+	if len(profiles) != 2 {
+		t.Errorf("expected only the services with profiles, got %v", profiles)
+	}
+
+	if strings.Join(profiles["mailhog"], ",") != "tools" {
+		t.Errorf("unexpected mailhog profiles: %v", profiles["mailhog"])
+	}
+
+	if strings.Join(profiles["adminer"], ",") != "tools,debug" {
+		t.Errorf("unexpected adminer profiles: %v", profiles["adminer"])
+	}
+
+	if _, err = ParseProfiles("services: [invalid"); err == nil {
+		t.Error("expected error parsing invalid content")
+	}
+}
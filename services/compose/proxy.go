@@ -0,0 +1,43 @@
+package compose
+
+import (
+	"sort"
+)
+
+// ProxyPortLabel is the service label telling the kool proxy
+// which container port to route the service domain to
+const ProxyPortLabel = "kool.proxy.port"
+
+// ProxiedService holds a docker-compose service served through the kool proxy
+type ProxiedService struct {
+	Service string
+	Port    string
+}
+
+type proxyCompose struct {
+	Services map[string]struct {
+		Labels interface{} `yaml:"labels"`
+	} `yaml:"services"`
+}
+
+// ParseProxiedServices reads the services labeled to be served through the
+// kool proxy from the given docker-compose file content, sorted by name
+func ParseProxiedServices(content string) (services []ProxiedService, err error) {
+	var parsed = new(proxyCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	for service, definition := range parsed.Services {
+		if port := parseKeyValues(definition.Labels)[ProxyPortLabel]; port != "" {
+			services = append(services, ProxiedService{service, port})
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Service < services[j].Service
+	})
+
+	return
+}
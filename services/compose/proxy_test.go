@@ -0,0 +1,48 @@
+package compose
+
+import (
+	"testing"
+)
+
+func TestParseProxiedServices(t *testing.T) {
+	content := `services:
+  web:
+    image: nginx
+    labels:
+      kool.proxy.port: 80
+  app:
+    image: node
+    labels:
+      - "kool.proxy.port=3000"
+      - "other=label"
+  database:
+    image: mysql
+    labels:
+      other: label
+  cache:
+    image: redis
+`
+
+	services, err := ParseProxiedServices(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(services) != 2 {
+		t.Fatalf("expected 2 proxied services, got %v", services)
+	}
+
+	if services[0].Service != "app" || services[0].Port != "3000" {
+		t.Errorf("unexpected app proxied service: %v", services[0])
+	}
+
+	if services[1].Service != "web" || services[1].Port != "80" {
+		t.Errorf("unexpected web proxied service: %v", services[1])
+	}
+}
+
+func TestParseProxiedServicesError(t *testing.T) {
+	if _, err := ParseProxiedServices("services: ["); err == nil {
+		t.Error("expected error parsing invalid content")
+	}
+}
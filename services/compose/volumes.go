@@ -0,0 +1,84 @@
+package compose
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var projectNameRegex = regexp.MustCompile(`[^-_a-z0-9]`)
+
+// Volume holds a named volume declared on a docker-compose file
+type Volume struct {
+	Key      string
+	Name     string
+	External bool
+}
+
+// HasFixedName tells whether the volume name does
+// not depend on the docker-compose project name
+func (v *Volume) HasFixedName() bool {
+	return v.Name != "" || v.External
+}
+
+// DockerName returns the actual docker volume name within the given project
+func (v *Volume) DockerName(project string) string {
+	if v.Name != "" {
+		return v.Name
+	}
+
+	if v.External {
+		return v.Key
+	}
+
+	return fmt.Sprintf("%s_%s", ProjectName(project), v.Key)
+}
+
+// ProjectName normalizes the given name the same
+// way docker-compose does for project names
+func ProjectName(name string) string {
+	return projectNameRegex.ReplaceAllString(strings.ToLower(name), "")
+}
+
+type volumesCompose struct {
+	Volumes map[string]map[string]interface{} `yaml:"volumes"`
+}
+
+// ParseVolumes reads the named volumes declared on the
+// given docker-compose file content, sorted by their keys
+func ParseVolumes(content string) (volumes []Volume, err error) {
+	var parsed = new(volumesCompose)
+
+	if err = yamlUnmarshalFn([]byte(content), parsed); err != nil {
+		return
+	}
+
+	for key, definition := range parsed.Volumes {
+		var volume = Volume{Key: key}
+
+		if name, ok := definition["name"].(string); ok {
+			volume.Name = name
+		}
+
+		switch external := definition["external"].(type) {
+		case bool:
+			volume.External = external
+		case map[interface{}]interface{}:
+			// legacy syntax: external: {name: some-volume}
+			volume.External = true
+
+			if name, ok := external["name"].(string); ok {
+				volume.Name = name
+			}
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	sort.Slice(volumes, func(i, j int) bool {
+		return volumes[i].Key < volumes[j].Key
+	})
+
+	return
+}
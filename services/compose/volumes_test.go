@@ -0,0 +1,89 @@
+package compose
+
+import (
+	"testing"
+)
+
+func TestParseVolumes(t *testing.T) {
+	content := `services:
+  app:
+    image: kooldev/php:8.0
+volumes:
+  database:
+  cache: {}
+  shared:
+    name: my-shared-data
+  legacy:
+    external:
+      name: old-data
+  external:
+    external: true
+`
+
+	volumes, err := ParseVolumes(content)
+
+	if err != nil {
+		t.Fatalf("unexpected error parsing volumes: %v", err)
+	}
+
+	expected := []Volume{
+		{"cache", "", false},
+		{"database", "", false},
+		{"external", "", true},
+		{"legacy", "old-data", true},
+		{"shared", "my-shared-data", false},
+	}
+
+	if len(volumes) != len(expected) {
+		t.Fatalf("expected %d volumes, got %v", len(expected), volumes)
+	}
+
+	for i := range expected {
+		if volumes[i] != expected[i] {
+			t.Errorf("expected volume %v, got %v", expected[i], volumes[i])
+		}
+	}
+
+	if _, err = ParseVolumes("\tinvalid"); err == nil {
+		t.Error("expected error parsing invalid docker-compose content")
+	}
+}
+
+func TestVolumeDockerName(t *testing.T) {
+	volumes := map[string]Volume{
+		"myproject_database": {Key: "database"},
+		"my-shared-data":     {Key: "shared", Name: "my-shared-data"},
+		"external":           {Key: "external", External: true},
+	}
+
+	for expected, volume := range volumes {
+		if name := volume.DockerName("My.Project"); name != expected {
+			t.Errorf("expected docker volume name %s, got %s", expected, name)
+		}
+	}
+
+	volume := Volume{Key: "database"}
+
+	if volume.HasFixedName() {
+		t.Error("project volume should not have a fixed name")
+	}
+
+	if volume = (Volume{Key: "external", External: true}); !volume.HasFixedName() {
+		t.Error("external volume should have a fixed name")
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	names := map[string]string{
+		"kool":        "kool",
+		"My Project!": "myproject",
+		"feature_x-1": "feature_x-1",
+		"feature/x.y": "featurexy",
+	}
+
+	for name, expected := range names {
+		if got := ProjectName(name); got != expected {
+			t.Errorf("expected project name %s for %s, got %s", expected, name, got)
+		}
+	}
+}
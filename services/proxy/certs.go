@@ -0,0 +1,213 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// caValidity is how long the local certificate authority is valid
+	caValidity = 10 * 365 * 24 * time.Hour
+	// certValidity is how long the domains certificates are valid; browsers
+	// refuse server certificates valid for longer than 825 days
+	certValidity = 825 * 24 * time.Hour
+	// certRenewBefore is how long before expiring the certificates are renewed
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// CA holds the local certificate authority signing the proxy certificates
+type CA struct {
+	CertFile string
+
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// LoadOrCreateCA loads the local certificate authority kept on the given
+// folder, creating it when there is none yet; an existing CA failing to
+// load is an error, as replacing it would void the root the user trusted
+func LoadOrCreateCA(dir string) (ca *CA, err error) {
+	var (
+		certFile = filepath.Join(dir, "ca.pem")
+		keyFile  = filepath.Join(dir, "ca-key.pem")
+		template *x509.Certificate
+		der      []byte
+	)
+
+	ca = &CA{CertFile: certFile}
+
+	if ca.cert, ca.key, err = readKeyPair(certFile, keyFile); err == nil || !os.IsNotExist(err) {
+		return
+	}
+
+	if ca.key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return
+	}
+
+	if template, err = newTemplate(caValidity); err != nil {
+		return
+	}
+
+	template.Subject = pkix.Name{Organization: []string{"kool"}, CommonName: "kool local CA"}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	if der, err = x509.CreateCertificate(rand.Reader, template, template, &ca.key.PublicKey, ca.key); err != nil {
+		return
+	}
+
+	if ca.cert, err = x509.ParseCertificate(der); err != nil {
+		return
+	}
+
+	err = writeKeyPair(certFile, keyFile, der, ca.key)
+	return
+}
+
+// Certificate returns the certificate and key files for the given hosts
+// signed by the CA, issuing a new one when there is none yet, when the
+// hosts changed or when it is about to expire
+func (ca *CA) Certificate(dir, name string, hosts []string) (certFile, keyFile string, err error) {
+	var (
+		cert     *x509.Certificate
+		key      *ecdsa.PrivateKey
+		template *x509.Certificate
+		der      []byte
+	)
+
+	if len(hosts) == 0 {
+		err = errors.New("no hosts to issue a certificate for")
+		return
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+
+	if cert, _, err = readKeyPair(certFile, keyFile); err == nil && ca.issued(cert, hosts) {
+		return
+	}
+
+	if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		return
+	}
+
+	if template, err = newTemplate(certValidity); err != nil {
+		return
+	}
+
+	template.Subject = pkix.Name{Organization: []string{"kool"}, CommonName: hosts[0]}
+	template.DNSNames = hosts
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+
+	if der, err = x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key); err != nil {
+		return
+	}
+
+	err = writeKeyPair(certFile, keyFile, der, key)
+	return
+}
+
+// issued tells whether the certificate was signed by the CA
+// for the given hosts and is not about to expire
+func (ca *CA) issued(cert *x509.Certificate, hosts []string) bool {
+	if cert.CheckSignatureFrom(ca.cert) != nil || time.Now().Add(certRenewBefore).After(cert.NotAfter) {
+		return false
+	}
+
+	if len(cert.DNSNames) != len(hosts) {
+		return false
+	}
+
+	names := append([]string{}, cert.DNSNames...)
+	wanted := append([]string{}, hosts...)
+
+	sort.Strings(names)
+	sort.Strings(wanted)
+
+	for i := range names {
+		if names[i] != wanted[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newTemplate(validity time.Duration) (template *x509.Certificate, err error) {
+	var serial *big.Int
+
+	if serial, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)); err != nil {
+		return
+	}
+
+	template = &x509.Certificate{
+		SerialNumber: serial,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validity),
+	}
+
+	return
+}
+
+func readKeyPair(certFile, keyFile string) (cert *x509.Certificate, key *ecdsa.PrivateKey, err error) {
+	var (
+		content []byte
+		block   *pem.Block
+	)
+
+	if content, err = os.ReadFile(certFile); err != nil {
+		return
+	}
+
+	if block, _ = pem.Decode(content); block == nil {
+		err = errors.New("invalid certificate file " + certFile)
+		return
+	}
+
+	if cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+		return
+	}
+
+	if content, err = os.ReadFile(keyFile); err != nil {
+		return
+	}
+
+	if block, _ = pem.Decode(content); block == nil {
+		err = errors.New("invalid key file " + keyFile)
+		return
+	}
+
+	key, err = x509.ParseECPrivateKey(block.Bytes)
+	return
+}
+
+func writeKeyPair(certFile, keyFile string, der []byte, key *ecdsa.PrivateKey) (err error) {
+	var keyDer []byte
+
+	if err = os.MkdirAll(filepath.Dir(certFile), os.ModePerm); err != nil {
+		return
+	}
+
+	if keyDer, err = x509.MarshalECPrivateKey(key); err != nil {
+		return
+	}
+
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		return
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	return
+}
@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readCertificate(t *testing.T, file string) *x509.Certificate {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		t.Fatalf("invalid certificate file %s", file)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func TestLoadOrCreateCA(t *testing.T) {
+	dir := t.TempDir()
+
+	ca, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ca.CertFile != filepath.Join(dir, "ca.pem") {
+		t.Errorf("unexpected CA certificate file %s", ca.CertFile)
+	}
+
+	cert := readCertificate(t, ca.CertFile)
+	if !cert.IsCA || cert.Subject.CommonName != "kool local CA" {
+		t.Errorf("unexpected CA certificate: %v", cert.Subject)
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, "ca-key.pem")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("CA key should be private; %v", err)
+	}
+
+	loaded, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loaded.cert.Equal(ca.cert) {
+		t.Error("should have loaded the existing CA")
+	}
+}
+
+func TestLoadOrCreateCAInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadOrCreateCA(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(dir, "ca-key.pem")
+
+	if err := os.WriteFile(keyFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadOrCreateCA(dir); err == nil || err.Error() != "invalid key file "+keyFile {
+		t.Errorf("expected error loading an invalid CA, got %v", err)
+	}
+
+	if content, _ := os.ReadFile(keyFile); string(content) != "invalid" {
+		t.Error("should not replace an existing CA failing to load")
+	}
+}
+
+func TestCACertificate(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certsDir = filepath.Join(dir, "certs")
+		hosts    = []string{"app.main.localhost", "*.main.localhost"}
+	)
+
+	ca, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile, err := ca.Certificate(certsDir, "main", hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if certFile != filepath.Join(certsDir, "main.pem") || keyFile != filepath.Join(certsDir, "main-key.pem") {
+		t.Errorf("unexpected certificate files %s and %s", certFile, keyFile)
+	}
+
+	cert := readCertificate(t, certFile)
+
+	if err := cert.CheckSignatureFrom(ca.cert); err != nil {
+		t.Errorf("certificate should be signed by the CA: %v", err)
+	}
+
+	if err := cert.VerifyHostname("app.main.localhost"); err != nil {
+		t.Errorf("certificate should be valid for app.main.localhost: %v", err)
+	}
+
+	// same hosts keep the certificate
+	if _, _, err = ca.Certificate(certsDir, "main", []string{"*.main.localhost", "app.main.localhost"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if again := readCertificate(t, certFile); !again.Equal(cert) {
+		t.Error("should have kept the certificate for the same hosts")
+	}
+
+	// new hosts issue a new certificate
+	if _, _, err = ca.Certificate(certsDir, "main", []string{"web.main.localhost"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if again := readCertificate(t, certFile); again.Equal(cert) || again.DNSNames[0] != "web.main.localhost" {
+		t.Error("should have issued a new certificate for new hosts")
+	}
+
+	// a new CA issues a new certificate
+	other, err := LoadOrCreateCA(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = other.Certificate(certsDir, "main", []string{"web.main.localhost"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := readCertificate(t, certFile).CheckSignatureFrom(other.cert); err != nil {
+		t.Errorf("should have issued a new certificate signed by the new CA: %v", err)
+	}
+
+	if _, _, err = ca.Certificate(certsDir, "main", nil); err == nil {
+		t.Error("expected error issuing a certificate without hosts")
+	}
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Route holds a domain served by the proxy and the
+// containers addresses the requests are forwarded to
+type Route struct {
+	Name    string
+	Host    string
+	Servers []string
+}
+
+// DynamicConfig renders the proxy routing configuration for the routes of
+// a project, served on both HTTP and HTTPS with the given certificate
+func DynamicConfig(routes []Route, certFile, keyFile string) ([]byte, error) {
+	var (
+		routers  = make(map[string]interface{})
+		services = make(map[string]interface{})
+	)
+
+	for _, route := range routes {
+		var (
+			rule    = fmt.Sprintf("Host(`%s`)", route.Host)
+			servers []map[string]string
+		)
+
+		for _, server := range route.Servers {
+			servers = append(servers, map[string]string{"url": server})
+		}
+
+		routers[route.Name] = map[string]interface{}{
+			"rule":        rule,
+			"entryPoints": []string{"websecure"},
+			"service":     route.Name,
+			"tls":         map[string]interface{}{},
+		}
+
+		routers[route.Name+"-http"] = map[string]interface{}{
+			"rule":        rule,
+			"entryPoints": []string{"web"},
+			"service":     route.Name,
+		}
+
+		services[route.Name] = map[string]interface{}{
+			"loadBalancer": map[string]interface{}{"servers": servers},
+		}
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"http": map[string]interface{}{
+			"routers":  routers,
+			"services": services,
+		},
+		"tls": map[string]interface{}{
+			"certificates": []map[string]string{
+				{"certFile": certFile, "keyFile": keyFile},
+			},
+		},
+	})
+}
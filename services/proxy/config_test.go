@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDynamicConfig(t *testing.T) {
+	routes := []Route{
+		{"main-app", "app.main.localhost", []string{"http://main_app_1:80", "http://main_app_2:80"}},
+	}
+
+	config, err := DynamicConfig(routes, "/etc/kool-proxy/certs/main.pem", "/etc/kool-proxy/certs/main-key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `http:
+  routers:
+    main-app:
+      entryPoints:
+      - websecure
+      rule: Host(` + "`app.main.localhost`" + `)
+      service: main-app
+      tls: {}
+    main-app-http:
+      entryPoints:
+      - web
+      rule: Host(` + "`app.main.localhost`" + `)
+      service: main-app
+  services:
+    main-app:
+      loadBalancer:
+        servers:
+        - url: http://main_app_1:80
+        - url: http://main_app_2:80
+tls:
+  certificates:
+  - certFile: /etc/kool-proxy/certs/main.pem
+    keyFile: /etc/kool-proxy/certs/main-key.pem
+`
+
+	if strings.TrimSpace(string(config)) != strings.TrimSpace(expected) {
+		t.Errorf("unexpected dynamic config:\n%s", config)
+	}
+}
@@ -0,0 +1,68 @@
+package tunnel
+
+const (
+	// DefaultExposeImage holds the default image of the expose client
+	DefaultExposeImage = "beyondcodegmbh/expose-server"
+	// DefaultExposeVersion holds the default version of the expose client
+	DefaultExposeVersion = "1.4.1"
+	// DefaultServerHost holds the host of the kool.live expose server
+	DefaultServerHost = "kool.live"
+)
+
+// Expose tunnels through an expose server (https://expose.dev),
+// either the kool.live one or a self-hosted one
+type Expose struct {
+	Image      string
+	Version    string
+	ServerHost string
+	ServerPort string
+	AuthToken  string
+}
+
+func newExpose(getenv func(string) string, serverHost string) *Expose {
+	expose := &Expose{
+		getenv("KOOL_SHARE_IMAGE"),
+		getenv("KOOL_SHARE_VERSION"),
+		getenv("KOOL_SHARE_SERVER_HOST"),
+		getenv("KOOL_SHARE_SERVER_PORT"),
+		getenv("KOOL_SHARE_AUTH_TOKEN"),
+	}
+
+	if expose.Image == "" {
+		expose.Image = DefaultExposeImage
+	}
+
+	if expose.Version == "" {
+		expose.Version = DefaultExposeVersion
+	}
+
+	if expose.ServerHost == "" {
+		expose.ServerHost = serverHost
+	}
+
+	return expose
+}
+
+// Args returns the arguments for running the expose client with the given share settings
+func (e *Expose) Args(share Share) (args []string) {
+	args = []string{e.Image + ":" + e.Version, "share", share.URI, "--server-host", e.ServerHost}
+
+	if e.ServerPort != "" {
+		args = append(args, "--server-port", e.ServerPort)
+	}
+
+	if e.AuthToken != "" {
+		args = append(args, "--auth", e.AuthToken)
+	}
+
+	if share.Subdomain != "" {
+		args = append(args, "--subdomain", share.Subdomain)
+	}
+
+	return
+}
+
+// Host returns the domain the shared URLs are served on
+func (e *Expose) Host() string {
+	return e.ServerHost
+}
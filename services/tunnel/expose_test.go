@@ -0,0 +1,20 @@
+package tunnel
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExposeArgs(t *testing.T) {
+	expose := &Expose{DefaultExposeImage, DefaultExposeVersion, DefaultServerHost, "", ""}
+
+	if args := strings.Join(expose.Args(Share{URI: "app"}), " "); args != "beyondcodegmbh/expose-server:1.4.1 share app --server-host kool.live" {
+		t.Errorf("unexpected args: %s", args)
+	}
+
+	expose = &Expose{"expose", "2.0.0", "tunnel.example.com", "8443", "secret"}
+
+	if args := strings.Join(expose.Args(Share{"app:8080", "review"}), " "); args != "expose:2.0.0 share app:8080 --server-host tunnel.example.com --server-port 8443 --auth secret --subdomain review" {
+		t.Errorf("unexpected args: %s", args)
+	}
+}
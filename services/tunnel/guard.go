@@ -0,0 +1,112 @@
+package tunnel
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// GuardImage holds the image of the sidecar container restricting
+// which client IPs can reach a shared service
+const GuardImage = "nginx:1.21-alpine"
+
+// GuardURI is the address the tunnel client reaches the guard on, as it
+// runs on the guard network namespace (see GuardNetwork)
+const GuardURI = "127.0.0.1"
+
+// GuardNetwork returns the docker network mode for running the tunnel
+// client on the network namespace of the given guard container
+func GuardNetwork(guard string) string {
+	return "container:" + guard
+}
+
+// guardHtpasswdFile is where the basic auth credentials are kept within the guard
+const guardHtpasswdFile = "/etc/nginx/htpasswd"
+
+// GuardConfig builds the nginx configuration proxying to the given service
+// address only the requests from the allowed IPs or CIDR ranges (if any),
+// and asking for the basic auth credentials when auth is set. The client
+// IP is the last one on the X-Forwarded-For header, which the tunnel server
+// appends the visitor address to; the header is only trusted on requests
+// from the loopback, that is from the tunnel client sharing the guard
+// network namespace, so other containers cannot spoof it.
+func GuardConfig(uri string, allowed []string, auth bool) (config string, err error) {
+	var b strings.Builder
+
+	b.WriteString("server {\n")
+	b.WriteString("    listen 80;\n")
+	b.WriteString("    set_real_ip_from 127.0.0.1;\n")
+	b.WriteString("    set_real_ip_from ::1;\n")
+	b.WriteString("    real_ip_header X-Forwarded-For;\n")
+	b.WriteString("    real_ip_recursive off;\n")
+
+	for _, entry := range allowed {
+		if net.ParseIP(entry) == nil {
+			if _, _, err = net.ParseCIDR(entry); err != nil {
+				err = fmt.Errorf("invalid IP address or range '%s'", entry)
+				return
+			}
+		}
+
+		fmt.Fprintf(&b, "    allow %s;\n", entry)
+	}
+
+	if len(allowed) > 0 {
+		b.WriteString("    deny all;\n")
+	}
+
+	if auth {
+		b.WriteString("    auth_basic \"kool share\";\n")
+		fmt.Fprintf(&b, "    auth_basic_user_file %s;\n", guardHtpasswdFile)
+	}
+
+	b.WriteString("\n")
+	b.WriteString("    location / {\n")
+	fmt.Fprintf(&b, "        proxy_pass http://%s;\n", uri)
+	b.WriteString("        proxy_set_header Host $host;\n")
+	b.WriteString("        proxy_set_header X-Forwarded-For $http_x_forwarded_for;\n")
+	b.WriteString("        proxy_set_header X-Forwarded-Proto $http_x_forwarded_proto;\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+
+	config = b.String()
+	return
+}
+
+// GuardArgs returns the arguments for running the guard container (its
+// image and command) with the given nginx configuration and htpasswd
+// entry; both go through the environment, keeping the credentials (which
+// are hashed anyway) off the command line
+func GuardArgs(config, htpasswd string) []string {
+	return []string{
+		"-e", "KOOL_SHARE_GUARD=" + config,
+		"-e", "KOOL_SHARE_HTPASSWD=" + htpasswd,
+		GuardImage,
+		"sh", "-c", `printf '%s' "$KOOL_SHARE_GUARD" > /etc/nginx/conf.d/default.conf && printf '%s\n' "$KOOL_SHARE_HTPASSWD" > ` + guardHtpasswdFile + ` && exec nginx -g 'daemon off;'`,
+	}
+}
+
+// Htpasswd returns the htpasswd entry for the given user:password
+// credentials, with the password salted and hashed ({SSHA})
+func Htpasswd(credentials string) (entry string, err error) {
+	var (
+		parts = strings.SplitN(credentials, ":", 2)
+		salt  = make([]byte, 8)
+	)
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		err = fmt.Errorf("invalid basic auth '%s'; use user:password", credentials)
+		return
+	}
+
+	if _, err = rand.Read(salt); err != nil {
+		return
+	}
+
+	sum := sha1.Sum(append([]byte(parts[1]), salt...))
+	entry = parts[0] + ":{SSHA}" + base64.StdEncoding.EncodeToString(append(sum[:], salt...))
+	return
+}
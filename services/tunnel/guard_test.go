@@ -0,0 +1,105 @@
+package tunnel
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestGuardConfig(t *testing.T) {
+	config, err := GuardConfig("app:8080", []string{"203.0.113.7", "10.0.0.0/8", "2001:db8::/32"}, false)
+
+	if err != nil {
+		t.Fatalf("unexpected error building guard config: %v", err)
+	}
+
+	for _, expected := range []string{
+		"allow 203.0.113.7;\n    allow 10.0.0.0/8;\n    allow 2001:db8::/32;\n    deny all;",
+		"set_real_ip_from 127.0.0.1;\n    set_real_ip_from ::1;\n    real_ip_header X-Forwarded-For;",
+		"real_ip_recursive off;",
+		"proxy_pass http://app:8080;",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("missing '%s' on guard config:\n%s", expected, config)
+		}
+	}
+
+	if strings.Contains(config, "auth_basic") {
+		t.Errorf("should not ask for credentials without basic auth:\n%s", config)
+	}
+
+	if strings.Contains(config, "0.0.0.0/0") || strings.Contains(config, "::/0") {
+		t.Errorf("should only trust X-Forwarded-For from the tunnel client:\n%s", config)
+	}
+
+	if _, err = GuardConfig("app", []string{"10.0.0.0/8", "not-an-ip"}, false); err == nil || err.Error() != "invalid IP address or range 'not-an-ip'" {
+		t.Errorf("expected invalid IP error, got %v", err)
+	}
+
+	if _, err = GuardConfig("app", []string{"10.0.0.0/33"}, false); err == nil {
+		t.Error("expected error on invalid CIDR range")
+	}
+}
+
+func TestGuardConfigBasicAuth(t *testing.T) {
+	config, err := GuardConfig("app", nil, true)
+
+	if err != nil {
+		t.Fatalf("unexpected error building guard config: %v", err)
+	}
+
+	if !strings.Contains(config, "auth_basic \"kool share\";\n    auth_basic_user_file /etc/nginx/htpasswd;") {
+		t.Errorf("missing basic auth on guard config:\n%s", config)
+	}
+
+	if strings.Contains(config, "deny all") {
+		t.Errorf("should not deny all without allowed IPs:\n%s", config)
+	}
+}
+
+func TestGuardArgs(t *testing.T) {
+	args := GuardArgs("server {}", "user:{SSHA}hash")
+
+	if len(args) != 8 || args[1] != "KOOL_SHARE_GUARD=server {}" || args[3] != "KOOL_SHARE_HTPASSWD=user:{SSHA}hash" || args[4] != GuardImage || args[5] != "sh" {
+		t.Errorf("unexpected guard args: %v", args)
+	}
+
+	if !strings.Contains(args[7], "> /etc/nginx/htpasswd") {
+		t.Errorf("unexpected guard command: %s", args[7])
+	}
+}
+
+func TestHtpasswd(t *testing.T) {
+	entry, err := Htpasswd("user:pa:ss")
+
+	if err != nil {
+		t.Fatalf("unexpected error hashing credentials: %v", err)
+	}
+
+	if !strings.HasPrefix(entry, "user:{SSHA}") {
+		t.Fatalf("unexpected htpasswd entry: %s", entry)
+	}
+
+	// {SSHA} is base64(sha1(password + salt) + salt), as read by nginx
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(entry, "user:{SSHA}"))
+
+	if err != nil || len(raw) != sha1.Size+8 {
+		t.Fatalf("unexpected hash on htpasswd entry %s: %v", entry, err)
+	}
+
+	if sum := sha1.Sum(append([]byte("pa:ss"), raw[sha1.Size:]...)); !bytes.Equal(sum[:], raw[:sha1.Size]) {
+		t.Errorf("htpasswd entry does not match the password: %s", entry)
+	}
+
+	if other, _ := Htpasswd("user:pa:ss"); other == entry {
+		t.Error("expected a random salt on each entry")
+	}
+
+	for _, bad := range []string{"user", ":secret", "user:"} {
+		if _, err = Htpasswd(bad); err == nil || err.Error() != "invalid basic auth '"+bad+"'; use user:password" {
+			t.Errorf("expected invalid basic auth error for '%s', got %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package tunnel
+
+import (
+	"fmt"
+)
+
+const (
+	// ProviderKool tunnels through the kool.live hosted expose server
+	ProviderKool = "kool"
+	// ProviderExpose tunnels through a self-hosted expose server
+	ProviderExpose = "expose"
+)
+
+// Share holds the settings of a tunnel sharing a local service
+type Share struct {
+	// URI is the address of the shared service on the global network
+	URI string
+	// Subdomain is the requested subdomain for the public URL, if any
+	Subdomain string
+}
+
+// Provider defines a tunnel provider for sharing a
+// local service on the Internet
+type Provider interface {
+	// Args returns the arguments for running the tunnel client container
+	// (its image and command) with the given share settings
+	Args(share Share) []string
+	// Host returns the domain the shared URLs are served on
+	Host() string
+}
+
+// NewProvider initializes the tunnel provider with the given name, configured
+// by the KOOL_SHARE_* variables looked up with the given function
+func NewProvider(name string, getenv func(string) string) (provider Provider, err error) {
+	switch name {
+	case "", ProviderKool:
+		provider = newExpose(getenv, DefaultServerHost)
+	case ProviderExpose:
+		if getenv("KOOL_SHARE_SERVER_HOST") == "" {
+			err = fmt.Errorf("KOOL_SHARE_SERVER_HOST must be set for the %s tunnel provider", ProviderExpose)
+			return
+		}
+
+		provider = newExpose(getenv, "")
+	default:
+		err = fmt.Errorf("unknown tunnel provider '%s'; use '%s' or '%s'", name, ProviderKool, ProviderExpose)
+	}
+
+	return
+}
@@ -0,0 +1,55 @@
+package tunnel
+
+import (
+	"strings"
+	"testing"
+)
+
+func getenvFrom(envs map[string]string) func(string) string {
+	return func(key string) string {
+		return envs[key]
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range []string{"", ProviderKool} {
+		provider, err := NewProvider(name, getenvFrom(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expose, ok := provider.(*Expose)
+		if !ok {
+			t.Fatalf("unexpected provider for '%s': %T", name, provider)
+		}
+
+		if expose.Image != DefaultExposeImage || expose.Version != DefaultExposeVersion || expose.Host() != DefaultServerHost {
+			t.Errorf("unexpected defaults for '%s': %+v", name, expose)
+		}
+	}
+
+	provider, err := NewProvider(ProviderExpose, getenvFrom(map[string]string{
+		"KOOL_SHARE_SERVER_HOST": "tunnel.example.com",
+		"KOOL_SHARE_SERVER_PORT": "8443",
+		"KOOL_SHARE_AUTH_TOKEN":  "secret",
+		"KOOL_SHARE_IMAGE":       "registry.example.com/expose",
+		"KOOL_SHARE_VERSION":     "2.0.0",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expose := provider.(*Expose); expose.Host() != "tunnel.example.com" || expose.ServerPort != "8443" || expose.AuthToken != "secret" || expose.Image != "registry.example.com/expose" || expose.Version != "2.0.0" {
+		t.Errorf("unexpected self-hosted expose provider: %+v", expose)
+	}
+}
+
+func TestNewProviderErrors(t *testing.T) {
+	if _, err := NewProvider(ProviderExpose, getenvFrom(nil)); err == nil || !strings.Contains(err.Error(), "KOOL_SHARE_SERVER_HOST must be set") {
+		t.Errorf("expected missing server host error, got %v", err)
+	}
+
+	if _, err := NewProvider("ngrok", getenvFrom(nil)); err == nil || !strings.Contains(err.Error(), "unknown tunnel provider 'ngrok'") {
+		t.Errorf("expected unknown provider error, got %v", err)
+	}
+}